@@ -37,6 +37,7 @@ struct Assertion {
     std::vector<MachineEmission<Value>> logs;
     std::vector<MachineEmission<Value>> debug_prints;
     std::optional<uint256_t> sideload_block_number;
+    std::optional<ExecutionProfile> profile;
 };
 
 class MachineExecutionConfig {
@@ -49,6 +50,7 @@ class MachineExecutionConfig {
     bool stop_on_breakpoint{false};
     std::optional<uint256_t> stop_after_log_count;
     bool trace{false};
+    bool profile{false};
 
     MachineExecutionConfig() = default;
 
@@ -91,6 +93,11 @@ class Machine {
     virtual void abort();
     virtual bool isAborted();
 
+    // fork returns a copy of the machine which can be run without affecting
+    // this one. Unlike the copy constructor it doesn't copy the assertion
+    // context, and code segments the fork creates stay private to it.
+    std::unique_ptr<Machine> fork() const;
+
     Assertion run(
         uint32_t yield_instruction_count = BASE_YIELD_INSTRUCTION_COUNT);
 
@@ -20,6 +20,7 @@
 #include <avm/inboxmessage.hpp>
 #include <avm/machinestate/blockreason.hpp>
 #include <avm/machinestate/datastack.hpp>
+#include <avm/machinestate/profile.hpp>
 #include <avm/machinestate/status.hpp>
 #include <avm_values/valueloader.hpp>
 
@@ -27,6 +28,7 @@
 #include <avm_values/vmValueParser.hpp>
 
 #include <deque>
+#include <functional>
 #include <memory>
 #include <vector>
 
@@ -53,6 +55,15 @@ struct MachineEmission {
     uint256_t log_count;
 };
 
+struct ExecutionStep {
+    CodePointRef pc;
+    OpCode opcode;
+    // The top of the data stack when the instruction started, after its
+    // immediate was pushed
+    std::optional<Value> stack_top;
+    uint64_t gas_used;
+};
+
 struct AssertionContext {
     std::vector<MachineMessage> inbox_messages;
 
@@ -66,6 +77,11 @@ struct AssertionContext {
     bool go_over_gas{false};
     bool first_instruction{true};
     std::optional<uint256_t> stop_after_log_count;
+    // Only set if profiling was requested, since recording every step is
+    // expensive
+    std::optional<ExecutionProfile> profile;
+    // Called after every instruction which isn't blocked, if set
+    std::function<void(const ExecutionStep&)> step_hook;
 
    private:
     size_t inbox_messages_consumed{0};
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+#ifndef profile_hpp
+#define profile_hpp
+
+#include <avm_values/codepointstub.hpp>
+#include <avm_values/opcodes.hpp>
+
+#include <array>
+#include <map>
+#include <utility>
+#include <vector>
+
+struct ExecutionStats {
+    uint64_t count{0};
+    uint64_t gas{0};
+
+    void add(uint64_t gas_used) {
+        count++;
+        gas += gas_used;
+    }
+};
+
+// ExecutionProfile tracks how many times each opcode and each codepoint was
+// executed and how much gas they used in total
+struct ExecutionProfile {
+    std::array<ExecutionStats, 256> opcodes{};
+    std::map<std::pair<uint64_t, uint64_t>, ExecutionStats> codepoints;
+
+    void record(OpCode opcode, const CodePointRef& pc, uint64_t gas_used) {
+        opcodes[static_cast<size_t>(opcode)].add(gas_used);
+        codepoints[{pc.segment, pc.pc}].add(gas_used);
+    }
+
+    // merge adds the counts and gas of other to this profile
+    void merge(const ExecutionProfile& other);
+
+    void marshal(std::vector<unsigned char>& buf) const;
+};
+
+#endif /* profile_hpp */
@@ -18,6 +18,7 @@
 
 #include <avm/inboxmessage.hpp>
 #include <avm/machine.hpp>
+#include <avm_values/code.hpp>
 #include <avm_values/opcodes.hpp>
 
 std::ostream& operator<<(std::ostream& os, const Machine& val) {
@@ -64,6 +65,22 @@ bool Machine::isAborted() {
     return is_aborted.load();
 }
 
+std::unique_ptr<Machine> Machine::fork() const {
+    MachineState state{machine_state.output,
+                       machine_state.pc,
+                       std::make_shared<RunningCode>(machine_state.code),
+                       machine_state.value_loader,
+                       machine_state.registerVal,
+                       machine_state.static_val,
+                       machine_state.stack,
+                       machine_state.auxstack,
+                       machine_state.arb_gas_remaining,
+                       machine_state.state,
+                       machine_state.errpc,
+                       machine_state.lazy_loaded};
+    return std::make_unique<Machine>(std::move(state));
+}
+
 Assertion Machine::run(uint32_t yield_instruction_count) {
     uint256_t start_steps = machine_state.output.total_steps;
     uint256_t start_gas = machine_state.output.arb_gas_used;
@@ -126,5 +143,6 @@ Assertion Machine::run(uint32_t yield_instruction_count) {
             std::move(machine_state.context.sends),
             std::move(machine_state.context.logs),
             std::move(machine_state.context.debug_prints),
-            sideload_block_number};
+            sideload_block_number,
+            std::move(machine_state.context.profile)};
 }
@@ -38,7 +38,11 @@ AssertionContext::AssertionContext(MachineExecutionConfig config)
       max_gas(config.max_gas),
       go_over_gas(config.go_over_gas),
       stop_after_log_count(config.stop_after_log_count),
-      inbox_messages_consumed(0) {}
+      inbox_messages_consumed(0) {
+    if (config.profile) {
+        profile = ExecutionProfile{};
+    }
+}
 
 MachineStateKeys::MachineStateKeys(const MachineState& machine)
     : output(machine.output),
@@ -479,6 +483,9 @@ BlockReason MachineState::runOne() {
     }
 
     auto& op = loadCurrentOperation();
+    auto start_pc = pc;
+    auto start_opcode = op.opcode;
+    auto start_gas_used = output.arb_gas_used;
 
     static const auto error_gas_cost =
         instructionGasCosts()[static_cast<size_t>(OpCode::ERROR)];
@@ -489,6 +496,11 @@ BlockReason MachineState::runOne() {
         stack.push(std::move(imm));
     }
 
+    std::optional<Value> start_stack_top;
+    if (context.step_hook && stack.stacksize() > 0) {
+        start_stack_top = stack[0];
+    }
+
     // save stack size for stack cleanup in case of error
     uint64_t start_stack_size = stack.stacksize();
     uint64_t start_auxstack_size = auxstack.stacksize();
@@ -562,6 +574,15 @@ BlockReason MachineState::runOne() {
 
     if (std::holds_alternative<NotBlocked>(blockReason)) {
         output.total_steps += 1;
+        auto step_gas_used =
+            intx::narrow_cast<uint64_t>(output.arb_gas_used - start_gas_used);
+        if (context.profile) {
+            context.profile->record(start_opcode, start_pc, step_gas_used);
+        }
+        if (context.step_hook) {
+            context.step_hook({start_pc, start_opcode,
+                               std::move(start_stack_top), step_gas_used});
+        }
     }
 
     if (state == Status::Error) {
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+#include <avm/machinestate/profile.hpp>
+
+#include <avm_values/value.hpp>
+
+void ExecutionProfile::merge(const ExecutionProfile& other) {
+    for (size_t i = 0; i < opcodes.size(); i++) {
+        opcodes[i].count += other.opcodes[i].count;
+        opcodes[i].gas += other.opcodes[i].gas;
+    }
+    for (const auto& item : other.codepoints) {
+        auto& stats = codepoints[item.first];
+        stats.count += item.second.count;
+        stats.gas += item.second.gas;
+    }
+}
+
+void ExecutionProfile::marshal(std::vector<unsigned char>& buf) const {
+    uint64_t opcode_count = 0;
+    for (const auto& stats : opcodes) {
+        if (stats.count > 0) {
+            opcode_count++;
+        }
+    }
+    marshal_uint64_t(opcode_count, buf);
+    for (size_t i = 0; i < opcodes.size(); i++) {
+        if (opcodes[i].count == 0) {
+            continue;
+        }
+        buf.push_back(static_cast<unsigned char>(i));
+        marshal_uint64_t(opcodes[i].count, buf);
+        marshal_uint64_t(opcodes[i].gas, buf);
+    }
+
+    marshal_uint64_t(codepoints.size(), buf);
+    for (const auto& item : codepoints) {
+        marshal_uint64_t(item.first.first, buf);
+        marshal_uint64_t(item.first.second, buf);
+        marshal_uint64_t(item.second.count, buf);
+        marshal_uint64_t(item.second.gas, buf);
+    }
+}
@@ -14,6 +14,9 @@
  * limitations under the License.
  */
 
+// Code generated by packages/arb-util/value/gentypecodes.go from
+// packages/arb-util/value/encoding.json. DO NOT EDIT.
+
 #ifndef valuetype_h
 #define valuetype_h
 
@@ -21,10 +24,10 @@
 // All types declared with types creater than 11 are used for the internal
 // marshalling format Used to pass values between the AVM and the validator
 enum ValueTypes {
-    NUM,
-    CODEPT,
-    HASH_PRE_IMAGE,
-    TUPLE,
+    NUM = 0,
+    CODEPT = 1,
+    HASH_PRE_IMAGE = 2,
+    TUPLE = 3,
     BUFFER = 12,
     CODE_POINT_STUB = 13
 };
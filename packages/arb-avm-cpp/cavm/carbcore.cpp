@@ -597,6 +597,95 @@ void arbCoreUpdateCheckpointPruningGas(CArbCore* arbcore_ptr,
     arbCore->updateCheckpointPruningGas(gas);
 }
 
+CCheckpointPruningStats arbCoreGetCheckpointPruningStats(
+    CArbCore* arbcore_ptr) {
+    auto arbCore = static_cast<ArbCore*>(arbcore_ptr);
+    try {
+        return {arbCore->prunedCheckpointCount(),
+                arbCore->prunedCheckpointBytes(),
+                arbCore->getDataStorage()->totalSstFilesSize()};
+    } catch (const DataStorage::shutting_down_exception&) {
+        return {arbCore->prunedCheckpointCount(),
+                arbCore->prunedCheckpointBytes(), 0};
+    }
+}
+
+CCheckpointStats arbCoreGetCheckpointStats(CArbCore* arbcore_ptr) {
+    auto arbCore = static_cast<ArbCore*>(arbcore_ptr);
+    auto stats = arbCore->checkpointStats();
+    CCheckpointStats result{stats.saved_count,
+                            stats.saved_bytes,
+                            stats.save_nanoseconds,
+                            stats.last_save_nanoseconds,
+                            stats.last_restore_nanoseconds,
+                            0,
+                            0,
+                            0};
+    try {
+        auto storage = arbCore->getDataStorage();
+        result.machine_size =
+            storage->sstFilesSize(DataStorage::STATE_COLUMN) +
+            storage->sstFilesSize(DataStorage::REFCOUNTED_COLUMN);
+        result.checkpoint_size =
+            storage->sstFilesSize(DataStorage::CHECKPOINT_COLUMN);
+        result.inbox_size =
+            storage->sstFilesSize(DataStorage::DELAYEDMESSAGE_COLUMN) +
+            storage->sstFilesSize(DataStorage::SEQUENCERBATCHITEM_COLUMN) +
+            storage->sstFilesSize(DataStorage::SEQUENCERBATCH_COLUMN);
+    } catch (const DataStorage::shutting_down_exception&) {
+        // Sizes are left as zero while shutting down
+    }
+    return result;
+}
+
+CCheckpointVerification arbCoreVerifyCheckpoints(CArbCore* arbcore_ptr) {
+    auto arbCore = static_cast<ArbCore*>(arbcore_ptr);
+    try {
+        auto result = arbCore->verifyCheckpoints();
+        return {result.first, result.second, true};
+    } catch (const std::exception& e) {
+        std::cerr << "Exception while verifying checkpoints: " << e.what()
+                  << std::endl;
+        return {0, 0, false};
+    }
+}
+
+ByteSliceArrayResult arbCoreGetCheckpointSummaries(CArbCore* arbcore_ptr,
+                                                   const void* from_gas_ptr,
+                                                   uint64_t max_count) {
+    auto arbCore = static_cast<ArbCore*>(arbcore_ptr);
+    try {
+        auto summaries = arbCore->checkpointSummaries(
+            receiveUint256(from_gas_ptr), max_count);
+        if (!summaries.status.ok()) {
+            return {{}, false};
+        }
+        // Each summary is the gas used, L2 block number, messages read,
+        // send count, log count, and a flag byte followed by the machine
+        // hash if the checkpoint kept its machine
+        std::vector<std::vector<unsigned char>> data;
+        for (const auto& summary : summaries.data) {
+            std::vector<unsigned char> marshalled;
+            marshal_uint256_t(summary.output.arb_gas_used, marshalled);
+            marshal_uint256_t(summary.output.l2_block_number, marshalled);
+            marshal_uint256_t(summary.output.fully_processed_inbox.count,
+                              marshalled);
+            marshal_uint256_t(summary.output.send_count, marshalled);
+            marshal_uint256_t(summary.output.log_count, marshalled);
+            marshalled.push_back(summary.machine_hash.has_value() ? 1 : 0);
+            if (summary.machine_hash.has_value()) {
+                marshal_uint256_t(*summary.machine_hash, marshalled);
+            }
+            data.push_back(std::move(marshalled));
+        }
+        return {returnCharVectorVector(data), true};
+    } catch (const std::exception& e) {
+        std::cerr << "Exception while reading checkpoints: " << e.what()
+                  << std::endl;
+        return {{}, false};
+    }
+}
+
 CMachine* arbCoreTakeMachine(CArbCore* arbcore_ptr,
                              CExecutionCursor* execution_cursor_ptr) {
     auto arbCore = static_cast<ArbCore*>(arbcore_ptr);
@@ -610,6 +699,22 @@ void arbCorePrintCoreThreadBacktrace(CArbCore* arbcore_ptr) {
     arb_core->printCoreThreadBacktrace();
 }
 
+void arbCoreSetProfiling(CArbCore* arbcore_ptr, int enabled) {
+    auto arb_core = static_cast<ArbCore*>(arbcore_ptr);
+    arb_core->setProfiling(enabled != 0);
+}
+
+ByteSliceResult arbCoreGetProfile(CArbCore* arbcore_ptr) {
+    auto arb_core = static_cast<ArbCore*>(arbcore_ptr);
+    auto profile = arb_core->getProfile();
+    if (!profile) {
+        return {{}, false};
+    }
+    std::vector<unsigned char> data;
+    profile->marshal(data);
+    return {returnCharVector(data), true};
+}
+
 CExecutionCursorResult arbCoreGetExecutionCursorAtEndOfBlock(
     CArbCore* arbcore_ptr,
     uint64_t block_number,
@@ -123,9 +123,19 @@ CExecutionCursorResult arbCoreGetExecutionCursorAtEndOfBlock(
 
 void arbCoreUpdateCheckpointPruningGas(CArbCore* arbcore_ptr,
                                        const void* gas_ptr);
+CCheckpointPruningStats arbCoreGetCheckpointPruningStats(
+    CArbCore* arbcore_ptr);
+CCheckpointStats arbCoreGetCheckpointStats(CArbCore* arbcore_ptr);
+CCheckpointVerification arbCoreVerifyCheckpoints(CArbCore* arbcore_ptr);
+ByteSliceArrayResult arbCoreGetCheckpointSummaries(CArbCore* arbcore_ptr,
+                                                   const void* from_gas_ptr,
+                                                   uint64_t max_count);
 
 void arbCorePrintCoreThreadBacktrace(CArbCore* arbcore_ptr);
 
+void arbCoreSetProfiling(CArbCore* arbcore_ptr, int enabled);
+ByteSliceResult arbCoreGetProfile(CArbCore* arbcore_ptr);
+
 #ifdef __cplusplus
 }
 #endif
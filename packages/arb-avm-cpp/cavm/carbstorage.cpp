@@ -25,6 +25,8 @@
 #include <avm/machine.hpp>
 #include <avm_values/value.hpp>
 
+#include <algorithm>
+#include <array>
 #include <iostream>
 #include <string>
 
@@ -65,6 +67,28 @@ CArbStorage* createArbStorage(const char* db_path,
     coreConfig.debug_timing = arb_core_config.debug_timing;
     coreConfig.database_save_interval = arb_core_config.database_save_interval;
     coreConfig.database_save_path = string_save_rocksdb_path;
+    coreConfig.database_save_incremental =
+        arb_core_config.database_save_incremental;
+    coreConfig.database_save_max_backups =
+        arb_core_config.database_save_max_backups;
+    coreConfig.database_save_queue_size =
+        arb_core_config.database_save_queue_size;
+    coreConfig.database_compression =
+        std::string(arb_core_config.database_compression);
+    if (arb_core_config.checkpoint_encryption_key_length != 0) {
+        std::array<unsigned char, 32> key{};
+        if (static_cast<size_t>(
+                arb_core_config.checkpoint_encryption_key_length) !=
+            key.size()) {
+            std::cerr << "Invalid checkpoint encryption key length"
+                      << std::endl;
+            return nullptr;
+        }
+        auto key_ptr = reinterpret_cast<const unsigned char*>(
+            arb_core_config.checkpoint_encryption_key);
+        std::copy(key_ptr, key_ptr + key.size(), key.begin());
+        coreConfig.checkpoint_encryption_key = key;
+    }
     coreConfig.lazy_load_core_machine = arb_core_config.lazy_load_core_machine;
     coreConfig.lazy_load_archive_queries =
         arb_core_config.lazy_load_archive_queries;
@@ -74,6 +98,10 @@ CArbStorage* createArbStorage(const char* db_path,
         arb_core_config.checkpoint_pruning_mode;
     coreConfig.checkpoint_max_to_prune =
         arb_core_config.checkpoint_max_to_prune;
+    coreConfig.checkpoint_pruning_age_seconds =
+        arb_core_config.checkpoint_pruning_age_seconds;
+    coreConfig.checkpoint_retain_interval =
+        arb_core_config.checkpoint_retain_interval;
     coreConfig.database_compact = arb_core_config.database_compact;
     coreConfig.database_save_on_startup =
         arb_core_config.database_save_on_startup;
@@ -191,3 +219,13 @@ CAggregatorStore* createAggregatorStore(CArbStorage* storage_ptr) {
     auto storage = static_cast<ArbStorage*>(storage_ptr);
     return storage->getAggregatorStore().release();
 }
+
+int restoreArbStorageBackup(const char* backup_path, const char* db_path) {
+    auto status = restoreRocksdbBackup(backup_path, db_path);
+    if (!status.ok()) {
+        std::cerr << "Error restoring database backup: " << status.ToString()
+                  << std::endl;
+        return false;
+    }
+    return true;
+}
@@ -50,9 +50,17 @@ typedef struct {
     int32_t checkpoint_prune_on_startup;
     PruningMode checkpoint_pruning_mode;
     int32_t checkpoint_max_to_prune;
+    int32_t checkpoint_pruning_age_seconds;
+    int32_t checkpoint_retain_interval;
     int32_t database_compact;
     int32_t database_save_interval;
     const char* database_save_path;
+    int32_t database_save_incremental;
+    int32_t database_save_max_backups;
+    int32_t database_save_queue_size;
+    const char* database_compression;
+    const void* checkpoint_encryption_key;
+    int32_t checkpoint_encryption_key_length;
     int32_t database_save_on_startup;
     int32_t database_exit_after;
     int32_t test_reorg_to_l1_block;
@@ -74,6 +82,7 @@ int arbStorageInitialized(CArbStorage* storage_ptr);
 void destroyArbStorage(CArbStorage* storage);
 int closeArbStorage(CArbStorage* storage_ptr);
 int cleanupValidator(CArbStorage* storage_ptr);
+int restoreArbStorageBackup(const char* backup_path, const char* db_path);
 
 CArbCore* createArbCore(CArbStorage* storage_ptr);
 CAggregatorStore* createAggregatorStore(CArbStorage* storage_ptr);
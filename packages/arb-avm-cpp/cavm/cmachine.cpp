@@ -22,6 +22,7 @@
 #include <data_storage/value/machine.hpp>
 
 #include <iostream>
+#include <optional>
 #include <sstream>
 
 typedef struct {
@@ -69,10 +70,7 @@ int machineHash(CMachine* m, void* ret) {
 void* machineClone(CMachine* m) {
     assert(m);
     auto mach = static_cast<Machine*>(m);
-    auto cloneMach = new Machine(*mach);
-    cloneMach->machine_state.code =
-        std::make_shared<RunningCode>(cloneMach->machine_state.code);
-    return static_cast<void*>(cloneMach);
+    return static_cast<void*>(mach->fork().release());
 }
 
 char* machineInfo(CMachine* m) {
@@ -215,8 +213,80 @@ void machineExecutionConfigSetTrace(CMachineExecutionConfig* c, int trace) {
     config->trace = trace != 0;
 }
 
+void machineExecutionConfigSetProfile(CMachineExecutionConfig* c,
+                                      int profile) {
+    assert(c);
+    auto config = static_cast<MachineExecutionConfig*>(c);
+    config->profile = profile != 0;
+}
+
+namespace {
+enum StackTopType : unsigned char {
+    STACK_TOP_EMPTY = 0,
+    STACK_TOP_INT = 1,
+    STACK_TOP_HASH = 2
+};
+
+void marshalExecutionStep(const ExecutionStep& step,
+                          std::vector<unsigned char>& buf) {
+    step.pc.marshal(buf);
+    buf.push_back(static_cast<unsigned char>(step.opcode));
+    marshal_uint64_t(step.gas_used, buf);
+    if (!step.stack_top) {
+        buf.push_back(STACK_TOP_EMPTY);
+    } else if (auto val = get_if<uint256_t>(&*step.stack_top)) {
+        buf.push_back(STACK_TOP_INT);
+        marshal_uint256_t(*val, buf);
+    } else {
+        // Other values can be arbitrarily large, so only their hash is sent
+        buf.push_back(STACK_TOP_HASH);
+        marshal_uint256_t(hash_value(*step.stack_top), buf);
+    }
+}
+
+// Steps are passed on in chunks of this many so that tracing a long
+// assertion doesn't hold all of its steps in memory
+constexpr uint64_t step_trace_chunk_size = 1024;
+
+class StepStreamer {
+    StepTraceCallback callback;
+    uint64_t tracer;
+    std::vector<unsigned char> data;
+    uint64_t count{0};
+
+   public:
+    StepStreamer(StepTraceCallback callback_, uint64_t tracer_)
+        : callback(callback_), tracer(tracer_) {}
+
+    void add(const ExecutionStep& step) {
+        marshalExecutionStep(step, data);
+        count++;
+        if (count == step_trace_chunk_size) {
+            flush();
+        }
+    }
+
+    void flush() {
+        if (count == 0) {
+            return;
+        }
+        callback(tracer, data.data(), static_cast<int>(data.size()), count);
+        data.clear();
+        count = 0;
+    }
+};
+}  // namespace
+
 RawAssertionResult executeAssertion(CMachine* m,
                                     const CMachineExecutionConfig* c) {
+    return executeAssertionWithStepTracer(m, c, nullptr, 0);
+}
+
+RawAssertionResult executeAssertionWithStepTracer(
+    CMachine* m,
+    const CMachineExecutionConfig* c,
+    StepTraceCallback callback,
+    uint64_t tracer) {
     assert(m);
     assert(c);
     auto mach = static_cast<Machine*>(m);
@@ -226,7 +296,18 @@ RawAssertionResult executeAssertion(CMachine* m,
         mach->machine_state.context = AssertionContext{*config};
         mach->machine_state.context.max_gas +=
             mach->machine_state.output.arb_gas_used;
+        std::optional<StepStreamer> streamer;
+        if (callback != nullptr) {
+            streamer.emplace(callback, tracer);
+            mach->machine_state.context.step_hook =
+                [&](const ExecutionStep& step) { streamer->add(step); };
+        }
         Assertion assertion = mach->run();
+        // The hook refers to locals, so it mustn't outlive this call
+        mach->machine_state.context.step_hook = nullptr;
+        if (streamer) {
+            streamer->flush();
+        }
         if (mach->isAborted()) {
             return {makeEmptyAssertion(), false};
         }
@@ -254,6 +335,11 @@ RawAssertionResult executeAssertion(CMachine* m,
             }
         }
 
+        std::vector<unsigned char> profileData;
+        if (assertion.profile) {
+            assertion.profile->marshal(profileData);
+        }
+
         // TODO extend usage of uint256
         return {
             {intx::narrow_cast<uint64_t>(assertion.inbox_messages_consumed),
@@ -262,11 +348,14 @@ RawAssertionResult executeAssertion(CMachine* m,
              returnCharVector(logData), static_cast<int>(assertion.logs.size()),
              returnCharVector(debugPrintData), debugPrintDataCount,
              intx::narrow_cast<uint64_t>(assertion.step_count),
-             intx::narrow_cast<uint64_t>(assertion.gas_count)},
+             intx::narrow_cast<uint64_t>(assertion.gas_count),
+             returnCharVector(profileData)},
             false};
     } catch (const DataStorage::shutting_down_exception& e) {
+        mach->machine_state.context.step_hook = nullptr;
         return {makeEmptyAssertion(), true};
     } catch (const std::exception& e) {
+        mach->machine_state.context.step_hook = nullptr;
         std::cerr << "Failed to make assertion " << e.what() << "\n";
         return {makeEmptyAssertion(), false};
     }
@@ -68,6 +68,20 @@ CBlockReason machineIsBlocked(CMachine* m, int newMessages);
 RawAssertionResult executeAssertion(CMachine* m,
                                     const CMachineExecutionConfig* c);
 
+// Called with chunks of marshalled steps while an assertion is executing
+typedef void (*StepTraceCallback)(uint64_t tracer,
+                                  void* data,
+                                  int length,
+                                  uint64_t count);
+
+// Like executeAssertion, but passes every step executed to callback, along
+// with tracer, as it goes
+RawAssertionResult executeAssertionWithStepTracer(
+    CMachine* m,
+    const CMachineExecutionConfig* c,
+    StepTraceCallback callback,
+    uint64_t tracer);
+
 COneStepProof machineMarshallForProof(CMachine* m);
 
 ByteSlice machineMarshallState(CMachine* m);
@@ -92,6 +106,9 @@ void machineExecutionConfigSetStopOnBreakpoint(CMachineExecutionConfig* c,
                                                int stop_on_breakpoint);
 void machineExecutionConfigSetTrace(CMachineExecutionConfig* c, int trace);
 
+void machineExecutionConfigSetProfile(CMachineExecutionConfig* c,
+                                      int profile);
+
 int dumpRetryables(CArbCore* a, CMachine* m, const char* filename);
 
 int dumpAccounts(CArbCore* a, CMachine* m, const char* filename);
@@ -91,6 +91,7 @@ typedef struct {
     int debug_print_count;
     uint64_t num_steps;
     uint64_t num_gas;
+    ByteSlice profile;
 } RawAssertion;
 
 typedef struct {
@@ -98,6 +99,29 @@ typedef struct {
     int slow_error;
 } CMachineResult;
 
+typedef struct {
+    uint64_t pruned_checkpoints;
+    uint64_t pruned_checkpoint_bytes;
+    uint64_t database_size;
+} CCheckpointPruningStats;
+
+typedef struct {
+    uint64_t saved_checkpoints;
+    uint64_t saved_checkpoint_bytes;
+    uint64_t save_nanoseconds;
+    uint64_t last_save_nanoseconds;
+    uint64_t last_restore_nanoseconds;
+    uint64_t machine_size;
+    uint64_t checkpoint_size;
+    uint64_t inbox_size;
+} CCheckpointStats;
+
+typedef struct {
+    uint64_t checked;
+    uint64_t corrupt;
+    int found;
+} CCheckpointVerification;
+
 typedef struct {
     void* execution_cursor;
     int slow_error;
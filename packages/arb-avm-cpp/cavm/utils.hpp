@@ -133,7 +133,7 @@ inline RawAssertion makeEmptyAssertion() {
             0, returnCharVector(std::vector<char>{}),
             0, returnCharVector(std::vector<char>{}),
             0, 0,
-            0};
+            0, returnCharVector(std::vector<char>{})};
 }
 
 inline Tuple getTuple(void* data) {
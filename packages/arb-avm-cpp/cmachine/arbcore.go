@@ -30,6 +30,7 @@ import (
 	"math/big"
 	"path/filepath"
 	"runtime"
+	"time"
 	"unsafe"
 
 	"github.com/ethereum/go-ethereum/common/math"
@@ -99,6 +100,20 @@ func (ac *ArbCore) PrintCoreThreadBacktrace() {
 	C.arbCorePrintCoreThreadBacktrace(ac.c)
 }
 
+func (ac *ArbCore) SetProfiling(enabled bool) {
+	defer runtime.KeepAlive(ac)
+	C.arbCoreSetProfiling(ac.c, boolToCInt(enabled))
+}
+
+func (ac *ArbCore) Profile() (*machine.Profile, error) {
+	defer runtime.KeepAlive(ac)
+	res := C.arbCoreGetProfile(ac.c)
+	if res.found == 0 {
+		return nil, nil
+	}
+	return machine.NewProfileFromReader(bytes.NewReader(receiveByteSlice(res.slice)))
+}
+
 // Note: the slices field of the returned struct needs manually freed by C.free
 func sequencerBatchItemsToByteSliceArray(batchItems []inbox.SequencerBatchItem) C.struct_ByteSliceArrayStruct {
 	return bytesArrayToByteSliceArray(encodeSequencerBatchItems(batchItems))
@@ -201,7 +216,9 @@ func (ac *ArbCore) GetSends(startIndex *big.Int, count *big.Int) ([][]byte, erro
 	return receiveByteSliceArray(result.array), nil
 }
 
-func unmarshalLog(marshaled []byte) (core.ValueAndInbox, error) {
+// unmarshalLog reads a log and the inbox state it was emitted at, allocating
+// the log's tuples in arena, which may be nil
+func unmarshalLog(marshaled []byte, arena *value.TupleArena) (core.ValueAndInbox, error) {
 	reader := bytes.NewReader(marshaled)
 	var inboxData [64]byte
 	_, err := reader.Read(inboxData[:])
@@ -212,7 +229,7 @@ func unmarshalLog(marshaled []byte) (core.ValueAndInbox, error) {
 	var inboxAccumulator common.Hash
 	copy(inboxAccumulator[:], inboxData[32:])
 
-	val, err := value.UnmarshalValue(reader)
+	val, err := arena.UnmarshalValue(reader)
 	if err != nil {
 		return core.ValueAndInbox{}, err
 	}
@@ -240,8 +257,9 @@ func (ac *ArbCore) GetLogs(startIndex *big.Int, count *big.Int) ([]core.ValueAnd
 
 	marshaledLogs := receiveByteSliceArray(result.array)
 	logVals := make([]core.ValueAndInbox, 0, len(marshaledLogs))
+	arena := value.NewTupleArena(0)
 	for _, marshaledLog := range marshaledLogs {
-		log, err := unmarshalLog(marshaledLog)
+		log, err := unmarshalLog(marshaledLog, arena)
 		if err != nil {
 			return nil, err
 		}
@@ -432,13 +450,14 @@ func (ac *ArbCore) AdvanceExecutionCursorWithTracing(executionCursor core.Execut
 	valCount := uint64(result.data.count)
 	rd := bytes.NewReader(receiveByteSlice(result.data.slice))
 	vals := make([]core.MachineEmission, 0, valCount)
+	arena := value.NewTupleArena(0)
 	for i := uint64(0); i < valCount; i++ {
 		var logCountData [32]byte
 		_, err := rd.Read(logCountData[:])
 		if err != nil {
 			return nil, err
 		}
-		val, err := value.UnmarshalValue(rd)
+		val, err := arena.UnmarshalValue(rd)
 		if err != nil {
 			return nil, err
 		}
@@ -456,7 +475,7 @@ func (ac *ArbCore) GetLastMachine() (machine.Machine, error) {
 	if cMachine == nil {
 		return nil, errors.Errorf("error getting last machine")
 	}
-	ret := &Machine{cMachine}
+	ret := &Machine{c: cMachine}
 
 	runtime.SetFinalizer(ret, cdestroyVM)
 	return ret, nil
@@ -488,6 +507,80 @@ func (ac *ArbCore) UpdateCheckpointPruningGas(gas *big.Int) {
 	C.arbCoreUpdateCheckpointPruningGas(ac.c, unsafeDataPointer(gasData))
 }
 
+func (ac *ArbCore) CheckpointPruningStats() core.CheckpointPruningStats {
+	defer runtime.KeepAlive(ac)
+	stats := C.arbCoreGetCheckpointPruningStats(ac.c)
+	return core.CheckpointPruningStats{
+		PrunedCheckpoints: uint64(stats.pruned_checkpoints),
+		PrunedBytes:       uint64(stats.pruned_checkpoint_bytes),
+		DatabaseSize:      uint64(stats.database_size),
+	}
+}
+
+func (ac *ArbCore) CheckpointStats() core.CheckpointStats {
+	defer runtime.KeepAlive(ac)
+	stats := C.arbCoreGetCheckpointStats(ac.c)
+	return core.CheckpointStats{
+		SavedCheckpoints: uint64(stats.saved_checkpoints),
+		SavedBytes:       uint64(stats.saved_checkpoint_bytes),
+		SaveTime:         time.Duration(stats.save_nanoseconds),
+		LastSaveTime:     time.Duration(stats.last_save_nanoseconds),
+		LastRestoreTime:  time.Duration(stats.last_restore_nanoseconds),
+		MachineSize:      uint64(stats.machine_size),
+		CheckpointSize:   uint64(stats.checkpoint_size),
+		InboxSize:        uint64(stats.inbox_size),
+	}
+}
+
+func (ac *ArbCore) CheckpointSummaries(fromGas *big.Int, maxCount uint64) ([]core.CheckpointSummary, error) {
+	defer runtime.KeepAlive(ac)
+	fromGasData := math.U256Bytes(fromGas)
+	result := C.arbCoreGetCheckpointSummaries(ac.c, unsafeDataPointer(fromGasData), C.uint64_t(maxCount))
+	if result.found == 0 {
+		return nil, errors.New("failed to get checkpoints")
+	}
+	var summaries []core.CheckpointSummary
+	for _, data := range receiveByteSliceArray(result.array) {
+		summary, err := unmarshalCheckpointSummary(data)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+func unmarshalCheckpointSummary(data []byte) (core.CheckpointSummary, error) {
+	if len(data) != 32*5+1 && len(data) != 32*6+1 {
+		return core.CheckpointSummary{}, errors.Errorf("checkpoint summary has unexpected length %v", len(data))
+	}
+	summary := core.CheckpointSummary{
+		TotalGasConsumed:  new(big.Int).SetBytes(data[:32]),
+		L2BlockNumber:     new(big.Int).SetBytes(data[32:64]),
+		TotalMessagesRead: new(big.Int).SetBytes(data[64:96]),
+		TotalSendCount:    new(big.Int).SetBytes(data[96:128]),
+		TotalLogCount:     new(big.Int).SetBytes(data[128:160]),
+	}
+	if data[160] != 0 {
+		if len(data) != 32*6+1 {
+			return core.CheckpointSummary{}, errors.New("checkpoint summary missing machine hash")
+		}
+		var hash common.Hash
+		copy(hash[:], data[161:])
+		summary.MachineHash = &hash
+	}
+	return summary, nil
+}
+
+func (ac *ArbCore) VerifyCheckpoints() (uint64, uint64, error) {
+	defer runtime.KeepAlive(ac)
+	result := C.arbCoreVerifyCheckpoints(ac.c)
+	if result.found == 0 {
+		return 0, 0, errors.New("failed to verify checkpoints")
+	}
+	return uint64(result.checked), uint64(result.corrupt), nil
+}
+
 func (ac *ArbCore) TakeMachine(executionCursor core.ExecutionCursor) (machine.Machine, error) {
 	defer runtime.KeepAlive(ac)
 	defer runtime.KeepAlive(executionCursor)
@@ -499,7 +592,7 @@ func (ac *ArbCore) TakeMachine(executionCursor core.ExecutionCursor) (machine.Ma
 	if cMachine == nil {
 		return nil, errors.Errorf("error taking machine from execution cursor")
 	}
-	ret := &Machine{cMachine}
+	ret := &Machine{c: cMachine}
 
 	runtime.SetFinalizer(ret, cdestroyVM)
 	return ret, nil
@@ -602,11 +695,14 @@ func (ac *ArbCore) LogsCursorGetLogs(cursorIndex *big.Int) (*big.Int, []core.Val
 	}
 
 	firstIndex := receiveBigInt(result.first_index)
+	// Logs are read in large batches while catching up, and are processed
+	// and dropped together
+	arena := value.NewTupleArena(0)
 	data := receiveByteSliceArray(result.first_array)
 	logs := make([]core.ValueAndInbox, len(data))
 	for i, slice := range data {
 		var err error
-		logs[i], err = unmarshalLog(slice[:])
+		logs[i], err = unmarshalLog(slice[:], arena)
 		if err != nil {
 			return nil, nil, nil, err
 		}
@@ -616,7 +712,7 @@ func (ac *ArbCore) LogsCursorGetLogs(cursorIndex *big.Int) (*big.Int, []core.Val
 	deletedLogs := make([]core.ValueAndInbox, len(deletedData))
 	for i, slice := range deletedData {
 		var err error
-		deletedLogs[i], err = unmarshalLog(slice[:])
+		deletedLogs[i], err = unmarshalLog(slice[:], arena)
 		if err != nil {
 			return nil, nil, nil, err
 		}
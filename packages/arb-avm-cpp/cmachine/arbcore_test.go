@@ -18,8 +18,62 @@ package cmachine
 
 import (
 	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
 )
 
 func TestArbCore(t *testing.T) {
 
 }
+
+func TestArbCoreProfile(t *testing.T) {
+	dbPath := t.TempDir()
+	arbStorage, err := NewArbStorage(dbPath, configuration.DefaultCoreSettingsMaxExecution())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := arbStorage.Initialize(codeFile); err != nil {
+		t.Fatal(err)
+	}
+	defer arbStorage.CloseArbStorage()
+	arbCore := arbStorage.GetArbCore()
+
+	profile, err := arbCore.Profile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile != nil {
+		t.Fatal("profile recorded before profiling was enabled")
+	}
+
+	arbCore.SetProfiling(true)
+	if !arbCore.StartThread() {
+		t.Fatal("failed to start core thread")
+	}
+
+	// The core machine runs its initialization until it needs a message
+	deadline := time.Now().Add(time.Minute)
+	for !arbCore.MachineIdle() {
+		if time.Now().After(deadline) {
+			t.Fatal("core machine didn't become idle")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	profile, err = arbCore.Profile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile == nil || profile.TotalGas() == 0 {
+		t.Fatal("core machine execution wasn't profiled")
+	}
+
+	arbCore.SetProfiling(false)
+	stopped, err := arbCore.Profile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped == nil || stopped.TotalGas() != profile.TotalGas() {
+		t.Error("profile lost when profiling was turned off")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmachine
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+)
+
+const checkpointKeyLength = 32
+
+// loadCheckpointKey returns the hex encoded AES-256 key used to encrypt
+// checkpoints, read from a file, an environment variable or a key management
+// service URL, or nil if none is configured
+func loadCheckpointKey(config configuration.Database) ([]byte, error) {
+	var encoded string
+	sources := 0
+	if config.CheckpointKeyFile != "" {
+		sources++
+		data, err := ioutil.ReadFile(config.CheckpointKeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't read checkpoint key file")
+		}
+		encoded = string(data)
+	}
+	if config.CheckpointKeyEnv != "" {
+		sources++
+		var ok bool
+		encoded, ok = os.LookupEnv(config.CheckpointKeyEnv)
+		if !ok {
+			return nil, errors.Errorf("checkpoint key environment variable %v not set", config.CheckpointKeyEnv)
+		}
+	}
+	if config.CheckpointKeyURL != "" {
+		sources++
+		var err error
+		encoded, err = fetchCheckpointKey(config.CheckpointKeyURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if sources == 0 {
+		return nil, nil
+	}
+	if sources > 1 {
+		return nil, errors.New("only one checkpoint key source can be configured")
+	}
+
+	encoded = strings.TrimPrefix(strings.TrimSpace(encoded), "0x")
+	key, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "checkpoint key isn't hex encoded")
+	}
+	if len(key) != checkpointKeyLength {
+		return nil, errors.Errorf("checkpoint key must be %v bytes, got %v", checkpointKeyLength, len(key))
+	}
+	return key, nil
+}
+
+func fetchCheckpointKey(url string) (string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't fetch checkpoint key")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("checkpoint key service returned %v", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't read checkpoint key")
+	}
+	return string(data), nil
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmachine
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/gotest"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/gomachine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// maxDifferentialSteps bounds each opcode test in case the machines loop
+const maxDifferentialSteps = 1000000
+
+// TestGoMachineMatchesCMachine runs every opcode test on both engines one
+// instruction at a time, so the first instruction they disagree on is reported
+func TestGoMachineMatchesCMachine(t *testing.T) {
+	files, err := gotest.OpCodeTestFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			cmach, err := New(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gomach, err := gomachine.New(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cmach.Hash() != gomach.Hash() {
+				t.Fatalf("initial hash: cpp %v, go %v", cmach.Hash(), gomach.Hash())
+			}
+			for step := 0; step < maxDifferentialSteps; step++ {
+				cAssertion, _, cSteps, err := cmach.ExecuteAssertion(ctx, 1, true, nil, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				goAssertion, _, goSteps, err := gomach.ExecuteAssertion(ctx, 1, true, nil, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if cSteps != goSteps {
+					t.Fatalf("step %v: cpp ran %v steps, go ran %v", step, cSteps, goSteps)
+				}
+				if cAssertion.NumGas != goAssertion.NumGas {
+					t.Fatalf("step %v: cpp used %v gas, go used %v", step, cAssertion.NumGas, goAssertion.NumGas)
+				}
+				compareLogs(t, step, cAssertion.Logs, goAssertion.Logs)
+				if len(cAssertion.Sends) != len(goAssertion.Sends) {
+					t.Fatalf("step %v: cpp produced %v sends, go produced %v", step, len(cAssertion.Sends), len(goAssertion.Sends))
+				}
+				for i := range cAssertion.Sends {
+					if !bytes.Equal(cAssertion.Sends[i], goAssertion.Sends[i]) {
+						t.Fatalf("step %v: send %v: cpp %x, go %x", step, i, cAssertion.Sends[i], goAssertion.Sends[i])
+					}
+				}
+				diffs, err := machine.DiffStates(cmach, gomach)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(diffs) > 0 {
+					t.Fatalf("step %v: machines diverged at %v", step, diffs)
+				}
+				if cmach.Hash() != gomach.Hash() {
+					t.Fatalf("step %v: hash: cpp %v, go %v", step, cmach.Hash(), gomach.Hash())
+				}
+				if cSteps == 0 {
+					return
+				}
+			}
+			t.Fatal("machines didn't stop")
+		})
+	}
+}
+
+func compareLogs(t *testing.T, step int, expected []value.Value, actual []value.Value) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("step %v: cpp produced %v logs, go produced %v", step, len(expected), len(actual))
+	}
+	for i := range expected {
+		if !value.Eq(expected[i], actual[i]) {
+			t.Fatalf("step %v: log %v: cpp %v, go %v", step, i, expected[i], actual[i])
+		}
+	}
+}
+
+// TestStepTracersMatch checks that both engines trace the same steps, and
+// that every step is traced even when the AVM passes them on in chunks
+func TestStepTracersMatch(t *testing.T) {
+	files, err := gotest.OpCodeTestFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			cmach, err := New(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gomach, err := gomachine.New(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var cTrace, goTrace []machine.ExecutionStep
+			cmach.SetStepTracer(machine.StepTracerFunc(func(step machine.ExecutionStep) {
+				cTrace = append(cTrace, step)
+			}))
+			gomach.SetStepTracer(machine.StepTracerFunc(func(step machine.ExecutionStep) {
+				goTrace = append(goTrace, step)
+			}))
+			_, _, cSteps, err := cmach.ExecuteAssertion(ctx, 0, false, nil, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _, goSteps, err := gomach.ExecuteAssertion(ctx, 0, false, nil, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if uint64(len(cTrace)) != cSteps || uint64(len(goTrace)) != goSteps {
+				t.Fatalf("cpp traced %v of %v steps, go traced %v of %v", len(cTrace), cSteps, len(goTrace), goSteps)
+			}
+			if len(cTrace) != len(goTrace) {
+				t.Fatalf("cpp traced %v steps, go traced %v", len(cTrace), len(goTrace))
+			}
+			for i := range cTrace {
+				if !sameStep(cTrace[i], goTrace[i]) {
+					t.Fatalf("step %v: cpp %+v, go %+v", i, cTrace[i], goTrace[i])
+				}
+			}
+		})
+	}
+}
+
+func sameStep(a machine.ExecutionStep, b machine.ExecutionStep) bool {
+	if a.CodePointRef != b.CodePointRef || a.Opcode != b.Opcode || a.Gas != b.Gas {
+		return false
+	}
+	if (a.StackTop == nil) != (b.StackTop == nil) || (a.StackTop != nil && a.StackTop.Cmp(b.StackTop) != 0) {
+		return false
+	}
+	if (a.StackTopHash == nil) != (b.StackTopHash == nil) || (a.StackTopHash != nil && *a.StackTopHash != *b.StackTopHash) {
+		return false
+	}
+	return true
+}
@@ -21,12 +21,16 @@ package cmachine
 #include "../cavm/carbstorage.h"
 #include <stdio.h>
 #include <stdlib.h>
+
+void traceExecutionSteps(uint64_t tracer, void* data, int length, uint64_t count);
 */
 import "C"
 
 import (
+	"bytes"
 	"context"
 	"runtime"
+	"sync"
 	"unsafe"
 
 	"github.com/ethereum/go-ethereum/metrics"
@@ -46,6 +50,11 @@ var (
 
 type Machine struct {
 	c unsafe.Pointer
+
+	debugMutex sync.Mutex
+	profiling  bool
+	profile    *machine.Profile
+	stepTracer machine.StepTracer
 }
 
 func New(codeFile string) (*Machine, error) {
@@ -68,7 +77,7 @@ func cdestroyVM(cMachine *Machine) {
 }
 
 func WrapCMachine(cMachine unsafe.Pointer) *Machine {
-	ret := &Machine{cMachine}
+	ret := &Machine{c: cMachine}
 	runtime.SetFinalizer(ret, cdestroyVM)
 	return ret
 }
@@ -97,7 +106,7 @@ func (m *Machine) CodePointHash() (ret common.Hash) {
 func (m *Machine) Clone() machine.Machine {
 	defer runtime.KeepAlive(m)
 	cMachine := C.machineClone(m.c)
-	ret := &Machine{cMachine}
+	ret := &Machine{c: cMachine}
 	runtime.SetFinalizer(ret, cdestroyVM)
 	return ret
 }
@@ -212,10 +221,27 @@ func (m *Machine) ExecuteAssertionAdvanced(
 
 	C.machineExecutionConfigSetTrace(conf, boolToCInt(trace))
 
+	// Results are added to the profile which was current when execution
+	// started, even if profiling is restarted in the meantime
+	m.debugMutex.Lock()
+	var profile *machine.Profile
+	if m.profiling {
+		profile = m.profile
+	}
+	stepTracer := m.stepTracer
+	m.debugMutex.Unlock()
+	C.machineExecutionConfigSetProfile(conf, boolToCInt(profile != nil))
+
 	resultChan := make(chan C.RawAssertionResult, 1)
 	go func() {
 		defer close(resultChan)
-		resultChan <- C.executeAssertion(m.c, conf)
+		if stepTracer == nil {
+			resultChan <- C.executeAssertion(m.c, conf)
+			return
+		}
+		tracerID := registerStepTracer(stepTracer)
+		defer unregisterStepTracer(tracerID)
+		resultChan <- C.executeAssertionWithStepTracer(m.c, conf, C.StepTraceCallback(C.traceExecutionSteps), C.uint64_t(tracerID))
 	}()
 
 	aborted := false
@@ -231,6 +257,7 @@ func (m *Machine) ExecuteAssertionAdvanced(
 
 	// Make sure result is cleaned up properly
 	executionAssertion, values, steps, err := makeExecutionAssertion(assertionResult.assertion)
+	profileData := receiveByteSlice(assertionResult.assertion.profile)
 
 	if aborted {
 		return nil, nil, 0, ctx.Err()
@@ -240,11 +267,47 @@ func (m *Machine) ExecuteAssertionAdvanced(
 		return nil, nil, 0, errors.New("Shutting down")
 	}
 
+	if profile != nil && len(profileData) > 0 {
+		assertionProfile, err := machine.NewProfileFromReader(bytes.NewReader(profileData))
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		m.debugMutex.Lock()
+		profile.Merge(assertionProfile)
+		m.debugMutex.Unlock()
+	}
+
 	GasCounter.Inc(int64(executionAssertion.NumGas))
 	StepsCounter.Inc(int64(steps))
 	return executionAssertion, values, steps, err
 }
 
+func (m *Machine) SetProfiling(enabled bool) {
+	m.debugMutex.Lock()
+	defer m.debugMutex.Unlock()
+	if enabled && !m.profiling {
+		m.profile = machine.NewProfile()
+	}
+	m.profiling = enabled
+}
+
+func (m *Machine) SetStepTracer(tracer machine.StepTracer) {
+	m.debugMutex.Lock()
+	defer m.debugMutex.Unlock()
+	m.stepTracer = tracer
+}
+
+func (m *Machine) Profile() *machine.Profile {
+	m.debugMutex.Lock()
+	defer m.debugMutex.Unlock()
+	if m.profile == nil {
+		return nil
+	}
+	profile := machine.NewProfile()
+	profile.Merge(m.profile)
+	return profile
+}
+
 func (m *Machine) MarshalForProof() ([]byte, []byte, error) {
 	defer runtime.KeepAlive(m)
 	rawProof := C.machineMarshallForProof(m.c)
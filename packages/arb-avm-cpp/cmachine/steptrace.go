@@ -0,0 +1,72 @@
+/*
+ * Copyright 2019-2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmachine
+
+/*
+#include <stdint.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"sync"
+	"unsafe"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+)
+
+// Step tracers are identified to the AVM by number, since it can't hold
+// references to Go values
+var (
+	stepTracersMutex sync.Mutex
+	stepTracers             = make(map[uint64]machine.StepTracer)
+	nextStepTracer   uint64 = 1
+)
+
+func registerStepTracer(tracer machine.StepTracer) uint64 {
+	stepTracersMutex.Lock()
+	defer stepTracersMutex.Unlock()
+	id := nextStepTracer
+	nextStepTracer++
+	stepTracers[id] = tracer
+	return id
+}
+
+func unregisterStepTracer(id uint64) {
+	stepTracersMutex.Lock()
+	defer stepTracersMutex.Unlock()
+	delete(stepTracers, id)
+}
+
+//export traceExecutionSteps
+func traceExecutionSteps(tracerID C.uint64_t, data unsafe.Pointer, length C.int, count C.uint64_t) {
+	stepTracersMutex.Lock()
+	tracer := stepTracers[uint64(tracerID)]
+	stepTracersMutex.Unlock()
+	if tracer == nil {
+		return
+	}
+	reader := bytes.NewReader(C.GoBytes(data, length))
+	for i := uint64(0); i < uint64(count); i++ {
+		step, err := machine.NewExecutionStepFromReader(reader)
+		if err != nil {
+			// The AVM marshalled the steps itself, so this can only be a bug
+			panic(err)
+		}
+		tracer.TraceStep(step)
+	}
+}
@@ -57,6 +57,14 @@ func stringToPruningMode(mode string) (C.PruningMode, error) {
 	return C.PRUNING_MODE_DEFAULT, errors.Errorf("unrecognized checkpoint pruning mode: '%s'", mode)
 }
 
+func validateCompression(compression string) error {
+	switch compression {
+	case "", "default", "none", "snappy", "lz4", "zstd":
+		return nil
+	}
+	return errors.Errorf("unrecognized database compression: '%s'", compression)
+}
+
 func NewArbStorage(dbPath string, coreConfig *configuration.Core) (*ArbStorage, error) {
 	return NewArbStorageWithFinalBlock(dbPath, coreConfig, 0)
 }
@@ -68,6 +76,22 @@ func NewArbStorageWithFinalBlock(dbPath string, coreConfig *configuration.Core,
 	cDatabaseSavePath := C.CString(coreConfig.Database.SavePath)
 	defer C.free(unsafe.Pointer(cDatabaseSavePath))
 
+	if err := validateCompression(coreConfig.Database.Compression); err != nil {
+		return nil, err
+	}
+	cDatabaseCompression := C.CString(coreConfig.Database.Compression)
+	defer C.free(unsafe.Pointer(cDatabaseCompression))
+
+	checkpointKey, err := loadCheckpointKey(coreConfig.Database)
+	if err != nil {
+		return nil, err
+	}
+	var cCheckpointKey unsafe.Pointer
+	if len(checkpointKey) > 0 {
+		cCheckpointKey = C.CBytes(checkpointKey)
+		defer C.free(cCheckpointKey)
+	}
+
 	checkpointPruningMode, err := stringToPruningMode(coreConfig.CheckpointPruningMode)
 	if err != nil {
 		return nil, err
@@ -100,11 +124,19 @@ func NewArbStorageWithFinalBlock(dbPath string, coreConfig *configuration.Core,
 		checkpoint_prune_on_startup:        boolToCInt(coreConfig.CheckpointPruneOnStartup),
 		checkpoint_pruning_mode:            checkpointPruningMode,
 		checkpoint_max_to_prune:            C.int(coreConfig.CheckpointMaxToPrune),
+		checkpoint_pruning_age_seconds:     C.int(coreConfig.CheckpointPruningAge.Seconds()),
+		checkpoint_retain_interval:         C.int(coreConfig.CheckpointRetainInterval),
 		database_compact:                   boolToCInt(coreConfig.Database.Compact),
 		database_save_on_startup:           boolToCInt(coreConfig.Database.SaveOnStartup),
 		database_exit_after:                boolToCInt(coreConfig.Database.ExitAfter),
 		database_save_interval:             C.int(databaseSaveIntervalSeconds),
 		database_save_path:                 cDatabaseSavePath,
+		database_save_incremental:          boolToCInt(coreConfig.Database.SaveIncremental),
+		database_save_max_backups:          C.int(coreConfig.Database.SaveMaxBackups),
+		database_save_queue_size:           C.int(coreConfig.Database.SaveQueueSize),
+		database_compression:               cDatabaseCompression,
+		checkpoint_encryption_key:          cCheckpointKey,
+		checkpoint_encryption_key_length:   C.int(len(checkpointKey)),
 		test_reorg_to_l1_block:             C.int(coreConfig.Test.ReorgTo.L1Block),
 		test_reorg_to_l2_block:             C.int(coreConfig.Test.ReorgTo.L2Block),
 		test_reorg_to_log:                  C.int(coreConfig.Test.ReorgTo.Log),
@@ -132,6 +164,19 @@ func (s *ArbStorage) PrintDatabaseMetadata() {
 	C.printDatabaseMetadata(s.c)
 }
 
+// RestoreDatabaseBackup restores the latest incremental backup in backupPath
+// into a new database at dbPath
+func RestoreDatabaseBackup(backupPath string, dbPath string) error {
+	cBackupPath := C.CString(backupPath)
+	defer C.free(unsafe.Pointer(cBackupPath))
+	cDbPath := C.CString(dbPath)
+	defer C.free(unsafe.Pointer(cDbPath))
+	if C.restoreArbStorageBackup(cBackupPath, cDbPath) == 0 {
+		return errors.Errorf("error restoring database backup from %v", backupPath)
+	}
+	return nil
+}
+
 func (s *ArbStorage) CleanupValidator() error {
 	defer runtime.KeepAlive(s)
 	success := C.cleanupValidator(s.c)
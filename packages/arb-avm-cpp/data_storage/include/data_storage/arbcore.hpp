@@ -32,6 +32,8 @@
 #include <data_storage/value/code.hpp>
 #include <data_storage/value/valuecache.hpp>
 
+#include <condition_variable>
+#include <deque>
 #include <map>
 #include <memory>
 #include <queue>
@@ -153,8 +155,31 @@ class ArbCore {
     std::mutex checkpoint_pruning_mutex;
     uint256_t unsafe_checkpoint_pruning_gas_used;
 
+    // Core thread output
+    std::atomic<uint64_t> pruned_checkpoint_count{0};
+    std::atomic<uint64_t> pruned_checkpoint_bytes{0};
+    std::atomic<uint64_t> saved_checkpoint_count{0};
+    std::atomic<uint64_t> saved_checkpoint_bytes{0};
+    std::atomic<uint64_t> checkpoint_save_nanoseconds{0};
+    std::atomic<uint64_t> last_checkpoint_save_nanoseconds{0};
+    std::atomic<uint64_t> last_checkpoint_restore_nanoseconds{0};
+
     // Core thread input
     std::atomic<bool> trigger_save_rocksdb_checkpoint{false};
+    std::atomic<bool> profiling{false};
+
+    // Opcode profile of the core machine's execution since profiling was
+    // last turned on
+    std::mutex profile_mutex;
+    std::optional<ExecutionProfile> profile;
+
+    // Database saves are copied by a separate thread so that disk I/O
+    // doesn't stall the core thread
+    std::unique_ptr<std::thread> database_save_thread;
+    std::mutex database_save_mutex;
+    std::condition_variable database_save_cv;
+    std::deque<boost::filesystem::path> database_save_queue;
+    bool database_save_stopping{false};
 
     // Core thread holds mutex only during reorg.
     // Routines accessing database for log entries will need to acquire mutex
@@ -323,6 +348,40 @@ class ArbCore {
 
     // Controlling checkpoint pruning
     void updateCheckpointPruningGas(uint256_t gas);
+    uint64_t prunedCheckpointCount() const;
+    std::pair<uint64_t, uint64_t> verifyCheckpoints();
+
+    // CheckpointSummary is what a checkpoint record holds without loading
+    // the machine it references. machine_hash is empty for checkpoints
+    // which only kept their output.
+    struct CheckpointSummary {
+        MachineOutput output;
+        std::optional<uint256_t> machine_hash;
+    };
+    // Reads up to max_count checkpoints starting at from_gas, without
+    // loading machine state, so that tools can inspect them cheaply
+    ValueResult<std::vector<CheckpointSummary>> checkpointSummaries(
+        const uint256_t& from_gas,
+        size_t max_count);
+    uint64_t prunedCheckpointBytes() const;
+
+    struct CheckpointStats {
+        uint64_t saved_count;
+        // Size of the serialized checkpoint records, not the machine state
+        // they reference
+        uint64_t saved_bytes;
+        uint64_t save_nanoseconds;
+        uint64_t last_save_nanoseconds;
+        // Time taken to load the machine from the last matching checkpoint
+        // during the most recent startup or reorg
+        uint64_t last_restore_nanoseconds;
+    };
+    CheckpointStats checkpointStats() const;
+
+    // Turning profiling on starts a new profile of the core machine's
+    // execution. Turning it off keeps the profile so it can still be read.
+    void setProfiling(bool enabled);
+    std::optional<ExecutionProfile> getProfile();
 
     // Useful for manual value loading
     std::shared_ptr<DataStorage> getDataStorage();
@@ -553,6 +612,13 @@ class ArbCore {
         ReadTransaction& tx);
     void saveRocksdbCheckpoint(const boost::filesystem::path& save_rocksdb_path,
                                ReadTransaction& tx);
+    void queueRocksdbCheckpoint(
+        const boost::filesystem::path& save_rocksdb_path);
+    void startDatabaseSaveThread();
+    void stopDatabaseSaveThread();
+    void databaseSaveThreadBody();
+    static void cleanupIncompleteRocksdbCheckpoints(
+        const boost::filesystem::path& save_rocksdb_path);
     void setCoreError(const std::string& message);
     bool threadBody(ThreadDataStruct& thread_data);
     bool reorgIfInvalidMachine(uint32_t& thread_failure_count,
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+#ifndef data_storage_checkpointcipher_hpp
+#define data_storage_checkpointcipher_hpp
+
+#include <rocksdb/iterator.h>
+#include <rocksdb/slice.h>
+
+#include <array>
+#include <memory>
+#include <optional>
+#include <vector>
+
+// Checkpoint records are optionally encrypted with AES-256-GCM. Encrypted
+// records start with a flag byte that plaintext records never start with,
+// since those begin with a big-endian message count, so databases can hold a
+// mix of both while existing records are being migrated.
+using CheckpointKey = std::array<unsigned char, 32>;
+
+bool isEncryptedCheckpointRecord(const rocksdb::Slice& record);
+
+// The record's database key is authenticated along with it so that records
+// can't be swapped between keys
+std::vector<unsigned char> encryptCheckpointRecord(
+    const CheckpointKey& key,
+    const rocksdb::Slice& db_key,
+    const rocksdb::Slice& plaintext);
+
+// Returns plaintext records unchanged. Throws if the record is encrypted and
+// no key is given, or if it fails to authenticate.
+std::vector<unsigned char> decryptCheckpointRecord(
+    const std::optional<CheckpointKey>& key,
+    const rocksdb::Slice& db_key,
+    const rocksdb::Slice& record);
+
+// DecryptingIterator wraps a checkpoint column iterator so that value()
+// always returns the plaintext record
+class DecryptingIterator : public rocksdb::Iterator {
+    std::unique_ptr<rocksdb::Iterator> it;
+    std::optional<CheckpointKey> key_;
+    mutable std::vector<unsigned char> plaintext;
+
+   public:
+    DecryptingIterator(std::unique_ptr<rocksdb::Iterator> it_,
+                       std::optional<CheckpointKey> key)
+        : it(std::move(it_)), key_(key) {}
+
+    [[nodiscard]] bool Valid() const override { return it->Valid(); }
+    void SeekToFirst() override { it->SeekToFirst(); }
+    void SeekToLast() override { it->SeekToLast(); }
+    void Seek(const rocksdb::Slice& target) override { it->Seek(target); }
+    void SeekForPrev(const rocksdb::Slice& target) override {
+        it->SeekForPrev(target);
+    }
+    void Next() override { it->Next(); }
+    void Prev() override { it->Prev(); }
+    [[nodiscard]] rocksdb::Slice key() const override { return it->key(); }
+    [[nodiscard]] rocksdb::Slice value() const override;
+    [[nodiscard]] rocksdb::Status status() const override {
+        return it->status();
+    }
+};
+
+#endif /* data_storage_checkpointcipher_hpp */
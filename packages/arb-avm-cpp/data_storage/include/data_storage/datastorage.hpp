@@ -23,6 +23,7 @@
 #include <vector>
 
 #include <avm_values/bigint.hpp>
+#include <data_storage/checkpointcipher.hpp>
 #include <data_storage/storageresult.hpp>
 #include <data_storage/util.hpp>
 
@@ -72,6 +73,7 @@ class DataStorage {
     std::unique_ptr<rocksdb::TransactionDB> txn_db;
     std::vector<rocksdb::ColumnFamilyHandle*> column_handles;
     std::vector<uint8_t> secret_hash_seed;
+    std::optional<CheckpointKey> checkpoint_key;
 
     class shutting_down_exception : public std::exception {};
 
@@ -84,12 +86,15 @@ class DataStorage {
     [[nodiscard]] DbLockShared tryLockShared() const;
     rocksdb::Status cleanupValidator();
     rocksdb::Status compact(bool aggressive);
+    uint64_t totalSstFilesSize();
+    uint64_t sstFilesSize(column_family_indexes column);
 
    private:
     std::atomic<bool> shutting_down{false};
     mutable std::atomic<int64_t> concurrent_database_access_counter{0};
 
     rocksdb::Status updateSecretHashSeed();
+    rocksdb::Status encryptExistingCheckpoints();
 
     [[nodiscard]] std::unique_ptr<rocksdb::Transaction> beginTransaction() {
         // Make sure database isn't closed while it is being used
@@ -33,6 +33,9 @@ class ReadTransaction {
 
     [[nodiscard]] rocksdb::Status createRocksdbCheckpoint(
         const std::string& checkpoint_dir) const;
+    [[nodiscard]] rocksdb::Status createRocksdbBackup(
+        const std::string& backup_dir,
+        uint32_t max_backups) const;
     rocksdb::Status defaultGet(const rocksdb::Slice& key,
                                std::string* value) const;
     rocksdb::Status stateGet(const rocksdb::Slice& key,
@@ -130,4 +133,9 @@ class ReadConsistentTransaction : public ReadTransaction {
         : ReadTransaction(std::move(store)) {}
 };
 
+// Restores the latest backup made by createRocksdbBackup into db_dir, which
+// must not be open
+rocksdb::Status restoreRocksdbBackup(const std::string& backup_dir,
+                                     const std::string& db_dir);
+
 #endif  // data_storage_readtransaction_hpp
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+#ifndef data_storage_schemamigration_hpp
+#define data_storage_schemamigration_hpp
+
+#include <avm_values/bigint.hpp>
+#include <data_storage/storageresult.hpp>
+
+#include <functional>
+#include <memory>
+#include <string>
+#include <vector>
+
+class DataStorage;
+class ReadTransaction;
+class ReadWriteTransaction;
+
+// SchemaMigration upgrades a database from from_version to from_version + 1
+struct SchemaMigration {
+    uint256_t from_version;
+    std::string description;
+    std::function<rocksdb::Status(ReadWriteTransaction&)> migrate;
+};
+
+ValueResult<uint256_t> readSchemaVersion(ReadTransaction& tx);
+rocksdb::Status writeSchemaVersion(ReadWriteTransaction& tx,
+                                   const uint256_t& schema_version);
+
+// schemaMigrations lists the migrations applied to databases with an older
+// schema version on startup. When the layout of stored data changes, bump
+// the schema version in arbcore.cpp and add a migration from the previous
+// version here.
+const std::vector<SchemaMigration>& schemaMigrations();
+
+// migrateSchema upgrades the database from its stored schema version to
+// target_version. Each migration is committed along with the new schema
+// version, so an interrupted upgrade resumes after the last completed
+// migration. Nothing is changed if any step from the stored version is
+// missing from migrations.
+rocksdb::Status migrateSchema(std::shared_ptr<DataStorage> storage,
+                              const uint256_t& target_version,
+                              const std::vector<SchemaMigration>& migrations);
+
+#endif /* data_storage_schemamigration_hpp */
@@ -21,6 +21,9 @@
 
 #include "avm/machine.hpp"
 
+#include <array>
+#include <optional>
+
 struct ArbCoreConfig {
     // Maximum number of messages to process at a time
     uint32_t message_process_count{10};
@@ -85,6 +88,29 @@ struct ArbCoreConfig {
     // Rocksdb checkpoints will be saved in database_save_path/timestamp/
     std::string database_save_path{};
 
+    // Save incremental rocksdb backups in database_save_path/incremental/
+    // instead of full checkpoints, only copying files changed since the
+    // previous backup
+    bool database_save_incremental{false};
+
+    // Number of incremental backups to keep, 0 to keep all. Files only
+    // referenced by older backups are deleted.
+    uint32_t database_save_max_backups{0};
+
+    // Number of pending database saves to queue for the background writer,
+    // further saves are skipped while the queue is full. 0 to save on the
+    // core thread instead.
+    uint32_t database_save_queue_size{1};
+
+    // Block compression for all column families: "default", "none",
+    // "snappy", "lz4" or "zstd". Existing files keep the compression they
+    // were written with and are rewritten on compaction.
+    std::string database_compression{};
+
+    // Encrypt checkpoint records with this AES-256 key. Existing plaintext
+    // records are encrypted when the database is opened.
+    std::optional<std::array<unsigned char, 32>> checkpoint_encryption_key{};
+
     // If any profile_* parameters are non-zero, program will exit after
     // all profile conditions are satisfied.
 
@@ -130,9 +156,14 @@ struct ArbCoreConfig {
     // Exit after manipulating database
     bool database_exit_after{false};
 
-    // Number of seconds to keep checkpoints
+    // Never prune checkpoints whose last inbox timestamp is within this
+    // many seconds of now, 0 to disable
     uint64_t checkpoint_pruning_age_seconds{0};
 
+    // When pruning, keep the first checkpoint of every interval of this many
+    // L2 blocks instead of deleting everything, 0 to disable
+    uint64_t checkpoint_retain_interval{0};
+
     // Number of seconds to keep checkpoints
     PruningMode checkpoint_pruning_mode{PRUNING_MODE_DEFAULT};
 
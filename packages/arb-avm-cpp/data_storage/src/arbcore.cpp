@@ -23,6 +23,7 @@
 #include <data_storage/datastorage.hpp>
 #include <data_storage/readsnapshottransaction.hpp>
 #include <data_storage/readwritetransaction.hpp>
+#include <data_storage/schemamigration.hpp>
 #include <data_storage/storageresult.hpp>
 #include <data_storage/value/machine.hpp>
 #include <data_storage/value/utils.hpp>
@@ -35,6 +36,7 @@
 #include <sys/stat.h>
 #include <ethash/keccak.hpp>
 #include <filesystem>
+#include <fstream>
 #include <iomanip>
 #include <set>
 #include <sstream>
@@ -52,7 +54,6 @@ constexpr uint256_t arbcore_schema_version = 3;
 constexpr auto pruning_mode_key = std::array<char, 1>{-59};
 constexpr auto log_inserted_key = std::array<char, 1>{-60};
 constexpr auto send_inserted_key = std::array<char, 1>{-62};
-constexpr auto schema_version_key = std::array<char, 1>{-64};
 constexpr auto logscursor_current_prefix = std::array<char, 1>{-120};
 
 template <class T>
@@ -160,6 +161,10 @@ bool ArbCore::startThread() {
 
     core_thread =
         std::make_unique<std::thread>(std::reference_wrapper<ArbCore>(*this));
+    if (coreConfig.database_save_interval != 0 &&
+        coreConfig.database_save_queue_size != 0) {
+        startDatabaseSaveThread();
+    }
 
     return true;
 }
@@ -174,6 +179,114 @@ void ArbCore::abortThread() {
         core_thread->join();
         core_thread = nullptr;
     }
+    stopDatabaseSaveThread();
+}
+
+void ArbCore::startDatabaseSaveThread() {
+    if (database_save_thread) {
+        return;
+    }
+    cleanupIncompleteRocksdbCheckpoints(coreConfig.database_save_path);
+    {
+        std::lock_guard<std::mutex> lock(database_save_mutex);
+        database_save_stopping = false;
+    }
+    database_save_thread = std::make_unique<std::thread>(
+        [this]() { databaseSaveThreadBody(); });
+}
+
+void ArbCore::stopDatabaseSaveThread() {
+    if (!database_save_thread) {
+        return;
+    }
+    {
+        std::lock_guard<std::mutex> lock(database_save_mutex);
+        database_save_stopping = true;
+    }
+    database_save_cv.notify_all();
+    database_save_thread->join();
+    database_save_thread = nullptr;
+}
+
+void ArbCore::databaseSaveThreadBody() {
+    while (true) {
+        boost::filesystem::path save_rocksdb_path;
+        {
+            std::unique_lock<std::mutex> lock(database_save_mutex);
+            database_save_cv.wait(lock, [&]() {
+                return database_save_stopping || !database_save_queue.empty();
+            });
+            if (database_save_stopping) {
+                // Pending saves are dropped, intent records of any
+                // interrupted save are cleaned up on next start
+                database_save_queue.clear();
+                return;
+            }
+            save_rocksdb_path = database_save_queue.front();
+        }
+
+        try {
+            ReadTransaction tx(data_storage);
+            saveRocksdbCheckpoint(save_rocksdb_path, tx);
+        } catch (const DataStorage::shutting_down_exception&) {
+            return;
+        } catch (const std::exception& e) {
+            std::cerr << "Exception saving database: " << e.what()
+                      << std::endl;
+        }
+
+        std::lock_guard<std::mutex> lock(database_save_mutex);
+        database_save_queue.pop_front();
+    }
+}
+
+// queueRocksdbCheckpoint hands a database save to the background writer,
+// falling back to saving on the calling thread if there is no writer
+void ArbCore::queueRocksdbCheckpoint(
+    const boost::filesystem::path& save_rocksdb_path) {
+    if (!database_save_thread) {
+        ReadTransaction tx(data_storage);
+        saveRocksdbCheckpoint(save_rocksdb_path, tx);
+        return;
+    }
+    {
+        std::lock_guard<std::mutex> lock(database_save_mutex);
+        if (database_save_queue.size() >=
+            coreConfig.database_save_queue_size) {
+            std::cerr << "Skipping database save, "
+                      << database_save_queue.size()
+                      << " save(s) already pending" << std::endl;
+            return;
+        }
+        database_save_queue.push_back(save_rocksdb_path);
+    }
+    database_save_cv.notify_one();
+}
+
+// cleanupIncompleteRocksdbCheckpoints removes saves that were interrupted,
+// found by the intent record written before each save starts
+void ArbCore::cleanupIncompleteRocksdbCheckpoints(
+    const boost::filesystem::path& save_rocksdb_path) {
+    boost::system::error_code ec;
+    if (!boost::filesystem::is_directory(save_rocksdb_path, ec)) {
+        return;
+    }
+    for (auto& entry :
+         boost::filesystem::directory_iterator(save_rocksdb_path, ec)) {
+        auto intent_path = entry.path();
+        if (intent_path.extension() != ".intent") {
+            continue;
+        }
+        auto target = save_rocksdb_path / intent_path.stem();
+        if (intent_path.stem() != "incremental") {
+            // Incremental backups discard partial backups themselves
+            boost::filesystem::remove_all(target, ec);
+            boost::filesystem::remove_all(target.string() + ".tmp", ec);
+        }
+        std::cerr << "Cleaned up incomplete database save " << target
+                  << std::endl;
+        boost::filesystem::remove(intent_path, ec);
+    }
 }
 
 // deliverMessages sends messages to core thread
@@ -299,7 +412,7 @@ InitializeResult ArbCore::applyConfig() {
     ValueCache cache{1, 0};
 
     bool database_exists = false;
-    ValueResult<std::string> pruning_mode_result;
+    bool needs_migration = false;
     {
         // Validate database schema version
         ReadTransaction tx(data_storage);
@@ -320,13 +433,23 @@ InitializeResult ArbCore::applyConfig() {
                 return {rocksdb::Status::Corruption(), false};
             }
         } else if (schema_result.data != arbcore_schema_version) {
-            // Database has schema version that does not match
-            std::cerr << "Database version " << schema_result.data
-                      << " does not match expected version "
-                      << arbcore_schema_version
-                      << ", delete database and try again" << std::endl;
+            needs_migration = true;
+        }
+    }
+
+    if (needs_migration) {
+        // Upgrade older databases in place, fails if the database is newer
+        // or no migration exists
+        auto status = migrateSchema(data_storage, arbcore_schema_version,
+                                    schemaMigrations());
+        if (!status.ok()) {
             return {rocksdb::Status::Corruption(), false};
         }
+    }
+
+    ValueResult<std::string> pruning_mode_result;
+    {
+        ReadTransaction tx(data_storage);
 
         pruning_mode_result = pruningMode(tx);
 
@@ -657,6 +780,7 @@ void ArbCore::triggerSaveFullRocksdbCheckpointToDisk() {
 }
 
 rocksdb::Status ArbCore::saveCheckpoint(ReadWriteTransaction& tx) {
+    auto begin_timepoint = std::chrono::steady_clock::now();
     auto& state = core_machine->machine_state;
     if (!isValid(tx, state.output.fully_processed_inbox)) {
         std::cerr << "Attempted to save invalid checkpoint at gas "
@@ -686,6 +810,14 @@ rocksdb::Status ArbCore::saveCheckpoint(ReadWriteTransaction& tx) {
         return put_status;
     }
 
+    auto elapsed = std::chrono::duration_cast<std::chrono::nanoseconds>(
+                       std::chrono::steady_clock::now() - begin_timepoint)
+                       .count();
+    saved_checkpoint_count++;
+    saved_checkpoint_bytes += value_vec.size();
+    checkpoint_save_nanoseconds += elapsed;
+    last_checkpoint_save_nanoseconds = elapsed;
+
     return rocksdb::Status::OK();
 }
 
@@ -1279,6 +1411,11 @@ rocksdb::Status ArbCore::reorgCheckpoints(
         }
         // Calculate time including database commit
         printElapsed(reorg_machine_begin_timepoint, "Reorg took ");
+        last_checkpoint_restore_nanoseconds =
+            std::chrono::duration_cast<std::chrono::nanoseconds>(
+                std::chrono::steady_clock::now() -
+                reorg_machine_begin_timepoint)
+                .count();
 
         MachineOutput target_machine_output;
         auto last_database_checkpoint_output =
@@ -1686,6 +1823,13 @@ bool ArbCore::threadBody(ThreadDataStruct& thread_data) {
 
         auto last_assertion = core_machine->nextAssertion();
 
+        if (last_assertion.profile) {
+            std::lock_guard<std::mutex> lock(profile_mutex);
+            if (profile) {
+                profile->merge(*last_assertion.profile);
+            }
+        }
+
         // Save last machine output
         {
             std::unique_lock<std::shared_mutex> guard(last_machine_mutex);
@@ -1818,7 +1962,7 @@ bool ArbCore::threadBody(ThreadDataStruct& thread_data) {
         if (thread_data.perform_save_rocksdb_checkpoint) {
             thread_data.perform_save_rocksdb_checkpoint = false;
 
-            saveRocksdbCheckpoint(coreConfig.database_save_path, tx);
+            queueRocksdbCheckpoint(coreConfig.database_save_path);
         }
 
         auto output = getLastMachineOutput();
@@ -1891,6 +2035,7 @@ bool ArbCore::threadBody(ThreadDataStruct& thread_data) {
 
     if (core_machine->status() == MachineThread::MACHINE_NONE) {
         // Start execution of machine if new message available
+        thread_data.execConfig.profile = profiling;
         auto success = runCoreMachineWithMessages(
             thread_data.execConfig, coreConfig.message_process_count, true);
         if (!success) {
@@ -1978,6 +2123,27 @@ void ArbCore::operator()() {
     core_pthread = std::nullopt;
 #endif
 }
+namespace {
+// IntentRecord marks a database save as in progress for as long as it exists
+// so that saves interrupted by a crash can be cleaned up
+class IntentRecord {
+    boost::filesystem::path path;
+
+   public:
+    explicit IntentRecord(boost::filesystem::path path_)
+        : path(std::move(path_)) {
+        std::ofstream file(path.string());
+        file << seconds_since_epoch() << std::endl;
+    }
+    IntentRecord(const IntentRecord&) = delete;
+    IntentRecord& operator=(const IntentRecord&) = delete;
+    ~IntentRecord() {
+        boost::system::error_code ec;
+        boost::filesystem::remove(path, ec);
+    }
+};
+}  // namespace
+
 void ArbCore::saveRocksdbCheckpoint(
     const boost::filesystem::path& save_rocksdb_path,
     ReadTransaction& tx) {
@@ -1989,8 +2155,25 @@ void ArbCore::saveRocksdbCheckpoint(
     } else {
         auto save_rocksdb_checkpoint_begin_timepoint =
             std::chrono::steady_clock::now();
+        if (coreConfig.database_save_incremental) {
+            auto backup_dir = save_rocksdb_path / "incremental";
+            IntentRecord intent(save_rocksdb_path / "incremental.intent");
+            auto status = tx.createRocksdbBackup(
+                backup_dir.string(), coreConfig.database_save_max_backups);
+            if (!status.ok()) {
+                std::cerr << "Unable to save backup into " << backup_dir
+                          << ", error: " << status.ToString() << std::endl;
+            } else {
+                std::cerr << "Saving incremental rocksdb backup in "
+                          << backup_dir << std::endl;
+                printElapsed(save_rocksdb_checkpoint_begin_timepoint,
+                             "Saving rocksdb backup took ");
+            }
+            return;
+        }
         auto timestamp_dir = std::to_string(seconds_since_epoch());
         auto checkpoint_dir = save_rocksdb_path / timestamp_dir;
+        IntentRecord intent(save_rocksdb_path / (timestamp_dir + ".intent"));
         auto status = tx.createRocksdbCheckpoint(checkpoint_dir.string());
         if (!status.ok()) {
             std::cerr << "Unable to save checkpoint into " << checkpoint_dir
@@ -2721,7 +2904,7 @@ ValueResult<uint256_t> ArbCore::getDelayedMessagesToSequence(
 
 std::unique_ptr<Machine> ArbCore::getLastMachine() {
     std::shared_lock<std::shared_mutex> guard(last_machine_mutex);
-    return std::make_unique<Machine>(*last_machine);
+    return last_machine->fork();
 }
 
 MachineOutput ArbCore::getLastMachineOutput() {
@@ -3179,14 +3362,11 @@ rocksdb::Status ArbCore::updateSendInsertedCount(ReadWriteTransaction& tx,
 }
 
 ValueResult<uint256_t> ArbCore::schemaVersion(ReadTransaction& tx) const {
-    return tx.stateGetUint256(vecToSlice(schema_version_key));
+    return readSchemaVersion(tx);
 }
 rocksdb::Status ArbCore::updateSchemaVersion(ReadWriteTransaction& tx,
                                              const uint256_t& schema_version) {
-    std::vector<unsigned char> value;
-    marshal_uint256_t(schema_version, value);
-
-    return tx.statePut(vecToSlice(schema_version_key), vecToSlice(value));
+    return writeSchemaVersion(tx, schema_version);
 }
 
 ValueResult<std::string> ArbCore::pruningMode(ReadTransaction& tx) const {
@@ -4051,6 +4231,27 @@ rocksdb::Status ArbCore::pruneCheckpoints(
 
     auto prune_begin_timepoint = std::chrono::steady_clock::now();
     uint64_t pruned_count = 0;
+    uint64_t pruned_bytes = 0;
+
+    // Checkpoints at least this new are always kept
+    std::optional<uint256_t> retain_timestamp;
+    if (coreConfig.checkpoint_pruning_age_seconds > 0) {
+        auto now = std::chrono::duration_cast<std::chrono::seconds>(
+                       std::chrono::system_clock::now().time_since_epoch())
+                       .count();
+        if (static_cast<uint64_t>(now) >
+            coreConfig.checkpoint_pruning_age_seconds) {
+            retain_timestamp = static_cast<uint64_t>(now) -
+                               coreConfig.checkpoint_pruning_age_seconds;
+        } else {
+            retain_timestamp = uint256_t{0};
+        }
+    }
+
+    // First checkpoint of each retained block interval is kept. Since the
+    // kept checkpoint is always the first of its interval, repeated pruning
+    // passes keep the same checkpoints.
+    std::optional<uint256_t> retained_interval;
     it->SeekToFirst();
     if (!it->Valid()) {
         if (!it->status().ok()) {
@@ -4091,6 +4292,12 @@ rocksdb::Status ArbCore::pruneCheckpoints(
             break;
         }
 
+        if (retain_timestamp.has_value() &&
+            machine_output.last_inbox_timestamp >= *retain_timestamp) {
+            // Remaining checkpoints are too recent to delete
+            break;
+        }
+
         // Check to see if current checkpoint is the last entry
         it->Next();
         if (!it->Valid()) {
@@ -4098,9 +4305,19 @@ rocksdb::Status ArbCore::pruneCheckpoints(
             break;
         }
 
+        if (coreConfig.checkpoint_retain_interval > 0) {
+            auto interval = machine_output.l2_block_number /
+                            coreConfig.checkpoint_retain_interval;
+            if (retained_interval != interval) {
+                retained_interval = interval;
+                continue;
+            }
+        }
+
         deleteCheckpoint(tx, checkpoint_variant);
         printMachineOutputInfo("Pruned checkpoint", machine_output);
         pruned_count++;
+        pruned_bytes += checkpoint_vector.size();
     }
     if (!it->status().ok()) {
         std::cerr << "unable to delete old checkpoints, "
@@ -4119,6 +4336,8 @@ rocksdb::Status ArbCore::pruneCheckpoints(
     if (pruned_count == 0) {
         return rocksdb::Status::NotFound();
     }
+    pruned_checkpoint_count += pruned_count;
+    pruned_checkpoint_bytes += pruned_bytes;
 
     // Calculate time including database commit
     std::cout << "Pruned " << pruned_count << " checkpoint(s)"
@@ -4150,6 +4369,130 @@ void ArbCore::updateCheckpointPruningGas(uint256_t gas) {
     unsafe_checkpoint_pruning_gas_used = gas;
 }
 
+// verifyCheckpoints loads the machine for every stored checkpoint and checks
+// that the hashes of the loaded values match the ones the checkpoint was saved
+// with. Returns the number of checkpoints checked and the number that are
+// corrupt, details of which are printed.
+std::pair<uint64_t, uint64_t> ArbCore::verifyCheckpoints() {
+    ReadSnapshotTransaction tx(data_storage);
+    auto it = tx.checkpointGetIterator();
+    uint64_t checked = 0;
+    uint64_t corrupt = 0;
+    std::optional<uint256_t> previous_gas;
+    for (it->SeekToFirst(); it->Valid(); it->Next()) {
+        checked++;
+        std::vector<unsigned char> checkpoint_vector(
+            it->value().data(), it->value().data() + it->value().size());
+        std::optional<CheckpointVariant> parsed;
+        try {
+            parsed = extractMachineStateKeys(checkpoint_vector);
+        } catch (const std::exception& e) {
+            std::cerr << "corrupt checkpoint record: " << e.what()
+                      << std::endl;
+            corrupt++;
+            continue;
+        }
+        auto& checkpoint_variant = *parsed;
+        auto output = getMachineOutput(checkpoint_variant);
+
+        std::vector<unsigned char> expected_key;
+        marshal_uint256_t(output.arb_gas_used, expected_key);
+        if (it->key() != vecToSlice(expected_key) ||
+            (previous_gas.has_value() && output.arb_gas_used <= *previous_gas)) {
+            printMachineOutputInfo("Checkpoint stored under wrong key",
+                                   output);
+            corrupt++;
+            continue;
+        }
+        previous_gas = output.arb_gas_used;
+
+        if (!std::holds_alternative<MachineStateKeys>(checkpoint_variant)) {
+            // Only holds machine output, no machine state to check
+            continue;
+        }
+        auto& keys = std::get<MachineStateKeys>(checkpoint_variant);
+        try {
+            ValueCache value_cache{1, 0};
+            auto machine =
+                getMachineUsingStateKeys<Machine>(tx, keys, value_cache, false);
+            auto loaded = MachineStateKeys{machine->machine_state};
+            if (loaded.static_hash != keys.static_hash ||
+                loaded.register_hash != keys.register_hash ||
+                loaded.datastack_hash != keys.datastack_hash ||
+                loaded.auxstack_hash != keys.auxstack_hash ||
+                loaded.machineHash() != keys.machineHash()) {
+                printMachineOutputInfo("Checkpoint machine hash mismatch",
+                                       output);
+                corrupt++;
+            }
+        } catch (const DataStorage::shutting_down_exception&) {
+            throw;
+        } catch (const std::exception& e) {
+            printMachineOutputInfo("Checkpoint machine failed to load", output);
+            std::cerr << "Exception message: " << e.what() << std::endl;
+            corrupt++;
+        }
+    }
+    if (!it->status().ok()) {
+        std::cerr << "error iterating checkpoints: " << it->status().ToString()
+                  << std::endl;
+        corrupt++;
+    }
+
+    return {checked, corrupt};
+}
+
+ValueResult<std::vector<ArbCore::CheckpointSummary>>
+ArbCore::checkpointSummaries(const uint256_t& from_gas, size_t max_count) {
+    ReadSnapshotTransaction tx(data_storage);
+    auto it = tx.checkpointGetIterator();
+    std::vector<unsigned char> from_key;
+    marshal_uint256_t(from_gas, from_key);
+
+    std::vector<CheckpointSummary> summaries;
+    for (it->Seek(vecToSlice(from_key));
+         it->Valid() && summaries.size() < max_count; it->Next()) {
+        std::vector<unsigned char> checkpoint_vector(
+            it->value().data(), it->value().data() + it->value().size());
+        auto checkpoint_variant = extractMachineStateKeys(checkpoint_vector);
+        CheckpointSummary summary{getMachineOutput(checkpoint_variant),
+                                  std::nullopt};
+        if (std::holds_alternative<MachineStateKeys>(checkpoint_variant)) {
+            summary.machine_hash =
+                std::get<MachineStateKeys>(checkpoint_variant).machineHash();
+        }
+        summaries.push_back(std::move(summary));
+    }
+    return {it->status(), std::move(summaries)};
+}
+
+uint64_t ArbCore::prunedCheckpointCount() const {
+    return pruned_checkpoint_count;
+}
+
+uint64_t ArbCore::prunedCheckpointBytes() const {
+    return pruned_checkpoint_bytes;
+}
+
+ArbCore::CheckpointStats ArbCore::checkpointStats() const {
+    return {saved_checkpoint_count, saved_checkpoint_bytes,
+            checkpoint_save_nanoseconds, last_checkpoint_save_nanoseconds,
+            last_checkpoint_restore_nanoseconds};
+}
+
+void ArbCore::setProfiling(bool enabled) {
+    std::lock_guard<std::mutex> lock(profile_mutex);
+    if (enabled && !profiling) {
+        profile = ExecutionProfile{};
+    }
+    profiling = enabled;
+}
+
+std::optional<ExecutionProfile> ArbCore::getProfile() {
+    std::lock_guard<std::mutex> lock(profile_mutex);
+    return profile;
+}
+
 uint256_t ArbCore::getCheckpointPruningGas() {
     std::lock_guard<std::mutex> lock(checkpoint_pruning_mutex);
     return unsafe_checkpoint_pruning_gas_used;
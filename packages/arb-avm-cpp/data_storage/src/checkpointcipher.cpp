@@ -0,0 +1,126 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+#include <data_storage/checkpointcipher.hpp>
+
+#include <openssl/evp.h>
+#include <openssl/rand.h>
+
+#include <stdexcept>
+
+namespace {
+constexpr unsigned char encrypted_record_flag = 0xec;
+constexpr size_t nonce_size = 12;
+constexpr size_t tag_size = 16;
+
+struct CipherContext {
+    EVP_CIPHER_CTX* ctx;
+
+    CipherContext() : ctx(EVP_CIPHER_CTX_new()) {
+        if (ctx == nullptr) {
+            throw std::runtime_error("failed to create cipher context");
+        }
+    }
+    CipherContext(const CipherContext&) = delete;
+    CipherContext& operator=(const CipherContext&) = delete;
+    ~CipherContext() { EVP_CIPHER_CTX_free(ctx); }
+};
+}  // namespace
+
+bool isEncryptedCheckpointRecord(const rocksdb::Slice& record) {
+    return !record.empty() &&
+           static_cast<unsigned char>(record[0]) == encrypted_record_flag;
+}
+
+std::vector<unsigned char> encryptCheckpointRecord(
+    const CheckpointKey& key,
+    const rocksdb::Slice& db_key,
+    const rocksdb::Slice& plaintext) {
+    std::vector<unsigned char> record(1 + nonce_size + plaintext.size() +
+                                      tag_size);
+    record[0] = encrypted_record_flag;
+    auto nonce = record.data() + 1;
+    if (RAND_bytes(nonce, nonce_size) != 1) {
+        throw std::runtime_error("failed to generate checkpoint nonce");
+    }
+
+    CipherContext cipher;
+    int len = 0;
+    auto ciphertext = nonce + nonce_size;
+    if (EVP_EncryptInit_ex(cipher.ctx, EVP_aes_256_gcm(), nullptr,
+                           key.data(), nonce) != 1 ||
+        EVP_EncryptUpdate(
+            cipher.ctx, nullptr, &len,
+            reinterpret_cast<const unsigned char*>(db_key.data()),
+            static_cast<int>(db_key.size())) != 1 ||
+        EVP_EncryptUpdate(
+            cipher.ctx, ciphertext, &len,
+            reinterpret_cast<const unsigned char*>(plaintext.data()),
+            static_cast<int>(plaintext.size())) != 1 ||
+        EVP_EncryptFinal_ex(cipher.ctx, ciphertext + len, &len) != 1 ||
+        EVP_CIPHER_CTX_ctrl(cipher.ctx, EVP_CTRL_GCM_GET_TAG, tag_size,
+                            ciphertext + plaintext.size()) != 1) {
+        throw std::runtime_error("failed to encrypt checkpoint");
+    }
+    return record;
+}
+
+std::vector<unsigned char> decryptCheckpointRecord(
+    const std::optional<CheckpointKey>& key,
+    const rocksdb::Slice& db_key,
+    const rocksdb::Slice& record) {
+    auto data = reinterpret_cast<const unsigned char*>(record.data());
+    if (!isEncryptedCheckpointRecord(record)) {
+        return {data, data + record.size()};
+    }
+    if (!key.has_value()) {
+        throw std::runtime_error(
+            "checkpoint is encrypted but no checkpoint key is configured");
+    }
+    if (record.size() < 1 + nonce_size + tag_size) {
+        throw std::runtime_error("encrypted checkpoint is truncated");
+    }
+
+    auto nonce = data + 1;
+    auto ciphertext = nonce + nonce_size;
+    auto ciphertext_size = record.size() - 1 - nonce_size - tag_size;
+    std::vector<unsigned char> tag(ciphertext + ciphertext_size,
+                                   ciphertext + ciphertext_size + tag_size);
+    std::vector<unsigned char> plaintext(ciphertext_size);
+
+    CipherContext cipher;
+    int len = 0;
+    if (EVP_DecryptInit_ex(cipher.ctx, EVP_aes_256_gcm(), nullptr,
+                           key->data(), nonce) != 1 ||
+        EVP_DecryptUpdate(
+            cipher.ctx, nullptr, &len,
+            reinterpret_cast<const unsigned char*>(db_key.data()),
+            static_cast<int>(db_key.size())) != 1 ||
+        EVP_DecryptUpdate(cipher.ctx, plaintext.data(), &len, ciphertext,
+                          static_cast<int>(ciphertext_size)) != 1 ||
+        EVP_CIPHER_CTX_ctrl(cipher.ctx, EVP_CTRL_GCM_SET_TAG, tag_size,
+                            tag.data()) != 1 ||
+        EVP_DecryptFinal_ex(cipher.ctx, plaintext.data() + len, &len) != 1) {
+        throw std::runtime_error(
+            "failed to decrypt checkpoint, wrong key or corrupt record");
+    }
+    return plaintext;
+}
+
+rocksdb::Slice DecryptingIterator::value() const {
+    plaintext = decryptCheckpointRecord(key_, it->key(), it->value());
+    return {reinterpret_cast<const char*>(plaintext.data()), plaintext.size()};
+}
@@ -17,16 +17,34 @@
 #include <data_storage/datastorage.hpp>
 #include <data_storage/readtransaction.hpp>
 #include <data_storage/storageresult.hpp>
+#include <data_storage/value/utils.hpp>
 
 #include <openssl/rand.h>
 #include <rocksdb/convenience.h>
 #include <rocksdb/filter_policy.h>
+#include <rocksdb/write_batch.h>
 
 #include <iostream>
+#include <stdexcept>
 #include <string>
 #include <thread>
 #include <utility>
 
+namespace {
+rocksdb::CompressionType parseCompressionType(const std::string& name) {
+    if (name == "none") {
+        return rocksdb::CompressionType::kNoCompression;
+    } else if (name == "snappy") {
+        return rocksdb::CompressionType::kSnappyCompression;
+    } else if (name == "lz4") {
+        return rocksdb::CompressionType::kLZ4Compression;
+    } else if (name == "zstd") {
+        return rocksdb::CompressionType::kZSTD;
+    }
+    throw std::runtime_error("unrecognized database compression: " + name);
+}
+}  // namespace
+
 DataStorage::DataStorage(const std::string& db_path,
                          const ArbCoreConfig& coreConfig) {
     // Make sure database isn't closed while constructor still running
@@ -59,6 +77,18 @@ DataStorage::DataStorage(const std::string& db_path,
     // No need to keep old log files
     options.keep_log_file_num = 3;
 
+    // Compression type is recorded per block, so data written with a
+    // previous setting stays readable and is converted as it is compacted
+    if (!coreConfig.database_compression.empty() &&
+        coreConfig.database_compression != "default") {
+        auto compression =
+            parseCompressionType(coreConfig.database_compression);
+        cf_options.compression = compression;
+        if (compression == rocksdb::CompressionType::kZSTD) {
+            cf_options.bottommost_compression = compression;
+        }
+    }
+
     // Settings for small tables
     small_cf_options = cf_options;
     small_cf_options.num_levels = 2;
@@ -99,6 +129,15 @@ DataStorage::DataStorage(const std::string& db_path,
 
     txn_db = std::unique_ptr<rocksdb::TransactionDB>(db);
 
+    checkpoint_key = coreConfig.checkpoint_encryption_key;
+    if (checkpoint_key.has_value()) {
+        // Done before any compaction so old plaintext versions get removed
+        status = encryptExistingCheckpoints();
+        if (!status.ok()) {
+            throw std::runtime_error(status.ToString());
+        }
+    }
+
     if (coreConfig.database_compact) {
         // Optimize database
         // This is first database compaction, second compaction is done after
@@ -120,6 +159,26 @@ DataStorage::DataStorage(const std::string& db_path,
     }
 }
 
+uint64_t DataStorage::totalSstFilesSize() {
+    uint64_t total = 0;
+    for (size_t i = 0; i < FAMILY_COLUMN_COUNT; i++) {
+        total += sstFilesSize(static_cast<column_family_indexes>(i));
+    }
+    return total;
+}
+
+uint64_t DataStorage::sstFilesSize(column_family_indexes column) {
+    auto counter = tryLockShared();
+
+    uint64_t size = 0;
+    if (!txn_db->GetIntProperty(column_handles[column],
+                                rocksdb::DB::Properties::kTotalSstFilesSize,
+                                &size)) {
+        return 0;
+    }
+    return size;
+}
+
 DataStorage::~DataStorage() {
     auto status = closeDb();
     if (!status.ok()) {
@@ -259,6 +318,41 @@ rocksdb::Status DataStorage::clearDBExceptInbox() {
     return rocksdb::Status::OK();
 }
 
+// encryptExistingCheckpoints rewrites any plaintext checkpoint records with
+// the configured checkpoint key
+rocksdb::Status DataStorage::encryptExistingCheckpoints() {
+    // Make sure database isn't closed while it is being used
+    auto lock = tryLockShared();
+
+    rocksdb::WriteBatch batch;
+    uint64_t encrypted_count = 0;
+    std::unique_ptr<rocksdb::Iterator> it(txn_db->NewIterator(
+        rocksdb::ReadOptions(), column_handles[CHECKPOINT_COLUMN]));
+    for (it->SeekToFirst(); it->Valid(); it->Next()) {
+        if (isEncryptedCheckpointRecord(it->value())) {
+            continue;
+        }
+        auto record =
+            encryptCheckpointRecord(*checkpoint_key, it->key(), it->value());
+        auto status = batch.Put(column_handles[CHECKPOINT_COLUMN], it->key(),
+                                vecToSlice(record));
+        if (!status.ok()) {
+            return status;
+        }
+        encrypted_count++;
+    }
+    if (!it->status().ok()) {
+        return it->status();
+    }
+    if (encrypted_count == 0) {
+        return rocksdb::Status::OK();
+    }
+
+    std::cerr << "Encrypting " << encrypted_count << " existing checkpoint(s)"
+              << std::endl;
+    return txn_db->Write(rocksdb::WriteOptions(), &batch);
+}
+
 rocksdb::Status DataStorage::updateSecretHashSeed() {
     std::string key("secretHashSeed");
 
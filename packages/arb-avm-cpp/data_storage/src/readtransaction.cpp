@@ -15,9 +15,11 @@
  */
 
 #include "data_storage/readtransaction.hpp"
+#include <data_storage/checkpointcipher.hpp>
 #include <data_storage/storageresult.hpp>
 #include <data_storage/value/utils.hpp>
 
+#include <rocksdb/utilities/backupable_db.h>
 #include <rocksdb/utilities/checkpoint.h>
 
 ReadTransaction::ReadTransaction(std::shared_ptr<DataStorage> store)
@@ -41,6 +43,42 @@ rocksdb::Status ReadTransaction::createRocksdbCheckpoint(
     return status;
 }
 
+rocksdb::Status ReadTransaction::createRocksdbBackup(
+    const std::string& backup_dir,
+    uint32_t max_backups) const {
+    // Make sure database isn't closed while it is being used
+    auto counter = transaction->datastorage->tryLockShared();
+
+    rocksdb::BackupEngine* backup_engine;
+    auto status = rocksdb::BackupEngine::Open(
+        rocksdb::Env::Default(), rocksdb::BackupableDBOptions(backup_dir),
+        &backup_engine);
+    if (!status.ok()) {
+        return status;
+    }
+    std::unique_ptr<rocksdb::BackupEngine> engine(backup_engine);
+
+    status =
+        engine->CreateNewBackup(transaction->datastorage->txn_db.get(), true);
+    if (!status.ok() || max_backups == 0) {
+        return status;
+    }
+    return engine->PurgeOldBackups(max_backups);
+}
+
+rocksdb::Status restoreRocksdbBackup(const std::string& backup_dir,
+                                     const std::string& db_dir) {
+    rocksdb::BackupEngineReadOnly* backup_engine;
+    auto status = rocksdb::BackupEngineReadOnly::Open(
+        rocksdb::Env::Default(), rocksdb::BackupableDBOptions(backup_dir),
+        &backup_engine);
+    if (!status.ok()) {
+        return status;
+    }
+    std::unique_ptr<rocksdb::BackupEngineReadOnly> engine(backup_engine);
+    return engine->RestoreDBFromLatestBackup(db_dir, db_dir);
+}
+
 rocksdb::Status ReadTransaction::defaultGet(const rocksdb::Slice& key,
                                             std::string* value) const {
     // Make sure database isn't closed while it is being used
@@ -66,11 +104,17 @@ rocksdb::Status ReadTransaction::checkpointGet(const rocksdb::Slice& key,
     // Make sure database isn't closed while it is being used
     auto counter = transaction->datastorage->tryLockShared();
 
-    return transaction->transaction->Get(
+    auto status = transaction->transaction->Get(
         read_options,
         transaction->datastorage
             ->column_handles[DataStorage::CHECKPOINT_COLUMN],
         key, value);
+    if (status.ok() && isEncryptedCheckpointRecord(*value)) {
+        auto plaintext = decryptCheckpointRecord(
+            transaction->datastorage->checkpoint_key, key, *value);
+        value->assign(plaintext.begin(), plaintext.end());
+    }
+    return status;
 }
 rocksdb::Status ReadTransaction::logGet(const rocksdb::Slice& key,
                                         std::string* value) const {
@@ -158,7 +202,9 @@ std::unique_ptr<rocksdb::Iterator> ReadTransaction::checkpointGetIterator()
     auto it = transaction->transaction->GetIterator(
         read_options, transaction->datastorage
                           ->column_handles[DataStorage::CHECKPOINT_COLUMN]);
-    return std::unique_ptr<rocksdb::Iterator>(it);
+    return std::make_unique<DecryptingIterator>(
+        std::unique_ptr<rocksdb::Iterator>(it),
+        transaction->datastorage->checkpoint_key);
 }
 
 std::unique_ptr<rocksdb::Iterator>
@@ -15,6 +15,8 @@
  */
 
 #include "data_storage/readwritetransaction.hpp"
+#include <data_storage/checkpointcipher.hpp>
+#include <data_storage/value/utils.hpp>
 
 #include <utility>
 
@@ -47,6 +49,14 @@ rocksdb::Status ReadWriteTransaction::checkpointPut(
     // Make sure database isn't closed while it is being used
     auto counter = transaction->datastorage->tryLockShared();
 
+    auto& checkpoint_key = transaction->datastorage->checkpoint_key;
+    if (checkpoint_key.has_value()) {
+        auto record = encryptCheckpointRecord(*checkpoint_key, key, value);
+        return transaction->transaction->Put(
+            transaction->datastorage
+                ->column_handles[DataStorage::CHECKPOINT_COLUMN],
+            key, vecToSlice(record));
+    }
     return transaction->transaction->Put(
         transaction->datastorage
             ->column_handles[DataStorage::CHECKPOINT_COLUMN],
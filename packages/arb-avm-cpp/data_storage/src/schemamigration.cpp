@@ -0,0 +1,109 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+#include <data_storage/schemamigration.hpp>
+
+#include <data_storage/datastorage.hpp>
+#include <data_storage/readwritetransaction.hpp>
+#include <data_storage/value/utils.hpp>
+
+#include <array>
+#include <iostream>
+
+namespace {
+constexpr auto schema_version_key = std::array<char, 1>{-64};
+}  // namespace
+
+ValueResult<uint256_t> readSchemaVersion(ReadTransaction& tx) {
+    return tx.stateGetUint256(vecToSlice(schema_version_key));
+}
+
+rocksdb::Status writeSchemaVersion(ReadWriteTransaction& tx,
+                                   const uint256_t& schema_version) {
+    std::vector<unsigned char> value;
+    marshal_uint256_t(schema_version, value);
+
+    return tx.statePut(vecToSlice(schema_version_key), vecToSlice(value));
+}
+
+const std::vector<SchemaMigration>& schemaMigrations() {
+    // Layouts from before version 3 predate migrations and must be resynced
+    static const std::vector<SchemaMigration> migrations{};
+    return migrations;
+}
+
+rocksdb::Status migrateSchema(std::shared_ptr<DataStorage> storage,
+                              const uint256_t& target_version,
+                              const std::vector<SchemaMigration>& migrations) {
+    uint256_t version;
+    {
+        ReadTransaction tx(storage);
+        auto version_result = readSchemaVersion(tx);
+        if (!version_result.status.ok()) {
+            return version_result.status;
+        }
+        version = version_result.data;
+    }
+    if (version > target_version) {
+        std::cerr << "Database version " << version
+                  << " is newer than supported version " << target_version
+                  << ", upgrade the node" << std::endl;
+        return rocksdb::Status::NotSupported();
+    }
+
+    // Make sure every step is available before changing anything
+    std::vector<const SchemaMigration*> steps;
+    for (auto step_version = version; step_version < target_version;
+         step_version += 1) {
+        const SchemaMigration* step = nullptr;
+        for (const auto& migration : migrations) {
+            if (migration.from_version == step_version) {
+                step = &migration;
+                break;
+            }
+        }
+        if (step == nullptr) {
+            std::cerr << "No migration from database version " << step_version
+                      << ", delete database and try again" << std::endl;
+            return rocksdb::Status::NotSupported();
+        }
+        steps.push_back(step);
+    }
+
+    for (auto step : steps) {
+        std::cerr << "Migrating database from version " << step->from_version
+                  << " to " << step->from_version + 1 << ": "
+                  << step->description << std::endl;
+        ReadWriteTransaction tx(storage);
+        auto status = step->migrate(tx);
+        if (!status.ok()) {
+            std::cerr << "Database migration failed: " << status.ToString()
+                      << std::endl;
+            return status;
+        }
+        status = writeSchemaVersion(tx, step->from_version + 1);
+        if (!status.ok()) {
+            return status;
+        }
+        status = tx.commit();
+        if (!status.ok()) {
+            std::cerr << "Unable to commit database migration: "
+                      << status.ToString() << std::endl;
+            return status;
+        }
+    }
+    return rocksdb::Status::OK();
+}
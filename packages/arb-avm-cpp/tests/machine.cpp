@@ -167,6 +167,22 @@ TEST_CASE("Clone") {
     }
 }
 
+TEST_CASE("Fork") {
+    auto machine = Machine::loadFromFile(test_contract_path);
+    auto original_hash = machine.hash();
+
+    auto fork = machine.fork();
+    REQUIRE(fork->hash() == original_hash);
+
+    MachineExecutionConfig execConfig;
+    execConfig.max_gas = 100;
+    fork->machine_state.context = AssertionContext(execConfig);
+    auto assertion = fork->run();
+    REQUIRE(assertion.step_count > 0);
+    REQUIRE(fork->hash() != original_hash);
+    REQUIRE(machine.hash() == original_hash);
+}
+
 TEST_CASE("Machine hash") {
     MachineState machine = MachineState::loadFromFile(test_contract_path);
     auto pcHash = ::hash(machine.loadCurrentInstruction());
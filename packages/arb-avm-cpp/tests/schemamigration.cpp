@@ -0,0 +1,105 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+#include "helper.hpp"
+
+#include <data_storage/datastorage.hpp>
+#include <data_storage/readwritetransaction.hpp>
+#include <data_storage/schemamigration.hpp>
+#include <data_storage/value/utils.hpp>
+
+#include <catch2/catch.hpp>
+
+namespace {
+void setVersion(const std::shared_ptr<DataStorage>& storage,
+                const uint256_t& version) {
+    ReadWriteTransaction tx(storage);
+    REQUIRE(writeSchemaVersion(tx, version).ok());
+    REQUIRE(tx.commit().ok());
+}
+
+uint256_t getVersion(const std::shared_ptr<DataStorage>& storage) {
+    ReadTransaction tx(storage);
+    auto result = readSchemaVersion(tx);
+    REQUIRE(result.status.ok());
+    return result.data;
+}
+
+SchemaMigration markerMigration(const uint256_t& from_version, char marker) {
+    return {from_version, "write marker",
+            [marker](ReadWriteTransaction& tx) {
+                std::vector<unsigned char> key{static_cast<unsigned char>(
+                    marker)};
+                std::vector<unsigned char> value{1};
+                return tx.defaultPut(vecToSlice(key), vecToSlice(value));
+            }};
+}
+
+bool hasMarker(const std::shared_ptr<DataStorage>& storage, char marker) {
+    ReadTransaction tx(storage);
+    std::vector<unsigned char> key{static_cast<unsigned char>(marker)};
+    std::string value;
+    return tx.defaultGet(vecToSlice(key), &value).ok();
+}
+}  // namespace
+
+TEST_CASE("Schema migration") {
+    DBDeleter deleter;
+    ArbCoreConfig coreConfig{};
+    auto storage = std::make_shared<DataStorage>(dbpath, coreConfig);
+
+    SECTION("runs every step in order") {
+        setVersion(storage, 1);
+        std::vector<SchemaMigration> migrations{markerMigration(2, 'b'),
+                                                markerMigration(1, 'a')};
+        REQUIRE(migrateSchema(storage, 3, migrations).ok());
+        REQUIRE(getVersion(storage) == 3);
+        REQUIRE(hasMarker(storage, 'a'));
+        REQUIRE(hasMarker(storage, 'b'));
+    }
+
+    SECTION("current version is unchanged") {
+        setVersion(storage, 3);
+        REQUIRE(migrateSchema(storage, 3, {}).ok());
+        REQUIRE(getVersion(storage) == 3);
+    }
+
+    SECTION("missing step changes nothing") {
+        setVersion(storage, 1);
+        std::vector<SchemaMigration> migrations{markerMigration(2, 'b')};
+        REQUIRE(!migrateSchema(storage, 3, migrations).ok());
+        REQUIRE(getVersion(storage) == 1);
+        REQUIRE(!hasMarker(storage, 'b'));
+    }
+
+    SECTION("newer database is rejected") {
+        setVersion(storage, 4);
+        REQUIRE(!migrateSchema(storage, 3, {}).ok());
+        REQUIRE(getVersion(storage) == 4);
+    }
+
+    SECTION("failed step keeps completed steps") {
+        setVersion(storage, 1);
+        std::vector<SchemaMigration> migrations{
+            markerMigration(1, 'a'),
+            {2, "fail", [](ReadWriteTransaction&) {
+                 return rocksdb::Status::Corruption();
+             }}};
+        REQUIRE(!migrateSchema(storage, 3, migrations).ok());
+        REQUIRE(getVersion(storage) == 2);
+        REQUIRE(hasMarker(storage, 'a'));
+    }
+}
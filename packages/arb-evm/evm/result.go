@@ -365,7 +365,7 @@ func (fs *FeeSet) Total() *big.Int {
 func NewFeeSetFromValue(val value.Value) (*FeeSet, error) {
 	tup, ok := val.(*value.TupleValue)
 	if !ok || tup.Len() != 4 {
-		return nil, errors.Errorf("expected fee set tuple of length 4, but recieved %v", val)
+		return nil, errors.Errorf("expected fee set tuple of length 4, but recieved %v", value.Format(val, value.DefaultFormatOptions))
 	}
 	l1Transaction, _ := tup.GetByInt64(0)
 	l1Calldata, _ := tup.GetByInt64(1)
@@ -429,7 +429,7 @@ func (fs *FeeStats) TargetGasUsed() *big.Int {
 func NewFeeStatsFromValue(val value.Value) (*FeeStats, error) {
 	tup, ok := val.(*value.TupleValue)
 	if !ok || tup.Len() < 4 || tup.Len() > 5 {
-		return nil, errors.Errorf("expected gas fee tuple of length 4 or 5, but recieved %v", val)
+		return nil, errors.Errorf("expected gas fee tuple of length 4 or 5, but recieved %v", value.Format(val, value.DefaultFormatOptions))
 	}
 	pricesVal, _ := tup.GetByInt64(0)
 	unitsVal, _ := tup.GetByInt64(1)
@@ -576,7 +576,7 @@ func NewResultFromValue(val value.Value) (Result, error) {
 
 	if kindInt.BigInt().Uint64() == 0 {
 		if tup.Len() != 6 && tup.Len() != 7 {
-			return nil, errors.Errorf("tx result expected tuple of length 6 or 7, but recieved len %v: %v", tup.Len(), tup)
+			return nil, errors.Errorf("tx result expected tuple of length 6 or 7, but recieved len %v: %v", tup.Len(), value.Format(tup, value.DefaultFormatOptions))
 		}
 
 		// Tuple size already verified above, so error can be ignored
@@ -588,7 +588,7 @@ func NewResultFromValue(val value.Value) (Result, error) {
 		return parseTxResult(l1MsgVal, resultInfo, gasInfo, chainInfo, feeStats)
 	} else if kindInt.BigInt().Uint64() == 1 {
 		if tup.Len() != 8 {
-			return nil, errors.Errorf("block result expected tuple of length 8, but received len %v: %v", tup.Len(), tup)
+			return nil, errors.Errorf("block result expected tuple of length 8, but received len %v: %v", tup.Len(), value.Format(tup, value.DefaultFormatOptions))
 		}
 
 		// Tuple size already verified above, so error can be ignored
@@ -0,0 +1,234 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package assertions
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/arblog"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+var logger = arblog.Logger.With().Str("component", "assertions").Logger()
+
+// Assertion is a node created on L1 along with what the local checkpoint
+// store knows about its end state
+type Assertion struct {
+	*core.NodeInfo
+	// L2Block is the last L2 block produced by the assertion, or nil if its
+	// end state has no local checkpoint
+	L2Block *big.Int `json:"l2Block"`
+	// MatchesLocal is whether the asserted machine hash matches local
+	// execution, or nil if its end state has no local checkpoint
+	MatchesLocal *bool `json:"matchesLocal"`
+}
+
+// History looks up past assertions. Nodes are read from the rollup's
+// NodeCreated events and cached, so only new events are requested after the
+// first query. If the history has a node log, the cache survives restarts.
+type History struct {
+	rollup *ethbridge.RollupWatcher
+	lookup core.ArbCoreLookup
+
+	mutex sync.Mutex
+	nodes []*core.NodeInfo
+	log   *nodeLog
+}
+
+func NewHistory(rollup *ethbridge.RollupWatcher, lookup core.ArbCoreLookup) *History {
+	return &History{rollup: rollup, lookup: lookup}
+}
+
+// NewHistoryWithLog creates a history whose nodes are kept in a write-ahead
+// log at logPath, starting from the nodes already logged there
+func NewHistoryWithLog(rollup *ethbridge.RollupWatcher, lookup core.ArbCoreLookup, logPath string) (*History, error) {
+	log, nodes, err := openNodeLog(logPath)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info().Int("nodes", len(nodes)).Msg("loaded assertion history from node log")
+	return &History{rollup: rollup, lookup: lookup, nodes: nodes, log: log}, nil
+}
+
+// Close closes the node log, if any
+func (h *History) Close() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.log == nil {
+		return nil
+	}
+	err := h.log.close()
+	h.log = nil
+	return err
+}
+
+// addNodes logs nodes before adding them to the cache so that the log never
+// holds less than the cache
+func (h *History) addNodes(nodes []*core.NodeInfo) error {
+	if h.log != nil {
+		if err := h.log.append(nodes); err != nil {
+			return err
+		}
+	}
+	h.nodes = append(h.nodes, nodes...)
+	return nil
+}
+
+func (h *History) resetNodes() error {
+	h.nodes = nil
+	if h.log != nil {
+		return h.log.reset()
+	}
+	return nil
+}
+
+func (h *History) update(ctx context.Context) ([]*core.NodeInfo, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.updateLocked(ctx)
+}
+
+func (h *History) updateLocked(ctx context.Context) ([]*core.NodeInfo, error) {
+	if len(h.nodes) == 0 {
+		nodes, err := h.rollup.LookupNodesCreated(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := h.addNodes(nodes); err != nil {
+			return nil, err
+		}
+		return h.nodes, nil
+	}
+	// An L1 reorg can replace any node, so the whole list is reloaded if the
+	// latest cached node no longer exists as it was seen
+	last := h.nodes[len(h.nodes)-1]
+	node, err := h.rollup.LookupNode(ctx, last.NodeNum)
+	if err != nil || node.NodeHash != last.NodeHash {
+		logger.Info().Str("node", (*big.Int)(last.NodeNum).String()).Msg("cached node changed, reloading assertion history")
+		if err := h.resetNodes(); err != nil {
+			return nil, err
+		}
+		return h.updateLocked(ctx)
+	}
+	fromBlock := new(big.Int).Add(last.BlockProposed.Height.AsInt(), big.NewInt(1))
+	nodes, err := h.rollup.LookupNodesCreatedInRange(ctx, fromBlock, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.addNodes(nodes); err != nil {
+		return nil, err
+	}
+	return h.nodes, nil
+}
+
+func (h *History) withLocalState(nodes []*core.NodeInfo) []*Assertion {
+	assertions := make([]*Assertion, 0, len(nodes))
+	for _, node := range nodes {
+		assertion := &Assertion{NodeInfo: node}
+		// A checkpoint at exactly the node's end state can be read without
+		// loading its machine
+		checkpoints, err := h.lookup.CheckpointSummaries(node.Assertion.After.TotalGasConsumed, 1)
+		if err == nil && len(checkpoints) == 1 && checkpoints[0].MachineHash != nil &&
+			checkpoints[0].TotalGasConsumed.Cmp(node.Assertion.After.TotalGasConsumed) == 0 {
+			matches := *checkpoints[0].MachineHash == node.Assertion.After.MachineHash
+			assertion.L2Block = checkpoints[0].L2BlockNumber
+			assertion.MatchesLocal = &matches
+			assertions = append(assertions, assertion)
+			continue
+		}
+		cursor, err := h.lookup.GetExecutionCursor(node.Assertion.After.TotalGasConsumed, false)
+		if err == nil && cursor.TotalGasConsumed().Cmp(node.Assertion.After.TotalGasConsumed) == 0 {
+			matches := cursor.MachineHash() == node.Assertion.After.MachineHash
+			assertion.L2Block = cursor.L2BlockNumber()
+			assertion.MatchesLocal = &matches
+		}
+		assertions = append(assertions, assertion)
+	}
+	return assertions
+}
+
+// ByNodeHash returns the assertion of the node with the given hash
+func (h *History) ByNodeHash(ctx context.Context, hash common.Hash) (*Assertion, error) {
+	nodes, err := h.update(ctx)
+	if err != nil {
+		return nil, err
+	}
+	node := findByNodeHash(nodes, hash)
+	if node == nil {
+		return nil, errors.Errorf("no node with hash %v", hash)
+	}
+	return h.withLocalState([]*core.NodeInfo{node})[0], nil
+}
+
+// AtL1Block returns the assertions made in the given L1 block
+func (h *History) AtL1Block(ctx context.Context, block *big.Int) ([]*Assertion, error) {
+	nodes, err := h.update(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return h.withLocalState(filterByL1Block(nodes, block)), nil
+}
+
+// ByMessageRange returns the assertions which read any of the L2 messages
+// from start inclusive to end exclusive
+func (h *History) ByMessageRange(ctx context.Context, start, end *big.Int) ([]*Assertion, error) {
+	if start.Cmp(end) >= 0 {
+		return nil, errors.New("empty message range")
+	}
+	nodes, err := h.update(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return h.withLocalState(filterByMessageRange(nodes, start, end)), nil
+}
+
+func findByNodeHash(nodes []*core.NodeInfo, hash common.Hash) *core.NodeInfo {
+	for _, node := range nodes {
+		if node.NodeHash == hash {
+			return node
+		}
+	}
+	return nil
+}
+
+func filterByL1Block(nodes []*core.NodeInfo, block *big.Int) []*core.NodeInfo {
+	var ret []*core.NodeInfo
+	for _, node := range nodes {
+		if node.BlockProposed.Height.AsInt().Cmp(block) == 0 {
+			ret = append(ret, node)
+		}
+	}
+	return ret
+}
+
+func filterByMessageRange(nodes []*core.NodeInfo, start, end *big.Int) []*core.NodeInfo {
+	var ret []*core.NodeInfo
+	for _, node := range nodes {
+		before := node.Assertion.Before.TotalMessagesRead
+		after := node.Assertion.After.TotalMessagesRead
+		if before.Cmp(end) < 0 && after.Cmp(start) > 0 {
+			ret = append(ret, node)
+		}
+	}
+	return ret
+}
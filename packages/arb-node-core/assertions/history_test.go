@@ -0,0 +1,85 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package assertions
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+func testNode(num, l1Block, messagesBefore, messagesAfter int64) *core.NodeInfo {
+	return &core.NodeInfo{
+		NodeNum:       big.NewInt(num),
+		BlockProposed: &common.BlockId{Height: common.NewTimeBlocksInt(l1Block)},
+		Assertion: &core.Assertion{
+			Before: &core.ExecutionState{TotalMessagesRead: big.NewInt(messagesBefore)},
+			After:  &core.ExecutionState{TotalMessagesRead: big.NewInt(messagesAfter)},
+		},
+		NodeHash: common.Hash{byte(num)},
+	}
+}
+
+func nodeNums(nodes []*core.NodeInfo) []int64 {
+	nums := make([]int64, 0, len(nodes))
+	for _, node := range nodes {
+		nums = append(nums, (*big.Int)(node.NodeNum).Int64())
+	}
+	return nums
+}
+
+func checkNodeNums(t *testing.T, name string, nodes []*core.NodeInfo, expected ...int64) {
+	t.Helper()
+	nums := nodeNums(nodes)
+	if len(nums) != len(expected) {
+		t.Errorf("%v: expected nodes %v, got %v", name, expected, nums)
+		return
+	}
+	for i := range nums {
+		if nums[i] != expected[i] {
+			t.Errorf("%v: expected nodes %v, got %v", name, expected, nums)
+			return
+		}
+	}
+}
+
+func TestHistoryFilters(t *testing.T) {
+	nodes := []*core.NodeInfo{
+		testNode(1, 10, 0, 5),
+		testNode(2, 12, 5, 9),
+		testNode(3, 12, 5, 7),
+		testNode(4, 15, 9, 9),
+		testNode(5, 20, 9, 14),
+	}
+
+	if node := findByNodeHash(nodes, common.Hash{3}); node == nil || (*big.Int)(node.NodeNum).Int64() != 3 {
+		t.Error("didn't find node by hash")
+	}
+	if findByNodeHash(nodes, common.Hash{6}) != nil {
+		t.Error("found node with unknown hash")
+	}
+
+	checkNodeNums(t, "l1 block 12", filterByL1Block(nodes, big.NewInt(12)), 2, 3)
+	checkNodeNums(t, "l1 block 11", filterByL1Block(nodes, big.NewInt(11)))
+
+	checkNodeNums(t, "messages 0-5", filterByMessageRange(nodes, big.NewInt(0), big.NewInt(5)), 1)
+	checkNodeNums(t, "messages 4-6", filterByMessageRange(nodes, big.NewInt(4), big.NewInt(6)), 1, 2, 3)
+	checkNodeNums(t, "messages 8-10", filterByMessageRange(nodes, big.NewInt(8), big.NewInt(10)), 2, 5)
+	checkNodeNums(t, "messages 14-20", filterByMessageRange(nodes, big.NewInt(14), big.NewInt(20)))
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package assertions
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+// loggedNode is the serialized form of a core.NodeInfo in the node log
+type loggedNode struct {
+	NodeNum                 *big.Int             `json:"nodeNum"`
+	ProposedBlock           *big.Int             `json:"proposedBlock"`
+	ProposedBlockHash       common.Hash          `json:"proposedBlockHash"`
+	Before                  *core.ExecutionState `json:"before"`
+	After                   *core.ExecutionState `json:"after"`
+	InboxMaxCount           *big.Int             `json:"inboxMaxCount"`
+	NodeHash                common.Hash          `json:"nodeHash"`
+	AfterInboxBatchEndCount *big.Int             `json:"afterInboxBatchEndCount"`
+	AfterInboxBatchAcc      common.Hash          `json:"afterInboxBatchAcc"`
+}
+
+func newLoggedNode(node *core.NodeInfo) *loggedNode {
+	return &loggedNode{
+		NodeNum:                 node.NodeNum,
+		ProposedBlock:           node.BlockProposed.Height.AsInt(),
+		ProposedBlockHash:       node.BlockProposed.HeaderHash,
+		Before:                  node.Assertion.Before,
+		After:                   node.Assertion.After,
+		InboxMaxCount:           node.InboxMaxCount,
+		NodeHash:                node.NodeHash,
+		AfterInboxBatchEndCount: node.AfterInboxBatchEndCount,
+		AfterInboxBatchAcc:      node.AfterInboxBatchAcc,
+	}
+}
+
+func (n *loggedNode) nodeInfo() (*core.NodeInfo, error) {
+	if n.NodeNum == nil || n.ProposedBlock == nil || n.Before == nil || n.After == nil {
+		return nil, errors.New("incomplete node in log")
+	}
+	return &core.NodeInfo{
+		NodeNum: n.NodeNum,
+		BlockProposed: &common.BlockId{
+			Height:     common.NewTimeBlocks(n.ProposedBlock),
+			HeaderHash: n.ProposedBlockHash,
+		},
+		Assertion:               &core.Assertion{Before: n.Before, After: n.After},
+		InboxMaxCount:           n.InboxMaxCount,
+		NodeHash:                n.NodeHash,
+		AfterInboxBatchEndCount: n.AfterInboxBatchEndCount,
+		AfterInboxBatchAcc:      n.AfterInboxBatchAcc,
+	}, nil
+}
+
+// nodeLog is a write-ahead log of the nodes loaded from L1. Each batch of
+// nodes is written as a JSON line and synced before it's added to the
+// in-memory history, so after a crash the history is rebuilt from the log
+// and only blocks after the last logged node are queried again. A reload
+// after a reorg truncates the log.
+type nodeLog struct {
+	file *os.File
+}
+
+// openNodeLog opens or creates the log at path and returns the nodes it
+// holds. A partially written last record, left by a crash during an append,
+// is discarded.
+func openNodeLog(path string) (*nodeLog, []*core.NodeInfo, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	nodes, validLength, err := readNodeLog(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, errors.Wrapf(err, "couldn't read node log %v", path)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, errors.WithStack(err)
+	}
+	if info.Size() != validLength {
+		logger.Warn().
+			Int64("size", info.Size()).
+			Int64("valid", validLength).
+			Msg("discarding incomplete record at end of node log")
+		if err := file.Truncate(validLength); err != nil {
+			_ = file.Close()
+			return nil, nil, errors.WithStack(err)
+		}
+		if err := file.Sync(); err != nil {
+			_ = file.Close()
+			return nil, nil, errors.WithStack(err)
+		}
+	}
+	if _, err := file.Seek(validLength, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, nil, errors.WithStack(err)
+	}
+	return &nodeLog{file: file}, nodes, nil
+}
+
+// readNodeLog returns the logged nodes and the length of the log up to the
+// end of the last complete record
+func readNodeLog(r io.Reader) ([]*core.NodeInfo, int64, error) {
+	var nodes []*core.NodeInfo
+	var validLength int64
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// Anything after the last newline is an incomplete append
+			return nodes, validLength, nil
+		}
+		if err != nil {
+			return nil, 0, errors.WithStack(err)
+		}
+		var batch []*loggedNode
+		if err := json.Unmarshal(bytes.TrimSpace(line), &batch); err != nil {
+			return nil, 0, errors.Wrapf(err, "corrupt record at offset %v", validLength)
+		}
+		for _, logged := range batch {
+			node, err := logged.nodeInfo()
+			if err != nil {
+				return nil, 0, errors.Wrapf(err, "corrupt record at offset %v", validLength)
+			}
+			nodes = append(nodes, node)
+		}
+		validLength += int64(len(line))
+	}
+}
+
+func (l *nodeLog) append(nodes []*core.NodeInfo) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	batch := make([]*loggedNode, 0, len(nodes))
+	for _, node := range nodes {
+		batch = append(batch, newLoggedNode(node))
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(l.file.Sync())
+}
+
+func (l *nodeLog) reset() error {
+	if err := l.file.Truncate(0); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := l.file.Seek(0, io.SeekStart); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(l.file.Sync())
+}
+
+func (l *nodeLog) close() error {
+	return errors.WithStack(l.file.Close())
+}
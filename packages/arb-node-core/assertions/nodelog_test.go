@@ -0,0 +1,68 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package assertions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestNodeLogRecovery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nodelog")
+	test.FailIfError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "assertions.log")
+
+	log, nodes, err := openNodeLog(path)
+	test.FailIfError(t, err)
+	checkNodeNums(t, "new log", nodes)
+	test.FailIfError(t, log.append([]*core.NodeInfo{testNode(1, 10, 0, 5), testNode(2, 12, 5, 9)}))
+	test.FailIfError(t, log.append([]*core.NodeInfo{testNode(3, 12, 5, 7)}))
+	test.FailIfError(t, log.close())
+
+	// Simulate a crash part way through an append
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	test.FailIfError(t, err)
+	_, err = file.WriteString(`[{"nodeNum":4,"proposed`)
+	test.FailIfError(t, err)
+	test.FailIfError(t, file.Close())
+
+	log, nodes, err = openNodeLog(path)
+	test.FailIfError(t, err)
+	checkNodeNums(t, "recovered log", nodes, 1, 2, 3)
+	if nodes[1].BlockProposed.Height.AsInt().Int64() != 12 || nodes[1].Assertion.After.TotalMessagesRead.Int64() != 9 {
+		t.Error("node not restored correctly")
+	}
+	test.FailIfError(t, log.append([]*core.NodeInfo{testNode(4, 15, 9, 9)}))
+	test.FailIfError(t, log.close())
+
+	log, nodes, err = openNodeLog(path)
+	test.FailIfError(t, err)
+	checkNodeNums(t, "appended after recovery", nodes, 1, 2, 3, 4)
+	test.FailIfError(t, log.reset())
+	test.FailIfError(t, log.close())
+
+	log, nodes, err = openNodeLog(path)
+	test.FailIfError(t, err)
+	checkNodeNums(t, "reset log", nodes)
+	test.FailIfError(t, log.close())
+}
@@ -19,6 +19,11 @@ type Challenger struct {
 	lookup              core.ArbCoreLookup
 	challengedAssertion *core.Assertion
 	stakerAddress       common.Address
+	bisectionDegree     int
+	challengedNode      *big.Int
+	lastBisection       *SavedBisection
+	responderDeadline   *big.Int
+	strategy            MoveStrategy
 }
 
 func (c *Challenger) ChallengeAddress() common.Address {
@@ -32,23 +37,83 @@ func NewChallenger(challenge *ethbridge.Challenge, sequencerInbox *ethbridge.Seq
 		lookup:              lookup,
 		challengedAssertion: challengedAssertion,
 		stakerAddress:       stakerAddress,
+		bisectionDegree:     SegmentTarget(),
+		strategy:            DefaultMoveStrategy{},
 	}
 }
 
-func (c *Challenger) HandleConflict(ctx context.Context) (Move, error) {
-	isTimedOut, err := c.challenge.IsTimedOut(ctx)
+// SetMoveStrategy replaces the DefaultMoveStrategy the challenger was
+// created with
+func (c *Challenger) SetMoveStrategy(strategy MoveStrategy) {
+	c.strategy = strategy
+}
+
+// SetChallengedNode records the number of the node the challenge is over,
+// which is only used to describe the challenge
+func (c *Challenger) SetChallengedNode(node *big.Int) {
+	c.challengedNode = node
+}
+
+// SetBisectionDegree sets the number of segments our bisections split a
+// segment into. The challenge contract rejects bisections which don't use
+// the rollup's challengeExecutionBisectionDegree, which the rollup owner can
+// change, so this must be set from the rollup when the challenge starts.
+func (c *Challenger) SetBisectionDegree(degree int) {
+	c.bisectionDegree = degree
+}
+
+// Deadline returns the last L1 block in which the staker can make its next
+// move, or nil if it's the other party's turn
+func (c *Challenger) Deadline(ctx context.Context) (*big.Int, error) {
+	responder, err := c.challenge.CurrentResponder(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if isTimedOut {
-		move := &TimeoutMove{}
-		return move, move.execute(ctx, c.challenge)
+	if responder != c.stakerAddress {
+		return nil, nil
+	}
+	deadline, err := c.challenge.ResponderDeadline(ctx)
+	if err != nil {
+		return nil, err
 	}
+	c.responderDeadline = deadline
+	return deadline, nil
+}
 
+// OpponentDeadline returns the last L1 block in which the other party can
+// make its next move before we can time it out, or nil if it's our turn
+func (c *Challenger) OpponentDeadline(ctx context.Context) (*big.Int, error) {
 	responder, err := c.challenge.CurrentResponder(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if responder == c.stakerAddress {
+		return nil, nil
+	}
+	return c.challenge.ResponderDeadline(ctx)
+}
+
+// LastMoveBlock returns the L1 block of the last move made by either party
+func (c *Challenger) LastMoveBlock(ctx context.Context) (*big.Int, error) {
+	return c.challenge.LastMoveBlock(ctx)
+}
+
+func (c *Challenger) HandleConflict(ctx context.Context) (Move, error) {
+	isTimedOut, err := c.challenge.IsTimedOut(ctx)
+	if err != nil {
+		return nil, err
+	}
+	responder, err := c.challenge.CurrentResponder(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if isTimedOut {
+		if !c.strategy.ShouldClaimTimeout(responder == c.stakerAddress) {
+			return nil, nil
+		}
+		move := &TimeoutMove{}
+		return move, move.execute(ctx, c.challenge)
+	}
 	if responder != c.stakerAddress {
 		// Not our turn
 		return nil, nil
@@ -73,10 +138,18 @@ func (c *Challenger) HandleConflict(ctx context.Context) (Move, error) {
 	if prevBisection == nil {
 		prevBisection = c.challengedAssertion.InitialExecutionBisection()
 	}
-	move, err := handleChallenge(ctx, c.challengedAssertion, c.lookup, c.sequencerInbox, prevBisection)
+	move, err := handleChallenge(ctx, c.challengedAssertion, c.lookup, c.sequencerInbox, prevBisection, c.bisectionDegree, c.lastBisection, c.strategy)
 	if err != nil {
 		return nil, err
 	}
+	if bisection, ok := move.(*BisectMove); ok {
+		c.lastBisection = &SavedBisection{
+			SegmentStart:  bisection.inconsistentSegment.Start,
+			SegmentLength: bisection.inconsistentSegment.Length,
+			StartState:    bisection.startState,
+			Cuts:          bisection.subCuts,
+		}
+	}
 	return move, move.execute(ctx, c.challenge)
 }
 
@@ -86,6 +159,9 @@ func handleChallenge(
 	lookup core.ArbCoreLookup,
 	sequencerInbox *ethbridge.SequencerInboxWatcher,
 	prevBisection *core.Bisection,
+	degree int,
+	lastBisection *SavedBisection,
+	strategy MoveStrategy,
 ) (Move, error) {
 	logger.Debug().Str("start", prevBisection.ChallengedSegment.Start.String()).Str("end", prevBisection.ChallengedSegment.GetEnd().String()).Msg("Examining opponent's bisection")
 	prevCutOffsets := generateBisectionCutOffsets(prevBisection.ChallengedSegment, len(prevBisection.Cuts)-1)
@@ -96,24 +172,36 @@ func handleChallenge(
 	if divergence.DifferentIndex == 0 {
 		return nil, errors.New("first cut was already wrong")
 	}
-	cutToChallenge := divergence.DifferentIndex - 1
+	cutToChallenge := strategy.SelectSegment(divergence)
+	if cutToChallenge < 0 || cutToChallenge >= len(prevCutOffsets)-1 {
+		return nil, errors.Errorf("strategy selected invalid segment %v", cutToChallenge)
+	}
 	inconsistentSegment := &core.ChallengeSegment{
 		Start:  prevCutOffsets[cutToChallenge],
 		Length: new(big.Int).Sub(prevCutOffsets[cutToChallenge+1], prevCutOffsets[cutToChallenge]),
 	}
+	var segmentSteps *big.Int
+	if cutToChallenge == divergence.DifferentIndex-1 {
+		segmentSteps = divergence.SegmentSteps
+	}
 
-	cmp := divergence.SegmentSteps.Cmp(big.NewInt(1))
-	if cmp > 0 || divergence.EndIsUnreachable {
-		// Steps > 1 or the endpoint is unreachable: Dissect further
-		segmentCount := SegmentTarget()
-		if inconsistentSegment.Length.Cmp(big.NewInt(int64(segmentCount))) < 0 {
-			// Safe since this is less than 400
-			segmentCount = int(inconsistentSegment.Length.Int64())
-		}
-		subCutOffsets := generateBisectionCutOffsets(inconsistentSegment, segmentCount)
-		startState, subCuts, err := GetCuts(lookup, assertion, subCutOffsets)
-		if err != nil {
-			return nil, err
+	kind := strategy.SelectMove(inconsistentSegment, segmentSteps, divergence.EndIsUnreachable)
+	switch kind {
+	case BisectMoveKind:
+		segmentCount := bisectionDegree(inconsistentSegment.Length, degree)
+		var startState *core.ExecutionState
+		var subCuts []common.Hash
+		if lastBisection.matches(inconsistentSegment, segmentCount) {
+			// We already computed this bisection, but our move wasn't
+			// accepted
+			startState = lastBisection.StartState
+			subCuts = lastBisection.Cuts
+		} else {
+			subCutOffsets := generateBisectionCutOffsets(inconsistentSegment, segmentCount)
+			startState, subCuts, err = GetCuts(lookup, assertion, subCutOffsets)
+			if err != nil {
+				return nil, err
+			}
 		}
 		return &BisectMove{
 			prevBisection:       prevBisection,
@@ -122,11 +210,7 @@ func handleChallenge(
 			inconsistentSegment: inconsistentSegment,
 			subCuts:             subCuts,
 		}, nil
-	} else if cmp < 0 {
-		// Steps == 0: Prove that the previous instruction's execution continued through this gas window
-		// Also sometimes called a zero step proof, or a constraint win
-		// We specifically don't do this when we think the endpoint is unreachable,
-		// as we need to dissect unreachable endpoints to force our opponent to fail to prove them
+	case ProveContinuedMoveKind:
 		previousCut, _, err := getSegmentStartInfo(lookup, assertion, inconsistentSegment)
 		if err != nil {
 			return nil, err
@@ -138,8 +222,7 @@ func handleChallenge(
 			challengedSegment:  inconsistentSegment,
 			previousCut:        previousCut,
 		}, nil
-	} else {
-		// Steps == 1: Do a one step proof, proving the execution of this step specifically
+	case OneStepProofMoveKind:
 		return NewOneStepProofMove(
 			ctx,
 			assertion,
@@ -149,6 +232,8 @@ func handleChallenge(
 			sequencerInbox,
 			lookup,
 		)
+	default:
+		return nil, errors.Errorf("strategy selected unknown move kind %v", kind)
 	}
 }
 
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package challenge
+
+import (
+	"math/big"
+)
+
+// worstCaseOneStepProofGas covers a one step proof of an instruction with
+// the largest buffer proofs
+const worstCaseOneStepProofGas = 2_000_000
+
+// CostEstimate is the worst case L1 cost for one party of a challenge
+type CostEstimate struct {
+	// Rounds is the most bisections the challenge can take, counting both
+	// parties
+	Rounds int `json:"rounds"`
+	// Moves is the most moves one party makes, including the final proof
+	Moves int    `json:"moves"`
+	Gas   uint64 `json:"gas"`
+	// Cost is Gas at the gas price the estimate was made with
+	Cost *big.Int `json:"cost"`
+}
+
+// EstimateWorstCaseCost estimates the most L1 gas one party can spend on a
+// challenge of an assertion executing the given arbgas. Each bisection
+// divides the challenged segment by degree until it's a single step, and the
+// party is assumed to make every other bisection, with every cut, as well
+// as the final one step proof.
+func EstimateWorstCaseCost(gasLength *big.Int, degree int, gasPrice *big.Int) *CostEstimate {
+	rounds := 0
+	if degree >= 2 {
+		remaining := new(big.Int).Set(gasLength)
+		for remaining.Cmp(big.NewInt(1)) > 0 {
+			segments := big.NewInt(int64(bisectionDegree(remaining, degree)))
+			// The first segment of a bisection gets the remainder, so it's
+			// the longest
+			remaining = new(big.Int).Add(
+				new(big.Int).Div(remaining, segments),
+				new(big.Int).Mod(remaining, segments),
+			)
+			rounds++
+		}
+	}
+	bisections := (rounds + 1) / 2
+	gas := uint64(bisections)*(bisectionBaseGas+bisectionCutGas*uint64(degree+1)) + worstCaseOneStepProofGas
+	estimate := &CostEstimate{
+		Rounds: rounds,
+		Moves:  bisections + 1,
+		Gas:    gas,
+	}
+	if gasPrice != nil {
+		estimate.Cost = new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice)
+	}
+	return estimate
+}
@@ -0,0 +1,34 @@
+package challenge
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestEstimateWorstCaseCost(t *testing.T) {
+	cases := []struct {
+		gasLength int64
+		degree    int
+		rounds    int
+	}{
+		{1, 400, 0},
+		{2, 400, 1},
+		{400, 400, 1},
+		{401, 400, 2},
+		{160_000, 400, 2},
+		{160_001, 400, 3},
+		{1000, 2, 10},
+	}
+	for _, c := range cases {
+		estimate := EstimateWorstCaseCost(big.NewInt(c.gasLength), c.degree, big.NewInt(10))
+		if estimate.Rounds != c.rounds {
+			t.Errorf("%v gas with degree %v took %v rounds, expected %v", c.gasLength, c.degree, estimate.Rounds, c.rounds)
+		}
+		if estimate.Moves != (c.rounds+1)/2+1 {
+			t.Errorf("%v gas with degree %v took %v moves", c.gasLength, c.degree, estimate.Moves)
+		}
+		if estimate.Cost.Cmp(new(big.Int).Mul(new(big.Int).SetUint64(estimate.Gas), big.NewInt(10))) != 0 {
+			t.Error("cost doesn't match gas")
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"math/big"
+	"runtime"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/pkg/errors"
@@ -14,10 +16,21 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
 )
 
+// SegmentTarget is the rollup's default challengeExecutionBisectionDegree
 func SegmentTarget() int {
 	return 400
 }
 
+// bisectionDegree returns the number of segments a bisection of a segment
+// of the given length must have, matching the challenge contract
+func bisectionDegree(length *big.Int, target int) int {
+	if length.Cmp(big.NewInt(int64(target))) < 0 {
+		// Safe since this is less than target
+		return int(length.Int64())
+	}
+	return target
+}
+
 var unreachableCut common.Hash
 
 func getCutRaw(execTracker *core.ExecutionTracker, maxTotalMessagesRead *big.Int, gasTarget *big.Int) (*core.ExecutionState, bool, *big.Int, error) {
@@ -48,24 +61,74 @@ func getCut(execTracker *core.ExecutionTracker, maxTotalMessagesRead *big.Int, g
 	return cutHash(state, reachable), steps, nil
 }
 
+// CutWorkers is the most machines used at once to compute the cuts of a
+// bisection
+var CutWorkers = runtime.NumCPU()
+
+// GetCuts computes the cut at each of the sorted offsets. The offsets are
+// split into contiguous ranges which are executed in parallel, each from
+// its own execution cursor, so a bisection's cuts take about as long as
+// executing the longest range.
 func GetCuts(lookup core.ArbCoreLookup, assertion *core.Assertion, offsets []*big.Int) (*core.ExecutionState, []common.Hash, error) {
-	execTracker := core.NewExecutionTracker(lookup, true, offsets, true)
-	cuts := make([]common.Hash, 0, len(offsets))
-	var startState *core.ExecutionState
-	for i, offset := range offsets {
-		cut, reachable, _, err := getCutRaw(execTracker, assertion.After.TotalMessagesRead, offset)
-		if err != nil {
-			return nil, nil, err
+	return getCuts(lookup, assertion, offsets, CutWorkers)
+}
+
+func getCuts(lookup core.ArbCoreLookup, assertion *core.Assertion, offsets []*big.Int, workers int) (*core.ExecutionState, []common.Hash, error) {
+	if len(offsets) == 0 {
+		return nil, nil, nil
+	}
+	if workers > len(offsets) {
+		workers = len(offsets)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	rangeSize := (len(offsets) + workers - 1) / workers
+
+	states := make([]*core.ExecutionState, len(offsets))
+	reachable := make([]bool, len(offsets))
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		start := worker * rangeSize
+		end := start + rangeSize
+		if end > len(offsets) {
+			end = len(offsets)
+		}
+		if start >= end {
+			break
 		}
-		if i == 0 {
-			if !reachable {
-				return nil, nil, errors.New("first cut is unreachable")
+		wg.Add(1)
+		go func(worker, start, end int) {
+			defer wg.Done()
+			rangeOffsets := append([]*big.Int(nil), offsets[start:end]...)
+			execTracker := core.NewExecutionTracker(lookup, true, rangeOffsets, true)
+			for i := start; i < end; i++ {
+				state, isReachable, _, err := getCutRaw(execTracker, assertion.After.TotalMessagesRead, offsets[i])
+				if err != nil {
+					errs[worker] = err
+					return
+				}
+				states[i] = state
+				reachable[i] = isReachable
 			}
-			startState = cut
+		}(worker, start, end)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, nil, err
 		}
-		cuts = append(cuts, cutHash(cut, reachable))
 	}
-	return startState, cuts, nil
+
+	if !reachable[0] {
+		return nil, nil, errors.New("first cut is unreachable")
+	}
+	cuts := make([]common.Hash, 0, len(offsets))
+	for i := range offsets {
+		cuts = append(cuts, cutHash(states[i], reachable[i]))
+	}
+	return states[0], cuts, nil
 }
 
 type DivergenceInfo struct {
@@ -227,6 +290,19 @@ func NewOneStepProofMove(
 		return nil, err
 	}
 
+	key := newProofKey(previousCut)
+	if proof := cachedProofs.get(key); proof != nil {
+		return &OneStepProofMove{
+			assertion:          assertion,
+			prevBisection:      prevBisection,
+			segmentToChallenge: segmentToChallenge,
+			challengedSegment:  challengedSegment,
+			previousCut:        previousCut,
+			proofData:          proof.proofData,
+			bufferProofData:    proof.bufferProofData,
+		}, nil
+	}
+
 	proofData, bufferProofData, err := previousMachine.MarshalForProof()
 	if err != nil {
 		return nil, err
@@ -249,6 +325,7 @@ func NewOneStepProofMove(
 		}
 		proofData = append(proofData, inboxProof...)
 	}
+	cachedProofs.add(key, &oneStepProof{proofData: proofData, bufferProofData: bufferProofData})
 
 	return &OneStepProofMove{
 		assertion:          assertion,
@@ -147,3 +147,33 @@ func TestChallengeToUnreachableSmall(t *testing.T) {
 
 	saveChallengeData(t, challengedAssertion, messages, moves, asserterErr)
 }
+
+func TestParallelCuts(t *testing.T) {
+	mon, shutdown := monitor.PrepareArbCore(t)
+	defer shutdown()
+
+	_, _, _, _, _, _, _ = initializeChallengeTest(t, big.NewInt(10), big.NewInt(10), mon.Core)
+	assertion, err := initializeChallengeData(t, mon.Core, big.NewInt(0), big.NewInt(400*2))
+	test.FailIfError(t, err)
+
+	offsets := generateBisectionCutOffsets(&core.ChallengeSegment{
+		Start:  assertion.Before.TotalGasConsumed,
+		Length: new(big.Int).Sub(assertion.After.TotalGasConsumed, assertion.Before.TotalGasConsumed),
+	}, 37)
+	serialStart, serialCuts, err := getCuts(mon.Core, assertion, offsets, 1)
+	test.FailIfError(t, err)
+	parallelStart, parallelCuts, err := getCuts(mon.Core, assertion, offsets, 4)
+	test.FailIfError(t, err)
+
+	if serialStart.TotalGasConsumed.Cmp(parallelStart.TotalGasConsumed) != 0 {
+		t.Error("start states differ")
+	}
+	if len(serialCuts) != len(parallelCuts) {
+		t.Fatal("got", len(parallelCuts), "parallel cuts, expected", len(serialCuts))
+	}
+	for i := range serialCuts {
+		if serialCuts[i] != parallelCuts[i] {
+			t.Error("cut", i, "differs")
+		}
+	}
+}
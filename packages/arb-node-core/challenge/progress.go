@@ -0,0 +1,134 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package challenge
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+// SavedBisection is the last bisection the challenger computed. Computing
+// the cuts of a bisection means executing the whole segment, so they're kept
+// in case the move needs to be sent again.
+type SavedBisection struct {
+	SegmentStart  *big.Int             `json:"segmentStart"`
+	SegmentLength *big.Int             `json:"segmentLength"`
+	StartState    *core.ExecutionState `json:"startState"`
+	Cuts          []common.Hash        `json:"cuts"`
+}
+
+func (b *SavedBisection) matches(segment *core.ChallengeSegment, segmentCount int) bool {
+	return b != nil &&
+		b.SegmentStart.Cmp(segment.Start) == 0 &&
+		b.SegmentLength.Cmp(segment.Length) == 0 &&
+		len(b.Cuts) == segmentCount+1
+}
+
+// Progress is the context of a challenge which can't be cheaply recovered
+// from L1, so that a restarted validator can resume the challenge. The
+// challenge's position is always read from the contract, and moves sent
+// before a restart are tracked by the validator's receipt cache.
+type Progress struct {
+	Challenge           common.Address  `json:"challenge"`
+	ChallengedNode      *big.Int        `json:"challengedNode"`
+	ChallengedAssertion *core.Assertion `json:"challengedAssertion"`
+	BisectionDegree     int             `json:"bisectionDegree"`
+	LastBisection       *SavedBisection `json:"lastBisection,omitempty"`
+	// ResponderDeadline is the deadline of our next move, as of the last
+	// time we checked
+	ResponderDeadline *big.Int `json:"responderDeadline,omitempty"`
+}
+
+// Progress returns the challenger's current progress
+func (c *Challenger) Progress() *Progress {
+	return &Progress{
+		Challenge:           c.ChallengeAddress(),
+		ChallengedNode:      c.challengedNode,
+		ChallengedAssertion: c.challengedAssertion,
+		BisectionDegree:     c.bisectionDegree,
+		LastBisection:       c.lastBisection,
+		ResponderDeadline:   c.responderDeadline,
+	}
+}
+
+// RestoreChallenger recreates the challenger for the challenge which
+// progress was saved from
+func RestoreChallenger(challenge *ethbridge.Challenge, sequencerInbox *ethbridge.SequencerInboxWatcher, lookup core.ArbCoreLookup, progress *Progress, stakerAddress common.Address) (*Challenger, error) {
+	if progress.Challenge != challenge.Address() {
+		return nil, errors.Errorf("progress is for challenge %v, not %v", progress.Challenge, challenge.Address())
+	}
+	if progress.ChallengedAssertion == nil || progress.BisectionDegree < 2 {
+		return nil, errors.New("incomplete challenge progress")
+	}
+	c := NewChallenger(challenge, sequencerInbox, lookup, progress.ChallengedAssertion, stakerAddress)
+	c.SetChallengedNode(progress.ChallengedNode)
+	c.SetBisectionDegree(progress.BisectionDegree)
+	c.lastBisection = progress.LastBisection
+	c.responderDeadline = progress.ResponderDeadline
+	return c, nil
+}
+
+// SaveProgress writes progress to filename, replacing it atomically
+func SaveProgress(filename string, progress *Progress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	tmpFile, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create challenge progress file")
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return errors.Wrap(err, "failed to write challenge progress")
+	}
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return errors.Wrap(err, "failed to sync challenge progress")
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmpFile.Name(), filename))
+}
+
+// LoadProgress reads progress saved by SaveProgress, returning nil if there
+// isn't any
+func LoadProgress(filename string) (*Progress, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read challenge progress")
+	}
+	progress := &Progress{}
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal challenge progress")
+	}
+	return progress, nil
+}
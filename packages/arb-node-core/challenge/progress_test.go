@@ -0,0 +1,83 @@
+package challenge
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func testExecutionState(gas int64) *core.ExecutionState {
+	return &core.ExecutionState{
+		MachineHash:       common.RandHash(),
+		InboxAcc:          common.RandHash(),
+		TotalMessagesRead: big.NewInt(3),
+		TotalGasConsumed:  big.NewInt(gas),
+		TotalSendCount:    big.NewInt(1),
+		TotalLogCount:     big.NewInt(2),
+		SendAcc:           common.RandHash(),
+		LogAcc:            common.RandHash(),
+	}
+}
+
+func TestProgressRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "progress")
+	test.FailIfError(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "challenge.json")
+
+	progress, err := LoadProgress(filename)
+	test.FailIfError(t, err)
+	if progress != nil {
+		t.Fatal("loaded progress before any was saved")
+	}
+
+	saved := &Progress{
+		Challenge:      common.RandAddress(),
+		ChallengedNode: big.NewInt(7),
+		ChallengedAssertion: &core.Assertion{
+			Before: testExecutionState(0),
+			After:  testExecutionState(1000),
+		},
+		BisectionDegree: 400,
+		LastBisection: &SavedBisection{
+			SegmentStart:  big.NewInt(100),
+			SegmentLength: big.NewInt(50),
+			StartState:    testExecutionState(100),
+			Cuts:          []common.Hash{common.RandHash(), common.RandHash(), common.RandHash()},
+		},
+		ResponderDeadline: big.NewInt(12345),
+	}
+	test.FailIfError(t, SaveProgress(filename, saved))
+	progress, err = LoadProgress(filename)
+	test.FailIfError(t, err)
+
+	if progress.Challenge != saved.Challenge || progress.ChallengedNode.Cmp(saved.ChallengedNode) != 0 {
+		t.Error("challenge not restored")
+	}
+	if progress.ChallengedAssertion.After.CutHash() != saved.ChallengedAssertion.After.CutHash() {
+		t.Error("assertion not restored")
+	}
+	if progress.ResponderDeadline.Cmp(saved.ResponderDeadline) != 0 {
+		t.Error("deadline not restored")
+	}
+	segment := &core.ChallengeSegment{Start: big.NewInt(100), Length: big.NewInt(50)}
+	if !progress.LastBisection.matches(segment, 2) {
+		t.Error("restored bisection doesn't match its segment")
+	}
+	if progress.LastBisection.matches(segment, 3) {
+		t.Error("bisection matched a different segment count")
+	}
+	if progress.LastBisection.matches(&core.ChallengeSegment{Start: big.NewInt(100), Length: big.NewInt(51)}, 2) {
+		t.Error("bisection matched a different segment")
+	}
+	var missing *SavedBisection
+	if missing.matches(segment, 2) {
+		t.Error("missing bisection matched")
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package challenge
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+// proofCacheSize is the number of one step proofs kept. Buffer proofs can be
+// large, and a challenge only ends in one proof, so this is kept small.
+const proofCacheSize = 16
+
+// proofKey identifies the state a one step proof is made from. The machine
+// hash covers the whole machine, and the inbox accumulator determines the
+// message an inbox instruction reads, which the inbox proof is for.
+type proofKey struct {
+	machineHash common.Hash
+	inboxAcc    common.Hash
+}
+
+func newProofKey(state *core.ExecutionState) proofKey {
+	return proofKey{machineHash: state.MachineHash, inboxAcc: state.InboxAcc}
+}
+
+type oneStepProof struct {
+	proofData       []byte
+	bufferProofData []byte
+}
+
+// proofCache holds recently generated one step proofs, so that a proof which
+// has to be sent again, or which is needed by another challenge over the
+// same execution, isn't serialized from the machine again
+type proofCache struct {
+	cache *lru.Cache
+}
+
+var cachedProofs = newProofCache(proofCacheSize)
+
+func newProofCache(size int) *proofCache {
+	cache, err := lru.New(size)
+	if err != nil {
+		// Only fails for a non-positive size
+		panic(err)
+	}
+	return &proofCache{cache: cache}
+}
+
+func (c *proofCache) get(key proofKey) *oneStepProof {
+	proof, ok := c.cache.Get(key)
+	if !ok {
+		return nil
+	}
+	return proof.(*oneStepProof)
+}
+
+func (c *proofCache) add(key proofKey, proof *oneStepProof) {
+	c.cache.Add(key, proof)
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package challenge
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+// Rough L1 gas costs of challenge moves, used to budget a simulated
+// challenge. Bisections pay for their cuts' calldata and merkle tree on top
+// of a base cost, and one step proofs for their proof's calldata.
+const (
+	bisectionBaseGas    = 100_000
+	bisectionCutGas     = 1_000
+	oneStepProofBaseGas = 250_000
+	proofByteGas        = 16
+	proveContinuedGas   = 100_000
+	simulatedTimeoutGas = 100_000
+)
+
+const (
+	simulatedAsserterName = "asserter"
+	simulatedOurName      = "challenger"
+)
+
+type SimulatedMove struct {
+	Party         string   `json:"party"`
+	Kind          string   `json:"kind"`
+	SegmentStart  *big.Int `json:"segmentStart"`
+	SegmentLength *big.Int `json:"segmentLength"`
+	Gas           uint64   `json:"gas"`
+}
+
+type Simulation struct {
+	Moves []*SimulatedMove `json:"moves"`
+	// OurGas is the estimated L1 gas of our moves, which is what has to be
+	// budgeted to win the challenge
+	OurGas      uint64 `json:"ourGas"`
+	AsserterGas uint64 `json:"asserterGas"`
+}
+
+func (s *Simulation) add(move *SimulatedMove) {
+	s.Moves = append(s.Moves, move)
+	if move.Party == simulatedOurName {
+		s.OurGas += move.Gas
+	} else {
+		s.AsserterGas += move.Gas
+	}
+}
+
+func simulatedMove(move Move) *SimulatedMove {
+	switch m := move.(type) {
+	case *BisectMove:
+		return &SimulatedMove{
+			Kind:          "Bisect",
+			SegmentStart:  m.inconsistentSegment.Start,
+			SegmentLength: m.inconsistentSegment.Length,
+			Gas:           bisectionBaseGas + bisectionCutGas*uint64(len(m.subCuts)),
+		}
+	case *OneStepProofMove:
+		return &SimulatedMove{
+			Kind:          "OneStepProof",
+			SegmentStart:  m.challengedSegment.Start,
+			SegmentLength: m.challengedSegment.Length,
+			Gas:           oneStepProofBaseGas + proofByteGas*uint64(len(m.proofData)+len(m.bufferProofData)),
+		}
+	case *ProveContinuedMove:
+		return &SimulatedMove{
+			Kind:          "ProveContinued",
+			SegmentStart:  m.challengedSegment.Start,
+			SegmentLength: m.challengedSegment.Length,
+			Gas:           proveContinuedGas,
+		}
+	default:
+		return &SimulatedMove{Kind: "Unknown"}
+	}
+}
+
+// Simulate plays out a challenge of assertion, whose after state is
+// incorrect, against the local database without touching L1. The asserter
+// is assumed to agree with every one of our cuts except the last, and to
+// only disagree with the end of each of its own bisections, which makes the
+// challenge take as many rounds as possible. sequencerInbox is only used to
+// look up the batch an inbox instruction reads from when it's one step
+// proven.
+func Simulate(
+	ctx context.Context,
+	lookup core.ArbCoreLookup,
+	sequencerInbox *ethbridge.SequencerInboxWatcher,
+	assertion *core.Assertion,
+	degree int,
+) (*Simulation, error) {
+	if degree < 2 {
+		return nil, errors.Errorf("invalid bisection degree %v", degree)
+	}
+	prevBisection := assertion.InitialExecutionBisection()
+	offsets := generateBisectionCutOffsets(prevBisection.ChallengedSegment, 1)
+	_, honestCuts, err := GetCuts(lookup, assertion, offsets)
+	if err != nil {
+		return nil, err
+	}
+	if honestCuts[0] != prevBisection.Cuts[0] {
+		return nil, errors.New("assertion doesn't start from the local state")
+	}
+	dishonestEnd := prevBisection.Cuts[1]
+	if honestCuts[1] == dishonestEnd {
+		return nil, errors.New("assertion is correct, so it can't be challenged")
+	}
+
+	sim := &Simulation{}
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		move, err := handleChallenge(ctx, assertion, lookup, sequencerInbox, prevBisection, degree, nil, DefaultMoveStrategy{})
+		if err != nil {
+			return nil, err
+		}
+		ourMove := simulatedMove(move)
+		ourMove.Party = simulatedOurName
+		sim.add(ourMove)
+		bisection, ok := move.(*BisectMove)
+		if !ok {
+			// We proved the asserter wrong
+			return sim, nil
+		}
+
+		// The asserter challenges the last segment of our bisection
+		cutOffsets := generateBisectionCutOffsets(bisection.inconsistentSegment, len(bisection.subCuts)-1)
+		last := len(cutOffsets) - 2
+		segment := &core.ChallengeSegment{
+			Start:  cutOffsets[last],
+			Length: new(big.Int).Sub(cutOffsets[last+1], cutOffsets[last]),
+		}
+		if segment.Length.Cmp(big.NewInt(1)) <= 0 {
+			// The asserter would have to prove a step it got wrong, so it
+			// can only let its time run out
+			sim.add(&SimulatedMove{
+				Party:         simulatedOurName,
+				Kind:          "Timeout",
+				SegmentStart:  segment.Start,
+				SegmentLength: segment.Length,
+				Gas:           simulatedTimeoutGas,
+			})
+			return sim, nil
+		}
+		subOffsets := generateBisectionCutOffsets(segment, bisectionDegree(segment.Length, degree))
+		_, subCuts, err := GetCuts(lookup, assertion, subOffsets)
+		if err != nil {
+			return nil, err
+		}
+		subCuts[len(subCuts)-1] = dishonestEnd
+		sim.add(&SimulatedMove{
+			Party:         simulatedAsserterName,
+			Kind:          "Bisect",
+			SegmentStart:  segment.Start,
+			SegmentLength: segment.Length,
+			Gas:           bisectionBaseGas + bisectionCutGas*uint64(len(subCuts)),
+		})
+		prevBisection = &core.Bisection{ChallengedSegment: segment, Cuts: subCuts}
+	}
+}
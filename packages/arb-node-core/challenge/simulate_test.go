@@ -0,0 +1,84 @@
+package challenge
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/monitor"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func sameSimulatedMove(a, b *SimulatedMove) bool {
+	return a.Kind == b.Kind &&
+		a.SegmentStart.Cmp(b.SegmentStart) == 0 &&
+		a.SegmentLength.Cmp(b.SegmentLength) == 0 &&
+		a.Gas == b.Gas
+}
+
+// TestSimulateMatchesChallenge simulates a challenge and then plays it out on
+// L1, checking that the simulation starts with the same move and budgets for
+// at least as many moves and as much gas as the real challenger needed
+func TestSimulateMatchesChallenge(t *testing.T) {
+	ctx := context.Background()
+	mon, shutdown := monitor.PrepareArbCore(t)
+	defer shutdown()
+
+	client, tester, seqInboxAddr, asserterWallet, challengerWallet, startChallenge, _ := initializeChallengeTest(t, big.NewInt(10), big.NewInt(10), mon.Core)
+	faultyCore := NewFaultyCore(mon.Core, FaultConfig{DistortMachineAtGas: big.NewInt(1)})
+	challengedAssertion, err := initializeChallengeData(t, faultyCore, big.NewInt(0), big.NewInt(400*2))
+	test.FailIfError(t, err)
+
+	seqInbox, err := ethbridge.NewSequencerInboxWatcher(seqInboxAddr, client)
+	test.FailIfError(t, err)
+	sim, err := Simulate(ctx, mon.Core, seqInbox, challengedAssertion, SegmentTarget())
+	test.FailIfError(t, err)
+
+	startChallenge(challengedAssertion)
+	moves, err := executeChallenge(
+		t,
+		challengedAssertion,
+		mon.Core,
+		faultyCore,
+		client,
+		tester,
+		seqInboxAddr,
+		asserterWallet,
+		challengerWallet,
+	)
+	test.FailIfError(t, err)
+
+	// Every round of the challenge is a challenger move followed by an
+	// asserter move, so the challenger's moves are the even ones
+	var ourMoves []*SimulatedMove
+	var ourGas uint64
+	for i := 0; i < len(moves); i += 2 {
+		move := simulatedMove(moves[i])
+		ourMoves = append(ourMoves, move)
+		ourGas += move.Gas
+	}
+	var simulatedOurMoves []*SimulatedMove
+	for _, move := range sim.Moves {
+		if move.Party == simulatedOurName {
+			simulatedOurMoves = append(simulatedOurMoves, move)
+		}
+	}
+
+	if len(ourMoves) == 0 || len(simulatedOurMoves) == 0 {
+		t.Fatal("challenger made", len(ourMoves), "moves and simulation made", len(simulatedOurMoves))
+	}
+	if !sameSimulatedMove(ourMoves[0], simulatedOurMoves[0]) {
+		t.Errorf("simulated first move %+v, challenger made %+v", simulatedOurMoves[0], ourMoves[0])
+	}
+	if len(simulatedOurMoves) < len(ourMoves) {
+		t.Errorf("simulated %v moves, challenger made %v", len(simulatedOurMoves), len(ourMoves))
+	}
+	if sim.OurGas < ourGas {
+		t.Errorf("simulated %v gas, challenger's moves take %v", sim.OurGas, ourGas)
+	}
+	last := ourMoves[len(ourMoves)-1].Kind
+	if last != "OneStepProof" && last != "ProveContinued" {
+		t.Errorf("challenger finished with %v move", last)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package challenge
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+type MoveKind uint8
+
+const (
+	// BisectMoveKind splits the challenged segment into smaller segments
+	BisectMoveKind MoveKind = iota
+	// ProveContinuedMoveKind proves that the instruction executing at the
+	// start of the segment continued past its end
+	ProveContinuedMoveKind
+	// OneStepProofMoveKind proves the execution of the segment's single step
+	OneStepProofMoveKind
+)
+
+// MoveStrategy decides the moves the challenger makes. The contract checks
+// every move, so a strategy can only lose a challenge, not cheat in one.
+type MoveStrategy interface {
+	// ShouldClaimTimeout decides whether to end a challenge whose current
+	// responder has run out of time. ourTurn is whether we're the responder,
+	// in which case claiming the timeout loses the challenge.
+	ShouldClaimTimeout(ourTurn bool) bool
+	// SelectSegment returns the index of the segment of the opponent's
+	// bisection to challenge, given our cuts first differ from the
+	// opponent's at divergence.DifferentIndex, which is at least 1
+	SelectSegment(divergence DivergenceInfo) int
+	// SelectMove decides how to respond to the chosen segment. steps is the
+	// number of steps we execute in the segment, which is only known when
+	// the segment ends at the first divergence, and is nil otherwise.
+	SelectMove(segment *core.ChallengeSegment, steps *big.Int, endIsUnreachable bool) MoveKind
+}
+
+// DefaultMoveStrategy challenges the segment ending at the first cut we
+// disagree with, and bisects it until it's down to a single step
+type DefaultMoveStrategy struct{}
+
+// ShouldClaimTimeout always claims the timeout, as either party can do so
+func (DefaultMoveStrategy) ShouldClaimTimeout(bool) bool {
+	return true
+}
+
+func (DefaultMoveStrategy) SelectSegment(divergence DivergenceInfo) int {
+	return divergence.DifferentIndex - 1
+}
+
+func (DefaultMoveStrategy) SelectMove(_ *core.ChallengeSegment, steps *big.Int, endIsUnreachable bool) MoveKind {
+	if steps == nil {
+		return BisectMoveKind
+	}
+	cmp := steps.Cmp(big.NewInt(1))
+	if cmp > 0 || endIsUnreachable {
+		// Steps > 1 or the endpoint is unreachable: Dissect further
+		// We specifically don't prove continued when we think the endpoint
+		// is unreachable, as we need to dissect unreachable endpoints to
+		// force our opponent to fail to prove them
+		return BisectMoveKind
+	} else if cmp < 0 {
+		// Steps == 0: Prove that the previous instruction's execution
+		// continued through this gas window. Also sometimes called a zero
+		// step proof, or a constraint win
+		return ProveContinuedMoveKind
+	}
+	// Steps == 1: Do a one step proof, proving the execution of this step
+	// specifically
+	return OneStepProofMoveKind
+}
+
+// NoForfeitMoveStrategy moves like DefaultMoveStrategy, but never claims the
+// timeout of its own move, leaving it to the opponent to end the challenge
+// and pay for doing so
+type NoForfeitMoveStrategy struct {
+	DefaultMoveStrategy
+}
+
+func (NoForfeitMoveStrategy) ShouldClaimTimeout(ourTurn bool) bool {
+	return !ourTurn
+}
+
+// BuiltinMoveStrategy returns the move strategy with the given name, which
+// is case insensitive, with an empty name selecting DefaultMoveStrategy
+func BuiltinMoveStrategy(name string) (MoveStrategy, error) {
+	if name == "" || strings.EqualFold(name, "Default") {
+		return DefaultMoveStrategy{}, nil
+	} else if strings.EqualFold(name, "NoForfeit") {
+		return NoForfeitMoveStrategy{}, nil
+	}
+	return nil, errors.Errorf("unknown challenge strategy %v, should be Default or NoForfeit", name)
+}
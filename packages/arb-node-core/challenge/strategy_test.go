@@ -0,0 +1,56 @@
+package challenge
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+func TestDefaultMoveStrategy(t *testing.T) {
+	strategy := DefaultMoveStrategy{}
+	segment := &core.ChallengeSegment{Start: big.NewInt(0), Length: big.NewInt(100)}
+	cases := []struct {
+		name        string
+		steps       *big.Int
+		unreachable bool
+		expected    MoveKind
+	}{
+		{"many steps", big.NewInt(5), false, BisectMoveKind},
+		{"unknown steps", nil, false, BisectMoveKind},
+		{"unreachable end", big.NewInt(0), true, BisectMoveKind},
+		{"unreachable single step", big.NewInt(1), true, BisectMoveKind},
+		{"no steps", big.NewInt(0), false, ProveContinuedMoveKind},
+		{"single step", big.NewInt(1), false, OneStepProofMoveKind},
+	}
+	for _, c := range cases {
+		if kind := strategy.SelectMove(segment, c.steps, c.unreachable); kind != c.expected {
+			t.Errorf("%v: got move %v, expected %v", c.name, kind, c.expected)
+		}
+	}
+	if segment := strategy.SelectSegment(DivergenceInfo{DifferentIndex: 3}); segment != 2 {
+		t.Error("selected segment", segment, "instead of the one ending at the divergence")
+	}
+}
+
+func TestBuiltinMoveStrategy(t *testing.T) {
+	strategy, err := BuiltinMoveStrategy("noforfeit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strategy.ShouldClaimTimeout(true) {
+		t.Error("no forfeit strategy claimed the timeout of its own move")
+	}
+	if !strategy.ShouldClaimTimeout(false) {
+		t.Error("no forfeit strategy didn't claim the opponent's timeout")
+	}
+	if kind := strategy.SelectMove(&core.ChallengeSegment{}, big.NewInt(1), false); kind != OneStepProofMoveKind {
+		t.Error("no forfeit strategy chose move", kind, "instead of a one step proof")
+	}
+	if strategy, _ := BuiltinMoveStrategy(""); strategy != (DefaultMoveStrategy{}) {
+		t.Error("empty name selected", strategy, "instead of the default strategy")
+	}
+	if _, err := BuiltinMoveStrategy("Reckless"); err == nil {
+		t.Error("unknown strategy name was accepted")
+	}
+}
@@ -0,0 +1,300 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpointing
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/cmdhelp"
+)
+
+const (
+	intentSuffix     = ".intent"
+	replicatedSuffix = ".replicated"
+	archiveSuffix    = ".tar.gz"
+)
+
+// replicationTarget stores a replicated archive under name
+type replicationTarget interface {
+	put(ctx context.Context, name string, archivePath string, sums archiveSums) error
+}
+
+type archiveSums struct {
+	sha256 []byte
+	md5    []byte
+}
+
+func sumArchive(path string) (archiveSums, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return archiveSums{}, errors.WithStack(err)
+	}
+	defer f.Close()
+	sha := sha256.New()
+	md := md5.New()
+	if _, err := io.Copy(io.MultiWriter(sha, md), f); err != nil {
+		return archiveSums{}, errors.WithStack(err)
+	}
+	return archiveSums{sha256: sha.Sum(nil), md5: md.Sum(nil)}, nil
+}
+
+// Replicator copies the database saves the core writes every
+// core.database.save-interval to another location, so that a standby node
+// can be started from one with core.database.snapshot-url. Each save is
+// packed into an archive in the format of core.database.export, and a file
+// holding the archive's sha256 hash is stored next to it.
+//
+// The destination is either a directory, which can be a mounted remote
+// filesystem or bucket, or an http(s) URL which accepts PUT requests. Uploads
+// include a Content-MD5 header, which S3 and GCS check before storing an
+// object.
+type Replicator struct {
+	savePath string
+	target   replicationTarget
+	interval time.Duration
+}
+
+func NewReplicator(savePath string, destination string, interval time.Duration) (*Replicator, error) {
+	var target replicationTarget
+	if strings.HasPrefix(destination, "http://") || strings.HasPrefix(destination, "https://") {
+		base, err := url.Parse(destination)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		target = &httpTarget{base: base, client: &http.Client{}}
+	} else {
+		if err := os.MkdirAll(destination, 0755); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		target = &dirTarget{dir: destination}
+	}
+	return &Replicator{savePath: savePath, target: target, interval: interval}, nil
+}
+
+func (r *Replicator) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			if err := r.replicateSaves(ctx); err != nil {
+				logger.Warn().Err(err).Msg("failed to replicate database save")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// pendingSaves returns the completed saves which haven't been replicated,
+// oldest first, and removes markers of saves which no longer exist
+func pendingSaves(savePath string) ([]string, error) {
+	entries, err := ioutil.ReadDir(savePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	existing := make(map[string]bool)
+	for _, entry := range entries {
+		existing[entry.Name()] = true
+	}
+	var pending []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, replicatedSuffix) && !existing[strings.TrimSuffix(name, replicatedSuffix)] {
+			_ = os.Remove(filepath.Join(savePath, name))
+			continue
+		}
+		if !entry.IsDir() {
+			continue
+		}
+		// Full saves are named by their timestamp, incremental backups
+		// are updated in place and can't be replicated this way
+		if _, err := strconv.ParseUint(name, 10, 64); err != nil {
+			continue
+		}
+		if existing[name+intentSuffix] || existing[name+replicatedSuffix] {
+			continue
+		}
+		pending = append(pending, name)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		a, _ := strconv.ParseUint(pending[i], 10, 64)
+		b, _ := strconv.ParseUint(pending[j], 10, 64)
+		return a < b
+	})
+	return pending, nil
+}
+
+func (r *Replicator) replicateSaves(ctx context.Context) error {
+	pending, err := pendingSaves(r.savePath)
+	if err != nil {
+		return err
+	}
+	for _, name := range pending {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := r.replicateSave(ctx, name); err != nil {
+			return errors.Wrapf(err, "save %v", name)
+		}
+	}
+	return nil
+}
+
+func (r *Replicator) replicateSave(ctx context.Context, name string) error {
+	start := time.Now()
+	archivePath := filepath.Join(r.savePath, name+".replicating"+archiveSuffix)
+	_ = os.Remove(archivePath)
+	defer os.Remove(archivePath)
+	if err := cmdhelp.ExportDirectoryArchive(archivePath, filepath.Join(r.savePath, name), "db"); err != nil {
+		return err
+	}
+	sums, err := sumArchive(archivePath)
+	if err != nil {
+		return err
+	}
+	if err := r.target.put(ctx, name+archiveSuffix, archivePath, sums); err != nil {
+		return err
+	}
+	marker, err := os.Create(filepath.Join(r.savePath, name+replicatedSuffix))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := marker.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	logger.Info().
+		Str("save", name).
+		Str("sha256", hex.EncodeToString(sums.sha256)).
+		Dur("elapsed", time.Since(start)).
+		Msg("replicated database save")
+	return nil
+}
+
+type dirTarget struct {
+	dir string
+}
+
+func (t *dirTarget) put(_ context.Context, name string, archivePath string, sums archiveSums) error {
+	dest := filepath.Join(t.dir, name)
+	tmp := dest + ".tmp"
+	if err := copyFile(archivePath, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	// Read the copy back, since remote filesystems can fail silently
+	copied, err := sumArchive(tmp)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if !bytes.Equal(copied.sha256, sums.sha256) {
+		_ = os.Remove(tmp)
+		return errors.Errorf("replicated archive %v doesn't match", dest)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(ioutil.WriteFile(dest+".sha256", []byte(hex.EncodeToString(sums.sha256)+"\n"), 0644))
+}
+
+func copyFile(src string, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return errors.WithStack(err)
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(out.Close())
+}
+
+type httpTarget struct {
+	base   *url.URL
+	client *http.Client
+}
+
+func (t *httpTarget) put(ctx context.Context, name string, archivePath string, sums archiveSums) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := t.upload(ctx, name, f, info.Size(), sums.md5); err != nil {
+		return err
+	}
+	sum := []byte(hex.EncodeToString(sums.sha256) + "\n")
+	sumMD5 := md5.Sum(sum)
+	return t.upload(ctx, name+".sha256", bytes.NewReader(sum), int64(len(sum)), sumMD5[:])
+}
+
+func (t *httpTarget) upload(ctx context.Context, name string, body io.Reader, size int64, md5Sum []byte) error {
+	dest := *t.base
+	dest.Path = strings.TrimSuffix(dest.Path, "/") + "/" + name
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, dest.String(), body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum))
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't upload %v", name)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("uploading %v returned %v", name, resp.Status)
+	}
+	return nil
+}
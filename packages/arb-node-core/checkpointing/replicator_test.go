@@ -0,0 +1,81 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpointing
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/cmdhelp"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func writeSaveFile(t *testing.T, path string, data string) {
+	t.Helper()
+	test.FailIfError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	test.FailIfError(t, ioutil.WriteFile(path, []byte(data), 0644))
+}
+
+func TestReplicateSaves(t *testing.T) {
+	savePath := t.TempDir()
+	writeSaveFile(t, filepath.Join(savePath, "300", "CURRENT"), "MANIFEST-000003\n")
+	writeSaveFile(t, filepath.Join(savePath, "100", "CURRENT"), "MANIFEST-000001\n")
+	// Still being written
+	writeSaveFile(t, filepath.Join(savePath, "200", "CURRENT"), "MANIFEST-000002\n")
+	writeSaveFile(t, filepath.Join(savePath, "200.intent"), "")
+	writeSaveFile(t, filepath.Join(savePath, "incremental", "LATEST_BACKUP"), "1\n")
+	// Marker of a save which has since been deleted
+	writeSaveFile(t, filepath.Join(savePath, "50.replicated"), "")
+
+	pending, err := pendingSaves(savePath)
+	test.FailIfError(t, err)
+	if len(pending) != 2 || pending[0] != "100" || pending[1] != "300" {
+		t.Fatal("wrong pending saves", pending)
+	}
+	if _, err := os.Stat(filepath.Join(savePath, "50.replicated")); !os.IsNotExist(err) {
+		t.Error("stale marker not removed")
+	}
+
+	dest := t.TempDir()
+	replicator, err := NewReplicator(savePath, dest, time.Minute)
+	test.FailIfError(t, err)
+	test.FailIfError(t, replicator.replicateSaves(context.Background()))
+
+	pending, err = pendingSaves(savePath)
+	test.FailIfError(t, err)
+	if len(pending) != 0 {
+		t.Error("saves left after replication", pending)
+	}
+	for _, name := range []string{"100.tar.gz", "100.tar.gz.sha256", "300.tar.gz", "300.tar.gz.sha256"} {
+		if _, err := os.Stat(filepath.Join(dest, name)); err != nil {
+			t.Error("missing replicated file", name)
+		}
+	}
+
+	// A replicated save can be imported as a database
+	root := t.TempDir()
+	test.FailIfError(t, cmdhelp.ImportArchive(filepath.Join(dest, "300.tar.gz"), root))
+	data, err := ioutil.ReadFile(filepath.Join(root, "db", "CURRENT"))
+	test.FailIfError(t, err)
+	if string(data) != "MANIFEST-000003\n" {
+		t.Error("wrong database contents after import")
+	}
+}
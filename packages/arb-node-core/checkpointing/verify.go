@@ -0,0 +1,141 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpointing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/arblog"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+)
+
+var logger = arblog.Logger.With().Str("component", "checkpointing").Logger()
+
+// Only the first problems found are kept in a report
+const maxReportedProblems = 100
+
+type CheckpointVerifier interface {
+	VerifyCheckpoints() (uint64, uint64, error)
+}
+
+type Report struct {
+	CheckpointsChecked uint64
+	CorruptCheckpoints uint64
+	BlocksChecked      uint64
+	BlockProblemCount  uint64
+	// BlockProblems describes the first problems found in the block index
+	BlockProblems []string
+}
+
+func (r *Report) OK() bool {
+	return r.CorruptCheckpoints == 0 && r.BlockProblemCount == 0
+}
+
+// Err returns an error describing the corruption found, or nil if there was
+// none
+func (r *Report) Err() error {
+	if r.OK() {
+		return nil
+	}
+	return errors.Errorf(
+		"database corruption found: %v of %v checkpoints corrupt, %v problems in %v blocks",
+		r.CorruptCheckpoints,
+		r.CheckpointsChecked,
+		r.BlockProblemCount,
+		r.BlocksChecked,
+	)
+}
+
+func (r *Report) addBlockProblem(height uint64, problem string) {
+	r.BlockProblemCount++
+	if len(r.BlockProblems) < maxReportedProblems {
+		r.BlockProblems = append(r.BlockProblems, fmt.Sprintf("block %v: %v", height, problem))
+	}
+}
+
+// Verify walks the stored checkpoints, reloading each machine and checking
+// its hashes against the ones it was saved with, and then checks that the
+// L2 block index forms a hash chain. Corruption is returned in the report
+// rather than as an error, which is reserved for failing to run the checks.
+func Verify(ctx context.Context, core CheckpointVerifier, nodeStore machine.NodeStore) (*Report, error) {
+	report := &Report{}
+	checked, corrupt, err := core.VerifyCheckpoints()
+	if err != nil {
+		return nil, err
+	}
+	report.CheckpointsChecked = checked
+	report.CorruptCheckpoints = corrupt
+	logger.Info().
+		Uint64("checked", checked).
+		Uint64("corrupt", corrupt).
+		Msg("verified checkpoints")
+
+	if nodeStore != nil {
+		if err := verifyBlocks(ctx, nodeStore, report); err != nil {
+			return nil, err
+		}
+		logger.Info().
+			Uint64("checked", report.BlocksChecked).
+			Uint64("problems", report.BlockProblemCount).
+			Msg("verified block index")
+	}
+	return report, nil
+}
+
+func verifyBlocks(ctx context.Context, nodeStore machine.NodeStore, report *Report) error {
+	count, err := nodeStore.BlockCount()
+	if err != nil {
+		return err
+	}
+	var prev *machine.BlockInfo
+	for height := uint64(0); height < count; height++ {
+		if height%10000 == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		report.BlocksChecked++
+		info, err := nodeStore.GetBlockInfo(height)
+		if err != nil {
+			report.addBlockProblem(height, err.Error())
+			prev = nil
+			continue
+		}
+		if info == nil {
+			report.addBlockProblem(height, "missing")
+			prev = nil
+			continue
+		}
+		if info.Header.Number == nil || !info.Header.Number.IsUint64() || info.Header.Number.Uint64() != height {
+			report.addBlockProblem(height, fmt.Sprintf("header has number %v", info.Header.Number))
+		}
+		hash := info.Header.Hash()
+		if prev != nil {
+			if info.Header.ParentHash != prev.Header.Hash() {
+				report.addBlockProblem(height, "parent hash doesn't match previous block")
+			}
+		}
+		indexed := nodeStore.GetPossibleBlock(common.NewHashFromEth(hash))
+		if indexed == nil || *indexed != height {
+			report.addBlockProblem(height, "not found by hash "+hash.Hex())
+		}
+		prev = info
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpointing
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+type fakeVerifier struct {
+	checked uint64
+	corrupt uint64
+}
+
+func (f fakeVerifier) VerifyCheckpoints() (uint64, uint64, error) {
+	return f.checked, f.corrupt, nil
+}
+
+type fakeNodeStore struct {
+	machine.NodeStore
+	blocks []*machine.BlockInfo
+	byHash map[common.Hash]uint64
+}
+
+func newFakeNodeStore(count int) *fakeNodeStore {
+	store := &fakeNodeStore{byHash: make(map[common.Hash]uint64)}
+	parent := ethcommon.Hash{}
+	for i := 0; i < count; i++ {
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     big.NewInt(int64(i)),
+			Difficulty: big.NewInt(0),
+		}
+		store.blocks = append(store.blocks, &machine.BlockInfo{Header: header})
+		store.byHash[common.NewHashFromEth(header.Hash())] = uint64(i)
+		parent = header.Hash()
+	}
+	return store
+}
+
+func (f *fakeNodeStore) BlockCount() (uint64, error) {
+	return uint64(len(f.blocks)), nil
+}
+
+func (f *fakeNodeStore) GetBlockInfo(height uint64) (*machine.BlockInfo, error) {
+	return f.blocks[height], nil
+}
+
+func (f *fakeNodeStore) GetPossibleBlock(blockHash common.Hash) *uint64 {
+	height, ok := f.byHash[blockHash]
+	if !ok {
+		return nil
+	}
+	return &height
+}
+
+func TestVerifyHealthy(t *testing.T) {
+	report, err := Verify(context.Background(), fakeVerifier{checked: 5}, newFakeNodeStore(10))
+	test.FailIfError(t, err)
+	if !report.OK() || report.Err() != nil {
+		t.Fatal("healthy database reported as corrupt", report.BlockProblems)
+	}
+	if report.CheckpointsChecked != 5 || report.BlocksChecked != 10 {
+		t.Error("wrong counts in report", report)
+	}
+}
+
+func TestVerifyCorruptCheckpoint(t *testing.T) {
+	report, err := Verify(context.Background(), fakeVerifier{checked: 5, corrupt: 1}, nil)
+	test.FailIfError(t, err)
+	if report.OK() {
+		t.Fatal("corrupt checkpoint not reported")
+	}
+}
+
+func TestVerifyBrokenBlockChain(t *testing.T) {
+	store := newFakeNodeStore(10)
+	// Replace block 4 with one that doesn't link to block 3
+	store.blocks[4] = &machine.BlockInfo{Header: &types.Header{
+		ParentHash: ethcommon.Hash{1},
+		Number:     big.NewInt(4),
+		Difficulty: big.NewInt(0),
+	}}
+	store.blocks[7] = nil
+
+	report, err := Verify(context.Background(), fakeVerifier{}, store)
+	test.FailIfError(t, err)
+	if report.OK() {
+		t.Fatal("broken block chain not reported")
+	}
+	// Block 4 has a bad parent and isn't indexed by its hash, block 5's
+	// parent no longer matches and block 7 is missing
+	if report.BlockProblemCount != 4 {
+		t.Error("unexpected problems", report.BlockProblems)
+	}
+}
@@ -57,6 +57,10 @@ func startup() error {
 		fmt.Printf("Sample usage: %s --persistent.chain='.arbitrum/mainnet' --core.database.metadata\n", os.Args[0])
 		fmt.Printf("              %s --persistent.chain='.arbitrum/mainnet' --core.database.make-validator\n", os.Args[0])
 		fmt.Printf("              %s --persistent.chain='.arbitrum/mainnet' --core.database.prune-on-startup\n", os.Args[0])
+		fmt.Printf("              %s --persistent.chain='.arbitrum/mainnet' --core.database.restore-backup='db_checkpoints/incremental'\n", os.Args[0])
+		fmt.Printf("              %s --persistent.chain='.arbitrum/mainnet' --core.database.export='mainnet.tar.gz'\n", os.Args[0])
+		fmt.Printf("              %s --persistent.chain='.arbitrum/mainnet' --core.database.import='mainnet.tar.gz'\n", os.Args[0])
+		fmt.Printf("              %s --persistent.chain='.arbitrum/mainnet' --core.database.list-checkpoints=10\n", os.Args[0])
 		if err != nil && !strings.Contains(err.Error(), "help requested") {
 			fmt.Printf("%s\n", err.Error())
 		}
@@ -69,6 +73,21 @@ func startup() error {
 
 	var databasePath string
 	databasePath = config.GetDatabasePath()
+	if config.Core.Database.Import != "" {
+		if err := cmdhelp.ImportArchive(config.Core.Database.Import, config.Persistent.Chain); err != nil {
+			return err
+		}
+		println("Imported archive into: ", config.Persistent.Chain)
+	}
+	if config.Core.Database.RestoreBackup != "" {
+		if configuration.DatabaseInDirectory(databasePath) {
+			return errors.New("not restoring backup over existing database in " + databasePath)
+		}
+		if err := cmachine.RestoreDatabaseBackup(config.Core.Database.RestoreBackup, databasePath); err != nil {
+			return err
+		}
+		println("Restored backup into: ", databasePath)
+	}
 	if !configuration.DatabaseInDirectory(databasePath) {
 		if !configuration.DatabaseInDirectory(databasePath) {
 			return errors.New("unable to access database in " + databasePath)
@@ -77,6 +96,19 @@ func startup() error {
 
 	println("Using database: ", databasePath)
 
+	if config.Core.Database.Export != "" {
+		// Export the files as they are, without opening the database
+		if err := cmdhelp.ExportArchive(config.Core.Database.Export, config.Persistent.Chain, []string{"db"}); err != nil {
+			return err
+		}
+		println("Exported database to: ", config.Core.Database.Export)
+		return nil
+	}
+
+	if config.Core.Database.ListCheckpoints > 0 {
+		return cmdhelp.PrintCheckpoints(databasePath, &config.Core, config.Core.Database.ListCheckpoints)
+	}
+
 	if config.Core.Database.Metadata {
 		if err = cmdhelp.PrintDatabaseMetadata(databasePath, &config.Core); err != nil {
 			return errors.New("issue printing database " + databasePath)
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	golog "log"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog/pkgerrors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+)
+
+var logger zerolog.Logger
+
+const helpText = `commands:
+  step [n]          execute the next n instructions, printing each of them
+  next              execute the next instruction, and if it jumps away, run until execution returns to the instruction after it
+  continue          run until a breakpoint is reached or the machine blocks
+  break <hash>      stop before executing the codepoint with the given hash
+  delete <hash>     remove a breakpoint
+  breakpoints       list breakpoints
+  state             print the machine's state
+  quit              exit
+`
+
+func main() {
+	// Enable line numbers in logging
+	golog.SetFlags(golog.LstdFlags | golog.Lshortfile)
+
+	// Print stack trace when `.Error().Stack().Err(err).` is added to zerolog call
+	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
+
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	// Print line number that log was created on
+	logger = log.With().Caller().Stack().Str("component", "arb-replay").Logger()
+
+	if err := startup(); err != nil {
+		logger.Error().Err(err).Msg("Error running arb-replay")
+	}
+}
+
+func startup() error {
+	config, err := configuration.ParseReplayTool()
+	if err != nil || len(config.Persistent.Chain) == 0 {
+		fmt.Printf("\n")
+		fmt.Printf("Sample usage: %s --persistent.chain='.arbitrum/mainnet' --replay.gas=1000000000 --replay.messages=2\n", os.Args[0])
+		if err != nil && !strings.Contains(err.Error(), "help requested") {
+			fmt.Printf("%s\n", err.Error())
+		}
+
+		return nil
+	}
+
+	databasePath := config.GetDatabasePath()
+	if !configuration.DatabaseInDirectory(databasePath) {
+		return errors.New("unable to access database in " + databasePath)
+	}
+
+	storage, err := cmachine.NewArbStorage(databasePath, &config.Core)
+	if err != nil {
+		return err
+	}
+	defer storage.CloseArbStorage()
+	arbCore := storage.GetArbCore()
+
+	cursor, err := arbCore.GetExecutionCursor(new(big.Int).SetUint64(config.Replay.Gas), true)
+	if err != nil {
+		return errors.Wrap(err, "error getting machine")
+	}
+	messages, err := arbCore.GetMessages(cursor.TotalMessagesRead(), new(big.Int).SetUint64(config.Replay.Messages))
+	if err != nil {
+		return errors.Wrap(err, "error getting inbox messages")
+	}
+	mach, err := arbCore.TakeMachine(cursor)
+	if err != nil {
+		return errors.Wrap(err, "error getting machine")
+	}
+
+	fmt.Printf(
+		"loaded machine %v after %v gas, %v messages read, with %v messages to read\n",
+		mach.Hash(),
+		cursor.TotalGasConsumed(),
+		cursor.TotalMessagesRead(),
+		len(messages),
+	)
+	fmt.Print(helpText)
+
+	r := newReplayer(context.Background(), mach, messages, config.Replay.MaxSteps, os.Stdout)
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("> ")
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" || fields[0] == "exit" {
+			return nil
+		}
+		if err := runCommand(r, fields[0], fields[1:]); err != nil {
+			fmt.Printf("error: %v\n", err)
+		}
+	}
+}
+
+func runCommand(r *replayer, command string, args []string) error {
+	switch command {
+	case "step", "s":
+		count := uint64(1)
+		if len(args) > 0 {
+			var err error
+			count, err = strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return errors.Wrap(err, "invalid step count")
+			}
+		}
+		return r.step(count)
+	case "next", "n":
+		return r.stepOver()
+	case "continue", "c":
+		return r.continueExecution()
+	case "break", "b", "delete", "d":
+		if len(args) != 1 {
+			return errors.New("expected a codepoint hash")
+		}
+		h := common.HexToHash(args[0])
+		if command == "break" || command == "b" {
+			r.breakpoints[h] = true
+		} else {
+			delete(r.breakpoints, h)
+		}
+		return nil
+	case "breakpoints":
+		r.printBreakpoints()
+		return nil
+	case "state":
+		return r.printState()
+	case "help":
+		fmt.Print(helpText)
+		return nil
+	default:
+		return errors.Errorf("unknown command %v, try help", command)
+	}
+}
@@ -0,0 +1,246 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+var replayFormatOptions = value.FormatOptions{MaxDepth: 4, MaxBytes: 64}
+
+// stepResult is the outcome of executing a single instruction. step is nil
+// if the machine was blocked and didn't execute anything.
+type stepResult struct {
+	step        *machine.ExecutionStep
+	assertion   *protocol.ExecutionAssertion
+	debugPrints []value.Value
+}
+
+// replayer executes a machine an instruction at a time. The Machine
+// interface doesn't expose the position of the next instruction, so it's
+// learnt from the step tracer once the instruction has executed, and
+// commands which need to stop before an instruction keep a copy of the
+// machine to go back to.
+type replayer struct {
+	ctx         context.Context
+	mach        machine.Machine
+	messages    []inbox.InboxMessage
+	breakpoints map[common.Hash]bool
+	maxSteps    uint64
+	totalSteps  uint64
+	out         io.Writer
+}
+
+func newReplayer(ctx context.Context, mach machine.Machine, messages []inbox.InboxMessage, maxSteps uint64, out io.Writer) *replayer {
+	return &replayer{
+		ctx:         ctx,
+		mach:        mach,
+		messages:    messages,
+		breakpoints: make(map[common.Hash]bool),
+		maxSteps:    maxSteps,
+		out:         out,
+	}
+}
+
+func (r *replayer) executeOne() (*stepResult, error) {
+	var traced *machine.ExecutionStep
+	r.mach.SetStepTracer(machine.StepTracerFunc(func(step machine.ExecutionStep) {
+		traced = &step
+	}))
+	defer r.mach.SetStepTracer(nil)
+
+	// Every instruction uses some gas, so with a limit of one and going over
+	// gas allowed exactly one executes
+	assertion, debugPrints, _, err := r.mach.ExecuteAssertion(r.ctx, 1, true, r.messages, true)
+	if err != nil {
+		return nil, err
+	}
+	r.messages = r.messages[assertion.InboxMessagesConsumed:]
+	if traced != nil {
+		r.totalSteps++
+	}
+	return &stepResult{step: traced, assertion: assertion, debugPrints: debugPrints}, nil
+}
+
+func (r *replayer) printStep(res *stepResult) {
+	step := res.step
+	fmt.Fprintf(r.out, "%v:%v opcode 0x%02x gas %v", step.Segment, step.PC, uint8(step.Opcode), step.Gas)
+	if step.StackTop != nil {
+		fmt.Fprintf(r.out, " top %v", value.Format(value.NewIntValue(step.StackTop), replayFormatOptions))
+	} else if step.StackTopHash != nil {
+		fmt.Fprintf(r.out, " top hash %v", step.StackTopHash)
+	}
+	fmt.Fprintln(r.out)
+	r.printOutputs(res)
+}
+
+func (r *replayer) printOutputs(res *stepResult) {
+	for _, val := range res.debugPrints {
+		fmt.Fprintf(r.out, "  debugprint: %v\n", value.Format(val, replayFormatOptions))
+	}
+	for _, val := range res.assertion.Logs {
+		fmt.Fprintf(r.out, "  log: %v\n", value.Format(val, replayFormatOptions))
+	}
+	for _, send := range res.assertion.Sends {
+		fmt.Fprintf(r.out, "  send: %v\n", value.Format(value.NewBuffer(send), replayFormatOptions))
+	}
+	if res.assertion.InboxMessagesConsumed > 0 {
+		fmt.Fprintf(r.out, "  read %v inbox messages, %v left\n", res.assertion.InboxMessagesConsumed, len(r.messages))
+	}
+}
+
+func (r *replayer) printBlocked() {
+	reason := r.mach.IsBlocked(len(r.messages) > 0)
+	if reason == nil {
+		fmt.Fprintln(r.out, "machine didn't execute")
+		return
+	}
+	fmt.Fprintf(r.out, "machine is blocked: %v\n", reason)
+}
+
+// step executes count instructions, printing each of them
+func (r *replayer) step(count uint64) error {
+	for i := uint64(0); i < count; i++ {
+		res, err := r.executeOne()
+		if err != nil {
+			return err
+		}
+		if res.step == nil {
+			r.printBlocked()
+			return nil
+		}
+		r.printStep(res)
+	}
+	return nil
+}
+
+// stepOver executes the next instruction and, if it jumps away, continues
+// until execution comes back to the instruction after it, like stepping
+// over a function call
+func (r *replayer) stepOver() error {
+	res, err := r.executeOne()
+	if err != nil {
+		return err
+	}
+	if res.step == nil {
+		r.printBlocked()
+		return nil
+	}
+	r.printStep(res)
+	if res.step.PC == 0 {
+		return nil
+	}
+	target := machine.CodePointRef{Segment: res.step.Segment, PC: res.step.PC - 1}
+	return r.runUntil(func(step *machine.ExecutionStep) bool {
+		return step.CodePointRef == target
+	})
+}
+
+// continueExecution runs until a breakpoint is reached or the machine
+// blocks. A breakpoint at the current instruction is ignored so that
+// execution can continue from it.
+func (r *replayer) continueExecution() error {
+	res, err := r.executeOne()
+	if err != nil {
+		return err
+	}
+	if res.step == nil {
+		r.printBlocked()
+		return nil
+	}
+	r.printOutputs(res)
+	return r.runUntil(func(*machine.ExecutionStep) bool {
+		return false
+	})
+}
+
+// runUntil executes instructions until stop returns true for one, which is
+// left unexecuted, or a breakpoint or the step limit is reached. Outputs of
+// the instructions executed are printed, but not the instructions.
+func (r *replayer) runUntil(stop func(step *machine.ExecutionStep) bool) error {
+	for i := uint64(0); i < r.maxSteps; i++ {
+		if r.mach.CurrentStatus() == machine.Extensive && r.breakpoints[r.mach.CodePointHash()] {
+			fmt.Fprintf(r.out, "reached breakpoint %v at step %v\n", r.mach.CodePointHash(), r.totalSteps)
+			return nil
+		}
+		prevMach := r.mach.Clone()
+		prevMessages := r.messages
+		res, err := r.executeOne()
+		if err != nil {
+			return err
+		}
+		if res.step == nil {
+			fmt.Fprintf(r.out, "stopped at step %v\n", r.totalSteps)
+			r.printBlocked()
+			return nil
+		}
+		if stop(res.step) {
+			r.mach = prevMach
+			r.messages = prevMessages
+			r.totalSteps--
+			fmt.Fprintf(r.out, "stopped before %v:%v at step %v\n", res.step.Segment, res.step.PC, r.totalSteps)
+			return nil
+		}
+		r.printOutputs(res)
+	}
+	fmt.Fprintf(r.out, "stopped after reaching the limit of %v steps\n", r.maxSteps)
+	return nil
+}
+
+func (r *replayer) printState() error {
+	fmt.Fprintf(r.out, "status:        %v\n", r.mach.CurrentStatus())
+	fmt.Fprintf(r.out, "steps:         %v\n", r.totalSteps)
+	fmt.Fprintf(r.out, "messages left: %v\n", len(r.messages))
+	fmt.Fprintf(r.out, "machine hash:  %v\n", r.mach.Hash())
+	if r.mach.CurrentStatus() != machine.Extensive {
+		return nil
+	}
+	state, err := machine.GetState(r.mach)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "codepoint:     %v\n", state.CodePointHash)
+	fmt.Fprintf(r.out, "stack:         %v (size %v)\n", state.Stack.Hash(), state.Stack.Size())
+	fmt.Fprintf(r.out, "aux stack:     %v (size %v)\n", state.AuxStack.Hash(), state.AuxStack.Size())
+	fmt.Fprintf(r.out, "register:      %v\n", state.Register)
+	fmt.Fprintf(r.out, "static:        %v\n", state.Static)
+	fmt.Fprintf(r.out, "gas remaining: %v\n", state.ArbGasRemaining)
+	fmt.Fprintf(r.out, "error handler: %v\n", state.ErrCodePointHash)
+	return nil
+}
+
+func (r *replayer) printBreakpoints() {
+	hashes := make([]common.Hash, 0, len(r.breakpoints))
+	for h := range r.breakpoints {
+		hashes = append(hashes, h)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return hashes[i].String() < hashes[j].String()
+	})
+	for _, h := range hashes {
+		fmt.Fprintln(r.out, h)
+	}
+}
@@ -0,0 +1,327 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmdhelp
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	archiveManifestName    = "manifest.json"
+	archiveManifestVersion = 1
+)
+
+type archiveFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+
+	// source is where the file is read from when exporting
+	source string
+}
+
+// archiveDir is a directory to export along with its name in the archive
+type archiveDir struct {
+	name string
+	path string
+}
+
+type archiveManifest struct {
+	Version     int           `json:"version"`
+	Directories []string      `json:"directories"`
+	Files       []archiveFile `json:"files"`
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func buildArchiveManifest(root string, dirs []string) (*archiveManifest, error) {
+	sources := make([]archiveDir, 0, len(dirs))
+	for _, dir := range dirs {
+		sources = append(sources, archiveDir{name: dir, path: filepath.Join(root, dir)})
+	}
+	manifest, err := buildManifestFromDirs(sources)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Files) == 0 {
+		return nil, errors.Errorf("nothing to export in %v", root)
+	}
+	return manifest, nil
+}
+
+func buildManifestFromDirs(dirs []archiveDir) (*archiveManifest, error) {
+	manifest := &archiveManifest{Version: archiveManifestVersion}
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir.path); os.IsNotExist(err) {
+			continue
+		}
+		manifest.Directories = append(manifest.Directories, dir.name)
+		err := filepath.Walk(dir.path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir.path, path)
+			if err != nil {
+				return err
+			}
+			hash, err := hashFile(path)
+			if err != nil {
+				return err
+			}
+			manifest.Files = append(manifest.Files, archiveFile{
+				Path:   dir.name + "/" + filepath.ToSlash(rel),
+				Size:   info.Size(),
+				SHA256: hash,
+				source: path,
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+	return manifest, nil
+}
+
+// ExportArchive writes the given directories under root into a gzipped tar
+// archive at archivePath. The archive starts with a manifest listing the
+// sha256 hash of every file so that ImportArchive can verify it. The
+// database must not be in use while it is being exported.
+func ExportArchive(archivePath string, root string, dirs []string) error {
+	manifest, err := buildArchiveManifest(root, dirs)
+	if err != nil {
+		return err
+	}
+	return writeArchiveFile(archivePath, manifest)
+}
+
+// ExportDirectoryArchive writes an archive at archivePath holding dir under
+// the given name, in the same format as ExportArchive. This is used to
+// archive a saved copy of the database as "db" so that it can be imported
+// directly.
+func ExportDirectoryArchive(archivePath string, dir string, name string) error {
+	manifest, err := buildManifestFromDirs([]archiveDir{{name: name, path: dir}})
+	if err != nil {
+		return err
+	}
+	if len(manifest.Files) == 0 {
+		return errors.Errorf("nothing to export in %v", dir)
+	}
+	return writeArchiveFile(archivePath, manifest)
+}
+
+func writeArchiveFile(archivePath string, manifest *archiveManifest) error {
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	out, err := os.OpenFile(archivePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	if err := writeArchive(tw, manifest, manifestData); err != nil {
+		_ = out.Close()
+		_ = os.Remove(archivePath)
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := gz.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(out.Close())
+}
+
+func writeArchive(tw *tar.Writer, manifest *archiveManifest, manifestData []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name: archiveManifestName,
+		Mode: 0644,
+		Size: int64(len(manifestData)),
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := tw.Write(manifestData); err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, file := range manifest.Files {
+		err := tw.WriteHeader(&tar.Header{
+			Name: file.Path,
+			Mode: 0644,
+			Size: file.Size,
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		f, err := os.Open(file.source)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		h := sha256.New()
+		_, err = io.CopyN(io.MultiWriter(tw, h), f, file.Size)
+		_ = f.Close()
+		if err != nil {
+			return errors.Wrapf(err, "file %v changed during export", file.Path)
+		}
+		if hex.EncodeToString(h.Sum(nil)) != file.SHA256 {
+			return errors.Errorf("file %v changed during export", file.Path)
+		}
+	}
+	return nil
+}
+
+// ImportArchive extracts an archive created by ExportArchive into root,
+// verifying the hash of every file against the manifest. Nothing is moved
+// into place unless the whole archive verifies, and existing directories
+// are never overwritten.
+func ImportArchive(archivePath string, root string) error {
+	in, err := os.Open(archivePath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer in.Close()
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil {
+		return errors.Wrap(err, "couldn't read archive manifest")
+	}
+	if header.Name != archiveManifestName {
+		return errors.Errorf("archive doesn't start with %v", archiveManifestName)
+	}
+	var manifest archiveManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return errors.Wrap(err, "couldn't parse archive manifest")
+	}
+	if manifest.Version != archiveManifestVersion {
+		return errors.Errorf("unsupported archive version %v", manifest.Version)
+	}
+	for _, dir := range manifest.Directories {
+		if dir == "" || dir == "." || dir == ".." || filepath.Base(dir) != dir {
+			return errors.Errorf("invalid directory %v in archive", dir)
+		}
+		if _, err := os.Stat(filepath.Join(root, dir)); !os.IsNotExist(err) {
+			return errors.Errorf("not importing over existing %v", filepath.Join(root, dir))
+		}
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	staging, err := ioutil.TempDir(root, "import")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := extractArchive(tr, staging, &manifest); err != nil {
+		return err
+	}
+	for _, dir := range manifest.Directories {
+		if err := os.Rename(filepath.Join(staging, dir), filepath.Join(root, dir)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func extractArchive(tr *tar.Reader, staging string, manifest *archiveManifest) error {
+	expected := make(map[string]archiveFile, len(manifest.Files))
+	for _, file := range manifest.Files {
+		expected[file.Path] = file
+	}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		file, ok := expected[header.Name]
+		if !ok {
+			return errors.Errorf("archive contains unexpected file %v", header.Name)
+		}
+		delete(expected, header.Name)
+
+		dest := filepath.Join(staging, filepath.FromSlash(file.Path))
+		if !strings.HasPrefix(dest, staging+string(os.PathSeparator)) {
+			return errors.Errorf("invalid path %v in archive", file.Path)
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return errors.WithStack(err)
+		}
+		out, err := os.Create(dest)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		h := sha256.New()
+		n, err := io.Copy(io.MultiWriter(out, h), tr)
+		closeErr := out.Close()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if closeErr != nil {
+			return errors.WithStack(closeErr)
+		}
+		if n != file.Size || hex.EncodeToString(h.Sum(nil)) != file.SHA256 {
+			return errors.Errorf("integrity check failed for %v", file.Path)
+		}
+	}
+	if len(expected) != 0 {
+		return errors.Errorf("archive is missing %v files", len(expected))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmdhelp
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestArchive(out io.WriteCloser, root string, manifest *archiveManifest) error {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	entries := map[string][]byte{archiveManifestName: data}
+	names := []string{archiveManifestName}
+	for _, file := range manifest.Files {
+		contents, err := ioutil.ReadFile(filepath.Join(root, file.Path))
+		if err != nil {
+			return err
+		}
+		entries[file.Path] = contents
+		names = append(names, file.Path)
+	}
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(entries[name]))}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(entries[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	files := map[string][]byte{
+		"db/CURRENT":        []byte("MANIFEST-000001\n"),
+		"db/000005.sst":     bytes.Repeat([]byte{1, 2, 3}, 1000),
+		"wallet/000001.ldb": []byte("wallet"),
+	}
+	for name, data := range files {
+		writeTestFile(t, filepath.Join(src, name), data)
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "export.tar.gz")
+	if err := ExportArchive(archivePath, src, []string{"db", "wallet", "missing"}); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := t.TempDir()
+	if err := ImportArchive(archivePath, dest); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		imported, err := ioutil.ReadFile(filepath.Join(dest, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(imported, data) {
+			t.Errorf("%v has wrong contents after import", name)
+		}
+	}
+
+	// Importing again must not overwrite the existing database
+	if err := ImportArchive(archivePath, dest); err == nil {
+		t.Error("import over existing database succeeded")
+	}
+}
+
+func TestArchiveIntegrityCheck(t *testing.T) {
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "db", "CURRENT"), []byte("MANIFEST-000001\n"))
+	manifest, err := buildArchiveManifest(src, []string{"db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest.Files[0].SHA256 = manifest.Files[0].SHA256[1:] + "0"
+
+	// Write an archive whose manifest doesn't match its contents
+	archivePath := filepath.Join(t.TempDir(), "bad.tar.gz")
+	out, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTestArchive(out, src, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := t.TempDir()
+	if err := ImportArchive(archivePath, dest); err == nil {
+		t.Fatal("import of corrupted archive succeeded")
+	}
+	if _, err := os.Stat(filepath.Join(dest, "db")); !os.IsNotExist(err) {
+		t.Error("corrupted archive was partially imported")
+	}
+}
@@ -8,20 +8,25 @@ import (
 	"math/big"
 )
 
-func UpdatePrunePoint(ctx context.Context, rollup *ethbridge.RollupWatcher, lookup core.ArbCoreLookup) error {
+// UpdatePrunePoint lets the database prune checkpoints from before the last
+// retainNodes confirmed nodes preceding the latest confirmed node
+func UpdatePrunePoint(ctx context.Context, rollup *ethbridge.RollupWatcher, lookup core.ArbCoreLookup, retainNodes int) error {
 	// Prune any stale database entries while we wait
 	latestNode, err := rollup.LatestConfirmedNode(ctx)
 	if err != nil {
 		return errors.Wrap(err, "couldn't get latest confirmed node")
 	}
 
-	if latestNode.Cmp(big.NewInt(0)) == 0 {
-		logger.Info().Msg("no confirmed nodes so nothing to prune")
+	if retainNodes < 1 {
+		retainNodes = 1
+	}
+	if latestNode.Cmp(big.NewInt(int64(retainNodes))) < 0 {
+		logger.Info().Msg("not enough confirmed nodes to prune")
 		return nil
 	}
 
-	// Prune checkpoints up to confirmed node before last confirmed node
-	previousConfirmedNode := new(big.Int).Sub(latestNode, big.NewInt(1))
+	// Prune checkpoints up to the oldest retained confirmed node
+	previousConfirmedNode := new(big.Int).Sub(latestNode, big.NewInt(int64(retainNodes)))
 	previousNodeInfo, err := rollup.LookupNode(ctx, previousConfirmedNode)
 	if err != nil {
 		return errors.Wrap(err, "couldn't lookup previous confirmed node "+previousConfirmedNode.String())
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmdhelp
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/usbwallet"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+)
+
+// openHardwareWallet returns transact opts which sign using a connected Ledger
+// or Trezor device. Every transaction must be approved on the device.
+func openHardwareWallet(hardwareConfig configuration.WalletHardware, chainId *big.Int) (*bind.TransactOpts, error) {
+	var hub *usbwallet.Hub
+	var err error
+	switch strings.ToLower(hardwareConfig.Type) {
+	case "ledger":
+		hub, err = usbwallet.NewLedgerHub()
+	case "trezor":
+		hub, err = usbwallet.NewTrezorHubWithHID()
+	default:
+		return nil, errors.Errorf("unknown hardware wallet type %v, must be ledger or trezor", hardwareConfig.Type)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error searching for hardware wallet")
+	}
+
+	wallets := hub.Wallets()
+	if len(wallets) == 0 {
+		return nil, errors.New("no hardware wallet found, make sure the device is connected and unlocked")
+	}
+	if len(wallets) > 1 {
+		return nil, errors.Errorf("found %v hardware wallets, only connect one", len(wallets))
+	}
+	wallet := wallets[0]
+	if err := openHardwareWalletDevice(wallet); err != nil {
+		return nil, errors.Wrap(err, "error opening hardware wallet")
+	}
+
+	path, err := accounts.ParseDerivationPath(hardwareConfig.DerivationPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid derivation path %v", hardwareConfig.DerivationPath)
+	}
+	fmt.Println("Confirm address derivation on the hardware wallet if prompted")
+	account, err := wallet.Derive(path, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "error deriving hardware wallet account")
+	}
+
+	logger.
+		Info().
+		Str("signer", account.Address.Hex()).
+		Str("type", hardwareConfig.Type).
+		Str("path", hardwareConfig.DerivationPath).
+		Msg("hardware wallet used as signer")
+	return &bind.TransactOpts{
+		From: account.Address,
+		Signer: func(address ethcommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != account.Address {
+				return nil, bind.ErrNotAuthorized
+			}
+			fmt.Printf("Confirm transaction with nonce %v to %v on the hardware wallet\n", tx.Nonce(), tx.To())
+			return wallet.SignTx(account, tx, chainId)
+		},
+	}, nil
+}
+
+func openHardwareWalletDevice(wallet accounts.Wallet) error {
+	err := wallet.Open("")
+	if err == usbwallet.ErrTrezorPINNeeded {
+		fmt.Println("Look at the device for the number positions, then enter the matching digits of your PIN")
+		fmt.Print("Enter PIN: ")
+		pin, readErr := readPass()
+		if readErr != nil {
+			return readErr
+		}
+		err = wallet.Open(pin)
+	}
+	if err == usbwallet.ErrTrezorPassphraseNeeded {
+		fmt.Print("Enter wallet passphrase: ")
+		passphrase, readErr := readPass()
+		if readErr != nil {
+			return readErr
+		}
+		err = wallet.Open(passphrase)
+	}
+	return err
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 )
 
@@ -28,15 +29,23 @@ func CreateLaunchContext() (context.Context, func(), chan bool) {
 	canceledChanChan := make(chan bool, 1)
 	signal.Notify(interruptChan, os.Interrupt, syscall.SIGTERM)
 	ctx, cancelCtx := context.WithCancel(context.Background())
+	// Only the first of an interrupt or a call to cancel signals
+	// canceledChanChan, so cancel may be called more than once, such as during
+	// shutdown and again when the caller's deferred cancel runs
+	var cancelOnce sync.Once
 	go func() {
 		defer close(interruptChan)
 		<-interruptChan
-		cancelCtx()
-		canceledChanChan <- true
+		cancelOnce.Do(func() {
+			cancelCtx()
+			canceledChanChan <- true
+		})
 	}()
 	cancel := func() {
-		cancelCtx()
-		close(canceledChanChan)
+		cancelOnce.Do(func() {
+			cancelCtx()
+			close(canceledChanChan)
+		})
 	}
 	return ctx, cancel, canceledChanChan
 }
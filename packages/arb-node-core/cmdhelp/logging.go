@@ -18,12 +18,15 @@ package cmdhelp
 
 import (
 	"flag"
+	"fmt"
+	"math/big"
 
 	gethlog "github.com/ethereum/go-ethereum/log"
 	"github.com/rs/zerolog"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
 )
 
 func AddLogFlags(fs *flag.FlagSet) (*string, *string) {
@@ -59,3 +62,48 @@ func PrintDatabaseMetadata(path string, coreConfig *configuration.Core) error {
 
 	return nil
 }
+
+// PrintCheckpoints prints the last count checkpoints in the database. Only
+// the checkpoint records are read, so this is fast even for large machines.
+func PrintCheckpoints(path string, coreConfig *configuration.Core, count uint64) error {
+	storage, err := cmachine.NewArbStorage(path, coreConfig)
+	if err != nil {
+		return err
+	}
+	defer storage.CloseArbStorage()
+
+	arbCore := storage.GetArbCore()
+	var checkpoints []core.CheckpointSummary
+	fromGas := big.NewInt(0)
+	for {
+		batch, err := arbCore.CheckpointSummaries(fromGas, 1000)
+		if err != nil {
+			return err
+		}
+		checkpoints = append(checkpoints, batch...)
+		if uint64(len(checkpoints)) > count {
+			checkpoints = checkpoints[uint64(len(checkpoints))-count:]
+		}
+		if len(batch) < 1000 {
+			break
+		}
+		fromGas = new(big.Int).Add(batch[len(batch)-1].TotalGasConsumed, big.NewInt(1))
+	}
+
+	for _, checkpoint := range checkpoints {
+		machineHash := "pruned"
+		if checkpoint.MachineHash != nil {
+			machineHash = checkpoint.MachineHash.String()
+		}
+		fmt.Printf(
+			"gas %v, l2 block %v, messages %v, sends %v, logs %v, machine %v\n",
+			checkpoint.TotalGasConsumed,
+			checkpoint.L2BlockNumber,
+			checkpoint.TotalMessagesRead,
+			checkpoint.TotalSendCount,
+			checkpoint.TotalLogCount,
+			machineHash,
+		)
+	}
+	return nil
+}
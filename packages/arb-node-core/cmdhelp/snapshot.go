@@ -0,0 +1,190 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmdhelp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+// snapshotDownloadURL turns s3://bucket/key URLs into the bucket's public
+// https endpoint, and returns other URLs unchanged
+func snapshotDownloadURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	switch parsed.Scheme {
+	case "http", "https":
+		return rawURL, nil
+	case "s3":
+		if parsed.Host == "" || parsed.Path == "" {
+			return "", errors.Errorf("invalid s3 snapshot url %v", rawURL)
+		}
+		return "https://" + parsed.Host + ".s3.amazonaws.com" + parsed.Path, nil
+	default:
+		return "", errors.Errorf("unsupported snapshot url scheme %v", parsed.Scheme)
+	}
+}
+
+func download(ctx context.Context, rawURL string, out io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't download %v", rawURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("downloading %v returned %v", rawURL, resp.Status)
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return errors.Wrapf(err, "couldn't download %v", rawURL)
+	}
+	return nil
+}
+
+// checkSnapshotSignature checks that sig, a hex encoded 65 byte signature of
+// the snapshot's sha256 hash, was made by signer
+func checkSnapshotSignature(digest []byte, sig string, signer ethcommon.Address) error {
+	sigBytes, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(sig), "0x"))
+	if err != nil {
+		return errors.Wrap(err, "snapshot signature isn't hex encoded")
+	}
+	if len(sigBytes) != crypto.SignatureLength {
+		return errors.Errorf("snapshot signature must be %v bytes, got %v", crypto.SignatureLength, len(sigBytes))
+	}
+	if sigBytes[crypto.RecoveryIDOffset] >= 27 {
+		sigBytes[crypto.RecoveryIDOffset] -= 27
+	}
+	pubkey, err := crypto.SigToPub(digest, sigBytes)
+	if err != nil {
+		return errors.Wrap(err, "invalid snapshot signature")
+	}
+	if recovered := crypto.PubkeyToAddress(*pubkey); recovered != signer {
+		return errors.Errorf("snapshot signed by %v, expected %v", recovered.Hex(), signer.Hex())
+	}
+	return nil
+}
+
+// FastSync bootstraps a new database under root from the snapshot archive at
+// snapshotURL. If signer is non-empty, the snapshot must be signed by it.
+// The snapshot is only trusted for as long as it takes to start the node,
+// VerifyConfirmedState should be used to check it against L1 once the core
+// has loaded it.
+func FastSync(ctx context.Context, snapshotURL string, signer string, root string) error {
+	downloadURL, err := snapshotDownloadURL(snapshotURL)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(root, "snapshot-*.tar.gz")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	logger.Info().Str("url", downloadURL).Msg("downloading database snapshot")
+	hasher := sha256.New()
+	if err := download(ctx, downloadURL, io.MultiWriter(tmp, hasher)); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	digest := hasher.Sum(nil)
+	logger.Info().Hex("sha256", digest).Msg("downloaded database snapshot")
+
+	if signer != "" {
+		if !ethcommon.IsHexAddress(signer) {
+			return errors.Errorf("invalid snapshot signer %v", signer)
+		}
+		var sig strings.Builder
+		if err := download(ctx, downloadURL+".sig", &sig); err != nil {
+			return errors.Wrap(err, "couldn't get snapshot signature")
+		}
+		if err := checkSnapshotSignature(digest, sig.String(), ethcommon.HexToAddress(signer)); err != nil {
+			return err
+		}
+	} else {
+		logger.Warn().Msg("snapshot signer not set, trusting snapshot until it is checked against L1")
+	}
+
+	return ImportArchive(tmp.Name(), root)
+}
+
+// VerifyConfirmedState checks that the latest confirmed node which local
+// execution has reached matches the local machine state, so that a node
+// bootstrapped from a snapshot doesn't build on a state that L1 never
+// confirmed
+func VerifyConfirmedState(ctx context.Context, rollup *ethbridge.RollupWatcher, lookup core.ArbCoreLookup) error {
+	localGas, err := lookup.GetLastMachineTotalGas()
+	if err != nil {
+		return err
+	}
+	nodeNum, err := rollup.LatestConfirmedNode(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't get latest confirmed node")
+	}
+	for ; nodeNum.Sign() > 0; nodeNum = new(big.Int).Sub(nodeNum, big.NewInt(1)) {
+		node, err := rollup.LookupNode(ctx, nodeNum)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't lookup confirmed node %v", nodeNum)
+		}
+		after := node.Assertion.After
+		if after.TotalGasConsumed.Cmp(localGas) > 0 {
+			continue
+		}
+		cursor, err := lookup.GetExecutionCursor(after.TotalGasConsumed, false)
+		if err != nil {
+			return err
+		}
+		if cursor.TotalGasConsumed().Cmp(after.TotalGasConsumed) != 0 {
+			return errors.Errorf("couldn't reach gas %v of confirmed node %v", after.TotalGasConsumed, nodeNum)
+		}
+		if cursor.MachineHash() != after.MachineHash {
+			return errors.Errorf(
+				"local state %v doesn't match confirmed node %v state %v",
+				cursor.MachineHash(),
+				nodeNum,
+				after.MachineHash,
+			)
+		}
+		logger.Info().Str("node", nodeNum.String()).Msg("local state matches confirmed node")
+		return nil
+	}
+	// Only the genesis node is confirmed, which the machine file already
+	// ensures matches
+	return nil
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmdhelp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestSnapshotDownloadURL(t *testing.T) {
+	converted, err := snapshotDownloadURL("s3://snapshots/mainnet/db.tar.gz")
+	test.FailIfError(t, err)
+	if converted != "https://snapshots.s3.amazonaws.com/mainnet/db.tar.gz" {
+		t.Error("wrong s3 url", converted)
+	}
+	unchanged, err := snapshotDownloadURL("https://example.com/db.tar.gz")
+	test.FailIfError(t, err)
+	if unchanged != "https://example.com/db.tar.gz" {
+		t.Error("https url changed", unchanged)
+	}
+	if _, err := snapshotDownloadURL("ftp://example.com/db.tar.gz"); err == nil {
+		t.Error("accepted unsupported scheme")
+	}
+}
+
+func TestSnapshotSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+	other, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+	digest := sha256.Sum256([]byte("snapshot"))
+	sig, err := crypto.Sign(digest[:], key)
+	test.FailIfError(t, err)
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	test.FailIfError(t, checkSnapshotSignature(digest[:], "0x"+hex.EncodeToString(sig)+"\n", signer))
+	if err := checkSnapshotSignature(digest[:], hex.EncodeToString(sig), crypto.PubkeyToAddress(other.PublicKey)); err == nil {
+		t.Error("accepted signature from wrong signer")
+	}
+	tampered := sha256.Sum256([]byte("tampered"))
+	if err := checkSnapshotSignature(tampered[:], hex.EncodeToString(sig), signer); err == nil {
+		t.Error("accepted signature of different snapshot")
+	}
+}
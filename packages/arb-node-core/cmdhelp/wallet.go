@@ -34,6 +34,7 @@ import (
 	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/transactauth"
 	"github.com/pkg/errors"
 )
 
@@ -68,12 +69,12 @@ func GetKeystore(
 			return nil, nil, errors.New("using fireblocks keystore, remove --wallet.local.only-create-key to run normally")
 		}
 		fromAddress := ethcommon.HexToAddress(walletConfig.Fireblocks.SourceAddress)
-		logger.Info().Hex("address", fromAddress.Bytes()).Msg("fireblocks enabled")
+		logger.Info().Str("address", fromAddress.Hex()).Msg("fireblocks enabled")
 		auth = &bind.TransactOpts{
 			From: fromAddress,
 			Signer: func(address ethcommon.Address, tx *types.Transaction) (*types.Transaction, error) {
 				if address != fromAddress {
-					logger.Error().Hex("currentaddress", address.Bytes()).Hex("expectedaddress", fromAddress.Bytes()).Msg("incorrect from address provided")
+					logger.Error().Str("currentaddress", address.Hex()).Str("expectedaddress", fromAddress.Hex()).Msg("incorrect from address provided")
 					return nil, bind.ErrNotAuthorized
 				}
 				// Just return original unsigned transaction because fireblocks will handle signing
@@ -109,12 +110,36 @@ func GetKeystore(
 
 			logger.
 				Info().
-				Hex("signer", account.Address.Bytes()).
+				Str("signer", account.Address.Hex()).
 				Msg("feed signer wallet used as signer")
 			signer = func(data []byte) ([]byte, error) {
 				return ks.SignHash(*account, data)
 			}
 		}
+	} else if len(walletConfig.External.URL) != 0 {
+		if walletConfig.Local.OnlyCreateKey {
+			return nil, nil, errors.New("using external signer, remove --wallet.local.only-create-key to run normally")
+		}
+		if signerRequired {
+			return nil, nil, errors.New("external signer cannot be used to sign feed messages")
+		}
+		var err error
+		auth, err = transactauth.NewExternalSignerTransactor(walletConfig.External.URL, walletConfig.External.Address, chainId)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if len(walletConfig.Hardware.Type) != 0 {
+		if walletConfig.Local.OnlyCreateKey {
+			return nil, nil, errors.New("using hardware wallet, remove --wallet.local.only-create-key to run normally")
+		}
+		if signerRequired {
+			return nil, nil, errors.New("hardware wallet cannot be used to sign feed messages")
+		}
+		var err error
+		auth, err = openHardwareWallet(walletConfig.Hardware, chainId)
+		if err != nil {
+			return nil, nil, err
+		}
 	} else if len(walletConfig.Local.PrivateKey) != 0 {
 		if walletConfig.Local.OnlyCreateKey {
 			return nil, nil, errors.New("wallet key provided on command line, remove --wallet.local.only-create-key to run normally")
@@ -130,7 +155,7 @@ func GetKeystore(
 
 		logger.
 			Info().
-			Hex("signer", auth.From.Bytes()).
+			Str("signer", auth.From.Hex()).
 			Msg("private key used as signer")
 		signer = func(data []byte) ([]byte, error) {
 			return crypto.Sign(data, privateKey)
@@ -156,7 +181,7 @@ func GetKeystore(
 
 		logger.
 			Info().
-			Hex("signer", account.Address.Bytes()).
+			Str("signer", account.Address.Hex()).
 			Msg("wallet used as signer")
 		signer = func(data []byte) ([]byte, error) {
 			return ks.SignHash(*account, data)
@@ -211,11 +236,11 @@ func openKeystore(description string, walletPath string, walletPassword *string,
 			return nil, &accounts.Account{}, false, err
 		}
 
-		logger.Info().Hex("address", account.Address.Bytes()).Str("description", description).Msg("created new wallet")
+		logger.Info().Str("address", account.Address.Hex()).Str("description", description).Msg("created new wallet")
 	} else {
 		account = ks.Accounts()[0]
 
-		logger.Info().Hex("address", account.Address.Bytes()).Str("description", description).Msg("used existing wallet")
+		logger.Info().Str("address", account.Address.Hex()).Str("description", description).Msg("used existing wallet")
 	}
 
 	err := ks.Unlock(account, password)
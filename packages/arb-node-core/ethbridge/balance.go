@@ -39,7 +39,7 @@ func WaitForBalance(ctx context.Context, client ethutils.EthClient, tokenAddress
 		if balance.Cmp(big.NewInt(0)) > 0 {
 			return nil
 		}
-		logger.Info().Hex("account", userAddress.Bytes()).Msg("Waiting for account to receive ETH")
+		logger.Info().Str("account", userAddress.Hex()).Msg("Waiting for account to receive ETH")
 		timer := time.NewTicker(time.Second * 5)
 		for {
 			select {
@@ -68,8 +68,8 @@ func WaitForBalance(ctx context.Context, client ethutils.EthClient, tokenAddress
 			return nil
 		}
 		logger.Info().
-			Hex("account", userAddress.Bytes()).
-			Hex("contract", tokenAddress.Bytes()).
+			Str("account", userAddress.Hex()).
+			Str("contract", tokenAddress.Hex()).
 			Msg("Waiting for account to receive ERC-20 token from contract")
 		timer := time.NewTicker(time.Second * 5)
 		for {
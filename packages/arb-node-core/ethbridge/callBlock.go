@@ -0,0 +1,48 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+type callBlockKey struct{}
+
+// WithCallBlock returns a context which makes the watchers in this package
+// read contract state as of the given L1 block rather than the latest block.
+// Event lookups aren't affected.
+func WithCallBlock(ctx context.Context, block *big.Int) context.Context {
+	return context.WithValue(ctx, callBlockKey{}, block)
+}
+
+// CallBlock returns the block set by WithCallBlock, or nil if there isn't one
+func CallBlock(ctx context.Context) *big.Int {
+	block, _ := ctx.Value(callBlockKey{}).(*big.Int)
+	return block
+}
+
+func callOptsWithContext(ctx context.Context, base bind.CallOpts) *bind.CallOpts {
+	opts := base
+	opts.Context = ctx
+	if block := CallBlock(ctx); block != nil {
+		opts.BlockNumber = block
+	}
+	return &opts
+}
@@ -75,9 +75,7 @@ func NewChallengeWatcher(address ethcommon.Address, fromBlock int64, client ethu
 }
 
 func (c *ChallengeWatcher) getCallOpts(ctx context.Context) *bind.CallOpts {
-	opts := c.baseCallOpts
-	opts.Context = ctx
-	return &opts
+	return callOptsWithContext(ctx, c.baseCallOpts)
 }
 
 func (c *ChallengeWatcher) Address() common.Address {
@@ -124,22 +122,37 @@ func (c *ChallengeWatcher) ChallengeState(ctx context.Context) (common.Hash, err
 	return common.NewHashFromEth(challengeState), nil
 }
 
-func (c *ChallengeWatcher) IsTimedOut(ctx context.Context) (bool, error) {
-	currentBlock, err := c.client.BlockInfoByNumber(ctx, nil)
-	if err != nil {
-		return false, errors.WithStack(err)
-	}
+// LastMoveBlock returns the L1 block of the last move, or of the start of
+// the challenge if no moves have been made
+func (c *ChallengeWatcher) LastMoveBlock(ctx context.Context) (*big.Int, error) {
+	block, err := c.con.LastMoveBlock(c.getCallOpts(ctx))
+	return block, errors.WithStack(err)
+}
+
+// ResponderDeadline returns the last L1 block in which the current responder
+// can move before they can be timed out
+func (c *ChallengeWatcher) ResponderDeadline(ctx context.Context) (*big.Int, error) {
 	lastMoveBlock, err := c.con.LastMoveBlock(c.getCallOpts(ctx))
 	if err != nil {
-		return false, errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
 	timeLeft, err := c.con.CurrentResponderTimeLeft(c.getCallOpts(ctx))
 	if err != nil {
-		return false, errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
+	return new(big.Int).Add(lastMoveBlock, timeLeft), nil
+}
 
-	timeSinceLastMove := new(big.Int).Sub((*big.Int)(currentBlock.Number), lastMoveBlock)
-	return timeSinceLastMove.Cmp(timeLeft) > 0, nil
+func (c *ChallengeWatcher) IsTimedOut(ctx context.Context) (bool, error) {
+	currentBlock, err := c.client.BlockInfoByNumber(ctx, nil)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	deadline, err := c.ResponderDeadline(ctx)
+	if err != nil {
+		return false, err
+	}
+	return (*big.Int)(currentBlock.Number).Cmp(deadline) > 0, nil
 }
 
 func (c *ChallengeWatcher) LookupBisection(ctx context.Context, challengeState common.Hash) (*core.Bisection, error) {
@@ -150,7 +163,7 @@ func (c *ChallengeWatcher) LookupBisection(ctx context.Context, challengeState c
 		Addresses: []ethcommon.Address{c.address},
 		Topics:    [][]ethcommon.Hash{{bisectedID}, {challengeState.ToEthHash()}},
 	}
-	logs, err := c.client.FilterLogs(ctx, query)
+	logs, err := FilterLogsWithSplitting(ctx, c.client, query)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
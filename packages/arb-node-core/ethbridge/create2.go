@@ -0,0 +1,118 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/transactauth"
+)
+
+// DeterministicDeploymentProxy is the widely deployed CREATE2 factory which
+// deploys the init code following a 32 byte salt in its calldata
+var DeterministicDeploymentProxy = ethcommon.HexToAddress("0x4e59b44847b379578588920ca78fbf26c0b4956c")
+
+// ContractInitCode returns the creation code for a contract from its
+// generated binding metadata and constructor arguments
+func ContractInitCode(metadata *bind.MetaData, params ...interface{}) ([]byte, error) {
+	parsed, err := metadata.GetAbi()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	args, err := parsed.Pack("", params...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return append(ethcommon.FromHex(metadata.Bin), args...), nil
+}
+
+// Create2Address precomputes the address a contract with the given init code
+// will be deployed at by the given factory
+func Create2Address(factory ethcommon.Address, salt [32]byte, initCode []byte) ethcommon.Address {
+	return crypto.CreateAddress2(factory, salt, crypto.Keccak256(initCode))
+}
+
+// DeployCreate2 deploys the given init code through a CREATE2 factory which
+// follows the deterministic deployment proxy calling convention. If the code is
+// already deployed at the expected address, no transaction is sent.
+func DeployCreate2(
+	ctx context.Context,
+	client ethutils.EthClient,
+	auth transactauth.TransactAuth,
+	factory ethcommon.Address,
+	salt [32]byte,
+	initCode []byte,
+) (ethcommon.Address, *arbtransaction.ArbTransaction, error) {
+	address := Create2Address(factory, salt, initCode)
+	code, err := client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return ethcommon.Address{}, nil, errors.WithStack(err)
+	}
+	if len(code) > 0 {
+		logger.Info().Str("address", address.Hex()).Msg("contract already deployed with CREATE2")
+		return address, nil, nil
+	}
+	factoryCode, err := client.CodeAt(ctx, factory, nil)
+	if err != nil {
+		return ethcommon.Address{}, nil, errors.WithStack(err)
+	}
+	if len(factoryCode) == 0 {
+		return ethcommon.Address{}, nil, errors.Errorf("no CREATE2 factory deployed at %v", factory.Hex())
+	}
+	data := append(salt[:], initCode...)
+	factoryCon := bind.NewBoundContract(factory, abi.ABI{}, client, client, client)
+	arbTx, err := transactauth.MakeTx(ctx, auth, func(auth *bind.TransactOpts) (*types.Transaction, error) {
+		return factoryCon.RawTransact(auth, data)
+	})
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+	return address, arbTx, nil
+}
+
+// VerifyCreate2Deployment checks that the code at address was deployed from
+// the given init code and salt
+func VerifyCreate2Deployment(
+	ctx context.Context,
+	client ethutils.EthClient,
+	factory ethcommon.Address,
+	salt [32]byte,
+	initCode []byte,
+	address ethcommon.Address,
+) error {
+	expected := Create2Address(factory, salt, initCode)
+	if expected != address {
+		return errors.Errorf("address %v doesn't match expected CREATE2 address %v", address.Hex(), expected.Hex())
+	}
+	code, err := client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(code) == 0 {
+		return errors.Errorf("no contract deployed at %v", address.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+func TestCreate2Address(t *testing.T) {
+	// Example 0 from EIP-1014
+	address := Create2Address(ethcommon.Address{}, [32]byte{}, []byte{0})
+	expected := ethcommon.HexToAddress("0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38")
+	if address != expected {
+		t.Errorf("expected %v, got %v", expected.Hex(), address.Hex())
+	}
+}
@@ -112,7 +112,7 @@ func (r *DelayedBridgeWatcher) LookupMessagesInRange(ctx context.Context, from,
 		Addresses: []ethcommon.Address{r.address},
 		Topics:    [][]ethcommon.Hash{{messageDeliveredID}},
 	}
-	logs, err := r.client.FilterLogs(ctx, query)
+	logs, err := FilterLogsWithSplitting(ctx, r.client, query)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -137,7 +137,7 @@ func (r *DelayedBridgeWatcher) LookupMessageBlock(ctx context.Context, messageNu
 		Addresses: []ethcommon.Address{r.address},
 		Topics:    [][]ethcommon.Hash{{messageDeliveredID}, {msgNumBytes}},
 	}
-	logs, err := r.client.FilterLogs(ctx, query)
+	logs, err := FilterLogsWithSplitting(ctx, r.client, query)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+// VerifyContractCode checks that a contract is deployed at address, and if
+// expectedCodeHash is nonzero, that its runtime code has that hash. Failing
+// here gives a much clearer error than misinterpreting calls and logs later.
+func VerifyContractCode(ctx context.Context, client ethutils.EthClient, name string, address ethcommon.Address, expectedCodeHash ethcommon.Hash) error {
+	code, err := client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get code of %v", name)
+	}
+	if len(code) == 0 {
+		return errors.Errorf("no %v contract deployed at %v, check the address and L1 chain", name, address.Hex())
+	}
+	if expectedCodeHash != (ethcommon.Hash{}) {
+		codeHash := crypto.Keccak256Hash(code)
+		if codeHash != expectedCodeHash {
+			return errors.Errorf("%v contract at %v has code hash %v but expected %v", name, address.Hex(), codeHash.Hex(), expectedCodeHash.Hex())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/monitor"
+)
+
+// EventSource describes the logs of interest from a single contract
+type EventSource struct {
+	Address ethcommon.Address
+	Topics  [][]ethcommon.Hash
+}
+
+// EventFetcher queries the logs of several contracts over the same block
+// range concurrently and merges them in the order they were emitted
+type EventFetcher struct {
+	backfiller *LogBackfiller
+}
+
+func NewEventFetcher(client ethutils.EthClient) *EventFetcher {
+	return &EventFetcher{
+		backfiller: NewLogBackfiller(client, defaultLogBackfillConcurrency),
+	}
+}
+
+func (f *EventFetcher) FetchLogs(ctx context.Context, from, to *big.Int, sources []EventSource) ([]types.Log, error) {
+	logsBySource, err := f.FetchLogsBySource(ctx, from, to, sources)
+	if err != nil {
+		return nil, err
+	}
+	var merged []types.Log
+	for _, logs := range logsBySource {
+		merged = append(merged, logs...)
+	}
+	sortLogs(merged)
+	return merged, nil
+}
+
+// FetchLogsBySource returns the logs of each source separately, each in the
+// order they were emitted
+func (f *EventFetcher) FetchLogsBySource(ctx context.Context, from, to *big.Int, sources []EventSource) ([][]types.Log, error) {
+	type result struct {
+		logs []types.Log
+		err  error
+	}
+	results := make([]chan result, len(sources))
+	for i, source := range sources {
+		results[i] = make(chan result, 1)
+		go func(source EventSource, resultChan chan result) {
+			logs, err := f.backfiller.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: from,
+				ToBlock:   to,
+				Addresses: []ethcommon.Address{source.Address},
+				Topics:    source.Topics,
+			})
+			resultChan <- result{logs, err}
+		}(source, results[i])
+	}
+
+	logsBySource := make([][]types.Log, len(sources))
+	var firstErr error
+	for i, resultChan := range results {
+		res := <-resultChan
+		if res.err != nil && firstErr == nil {
+			firstErr = errors.WithStack(res.err)
+		}
+		logsBySource[i] = res.logs
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return logsBySource, nil
+}
+
+// sortLogs orders logs by their position in the chain
+func sortLogs(logs []types.Log) {
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		if logs[i].TxIndex != logs[j].TxIndex {
+			return logs[i].TxIndex < logs[j].TxIndex
+		}
+		return logs[i].Index < logs[j].Index
+	})
+}
+
+// LookupInboxEventsInRange fetches the delayed messages and sequencer batches
+// in the given range in parallel
+func LookupInboxEventsInRange(
+	ctx context.Context,
+	delayedBridge *DelayedBridgeWatcher,
+	sequencerInbox *SequencerInboxWatcher,
+	from, to *big.Int,
+) ([]*DeliveredInboxMessage, []SequencerBatchRef, error) {
+	sources := []EventSource{
+		{Address: delayedBridge.address, Topics: [][]ethcommon.Hash{{messageDeliveredID}}},
+		{Address: sequencerInbox.address, Topics: [][]ethcommon.Hash{{sequencerBatchDeliveredID, sequencerBatchDeliveredFromOriginID, delayedInboxForcedID}}},
+	}
+	logsBySource, err := NewEventFetcher(delayedBridge.client).FetchLogsBySource(ctx, from, to, sources)
+	if err != nil {
+		return nil, nil, err
+	}
+	delayedLogs, sequencerLogs := logsBySource[0], logsBySource[1]
+	for _, logs := range logsBySource {
+		for _, evmLog := range logs {
+			monitor.GlobalMonitor.ReaderGotBatch(common.NewHashFromEth(evmLog.TxHash))
+		}
+	}
+	delayedMessages, err := delayedBridge.logsToDeliveredMessages(ctx, delayedLogs)
+	if err != nil {
+		return nil, nil, err
+	}
+	sequencerBatches, err := sequencerInbox.logsToBatchRefs(ctx, sequencerLogs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return delayedMessages, sequencerBatches, nil
+}
@@ -87,7 +87,7 @@ func (r *StandardInboxWatcher) fillMessageDetails(
 		Addresses: []ethcommon.Address{r.address},
 		Topics:    [][]ethcommon.Hash{{inboxMessageDeliveredID, inboxMessageFromOriginID}, msgQuery},
 	}
-	logs, err := r.client.FilterLogs(ctx, query)
+	logs, err := FilterLogsWithSplitting(ctx, r.client, query)
 	if err != nil {
 		return errors.WithStack(err)
 	}
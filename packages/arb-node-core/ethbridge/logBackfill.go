@@ -0,0 +1,155 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+const defaultLogBackfillConcurrency = 4
+
+// Substrings of the errors returned by common providers when a log query
+// covers too many blocks or would return too many results
+var logRangeErrors = []string{
+	"query returned more than",
+	"log response size exceeded",
+	"block range is too wide",
+	"exceed maximum block range",
+	"block range too large",
+	"response is too big",
+	"query timeout exceeded",
+}
+
+func isLogRangeError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, substr := range logRangeErrors {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// LogBackfiller queries logs over arbitrarily large block ranges. It first
+// attempts the whole range at once and recursively bisects any range which
+// the provider rejects as too large.
+type LogBackfiller struct {
+	client    ethutils.EthClient
+	semaphore chan struct{}
+}
+
+func NewLogBackfiller(client ethutils.EthClient, maxConcurrency int) *LogBackfiller {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	return &LogBackfiller{
+		client:    client,
+		semaphore: make(chan struct{}, maxConcurrency),
+	}
+}
+
+// FilterLogsWithSplitting is a drop in replacement for FilterLogs which splits
+// the query up if the provider rejects it
+func FilterLogsWithSplitting(ctx context.Context, client ethutils.EthClient, query ethereum.FilterQuery) ([]types.Log, error) {
+	return NewLogBackfiller(client, defaultLogBackfillConcurrency).FilterLogs(ctx, query)
+}
+
+func (b *LogBackfiller) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	logs, err := b.filterLogs(ctx, query)
+	if err == nil || query.BlockHash != nil || !isLogRangeError(err) {
+		return logs, err
+	}
+
+	// Only resolve the range bounds once we know we need to split it
+	from := query.FromBlock
+	if from == nil {
+		from = big.NewInt(0)
+	}
+	to := query.ToBlock
+	if to == nil {
+		latest, err := b.client.BlockInfoByNumber(ctx, nil)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		to = latest.Number.ToInt()
+	}
+	if from.Cmp(to) >= 0 {
+		return nil, err
+	}
+	logger.Debug().
+		Str("fromBlock", from.String()).
+		Str("toBlock", to.String()).
+		Msg("splitting log query after provider rejected range")
+	return b.split(ctx, query, from, to)
+}
+
+func (b *LogBackfiller) filterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	select {
+	case b.semaphore <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() {
+		<-b.semaphore
+	}()
+	return b.client.FilterLogs(ctx, query)
+}
+
+func (b *LogBackfiller) split(ctx context.Context, query ethereum.FilterQuery, from, to *big.Int) ([]types.Log, error) {
+	mid := new(big.Int).Add(from, to)
+	mid.Rsh(mid, 1)
+
+	type result struct {
+		logs []types.Log
+		err  error
+	}
+	secondChan := make(chan result, 1)
+	go func() {
+		logs, err := b.queryRange(ctx, query, new(big.Int).Add(mid, big.NewInt(1)), to)
+		secondChan <- result{logs, err}
+	}()
+	first, err := b.queryRange(ctx, query, from, mid)
+	second := <-secondChan
+	if err != nil {
+		return nil, err
+	}
+	if second.err != nil {
+		return nil, second.err
+	}
+	return append(first, second.logs...), nil
+}
+
+func (b *LogBackfiller) queryRange(ctx context.Context, query ethereum.FilterQuery, from, to *big.Int) ([]types.Log, error) {
+	query.FromBlock = from
+	query.ToBlock = to
+	logs, err := b.filterLogs(ctx, query)
+	if err == nil {
+		return logs, nil
+	}
+	if !isLogRangeError(err) || from.Cmp(to) >= 0 {
+		return nil, errors.WithStack(err)
+	}
+	return b.split(ctx, query, from, to)
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+// limitedLogClient has one log in every block and rejects queries which would
+// return more than maxResults logs
+type limitedLogClient struct {
+	ethutils.EthClient
+	latest     uint64
+	maxResults uint64
+}
+
+func (c *limitedLogClient) BlockInfoByNumber(_ context.Context, _ *big.Int) (*ethutils.BlockInfo, error) {
+	return &ethutils.BlockInfo{Number: (*hexutil.Big)(new(big.Int).SetUint64(c.latest))}, nil
+}
+
+func (c *limitedLogClient) FilterLogs(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	from := uint64(0)
+	if query.FromBlock != nil {
+		from = query.FromBlock.Uint64()
+	}
+	to := c.latest
+	if query.ToBlock != nil {
+		to = query.ToBlock.Uint64()
+	}
+	if to-from+1 > c.maxResults {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	var logs []types.Log
+	for i := from; i <= to; i++ {
+		logs = append(logs, types.Log{BlockNumber: i})
+	}
+	return logs, nil
+}
+
+func TestLogBackfillSplitting(t *testing.T) {
+	client := &limitedLogClient{latest: 1000, maxResults: 30}
+	logs, err := NewLogBackfiller(client, 2).FilterLogs(context.Background(), ethereum.FilterQuery{FromBlock: big.NewInt(10)})
+	test.FailIfError(t, err)
+	if len(logs) != 991 {
+		t.Fatalf("expected 991 logs, got %v", len(logs))
+	}
+	for i, log := range logs {
+		if log.BlockNumber != uint64(i+10) {
+			t.Fatalf("log %v out of order, got block %v", i, log.BlockNumber)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+// MetricsClient wraps an EthClient, recording the number of calls, latency
+// and failures of each L1 request type so that operators can see which
+// interactions dominate load and where errors cluster
+type MetricsClient struct {
+	client   ethutils.EthClient
+	registry metrics.Registry
+}
+
+func NewMetricsClient(client ethutils.EthClient, registry metrics.Registry) *MetricsClient {
+	return &MetricsClient{
+		client:   client,
+		registry: registry,
+	}
+}
+
+// errorClass buckets errors into a small set of labels suitable for metric names
+func errorClass(err error) string {
+	if IsTimeoutError(err) {
+		return "timeout"
+	}
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+	if isThrottledError(err) {
+		return "throttled"
+	}
+	if isServerError(err) {
+		return "server"
+	}
+	if _, found := ethutils.RevertReason(nil, err); found {
+		return "revert"
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return "rpc"
+	}
+	return "other"
+}
+
+func (m *MetricsClient) record(method string, start time.Time, err error) {
+	prefix := "arbitrum/ethbridge/rpc/" + method
+	metrics.GetOrRegisterTimer(prefix+"/latency", m.registry).UpdateSince(start)
+	if err != nil {
+		metrics.GetOrRegisterCounter(prefix+"/errors/"+errorClass(err), m.registry).Inc(1)
+	}
+}
+
+func (m *MetricsClient) CodeAt(ctx context.Context, contract ethcommon.Address, blockNumber *big.Int) ([]byte, error) {
+	start := time.Now()
+	res, err := m.client.CodeAt(ctx, contract, blockNumber)
+	m.record("eth_getCode", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	start := time.Now()
+	res, err := m.client.CallContract(ctx, call, blockNumber)
+	m.record("eth_call", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	start := time.Now()
+	res, err := m.client.HeaderByNumber(ctx, number)
+	m.record("eth_getBlockByNumber", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) PendingCodeAt(ctx context.Context, account ethcommon.Address) ([]byte, error) {
+	start := time.Now()
+	res, err := m.client.PendingCodeAt(ctx, account)
+	m.record("eth_getCode", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) PendingNonceAt(ctx context.Context, account ethcommon.Address) (uint64, error) {
+	start := time.Now()
+	res, err := m.client.PendingNonceAt(ctx, account)
+	m.record("eth_getTransactionCount", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	start := time.Now()
+	res, err := m.client.SuggestGasPrice(ctx)
+	m.record("eth_gasPrice", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	start := time.Now()
+	res, err := m.client.SuggestGasTipCap(ctx)
+	m.record("eth_maxPriorityFeePerGas", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	start := time.Now()
+	res, err := m.client.EstimateGas(ctx, call)
+	m.record("eth_estimateGas", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	start := time.Now()
+	res, err := m.client.FilterLogs(ctx, query)
+	m.record("eth_getLogs", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	start := time.Now()
+	res, err := m.client.SubscribeFilterLogs(ctx, query, ch)
+	m.record("eth_subscribe", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) TransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*types.Receipt, error) {
+	start := time.Now()
+	res, err := m.client.TransactionReceipt(ctx, txHash)
+	m.record("eth_getTransactionReceipt", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) NonceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (uint64, error) {
+	start := time.Now()
+	res, err := m.client.NonceAt(ctx, account, blockNumber)
+	m.record("eth_getTransactionCount", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) HeaderByHash(ctx context.Context, hash ethcommon.Hash) (*types.Header, error) {
+	start := time.Now()
+	res, err := m.client.HeaderByHash(ctx, hash)
+	m.record("eth_getBlockByHash", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) BlockByHash(ctx context.Context, hash ethcommon.Hash) (*types.Block, error) {
+	start := time.Now()
+	res, err := m.client.BlockByHash(ctx, hash)
+	m.record("eth_getBlockByHash", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) BlockInfoByNumber(ctx context.Context, number *big.Int) (*ethutils.BlockInfo, error) {
+	start := time.Now()
+	res, err := m.client.BlockInfoByNumber(ctx, number)
+	m.record("eth_getBlockByNumber", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) TransactionInBlock(ctx context.Context, blockHash ethcommon.Hash, index uint) (*types.Transaction, error) {
+	start := time.Now()
+	res, err := m.client.TransactionInBlock(ctx, blockHash, index)
+	m.record("eth_getTransactionByBlockHashAndIndex", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
+	start := time.Now()
+	res, err := m.client.PendingCallContract(ctx, call)
+	m.record("eth_call", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) BalanceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (*big.Int, error) {
+	start := time.Now()
+	res, err := m.client.BalanceAt(ctx, account, blockNumber)
+	m.record("eth_getBalance", start, err)
+	return res, err
+}
+
+func (m *MetricsClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	start := time.Now()
+	err := m.client.SendTransaction(ctx, tx)
+	m.record("eth_sendRawTransaction", start, err)
+	return err
+}
+
+func (m *MetricsClient) TransactionByHash(ctx context.Context, hash ethcommon.Hash) (*types.Transaction, bool, error) {
+	start := time.Now()
+	tx, isPending, err := m.client.TransactionByHash(ctx, hash)
+	m.record("eth_getTransactionByHash", start, err)
+	return tx, isPending, err
+}
+
+func (m *MetricsClient) TraceTransactionOutput(ctx context.Context, txHash ethcommon.Hash) ([]byte, error) {
+	tracer, ok := m.client.(ethutils.TransactionTracer)
+	if !ok {
+		return nil, errors.New("underlying client does not support tracing")
+	}
+	start := time.Now()
+	res, err := tracer.TraceTransactionOutput(ctx, txHash)
+	m.record("debug_traceTransaction", start, err)
+	return res, err
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+// Subset of the Multicall2 ABI, which is also supported by Multicall3
+const multicallABIJSON = `[{"inputs":[{"internalType":"bool","name":"requireSuccess","type":"bool"},{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall2.Call[]","name":"calls","type":"tuple[]"}],"name":"tryAggregate","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall2.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"nonpayable","type":"function"}]`
+
+const defaultMulticallBatchSize = 100
+
+var multicallABI abi.ABI
+
+func init() {
+	parsedMulticall, err := abi.JSON(strings.NewReader(multicallABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	multicallABI = parsedMulticall
+}
+
+type MulticallCall struct {
+	Target   ethcommon.Address
+	CallData []byte
+}
+
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// Multicaller batches multiple contract reads into a single eth_call against
+// a deployed Multicall2 compatible contract
+type Multicaller struct {
+	address      ethcommon.Address
+	client       ethutils.EthClient
+	baseCallOpts bind.CallOpts
+	batchSize    int
+}
+
+func NewMulticaller(address ethcommon.Address, client ethutils.EthClient, callOpts bind.CallOpts) *Multicaller {
+	return &Multicaller{
+		address:      address,
+		client:       client,
+		baseCallOpts: callOpts,
+		batchSize:    defaultMulticallBatchSize,
+	}
+}
+
+// Aggregate executes all of the given calls, splitting them into multiple
+// requests if there are more than the batch size. Results are returned in the
+// same order as the calls, and an error is returned if any call reverted.
+func (m *Multicaller) Aggregate(ctx context.Context, calls []MulticallCall) ([][]byte, error) {
+	results := make([][]byte, 0, len(calls))
+	for start := 0; start < len(calls); start += m.batchSize {
+		end := start + m.batchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+		batchResults, err := m.aggregateBatch(ctx, calls[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for i, res := range batchResults {
+			if !res.Success {
+				reason, found := ethutils.RevertReason(res.ReturnData, nil)
+				if found {
+					return nil, errors.Errorf("multicall item %v reverted: %v", start+i, reason)
+				}
+				return nil, errors.Errorf("multicall item %v reverted", start+i)
+			}
+			results = append(results, res.ReturnData)
+		}
+	}
+	return results, nil
+}
+
+func (m *Multicaller) aggregateBatch(ctx context.Context, calls []MulticallCall) ([]multicallResult, error) {
+	data, err := multicallABI.Pack("tryAggregate", false, calls)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	msg := ethereum.CallMsg{
+		From: m.baseCallOpts.From,
+		To:   &m.address,
+		Data: data,
+	}
+	output, err := m.client.CallContract(ctx, msg, callOptsWithContext(ctx, m.baseCallOpts).BlockNumber)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	out, err := multicallABI.Unpack("tryAggregate", output)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	results := *abi.ConvertType(out[0], new([]multicallResult)).(*[]multicallResult)
+	if len(results) != len(calls) {
+		return nil, errors.Errorf("multicall returned %v results for %v calls", len(results), len(calls))
+	}
+	return results, nil
+}
+
+// RollupNodeCounts is the position of the rollup's confirmed, unresolved and
+// created nodes, read together so they're consistent with each other
+type RollupNodeCounts struct {
+	LatestConfirmed     *big.Int
+	FirstUnresolvedNode *big.Int
+	LatestNodeCreated   *big.Int
+}
+
+var rollupNodeCountMethods = []string{
+	"latestConfirmed",
+	"firstUnresolvedNode",
+	"latestNodeCreated",
+}
+
+func (r *RollupWatcher) packCall(method string, params ...interface{}) (MulticallCall, error) {
+	data, err := rollupABI.Pack(method, params...)
+	if err != nil {
+		return MulticallCall{}, errors.WithStack(err)
+	}
+	return MulticallCall{Target: r.address, CallData: data}, nil
+}
+
+// NodeCounts fetches the rollup's node counters in one request
+func (r *RollupWatcher) NodeCounts(ctx context.Context, m *Multicaller) (*RollupNodeCounts, error) {
+	calls := make([]MulticallCall, 0, len(rollupNodeCountMethods))
+	for _, method := range rollupNodeCountMethods {
+		call, err := r.packCall(method)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	results, err := m.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]*big.Int, 0, len(results))
+	for i, res := range results {
+		out, err := rollupABI.Unpack(rollupNodeCountMethods[i], res)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		values = append(values, *abi.ConvertType(out[0], new(*big.Int)).(**big.Int))
+	}
+	return &RollupNodeCounts{
+		LatestConfirmed:     values[0],
+		FirstUnresolvedNode: values[1],
+		LatestNodeCreated:   values[2],
+	}, nil
+}
+
+// StakerInfos is a batched version of StakerInfo. Entries for addresses which
+// aren't staked are nil.
+func (r *RollupWatcher) StakerInfos(ctx context.Context, m *Multicaller, stakers []common.Address) ([]*StakerInfo, error) {
+	calls := make([]MulticallCall, 0, len(stakers))
+	for _, staker := range stakers {
+		call, err := r.packCall("_stakerMap", staker.ToEthAddress())
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	results, err := m.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]*StakerInfo, 0, len(results))
+	for _, res := range results {
+		out, err := rollupABI.Unpack("_stakerMap", res)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		isStaked := *abi.ConvertType(out[4], new(bool)).(*bool)
+		if !isStaked {
+			infos = append(infos, nil)
+			continue
+		}
+		info := &StakerInfo{
+			Index:            *abi.ConvertType(out[0], new(*big.Int)).(**big.Int),
+			LatestStakedNode: *abi.ConvertType(out[1], new(*big.Int)).(**big.Int),
+			AmountStaked:     *abi.ConvertType(out[2], new(*big.Int)).(**big.Int),
+		}
+		currentChallenge := *abi.ConvertType(out[3], new(ethcommon.Address)).(*ethcommon.Address)
+		if currentChallenge != (ethcommon.Address{}) {
+			chal := common.NewAddressFromEth(currentChallenge)
+			info.CurrentChallenge = &chal
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
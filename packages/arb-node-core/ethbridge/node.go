@@ -46,9 +46,7 @@ func NewNodeWatcher(address ethcommon.Address, client ethutils.EthClient, callOp
 }
 
 func (n *NodeWatcher) getCallOpts(ctx context.Context) *bind.CallOpts {
-	opts := n.baseCallOpts
-	opts.Context = ctx
-	return &opts
+	return callOptsWithContext(ctx, n.baseCallOpts)
 }
 
 func (n *NodeWatcher) Prev(ctx context.Context) (*big.Int, error) {
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+type privateSubmissionKey struct{}
+
+// WithPrivateSubmission marks transactions sent with the returned context as
+// front-runnable, so they are sent through the private relay if one is
+// configured
+func WithPrivateSubmission(ctx context.Context) context.Context {
+	return context.WithValue(ctx, privateSubmissionKey{}, true)
+}
+
+func privateSubmissionFromContext(ctx context.Context) bool {
+	private, _ := ctx.Value(privateSubmissionKey{}).(bool)
+	return private
+}
+
+// PrivateRelay submits transactions marked with WithPrivateSubmission to a
+// Flashbots style relay instead of the public mempool. If a privately submitted
+// transaction hasn't been mined after the fallback delay, it is broadcast
+// publicly as well.
+type PrivateRelay struct {
+	client        ethutils.EthClient
+	httpClient    *http.Client
+	url           string
+	signingKey    *ecdsa.PrivateKey
+	fallbackDelay time.Duration
+}
+
+func NewPrivateRelay(client ethutils.EthClient, config configuration.PrivateRelay) (*PrivateRelay, error) {
+	var signingKey *ecdsa.PrivateKey
+	var err error
+	if config.SigningKey != "" {
+		signingKey, err = crypto.HexToECDSA(config.SigningKey)
+	} else {
+		// The relay only uses this key to track reputation, so a random one works
+		signingKey, err = crypto.GenerateKey()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid private relay signing key")
+	}
+	return &PrivateRelay{
+		client:        client,
+		httpClient:    &http.Client{Timeout: 30 * time.Second},
+		url:           config.URL,
+		signingKey:    signingKey,
+		fallbackDelay: config.FallbackDelay,
+	}, nil
+}
+
+func (r *PrivateRelay) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if !privateSubmissionFromContext(ctx) {
+		return r.client.SendTransaction(ctx, tx)
+	}
+	if err := r.sendPrivateTransaction(ctx, tx); err != nil {
+		logger.Warn().Err(err).Hex("tx", tx.Hash().Bytes()).Msg("private relay submission failed, sending publicly")
+		return r.client.SendTransaction(ctx, tx)
+	}
+	logger.Info().Hex("tx", tx.Hash().Bytes()).Msg("submitted transaction to private relay")
+	go r.fallbackToPublic(ctx, tx)
+	return nil
+}
+
+type relayRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type relayResponse struct {
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (r *PrivateRelay) sendPrivateTransaction(ctx context.Context, tx *types.Transaction) error {
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	body, err := json.Marshal(relayRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "eth_sendPrivateTransaction",
+		Params: []interface{}{map[string]interface{}{
+			"tx": hexutil.Encode(rawTx),
+		}},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	signature, err := crypto.Sign(accounts.TextHash([]byte(hexutil.Encode(crypto.Keccak256(body)))), r.signingKey)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Flashbots-Signature", crypto.PubkeyToAddress(r.signingKey.PublicKey).Hex()+":"+hexutil.Encode(signature))
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("private relay returned status %v: %v", resp.StatusCode, string(respBody))
+	}
+	var result relayResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return errors.Wrap(err, "failed to parse private relay response")
+	}
+	if result.Error != nil {
+		return errors.Errorf("private relay error %v: %v", result.Error.Code, result.Error.Message)
+	}
+	return nil
+}
+
+// fallbackToPublic broadcasts tx publicly unless it or another transaction
+// with its nonce was mined within the fallback delay. It gives up if ctx is
+// cancelled first.
+func (r *PrivateRelay) fallbackToPublic(ctx context.Context, tx *types.Transaction) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(r.fallbackDelay):
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	receipt, err := r.client.TransactionReceipt(ctx, tx.Hash())
+	if err == nil && receipt != nil {
+		return
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		logger.Warn().Err(err).Hex("tx", tx.Hash().Bytes()).Msg("failed to recover sender of private transaction")
+		return
+	}
+	nonce, err := r.client.NonceAt(ctx, from, nil)
+	if err != nil {
+		logger.Warn().Err(err).Hex("tx", tx.Hash().Bytes()).Msg("failed to check nonce of private transaction")
+		return
+	}
+	if nonce > tx.Nonce() {
+		return
+	}
+	logger.Warn().Hex("tx", tx.Hash().Bytes()).Dur("delay", r.fallbackDelay).Msg("private transaction not mined, sending publicly")
+	if err := r.client.SendTransaction(ctx, tx); err != nil {
+		logger.Warn().Err(err).Hex("tx", tx.Hash().Bytes()).Msg("failed to send private transaction publicly")
+	}
+}
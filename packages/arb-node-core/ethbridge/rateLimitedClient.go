@@ -0,0 +1,407 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+// JSON-RPC error code used by several providers when a request is rate limited
+const rpcLimitExceededCode = -32005
+
+// ErrL1Timeout is returned when a single L1 request exceeds the configured call
+// timeout, as opposed to the caller's context expiring
+var ErrL1Timeout = errors.New("L1 request timed out")
+
+func IsTimeoutError(err error) bool {
+	return errors.Is(err, ErrL1Timeout) || errors.Is(err, context.DeadlineExceeded)
+}
+
+type tokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mutex.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mutex.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// RateLimitedClient wraps an EthClient, limiting the rate of requests made to
+// the L1 node and retrying requests which were throttled or hit a server error
+// with jittered exponential backoff
+type RateLimitedClient struct {
+	client         ethutils.EthClient
+	limiter        *tokenBucket
+	methodLimiters map[string]*tokenBucket
+	config         configuration.L1RateLimit
+	callTimeout    time.Duration
+}
+
+// NewRateLimitedClient wraps client with the given rate limits. If callTimeout
+// is nonzero, each individual request is abandoned after that long.
+func NewRateLimitedClient(client ethutils.EthClient, config configuration.L1RateLimit, callTimeout time.Duration) (*RateLimitedClient, error) {
+	r := &RateLimitedClient{
+		client:         client,
+		methodLimiters: make(map[string]*tokenBucket),
+		config:         config,
+		callTimeout:    callTimeout,
+	}
+	if config.RequestsPerSecond > 0 {
+		r.limiter = newTokenBucket(config.RequestsPerSecond, config.Burst)
+	}
+	for _, budget := range config.MethodBudgets {
+		parts := strings.SplitN(budget, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid method budget %v, expected method=requests-per-second", budget)
+		}
+		rate, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || rate <= 0 {
+			return nil, errors.Errorf("invalid requests per second in method budget %v", budget)
+		}
+		r.methodLimiters[parts[0]] = newTokenBucket(rate, config.Burst)
+	}
+	return r, nil
+}
+
+func isThrottledError(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.ErrorCode() == rpcLimitExceededCode
+	}
+	return false
+}
+
+func isServerError(err error) bool {
+	var httpErr rpc.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode >= 500
+}
+
+func (r *RateLimitedClient) acquire(ctx context.Context, method string) error {
+	if r.limiter != nil {
+		if err := r.limiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+	if limiter, ok := r.methodLimiters[method]; ok {
+		if err := limiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RateLimitedClient) backoff(attempt int) time.Duration {
+	delay := r.config.InitialBackoff << uint(attempt)
+	if delay <= 0 || (r.config.MaxBackoff > 0 && delay > r.config.MaxBackoff) {
+		delay = r.config.MaxBackoff
+	}
+	// Full jitter so that many requests throttled together don't retry together
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// attempt runs a single try of f, applying the call timeout if one is set
+func (r *RateLimitedClient) attempt(ctx context.Context, method string, f func(ctx context.Context) error) error {
+	if r.callTimeout == 0 {
+		return f(ctx)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, r.callTimeout)
+	defer cancel()
+	err := f(callCtx)
+	if err != nil && ctx.Err() == nil && callCtx.Err() == context.DeadlineExceeded {
+		logger.Warn().
+			Str("method", method).
+			Dur("timeout", r.callTimeout).
+			Msg("L1 request timed out")
+		return errors.Wrapf(ErrL1Timeout, "%v after %v", method, r.callTimeout)
+	}
+	return err
+}
+
+// call runs f under the rate limits for method. Requests which are not
+// idempotent are only retried if the node explicitly rejected them for
+// exceeding a rate limit.
+func (r *RateLimitedClient) call(ctx context.Context, method string, idempotent bool, f func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		if err := r.acquire(ctx, method); err != nil {
+			return err
+		}
+		err := r.attempt(ctx, method, f)
+		if err == nil || attempt >= r.config.MaxRetries {
+			return err
+		}
+		retryable := isServerError(err) || errors.Is(err, ErrL1Timeout)
+		if !isThrottledError(err) && (!idempotent || !retryable) {
+			return err
+		}
+		delay := r.backoff(attempt)
+		logger.Debug().
+			Err(err).
+			Str("method", method).
+			Int("attempt", attempt+1).
+			Dur("delay", delay).
+			Msg("retrying L1 request")
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (r *RateLimitedClient) CodeAt(ctx context.Context, contract ethcommon.Address, blockNumber *big.Int) ([]byte, error) {
+	var res []byte
+	err := r.call(ctx, "eth_getCode", true, func(ctx context.Context) (err error) {
+		res, err = r.client.CodeAt(ctx, contract, blockNumber)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var res []byte
+	err := r.call(ctx, "eth_call", true, func(ctx context.Context) (err error) {
+		res, err = r.client.CallContract(ctx, call, blockNumber)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var res *types.Header
+	err := r.call(ctx, "eth_getBlockByNumber", true, func(ctx context.Context) (err error) {
+		res, err = r.client.HeaderByNumber(ctx, number)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) PendingCodeAt(ctx context.Context, account ethcommon.Address) ([]byte, error) {
+	var res []byte
+	err := r.call(ctx, "eth_getCode", true, func(ctx context.Context) (err error) {
+		res, err = r.client.PendingCodeAt(ctx, account)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) PendingNonceAt(ctx context.Context, account ethcommon.Address) (uint64, error) {
+	var res uint64
+	err := r.call(ctx, "eth_getTransactionCount", true, func(ctx context.Context) (err error) {
+		res, err = r.client.PendingNonceAt(ctx, account)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	var res *big.Int
+	err := r.call(ctx, "eth_gasPrice", true, func(ctx context.Context) (err error) {
+		res, err = r.client.SuggestGasPrice(ctx)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	var res *big.Int
+	err := r.call(ctx, "eth_maxPriorityFeePerGas", true, func(ctx context.Context) (err error) {
+		res, err = r.client.SuggestGasTipCap(ctx)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	var res uint64
+	err := r.call(ctx, "eth_estimateGas", true, func(ctx context.Context) (err error) {
+		res, err = r.client.EstimateGas(ctx, call)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return r.call(ctx, "eth_sendRawTransaction", false, func(ctx context.Context) error {
+		return r.client.SendTransaction(ctx, tx)
+	})
+}
+
+func (r *RateLimitedClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	var res []types.Log
+	err := r.call(ctx, "eth_getLogs", true, func(ctx context.Context) (err error) {
+		res, err = r.client.FilterLogs(ctx, query)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if err := r.acquire(ctx, "eth_subscribe"); err != nil {
+		return nil, err
+	}
+	return r.client.SubscribeFilterLogs(ctx, query, ch)
+}
+
+func (r *RateLimitedClient) TransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*types.Receipt, error) {
+	var res *types.Receipt
+	err := r.call(ctx, "eth_getTransactionReceipt", true, func(ctx context.Context) (err error) {
+		res, err = r.client.TransactionReceipt(ctx, txHash)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) NonceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (uint64, error) {
+	var res uint64
+	err := r.call(ctx, "eth_getTransactionCount", true, func(ctx context.Context) (err error) {
+		res, err = r.client.NonceAt(ctx, account, blockNumber)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) HeaderByHash(ctx context.Context, hash ethcommon.Hash) (*types.Header, error) {
+	var res *types.Header
+	err := r.call(ctx, "eth_getBlockByHash", true, func(ctx context.Context) (err error) {
+		res, err = r.client.HeaderByHash(ctx, hash)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) BlockByHash(ctx context.Context, hash ethcommon.Hash) (*types.Block, error) {
+	var res *types.Block
+	err := r.call(ctx, "eth_getBlockByHash", true, func(ctx context.Context) (err error) {
+		res, err = r.client.BlockByHash(ctx, hash)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) BlockInfoByNumber(ctx context.Context, number *big.Int) (*ethutils.BlockInfo, error) {
+	var res *ethutils.BlockInfo
+	err := r.call(ctx, "eth_getBlockByNumber", true, func(ctx context.Context) (err error) {
+		res, err = r.client.BlockInfoByNumber(ctx, number)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) TransactionByHash(ctx context.Context, hash ethcommon.Hash) (*types.Transaction, bool, error) {
+	var res *types.Transaction
+	var isPending bool
+	err := r.call(ctx, "eth_getTransactionByHash", true, func(ctx context.Context) (err error) {
+		res, isPending, err = r.client.TransactionByHash(ctx, hash)
+		return
+	})
+	return res, isPending, err
+}
+
+func (r *RateLimitedClient) TransactionInBlock(ctx context.Context, blockHash ethcommon.Hash, index uint) (*types.Transaction, error) {
+	var res *types.Transaction
+	err := r.call(ctx, "eth_getTransactionByBlockHashAndIndex", true, func(ctx context.Context) (err error) {
+		res, err = r.client.TransactionInBlock(ctx, blockHash, index)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
+	var res []byte
+	err := r.call(ctx, "eth_call", true, func(ctx context.Context) (err error) {
+		res, err = r.client.PendingCallContract(ctx, call)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) BalanceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (*big.Int, error) {
+	var res *big.Int
+	err := r.call(ctx, "eth_getBalance", true, func(ctx context.Context) (err error) {
+		res, err = r.client.BalanceAt(ctx, account, blockNumber)
+		return
+	})
+	return res, err
+}
+
+func (r *RateLimitedClient) TraceTransactionOutput(ctx context.Context, txHash ethcommon.Hash) ([]byte, error) {
+	tracer, ok := r.client.(ethutils.TransactionTracer)
+	if !ok {
+		return nil, errors.New("underlying client does not support tracing")
+	}
+	var res []byte
+	err := r.call(ctx, "debug_traceTransaction", true, func(ctx context.Context) (err error) {
+		res, err = tracer.TraceTransactionOutput(ctx, txHash)
+		return
+	})
+	return res, err
+}
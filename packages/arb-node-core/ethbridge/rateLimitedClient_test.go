@@ -0,0 +1,93 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestRateLimitedClientRetries(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewRateLimitedClient(nil, configuration.L1RateLimit{
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}, 0)
+	test.FailIfError(t, err)
+
+	attempts := 0
+	err = client.call(ctx, "eth_call", true, func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return rpc.HTTPError{StatusCode: 503}
+		}
+		return nil
+	})
+	test.FailIfError(t, err)
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %v", attempts)
+	}
+
+	attempts = 0
+	err = client.call(ctx, "eth_sendRawTransaction", false, func(context.Context) error {
+		attempts++
+		return rpc.HTTPError{StatusCode: 503}
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("non idempotent request retried after server error, attempts %v", attempts)
+	}
+
+	attempts = 0
+	err = client.call(ctx, "eth_sendRawTransaction", false, func(context.Context) error {
+		attempts++
+		return rpc.HTTPError{StatusCode: 429}
+	})
+	if err == nil || attempts != 4 {
+		t.Errorf("expected throttled request to be attempted 4 times, got %v", attempts)
+	}
+}
+
+func TestRateLimitedClientTimeout(t *testing.T) {
+	client, err := NewRateLimitedClient(nil, configuration.L1RateLimit{}, time.Millisecond)
+	test.FailIfError(t, err)
+	err = client.call(context.Background(), "eth_call", true, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, ErrL1Timeout) {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestRateLimitedClientMethodBudgets(t *testing.T) {
+	if _, err := NewRateLimitedClient(nil, configuration.L1RateLimit{MethodBudgets: []string{"eth_getLogs"}}, 0); err == nil {
+		t.Error("expected error for budget without rate")
+	}
+	client, err := NewRateLimitedClient(nil, configuration.L1RateLimit{MethodBudgets: []string{"eth_getLogs=1000"}}, 0)
+	test.FailIfError(t, err)
+	if _, ok := client.methodLimiters["eth_getLogs"]; !ok {
+		t.Error("missing eth_getLogs limiter")
+	}
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+// ReorgHandler is called with the most recent block which is still part of
+// the canonical chain. Handlers are called from the detector's thread and
+// must not block.
+type ReorgHandler func(ctx context.Context, forkPoint *common.BlockId)
+
+// ReorgDetector remembers the hashes of the most recent L1 blocks and notifies
+// registered handlers when one of them is no longer canonical
+type ReorgDetector struct {
+	client   ethutils.EthClient
+	maxDepth int
+
+	// Only in check thread
+	blocks []*common.BlockId
+	// The parent hash of blocks[0], which is where a reorg removing every
+	// tracked block forked
+	oldestParent common.Hash
+
+	handlersMutex sync.Mutex
+	handlers      []ReorgHandler
+}
+
+func NewReorgDetector(client ethutils.EthClient, maxDepth int) *ReorgDetector {
+	return &ReorgDetector{
+		client:   client,
+		maxDepth: maxDepth,
+	}
+}
+
+func (r *ReorgDetector) OnReorg(handler ReorgHandler) {
+	r.handlersMutex.Lock()
+	defer r.handlersMutex.Unlock()
+	r.handlers = append(r.handlers, handler)
+}
+
+func (r *ReorgDetector) RunInBackground(ctx context.Context, interval time.Duration) chan bool {
+	done := make(chan bool)
+	go func() {
+		defer func() {
+			done <- true
+		}()
+		for {
+			if err := r.Check(ctx); err != nil {
+				logger.Warn().Err(err).Msg("error checking for L1 reorg")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+	return done
+}
+
+func (r *ReorgDetector) blockID(ctx context.Context, number *big.Int) (*common.BlockId, common.Hash, error) {
+	info, err := r.client.BlockInfoByNumber(ctx, number)
+	if err != nil {
+		return nil, common.Hash{}, errors.WithStack(err)
+	}
+	if info == nil {
+		return nil, common.Hash{}, errors.WithStack(ethereum.NotFound)
+	}
+	return &common.BlockId{
+		Height:     common.NewTimeBlocks(info.Number.ToInt()),
+		HeaderHash: common.NewHashFromEth(info.Hash),
+	}, common.NewHashFromEth(info.ParentHash), nil
+}
+
+// Check compares the tracked blocks against the canonical chain, notifying
+// handlers of any reorg, and then extends the tracked blocks to the latest
+// block
+func (r *ReorgDetector) Check(ctx context.Context) error {
+	latest, _, err := r.blockID(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if len(r.blocks) > 0 {
+		forkIndex, err := r.findForkIndex(ctx)
+		if err != nil {
+			return err
+		}
+		if forkIndex != len(r.blocks)-1 {
+			depth := len(r.blocks) - 1 - forkIndex
+			var forkPoint *common.BlockId
+			if forkIndex < 0 {
+				// We can't tell where the chain forked, so assume it was
+				// just before the oldest block we know of
+				oldest := r.blocks[0]
+				forkPoint = &common.BlockId{
+					Height:     common.NewTimeBlocks(new(big.Int).Sub(oldest.Height.AsInt(), big.NewInt(1))),
+					HeaderHash: r.oldestParent,
+				}
+				logger.Error().
+					Int("depth", depth).
+					Str("forkBlock", forkPoint.Height.String()).
+					Msg("L1 reorg deeper than tracked blocks")
+				r.blocks = nil
+			} else {
+				forkPoint = r.blocks[forkIndex]
+				logger.Warn().
+					Int("depth", depth).
+					Str("forkBlock", forkPoint.Height.String()).
+					Str("forkHash", forkPoint.HeaderHash.String()).
+					Msg("detected L1 reorg")
+				r.blocks = r.blocks[:forkIndex+1]
+			}
+			r.notify(ctx, forkPoint)
+		}
+	}
+	return r.extend(ctx, latest)
+}
+
+// findForkIndex returns the index of the newest tracked block which is still
+// canonical, or -1 if none of them are. Tracked blocks above the head of a
+// shorter new chain aren't found, and so aren't canonical.
+func (r *ReorgDetector) findForkIndex(ctx context.Context) (int, error) {
+	for i := len(r.blocks) - 1; i >= 0; i-- {
+		canonical, _, err := r.blockID(ctx, r.blocks[i].Height.AsInt())
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if canonical.HeaderHash == r.blocks[i].HeaderHash {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+func (r *ReorgDetector) extend(ctx context.Context, latest *common.BlockId) error {
+	next := new(big.Int).Sub(latest.Height.AsInt(), big.NewInt(int64(r.maxDepth-1)))
+	if len(r.blocks) > 0 {
+		afterTracked := new(big.Int).Add(r.blocks[len(r.blocks)-1].Height.AsInt(), big.NewInt(1))
+		if afterTracked.Cmp(next) > 0 {
+			next = afterTracked
+		} else {
+			// We fell too far behind to link the new blocks to the old ones
+			r.blocks = nil
+		}
+	}
+	if next.Sign() < 0 {
+		next.SetInt64(0)
+	}
+	for ; next.Cmp(latest.Height.AsInt()) <= 0; next.Add(next, big.NewInt(1)) {
+		block, parentHash, err := r.blockID(ctx, next)
+		if err != nil {
+			return err
+		}
+		if len(r.blocks) > 0 && r.blocks[len(r.blocks)-1].HeaderHash != parentHash {
+			// The chain changed while we were reading it, try again on the next check
+			return nil
+		}
+		if len(r.blocks) == 0 {
+			r.oldestParent = parentHash
+		}
+		r.blocks = append(r.blocks, block)
+	}
+	if len(r.blocks) > r.maxDepth {
+		dropped := len(r.blocks) - r.maxDepth
+		r.oldestParent = r.blocks[dropped-1].HeaderHash
+		r.blocks = r.blocks[dropped:]
+	}
+	return nil
+}
+
+func (r *ReorgDetector) notify(ctx context.Context, forkPoint *common.BlockId) {
+	r.handlersMutex.Lock()
+	handlers := append([]ReorgHandler{}, r.handlers...)
+	r.handlersMutex.Unlock()
+	for _, handler := range handlers {
+		handler(ctx, forkPoint)
+	}
+}
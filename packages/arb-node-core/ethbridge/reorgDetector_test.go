@@ -0,0 +1,113 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+// fakeChain serves the block hashes of a chain, where the hash of each
+// block is derived from its number and the fork it's on
+type fakeChain struct {
+	ethutils.EthClient
+	forks []byte
+}
+
+func fakeBlockHash(height int, fork byte) ethcommon.Hash {
+	return ethcommon.Hash{fork, byte(height >> 8), byte(height)}
+}
+
+// reorg replaces every block from height on, and changes the chain's length
+// to length
+func (c *fakeChain) reorg(height int, length int) {
+	forks := make([]byte, length)
+	copy(forks, c.forks)
+	for i := height; i < length; i++ {
+		var fork byte
+		if i < len(c.forks) {
+			fork = c.forks[i]
+		}
+		forks[i] = fork + 1
+	}
+	c.forks = forks
+}
+
+func (c *fakeChain) BlockInfoByNumber(_ context.Context, number *big.Int) (*ethutils.BlockInfo, error) {
+	height := len(c.forks) - 1
+	if number != nil {
+		height = int(number.Int64())
+	}
+	if height >= len(c.forks) {
+		return nil, ethereum.NotFound
+	}
+	info := &ethutils.BlockInfo{
+		Hash:   fakeBlockHash(height, c.forks[height]),
+		Number: (*hexutil.Big)(big.NewInt(int64(height))),
+	}
+	if height > 0 {
+		info.ParentHash = fakeBlockHash(height-1, c.forks[height-1])
+	}
+	return info, nil
+}
+
+func TestReorgDetector(t *testing.T) {
+	ctx := context.Background()
+	chain := &fakeChain{forks: make([]byte, 20)}
+	detector := NewReorgDetector(chain, 5)
+	var forkPoints []*common.BlockId
+	detector.OnReorg(func(_ context.Context, forkPoint *common.BlockId) {
+		forkPoints = append(forkPoints, forkPoint)
+	})
+	test.FailIfError(t, detector.Check(ctx))
+
+	checkForkPoint := func(name string, height int64, hash ethcommon.Hash) {
+		t.Helper()
+		if len(forkPoints) != 1 {
+			t.Fatalf("%v: expected a reorg notification, got %v", name, len(forkPoints))
+		}
+		if forkPoints[0].Height.AsInt().Int64() != height || forkPoints[0].HeaderHash.ToEthHash() != hash {
+			t.Errorf("%v: expected fork point %v %v, got %v %v", name, height, hash, forkPoints[0].Height, forkPoints[0].HeaderHash)
+		}
+		forkPoints = nil
+	}
+
+	// A new, shorter chain replacing the newest blocks
+	chain.reorg(18, 19)
+	test.FailIfError(t, detector.Check(ctx))
+	checkForkPoint("shorter chain", 17, fakeBlockHash(17, 0))
+
+	// A reorg of every tracked block forks before the oldest one
+	chain.reorg(10, 19)
+	test.FailIfError(t, detector.Check(ctx))
+	checkForkPoint("deep reorg", 14, fakeBlockHash(14, 0))
+
+	// The detector tracks the new chain afterwards
+	test.FailIfError(t, detector.Check(ctx))
+	if len(forkPoints) != 0 {
+		t.Errorf("unexpected reorg notification at fork point %v", forkPoints[0].Height)
+	}
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
 )
 
+var rollupABI abi.ABI
 var rollupCreatedID ethcommon.Hash
 var nodeCreatedID ethcommon.Hash
 var challengeCreatedID ethcommon.Hash
@@ -47,6 +48,7 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	rollupABI = parsedRollup
 	rollupCreatedID = parsedRollup.Events["RollupCreated"].ID
 	nodeCreatedID = parsedRollup.Events["NodeCreated"].ID
 	challengeCreatedID = parsedRollup.Events["RollupChallengeStarted"].ID
@@ -103,9 +105,7 @@ func NewRollupWatcher(address ethcommon.Address, fromBlock int64, client ethutil
 }
 
 func (r *RollupWatcher) getCallOpts(ctx context.Context) *bind.CallOpts {
-	opts := r.baseCallOpts
-	opts.Context = ctx
-	return &opts
+	return callOptsWithContext(ctx, r.baseCallOpts)
 }
 
 func (r *RollupWatcher) LookupCreation(ctx context.Context) (*ethbridgecontracts.RollupUserFacetRollupCreated, error) {
@@ -116,7 +116,7 @@ func (r *RollupWatcher) LookupCreation(ctx context.Context) (*ethbridgecontracts
 		Addresses: []ethcommon.Address{r.address},
 		Topics:    [][]ethcommon.Hash{{rollupCreatedID}},
 	}
-	logs, err := r.client.FilterLogs(ctx, query)
+	logs, err := FilterLogsWithSplitting(ctx, r.client, query)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -130,6 +130,22 @@ func (r *RollupWatcher) LookupCreation(ctx context.Context) (*ethbridgecontracts
 	return ev, errors.WithStack(err)
 }
 
+// HasActivity returns whether the rollup emitted any events, such as nodes
+// being created, confirmed or staked on, between fromBlock and toBlock inclusive
+func (r *RollupWatcher) HasActivity(ctx context.Context, fromBlock, toBlock *big.Int) (bool, error) {
+	var query = ethereum.FilterQuery{
+		BlockHash: nil,
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Addresses: []ethcommon.Address{r.address},
+	}
+	logs, err := FilterLogsWithSplitting(ctx, r.client, query)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return len(logs) > 0, nil
+}
+
 func (r *RollupWatcher) LookupNode(ctx context.Context, number *big.Int) (*core.NodeInfo, error) {
 	var numberAsHash ethcommon.Hash
 	copy(numberAsHash[:], math.U256Bytes(number))
@@ -140,7 +156,7 @@ func (r *RollupWatcher) LookupNode(ctx context.Context, number *big.Int) (*core.
 		Addresses: []ethcommon.Address{r.address},
 		Topics:    [][]ethcommon.Hash{{nodeCreatedID}, {numberAsHash}},
 	}
-	logs, err := r.client.FilterLogs(ctx, query)
+	logs, err := FilterLogsWithSplitting(ctx, r.client, query)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -178,7 +194,7 @@ func (r *RollupWatcher) LookupNodeChildren(ctx context.Context, parentHash [32]b
 		Addresses: []ethcommon.Address{r.address},
 		Topics:    [][]ethcommon.Hash{{nodeCreatedID}, nil, {parentHash}},
 	}
-	logs, err := r.client.FilterLogs(ctx, query)
+	logs, err := FilterLogsWithSplitting(ctx, r.client, query)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -211,6 +227,49 @@ func (r *RollupWatcher) LookupNodeChildren(ctx context.Context, parentHash [32]b
 	return infos, nil
 }
 
+// LookupNodesCreated returns every node created from the rollup's creation up
+// to toBlock inclusive, in creation order
+func (r *RollupWatcher) LookupNodesCreated(ctx context.Context, toBlock *big.Int) ([]*core.NodeInfo, error) {
+	return r.LookupNodesCreatedInRange(ctx, big.NewInt(r.fromBlock), toBlock)
+}
+
+// LookupNodesCreatedInRange returns every node created from fromBlock to
+// toBlock inclusive, in creation order. A nil toBlock means the latest block.
+func (r *RollupWatcher) LookupNodesCreatedInRange(ctx context.Context, fromBlock, toBlock *big.Int) ([]*core.NodeInfo, error) {
+	var query = ethereum.FilterQuery{
+		BlockHash: nil,
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Addresses: []ethcommon.Address{r.address},
+		Topics:    [][]ethcommon.Hash{{nodeCreatedID}},
+	}
+	logs, err := FilterLogsWithSplitting(ctx, r.client, query)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	infos := make([]*core.NodeInfo, 0, len(logs))
+	for _, ethLog := range logs {
+		parsedLog, err := r.con.ParseNodeCreated(ethLog)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		proposed := &common.BlockId{
+			Height:     common.NewTimeBlocks(new(big.Int).SetUint64(ethLog.BlockNumber)),
+			HeaderHash: common.NewHashFromEth(ethLog.BlockHash),
+		}
+		infos = append(infos, &core.NodeInfo{
+			NodeNum:                 parsedLog.NodeNum,
+			BlockProposed:           proposed,
+			Assertion:               core.NewAssertionFromFields(parsedLog.AssertionBytes32Fields, parsedLog.AssertionIntFields),
+			InboxMaxCount:           parsedLog.InboxMaxCount,
+			AfterInboxBatchEndCount: parsedLog.AfterInboxBatchEndCount,
+			AfterInboxBatchAcc:      parsedLog.AfterInboxBatchAcc,
+			NodeHash:                parsedLog.NodeHash,
+		})
+	}
+	return infos, nil
+}
+
 func (r *RollupWatcher) LookupChallengedNode(ctx context.Context, address common.Address) (*big.Int, error) {
 	addressQuery := ethcommon.Hash{}
 	copy(addressQuery[12:], address.Bytes())
@@ -222,7 +281,7 @@ func (r *RollupWatcher) LookupChallengedNode(ctx context.Context, address common
 		Addresses: []ethcommon.Address{r.address},
 		Topics:    [][]ethcommon.Hash{{challengeCreatedID}, {addressQuery}},
 	}
-	logs, err := r.client.FilterLogs(ctx, query)
+	logs, err := FilterLogsWithSplitting(ctx, r.client, query)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -306,6 +365,14 @@ func (r *RollupWatcher) ArbGasSpeedLimitPerBlock(ctx context.Context) (*big.Int,
 	return speed, errors.WithStack(err)
 }
 
+// ChallengeExecutionBisectionDegree returns the number of segments an
+// execution challenge bisection must split a segment into, unless the
+// segment is shorter
+func (r *RollupWatcher) ChallengeExecutionBisectionDegree(ctx context.Context) (*big.Int, error) {
+	degree, err := r.con.ChallengeExecutionBisectionDegree(r.getCallOpts(ctx))
+	return degree, errors.WithStack(err)
+}
+
 func (r *RollupWatcher) CurrentRequiredStake(ctx context.Context) (*big.Int, error) {
 	stake, err := r.con.CurrentRequiredStake(r.getCallOpts(ctx))
 	return stake, errors.WithStack(err)
@@ -331,6 +398,24 @@ func (r *RollupWatcher) LatestNodeCreated(ctx context.Context) (*big.Int, error)
 	return node, errors.WithStack(err)
 }
 
+// ZombieLatestStakedNodes returns the latest node each zombie, a staker which
+// lost a challenge but still has its stake recorded on nodes, is staked on
+func (r *RollupWatcher) ZombieLatestStakedNodes(ctx context.Context) ([]*big.Int, error) {
+	count, err := r.con.ZombieCount(r.getCallOpts(ctx))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	nodes := make([]*big.Int, 0, count.Int64())
+	for i := int64(0); i < count.Int64(); i++ {
+		node, err := r.con.ZombieLatestStakedNode(r.getCallOpts(ctx), big.NewInt(i))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
 func (r *RollupWatcher) ConfirmPeriodBlocks(ctx context.Context) (*big.Int, error) {
 	blocks, err := r.con.ConfirmPeriodBlocks(r.getCallOpts(ctx))
 	return blocks, errors.WithStack(err)
@@ -107,7 +107,7 @@ func (r *SequencerInboxWatcher) LookupBatchesInRange(ctx context.Context, from,
 		Addresses: []ethcommon.Address{r.address},
 		Topics:    [][]ethcommon.Hash{{sequencerBatchDeliveredID, sequencerBatchDeliveredFromOriginID, delayedInboxForcedID}},
 	}
-	logs, err := r.client.FilterLogs(ctx, query)
+	logs, err := FilterLogsWithSplitting(ctx, r.client, query)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
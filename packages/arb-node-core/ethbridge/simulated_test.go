@@ -0,0 +1,50 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethbridgecontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/transactauth"
+)
+
+func TestAutoMiningClient(t *testing.T) {
+	ctx := context.Background()
+	backend, auths := test.SimulatedBackend(t)
+	client := ethutils.NewAutoMiningEthClient(backend)
+
+	_, tx, _, err := ethbridgecontracts.DeployBridge(auths[0], client)
+	test.FailIfError(t, err)
+
+	receipt, err := transactauth.WaitForReceiptWithResults(
+		ctx,
+		client,
+		auths[0].From,
+		arbtransaction.NewArbTransaction(tx),
+		"DeployBridge",
+		transactauth.NewEthArbReceiptFetcher(client),
+	)
+	test.FailIfError(t, err)
+	if receipt.BlockNumber.Sign() <= 0 {
+		t.Error("transaction wasn't mined")
+	}
+}
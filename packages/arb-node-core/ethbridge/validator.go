@@ -244,7 +244,7 @@ func CreateValidatorWallet(
 			Addresses: []ethcommon.Address{validatorWalletFactoryAddr},
 			Topics:    [][]ethcommon.Hash{{walletCreatedID}, nil, {transactAuth.From().Hash()}},
 		}
-		logs, err = client.FilterLogs(ctx, query)
+		logs, err = FilterLogsWithSplitting(ctx, client, query)
 		if err != nil {
 			return ethcommon.Address{}, errors.WithStack(err)
 		}
@@ -72,9 +72,7 @@ func NewValidatorUtils(address, rollupAddress ethcommon.Address, client ethutils
 }
 
 func (v *ValidatorUtils) getCallOpts(ctx context.Context) *bind.CallOpts {
-	opts := v.baseCallOpts
-	opts.Context = ctx
-	return &opts
+	return callOptsWithContext(ctx, v.baseCallOpts)
 }
 
 func (v *ValidatorUtils) RefundableStakers(ctx context.Context) ([]common.Address, error) {
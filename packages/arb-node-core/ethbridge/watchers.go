@@ -0,0 +1,97 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethbridge
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+// RollupWatchers are read only views of all of the contracts making up a
+// rollup chain. They never need a key or funds, so they can be used by
+// deployments which only observe the chain.
+type RollupWatchers struct {
+	Rollup         *RollupWatcher
+	DelayedBridge  *DelayedBridgeWatcher
+	SequencerInbox *SequencerInboxWatcher
+	BridgeUtils    *BridgeUtils
+
+	client    ethutils.EthClient
+	fromBlock int64
+	callOpts  bind.CallOpts
+}
+
+// NewRollupWatchers looks up the bridge contracts of the rollup at the given
+// address and creates watchers for each of them
+func NewRollupWatchers(
+	ctx context.Context,
+	rollupAddress common.Address,
+	fromBlock int64,
+	bridgeUtilsAddress common.Address,
+	client ethutils.EthClient,
+	callOpts bind.CallOpts,
+) (*RollupWatchers, error) {
+	rollup, err := NewRollupWatcher(rollupAddress.ToEthAddress(), fromBlock, client, callOpts)
+	if err != nil {
+		return nil, err
+	}
+	delayedBridgeAddress, err := rollup.DelayedBridge(ctx)
+	if err != nil {
+		return nil, err
+	}
+	delayedBridge, err := NewDelayedBridgeWatcher(delayedBridgeAddress.ToEthAddress(), fromBlock, client)
+	if err != nil {
+		return nil, err
+	}
+	sequencerAddress, err := rollup.SequencerBridge(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sequencerInbox, err := NewSequencerInboxWatcher(sequencerAddress.ToEthAddress(), client)
+	if err != nil {
+		return nil, err
+	}
+	bridgeUtils, err := NewBridgeUtils(bridgeUtilsAddress.ToEthAddress(), client, delayedBridge, sequencerInbox)
+	if err != nil {
+		return nil, err
+	}
+	return &RollupWatchers{
+		Rollup:         rollup,
+		DelayedBridge:  delayedBridge,
+		SequencerInbox: sequencerInbox,
+		BridgeUtils:    bridgeUtils,
+		client:         client,
+		fromBlock:      fromBlock,
+		callOpts:       callOpts,
+	}, nil
+}
+
+func (w *RollupWatchers) ChallengeWatcher(address common.Address) (*ChallengeWatcher, error) {
+	return NewChallengeWatcher(address.ToEthAddress(), w.fromBlock, w.client, w.callOpts)
+}
+
+func (w *RollupWatchers) NodeWatcher(address common.Address) (*NodeWatcher, error) {
+	return NewNodeWatcher(address.ToEthAddress(), w.client, w.callOpts)
+}
+
+func (w *RollupWatchers) ValidatorUtils(address common.Address) (*ValidatorUtils, error) {
+	return NewValidatorUtils(address.ToEthAddress(), w.Rollup.address, w.client, w.callOpts)
+}
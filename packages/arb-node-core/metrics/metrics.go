@@ -130,6 +130,83 @@ func (m *Config) RegisterArbCoreMetrics(arbCore core.ArbCore) {
 			return gas.Int64()
 		},
 	)
+	metrics.NewRegisteredFunctionalGauge(
+		"arbitrum/core/checkpoints/pruned",
+		m.Registry,
+		func() int64 {
+			return int64(arbCore.CheckpointPruningStats().PrunedCheckpoints)
+		},
+	)
+	metrics.NewRegisteredFunctionalGauge(
+		"arbitrum/core/checkpoints/pruned_bytes",
+		m.Registry,
+		func() int64 {
+			return int64(arbCore.CheckpointPruningStats().PrunedBytes)
+		},
+	)
+	metrics.NewRegisteredFunctionalGauge(
+		"arbitrum/core/checkpoints/saved",
+		m.Registry,
+		func() int64 {
+			return int64(arbCore.CheckpointStats().SavedCheckpoints)
+		},
+	)
+	metrics.NewRegisteredFunctionalGauge(
+		"arbitrum/core/checkpoints/saved_bytes",
+		m.Registry,
+		func() int64 {
+			return int64(arbCore.CheckpointStats().SavedBytes)
+		},
+	)
+	metrics.NewRegisteredFunctionalGauge(
+		"arbitrum/core/checkpoints/save_time_ms",
+		m.Registry,
+		func() int64 {
+			return arbCore.CheckpointStats().SaveTime.Milliseconds()
+		},
+	)
+	metrics.NewRegisteredFunctionalGauge(
+		"arbitrum/core/checkpoints/last_save_time_us",
+		m.Registry,
+		func() int64 {
+			return arbCore.CheckpointStats().LastSaveTime.Microseconds()
+		},
+	)
+	metrics.NewRegisteredFunctionalGauge(
+		"arbitrum/core/checkpoints/last_restore_time_ms",
+		m.Registry,
+		func() int64 {
+			return arbCore.CheckpointStats().LastRestoreTime.Milliseconds()
+		},
+	)
+	metrics.NewRegisteredFunctionalGauge(
+		"arbitrum/core/database_size_bytes/machine",
+		m.Registry,
+		func() int64 {
+			return int64(arbCore.CheckpointStats().MachineSize)
+		},
+	)
+	metrics.NewRegisteredFunctionalGauge(
+		"arbitrum/core/database_size_bytes/checkpoints",
+		m.Registry,
+		func() int64 {
+			return int64(arbCore.CheckpointStats().CheckpointSize)
+		},
+	)
+	metrics.NewRegisteredFunctionalGauge(
+		"arbitrum/core/database_size_bytes/inbox",
+		m.Registry,
+		func() int64 {
+			return int64(arbCore.CheckpointStats().InboxSize)
+		},
+	)
+	metrics.NewRegisteredFunctionalGauge(
+		"arbitrum/core/database_size_bytes",
+		m.Registry,
+		func() int64 {
+			return int64(arbCore.CheckpointPruningStats().DatabaseSize)
+		},
+	)
 	metrics.NewRegisteredFunctionalGauge(
 		"arbitrum/core/logs_cursor_position",
 		m.Registry,
@@ -68,8 +68,13 @@ type InboxReader struct {
 	sequencerInbox       *ethbridge.SequencerInboxWatcher
 	bridgeUtils          *ethbridge.BridgeUtils
 	caughtUpChan         chan bool
+	reorgChan            chan *big.Int
+	stopped              chan struct{}
 	MessageDeliveryMutex sync.Mutex
 	BroadcastFeed        chan broadcaster.BroadcastFeedMessage
+	listenersMutex       sync.Mutex
+	listeners            []*listenerQueue
+	listenerMetrics      *listenerMetrics
 }
 
 func NewInboxReader(
@@ -81,6 +86,7 @@ func NewInboxReader(
 	healthChan chan nodehealth.Log,
 	broadcastFeed chan broadcaster.BroadcastFeedMessage,
 	inboxReaderConfig configuration.InboxReader,
+	registry metrics.Registry,
 ) (*InboxReader, error) {
 	firstMessageBlock := bridge.FromBlock()
 	if firstMessageBlock <= 1 {
@@ -98,9 +104,12 @@ func NewInboxReader(
 		firstMessageBlock:  big.NewInt(firstMessageBlock),
 		recentFeedItems:    make(map[common.Hash]time.Time),
 		caughtUpChan:       make(chan bool, 1),
+		reorgChan:          make(chan *big.Int, 1),
+		stopped:            make(chan struct{}),
 		healthChan:         healthChan,
 		BroadcastFeed:      broadcastFeed,
 		inboxReaderConfig:  inboxReaderConfig,
+		listenerMetrics:    newListenerMetrics(registry),
 		sequencerAddresses: make(map[ethcommon.Address]time.Time),
 	}, nil
 }
@@ -112,6 +121,8 @@ func (ir *InboxReader) Start(parentCtx context.Context, inboxReaderDelayBlocks i
 		defer func() {
 			done <- true
 		}()
+		// Closed before done is sent, as nothing may be receiving from done
+		defer close(ir.stopped)
 		justErrored := false
 		for {
 			err := ir.getMessages(ctx, justErrored, inboxReaderDelayBlocks)
@@ -141,6 +152,18 @@ func (ir *InboxReader) Stop() {
 	ir.cancelFunc()
 }
 
+// Shutdown stops the inbox reader and waits for its thread to exit and for
+// listeners to handle every notification already queued for them
+func (ir *InboxReader) Shutdown(ctx context.Context) error {
+	ir.Stop()
+	select {
+	case <-ir.stopped:
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "waiting for inbox reader to stop")
+	}
+	return ir.drainListeners(ctx)
+}
+
 // WaitToCatchUp may only be called once
 func (ir *InboxReader) WaitToCatchUp(ctx context.Context) {
 	select {
@@ -152,6 +175,23 @@ func (ir *InboxReader) WaitToCatchUp(ctx context.Context) {
 
 }
 
+// HandleReorg schedules the inbox to be reread starting from the given fork
+// point. It is safe to call from any thread and never blocks.
+func (ir *InboxReader) HandleReorg(_ context.Context, forkPoint *common.BlockId) {
+	forkHeight := forkPoint.Height.AsInt()
+	for {
+		select {
+		case ir.reorgChan <- forkHeight:
+			return
+		case pending := <-ir.reorgChan:
+			// Keep whichever fork point is earlier
+			if pending.Cmp(forkHeight) < 0 {
+				forkHeight = pending
+			}
+		}
+	}
+}
+
 func (ir *InboxReader) GetSequencerInboxWatcher() *ethbridge.SequencerInboxWatcher {
 	return ir.sequencerInbox
 }
@@ -184,7 +224,7 @@ func (ir *InboxReader) isValidSignature(ctx context.Context, message broadcaster
 			logger.
 				Error().
 				Err(err).
-				Hex("address", address.Bytes()).
+				Str("address", address.Hex()).
 				Msg("error validating sequencer feed signing address")
 			return false
 		}
@@ -192,7 +232,7 @@ func (ir *InboxReader) isValidSignature(ctx context.Context, message broadcaster
 		if !isSequencer {
 			logger.
 				Error().
-				Hex("address", address.Bytes()).
+				Str("address", address.Hex()).
 				Msg("invalid sequencer feed signing address")
 			return false
 		}
@@ -200,7 +240,7 @@ func (ir *InboxReader) isValidSignature(ctx context.Context, message broadcaster
 		expired := time.Now().Add(ir.inboxReaderConfig.SequencerSignatureExpiry)
 		logger.
 			Info().
-			Hex("address", address.Bytes()).
+			Str("address", address.Hex()).
 			Str("expires", expired.String()).
 			Msg("sequencer feed signing address validated")
 		ir.sequencerAddresses[address] = expired
@@ -233,6 +273,16 @@ func (ir *InboxReader) getMessages(ctx context.Context, temporarilyParanoid bool
 
 		reorgingDelayed := ir.inboxReaderConfig.Paranoid || temporarilyParanoid || missingFeedDelayedReference
 		reorgingSequencer := ir.inboxReaderConfig.Paranoid || temporarilyParanoid
+		select {
+		case forkHeight := <-ir.reorgChan:
+			logger.Info().Str("forkBlock", forkHeight.String()).Msg("rereading inbox after L1 reorg")
+			if forkHeight.Cmp(from) < 0 {
+				from = new(big.Int).Set(forkHeight)
+			}
+			reorgingDelayed = true
+			reorgingSequencer = true
+		default:
+		}
 		if ir.caughtUp {
 			latestDelayed, latestSeq, err := ir.bridgeUtils.GetCountsAndAccumulators(ctx)
 			if err != nil {
@@ -292,11 +342,7 @@ func (ir *InboxReader) getMessages(ctx context.Context, temporarilyParanoid bool
 			if to.Cmp(currentHeight) > 0 {
 				to = currentHeight
 			}
-			delayedMessages, err := ir.delayedBridge.LookupMessagesInRange(ctx, from, to)
-			if err != nil {
-				return err
-			}
-			sequencerBatches, err := ir.sequencerInbox.LookupBatchesInRange(ctx, from, to)
+			delayedMessages, sequencerBatches, err := ethbridge.LookupInboxEventsInRange(ctx, ir.delayedBridge, ir.sequencerInbox, from, to)
 			if err != nil {
 				return err
 			}
@@ -603,6 +649,7 @@ func (ir *InboxReader) addMessages(ctx context.Context, sequencerBatchRefs []eth
 	if err != nil {
 		return false, err
 	}
+	ir.notifyListeners(ctx, seqBatchItems, delayedMessages)
 	dupBroadcasterItems := 0
 	for _, item := range seqBatchItems {
 		if len(ir.sequencerFeedQueue) == 0 {
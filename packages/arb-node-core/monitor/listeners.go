@@ -0,0 +1,181 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+)
+
+// Number of deliveries which can be waiting for a listener before further
+// deliveries to it are dropped
+const listenerQueueSize = 64
+
+type listenerMetrics struct {
+	dropped metrics.Counter
+	panics  metrics.Counter
+	lag     metrics.Timer
+}
+
+func newListenerMetrics(registry metrics.Registry) *listenerMetrics {
+	return &listenerMetrics{
+		dropped: metrics.NewRegisteredCounter("arbitrum/inbox/listener/dropped", registry),
+		panics:  metrics.NewRegisteredCounter("arbitrum/inbox/listener/panics", registry),
+		lag:     metrics.NewRegisteredTimer("arbitrum/inbox/listener/lag", registry),
+	}
+}
+
+// InboxListener is notified after the inbox reader delivers messages read from
+// L1 to the core. Each listener is called from its own goroutine, so a slow
+// listener only delays its own notifications.
+type InboxListener interface {
+	InboxMessagesDelivered(ctx context.Context, seqBatchItems []inbox.SequencerBatchItem, delayedMessages []inbox.DelayedMessage)
+}
+
+type inboxDelivery struct {
+	ctx             context.Context
+	seqBatchItems   []inbox.SequencerBatchItem
+	delayedMessages []inbox.DelayedMessage
+	queuedAt        time.Time
+}
+
+// listenerQueue dispatches deliveries to a single listener, isolating the
+// inbox reader from listeners which block or panic
+type listenerQueue struct {
+	listener InboxListener
+	metrics  *listenerMetrics
+	queue    chan inboxDelivery
+	done     chan struct{}
+}
+
+func newListenerQueue(listener InboxListener, metrics *listenerMetrics) *listenerQueue {
+	q := &listenerQueue{
+		listener: listener,
+		metrics:  metrics,
+		queue:    make(chan inboxDelivery, listenerQueueSize),
+		done:     make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *listenerQueue) run() {
+	defer close(q.done)
+	for delivery := range q.queue {
+		q.metrics.lag.UpdateSince(delivery.queuedAt)
+		q.dispatch(delivery)
+	}
+}
+
+func (q *listenerQueue) dispatch(delivery inboxDelivery) {
+	defer func() {
+		if r := recover(); r != nil {
+			q.metrics.panics.Inc(1)
+			logger.Error().
+				Str("listener", fmt.Sprintf("%T", q.listener)).
+				Str("panic", fmt.Sprint(r)).
+				Bytes("stack", debug.Stack()).
+				Msg("inbox listener panicked")
+		}
+	}()
+	q.listener.InboxMessagesDelivered(delivery.ctx, delivery.seqBatchItems, delivery.delayedMessages)
+}
+
+func (q *listenerQueue) push(delivery inboxDelivery) {
+	select {
+	case q.queue <- delivery:
+	default:
+		q.metrics.dropped.Inc(1)
+		logger.Warn().
+			Str("listener", fmt.Sprintf("%T", q.listener)).
+			Msg("inbox listener is falling behind, dropping notification")
+	}
+}
+
+// ListenerHandle identifies a registration made by AddListener
+type ListenerHandle struct {
+	queue *listenerQueue
+}
+
+// AddListener registers l to be notified of delivered messages, returning a
+// handle to remove it with. It is safe to call while the inbox reader is
+// running. A listener added more than once is notified once per registration.
+func (ir *InboxReader) AddListener(l InboxListener) ListenerHandle {
+	ir.listenersMutex.Lock()
+	defer ir.listenersMutex.Unlock()
+	q := newListenerQueue(l, ir.listenerMetrics)
+	ir.listeners = append(ir.listeners, q)
+	return ListenerHandle{queue: q}
+}
+
+// RemoveListener removes the registration h, returning false if it was
+// already removed. Notifications already queued for it are still delivered.
+func (ir *InboxReader) RemoveListener(h ListenerHandle) bool {
+	ir.listenersMutex.Lock()
+	defer ir.listenersMutex.Unlock()
+	for i, q := range ir.listeners {
+		if q == h.queue {
+			ir.listeners = append(ir.listeners[:i], ir.listeners[i+1:]...)
+			close(q.queue)
+			return true
+		}
+	}
+	return false
+}
+
+// drainListeners unregisters all listeners and waits for them to handle their
+// queued notifications
+func (ir *InboxReader) drainListeners(ctx context.Context) error {
+	ir.listenersMutex.Lock()
+	queues := ir.listeners
+	ir.listeners = nil
+	for _, q := range queues {
+		close(q.queue)
+	}
+	ir.listenersMutex.Unlock()
+	for _, q := range queues {
+		select {
+		case <-q.done:
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "waiting for inbox listeners to finish")
+		}
+	}
+	return nil
+}
+
+func (ir *InboxReader) notifyListeners(ctx context.Context, seqBatchItems []inbox.SequencerBatchItem, delayedMessages []inbox.DelayedMessage) {
+	delivery := inboxDelivery{
+		ctx:             ctx,
+		seqBatchItems:   seqBatchItems,
+		delayedMessages: delayedMessages,
+		queuedAt:        time.Now(),
+	}
+	// Pushing never blocks, so holding the lock here can't stall the caller and
+	// prevents RemoveListener from closing a queue while we push to it
+	ir.listenersMutex.Lock()
+	defer ir.listenersMutex.Unlock()
+	for _, q := range ir.listeners {
+		q.push(delivery)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+)
+
+func newTestListenerReader(registry metrics.Registry) *InboxReader {
+	return &InboxReader{listenerMetrics: newListenerMetrics(registry)}
+}
+
+type countingListener struct {
+	mutex sync.Mutex
+	count int
+}
+
+func (l *countingListener) InboxMessagesDelivered(_ context.Context, _ []inbox.SequencerBatchItem, _ []inbox.DelayedMessage) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.count++
+}
+
+func (l *countingListener) deliveries() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.count
+}
+
+type listenerFunc func()
+
+func (f listenerFunc) InboxMessagesDelivered(_ context.Context, _ []inbox.SequencerBatchItem, _ []inbox.DelayedMessage) {
+	f()
+}
+
+func drain(t *testing.T, ir *InboxReader) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := ir.drainListeners(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoveListenerByHandle(t *testing.T) {
+	ir := newTestListenerReader(metrics.NewRegistry())
+	ctx := context.Background()
+	listener := &countingListener{}
+	first := ir.AddListener(listener)
+	second := ir.AddListener(listener)
+
+	ir.notifyListeners(ctx, nil, nil)
+	if !ir.RemoveListener(first) {
+		t.Fatal("failed to remove first registration")
+	}
+	if ir.RemoveListener(first) {
+		t.Error("removed first registration twice")
+	}
+	ir.notifyListeners(ctx, nil, nil)
+	if !ir.RemoveListener(second) {
+		t.Fatal("failed to remove second registration")
+	}
+	ir.notifyListeners(ctx, nil, nil)
+	drain(t, ir)
+
+	// Both registrations got the first delivery, only the second got the
+	// next and neither got the last
+	if listener.deliveries() != 3 {
+		t.Errorf("listener got %v deliveries, expected 3", listener.deliveries())
+	}
+}
+
+func TestListenerPanicIsContained(t *testing.T) {
+	registry := metrics.NewRegistry()
+	ir := newTestListenerReader(registry)
+	ctx := context.Background()
+	ir.AddListener(listenerFunc(func() {
+		panic("listener failure")
+	}))
+	listener := &countingListener{}
+	ir.AddListener(listener)
+
+	ir.notifyListeners(ctx, nil, nil)
+	ir.notifyListeners(ctx, nil, nil)
+	drain(t, ir)
+
+	if listener.deliveries() != 2 {
+		t.Errorf("listener got %v deliveries, expected 2", listener.deliveries())
+	}
+	if panics := ir.listenerMetrics.panics.Count(); panics != 2 {
+		t.Errorf("counted %v panics, expected 2", panics)
+	}
+	if registry.Get("arbitrum/inbox/listener/panics") == nil {
+		t.Error("listener metrics weren't registered in the given registry")
+	}
+}
+
+func TestSlowListenerDropsNotifications(t *testing.T) {
+	ir := newTestListenerReader(metrics.NewRegistry())
+	ctx := context.Background()
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	ir.AddListener(listenerFunc(func() {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	}))
+
+	// The slow listener holds the first delivery while the rest fill its queue
+	ir.notifyListeners(ctx, nil, nil)
+	<-started
+	extra := 5
+	for i := 0; i < listenerQueueSize+extra; i++ {
+		ir.notifyListeners(ctx, nil, nil)
+	}
+	if dropped := ir.listenerMetrics.dropped.Count(); dropped != int64(extra) {
+		t.Errorf("dropped %v notifications, expected %v", dropped, extra)
+	}
+	close(release)
+	drain(t, ir)
+}
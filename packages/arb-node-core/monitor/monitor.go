@@ -22,10 +22,12 @@ import (
 	"math/big"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/metrics"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
@@ -46,6 +48,11 @@ type Monitor struct {
 	Core       core.ArbCore
 	Reader     *InboxReader
 	CoreConfig *configuration.Core
+
+	// Set by StartInboxReader if reorg detection is enabled
+	ReorgDetector *ethbridge.ReorgDetector
+
+	closeOnce sync.Once
 }
 
 func NewInitializedMonitor(dbDir string, contractFile string, coreConfig *configuration.Core) (*Monitor, error) {
@@ -105,12 +112,34 @@ func (m *Monitor) Start() error {
 	return nil
 }
 
-func (m *Monitor) Close() {
+// Stop shuts the monitor down gracefully, waiting for the inbox reader and its
+// listeners to finish and then, if enabled, saving a rocksdb checkpoint. The
+// database is left open so that anything else still using Core can be stopped
+// before Close frees it.
+func (m *Monitor) Stop(ctx context.Context) error {
+	var err error
 	if m.Reader != nil {
-		m.Reader.Stop()
+		err = m.Reader.Shutdown(ctx)
+		if err != nil {
+			logger.Warn().Err(err).Msg("inbox reader didn't shut down cleanly")
+		}
 	}
-	m.Storage.CloseArbStorage()
-	logger.Info().Msg("Database closed")
+	if m.CoreConfig != nil && m.CoreConfig.CheckpointOnShutdown {
+		logger.Info().Msg("saving rocksdb checkpoint before shutdown")
+		m.Core.SaveRocksdbCheckpoint()
+	}
+	return err
+}
+
+// Close may be called more than once, such as after Stop
+func (m *Monitor) Close() {
+	m.closeOnce.Do(func() {
+		if m.Reader != nil {
+			m.Reader.Stop()
+		}
+		m.Storage.CloseArbStorage()
+		logger.Info().Msg("Database closed")
+	})
 }
 
 func (m *Monitor) StartInboxReader(
@@ -122,12 +151,13 @@ func (m *Monitor) StartInboxReader(
 	healthChan chan nodehealth.Log,
 	sequencerFeed chan broadcaster.BroadcastFeedMessage,
 	inboxReaderConfig configuration.InboxReader,
+	registry metrics.Registry,
 ) (*InboxReader, chan bool, error) {
-	rollup, err := ethbridge.NewRollupWatcher(rollupAddress.ToEthAddress(), fromBlock, ethClient, bind.CallOpts{})
+	watchers, err := ethbridge.NewRollupWatchers(ctx, rollupAddress, fromBlock, bridgeUtilsAddress, ethClient, bind.CallOpts{})
 	if err != nil {
 		return nil, nil, err
 	}
-	creationEvent, err := rollup.LookupCreation(ctx)
+	creationEvent, err := watchers.Rollup.LookupCreation(ctx)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "error checking initial chain state")
 	}
@@ -140,41 +170,27 @@ func (m *Monitor) StartInboxReader(
 		return nil, nil, errors.Errorf("Initial machine hash loaded from arbos.mexe doesn't match chain's initial machine hash: chain %v, arbCore %v", hexutil.Encode(creationEvent.MachineHash[:]), initialMachineHash)
 	}
 
-	delayedBridgeAddress, err := rollup.DelayedBridge(ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-	delayedBridgeWatcher, err := ethbridge.NewDelayedBridgeWatcher(delayedBridgeAddress.ToEthAddress(), fromBlock, ethClient)
-	if err != nil {
-		return nil, nil, err
-	}
-	sequencerAddress, err := rollup.SequencerBridge(ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-	sequencerInboxWatcher, err := ethbridge.NewSequencerInboxWatcher(sequencerAddress.ToEthAddress(), ethClient)
-	if err != nil {
-		return nil, nil, err
-	}
-	bridgeUtils, err := ethbridge.NewBridgeUtils(bridgeUtilsAddress.ToEthAddress(), ethClient, delayedBridgeWatcher, sequencerInboxWatcher)
-	if err != nil {
-		return nil, nil, err
-	}
 	reader, err := NewInboxReader(
 		ctx,
-		delayedBridgeWatcher,
-		sequencerInboxWatcher,
-		bridgeUtils,
+		watchers.DelayedBridge,
+		watchers.SequencerInbox,
+		watchers.BridgeUtils,
 		m.Core,
 		healthChan,
 		sequencerFeed,
 		inboxReaderConfig,
+		registry,
 	)
 	if err != nil {
 		return nil, nil, err
 	}
 	done := reader.Start(ctx, inboxReaderConfig.DelayBlocks)
 	m.Reader = reader
+	if inboxReaderConfig.ReorgDetectionDepth > 0 {
+		m.ReorgDetector = ethbridge.NewReorgDetector(ethClient, inboxReaderConfig.ReorgDetectionDepth)
+		m.ReorgDetector.OnReorg(reader.HandleReorg)
+		m.ReorgDetector.RunInBackground(ctx, inboxReaderConfig.ReorgDetectionInterval)
+	}
 	m.listenForSignal(ctx)
 	return reader, done, nil
 }
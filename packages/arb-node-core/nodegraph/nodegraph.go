@@ -0,0 +1,176 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nodegraph
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+type Node struct {
+	Num           *big.Int
+	Prev          *big.Int
+	DeadlineBlock *big.Int
+	StakerCount   *big.Int
+}
+
+type Staker struct {
+	Address          common.Address
+	LatestStakedNode *big.Int
+	CurrentChallenge *common.Address
+}
+
+// NodeGraph is a snapshot of the rollup's unresolved nodes, along with the
+// latest confirmed node they build on
+type NodeGraph struct {
+	CurrentBlock    *big.Int
+	LatestConfirmed *big.Int
+	Nodes           []*Node
+	Stakers         []*Staker
+}
+
+// Load reads the current node graph from L1. Resolved nodes other than the
+// latest confirmed node no longer exist on chain, so they aren't included.
+func Load(ctx context.Context, client ethutils.EthClient, rollup *ethbridge.RollupWatcher, validatorUtils *ethbridge.ValidatorUtils) (*NodeGraph, error) {
+	currentBlock, err := client.BlockInfoByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	latestConfirmed, err := rollup.LatestConfirmedNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	firstUnresolved, err := rollup.FirstUnresolvedNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latestCreated, err := rollup.LatestNodeCreated(ctx)
+	if err != nil {
+		return nil, err
+	}
+	graph := &NodeGraph{
+		CurrentBlock:    currentBlock.Number.ToInt(),
+		LatestConfirmed: latestConfirmed,
+	}
+	nums := []*big.Int{latestConfirmed}
+	for num := new(big.Int).Set(firstUnresolved); num.Cmp(latestCreated) <= 0; num = new(big.Int).Add(num, big.NewInt(1)) {
+		nums = append(nums, num)
+	}
+	for _, num := range nums {
+		nodeWatcher, err := rollup.GetNode(ctx, num)
+		if err != nil {
+			return nil, err
+		}
+		node := &Node{Num: num}
+		if node.Prev, err = nodeWatcher.Prev(ctx); err != nil {
+			return nil, err
+		}
+		if node.DeadlineBlock, err = nodeWatcher.DeadlineBlock(ctx); err != nil {
+			return nil, err
+		}
+		if node.StakerCount, err = nodeWatcher.StakerCount(ctx); err != nil {
+			return nil, err
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	stakers, err := validatorUtils.GetStakers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, staker := range stakers {
+		info, err := rollup.StakerInfo(ctx, staker)
+		if err != nil {
+			return nil, err
+		}
+		if info == nil {
+			continue
+		}
+		graph.Stakers = append(graph.Stakers, &Staker{
+			Address:          staker,
+			LatestStakedNode: info.LatestStakedNode,
+			CurrentChallenge: info.CurrentChallenge,
+		})
+	}
+	return graph, nil
+}
+
+func (g *NodeGraph) hasNode(num *big.Int) bool {
+	for _, node := range g.Nodes {
+		if node.Num.Cmp(num) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportDOT writes the graph in GraphViz's DOT format. Each node points to its
+// predecessor, and each staker points to the latest node it's staked on.
+func (g *NodeGraph) ExportDOT(w io.Writer) error {
+	var err error
+	printf := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+	printf("digraph rollup {\n")
+	printf("  rankdir=RL;\n")
+	printf("  label=\"L1 block %v\";\n", g.CurrentBlock)
+	printf("  node [shape=box];\n")
+	for _, node := range g.Nodes {
+		label := fmt.Sprintf("node %v\\nstakers: %v", node.Num, node.StakerCount)
+		style := ""
+		if node.Num.Cmp(g.LatestConfirmed) == 0 {
+			label += "\\nconfirmed"
+			style = ", style=filled, fillcolor=palegreen"
+		} else if node.DeadlineBlock.Cmp(g.CurrentBlock) <= 0 {
+			label += fmt.Sprintf("\\ndeadline %v (passed)", node.DeadlineBlock)
+			style = ", style=filled, fillcolor=lightsalmon"
+		} else {
+			label += fmt.Sprintf("\\ndeadline %v (%v blocks)", node.DeadlineBlock, new(big.Int).Sub(node.DeadlineBlock, g.CurrentBlock))
+		}
+		printf("  n%v [label=\"%v\"%v];\n", node.Num, label, style)
+	}
+	for _, node := range g.Nodes {
+		if node.Num.Cmp(g.LatestConfirmed) != 0 && g.hasNode(node.Prev) {
+			printf("  n%v -> n%v;\n", node.Num, node.Prev)
+		}
+	}
+	for _, staker := range g.Stakers {
+		id := "s" + staker.Address.Hex()
+		label := staker.Address.Hex()
+		style := ""
+		if staker.CurrentChallenge != nil {
+			label += "\\nchallenge " + staker.CurrentChallenge.Hex()
+			style = ", color=red"
+		}
+		printf("  %v [shape=ellipse, label=\"%v\"%v];\n", id, label, style)
+		if g.hasNode(staker.LatestStakedNode) {
+			printf("  %v -> n%v [style=dashed];\n", id, staker.LatestStakedNode)
+		}
+	}
+	printf("}\n")
+	return errors.WithStack(err)
+}
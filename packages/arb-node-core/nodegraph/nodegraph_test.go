@@ -0,0 +1,74 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nodegraph
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestExportDOT(t *testing.T) {
+	challenge := common.Address{9}
+	graph := &NodeGraph{
+		CurrentBlock:    big.NewInt(100),
+		LatestConfirmed: big.NewInt(4),
+		Nodes: []*Node{
+			{Num: big.NewInt(4), Prev: big.NewInt(3), DeadlineBlock: big.NewInt(20), StakerCount: big.NewInt(1)},
+			{Num: big.NewInt(5), Prev: big.NewInt(4), DeadlineBlock: big.NewInt(90), StakerCount: big.NewInt(1)},
+			{Num: big.NewInt(6), Prev: big.NewInt(4), DeadlineBlock: big.NewInt(150), StakerCount: big.NewInt(2)},
+		},
+		Stakers: []*Staker{
+			{Address: common.Address{1}, LatestStakedNode: big.NewInt(5), CurrentChallenge: &challenge},
+			{Address: common.Address{2}, LatestStakedNode: big.NewInt(2)},
+		},
+	}
+	var buf bytes.Buffer
+	test.FailIfError(t, graph.ExportDOT(&buf))
+	dot := buf.String()
+
+	expected := []string{
+		"digraph rollup {\n",
+		"n4 [label=\"node 4\\nstakers: 1\\nconfirmed\"",
+		"deadline 90 (passed)",
+		"deadline 150 (50 blocks)",
+		"n5 -> n4;",
+		"n6 -> n4;",
+		"challenge " + challenge.Hex(),
+		"s" + common.Address{1}.Hex() + " -> n5 [style=dashed];",
+	}
+	for _, s := range expected {
+		if !strings.Contains(dot, s) {
+			t.Errorf("expected output to contain %q, got\n%v", s, dot)
+		}
+	}
+	// The latest confirmed node's predecessor no longer exists
+	if strings.Contains(dot, "-> n3") {
+		t.Error("edge to node outside of graph")
+	}
+	// Stakers behind the latest confirmed node are shown without an edge
+	if strings.Contains(dot, "s"+common.Address{2}.Hex()+" ->") {
+		t.Error("edge from staker to node outside of graph")
+	}
+	if !strings.HasSuffix(dot, "}\n") {
+		t.Error("graph not closed")
+	}
+}
@@ -51,6 +51,8 @@ type configStruct struct {
 	disablePrimaryCheck bool
 	//Disable checking the OpenEthereum node
 	disableOpenEthereumCheck bool
+	//Enable checking the validator's staker
+	validatorCheck bool
 
 	// Store of metrics produced from healthcheck
 	registry metrics.Registry
@@ -91,6 +93,10 @@ type configStruct struct {
 	printRequests bool
 	//Debug variable to print the status of the configuration load
 	printConfigMsg bool
+
+	//Validator Healthcheck Config
+	//Maximum time since the staker last completed an action
+	validatorL1Timeout time.Duration
 }
 
 //Struct for storing the state of a node's different components
@@ -98,6 +104,8 @@ type healthState struct {
 	mu sync.Mutex
 	//InboxReader state struct
 	inboxReader inboxReaderState
+	//Staker state struct
+	validator validatorState
 }
 
 //Struct for storing inboxReader's current state
@@ -112,6 +120,17 @@ type inboxReaderState struct {
 	caughtUpTarget     *big.Int
 }
 
+//Struct for storing the staker's last reported status
+type validatorState struct {
+	AtHead              bool     `json:"atHead"`
+	LatestBlock         *big.Int `json:"latestBlock"`
+	LatestBlockHash     string   `json:"latestBlockHash"`
+	LatestConfirmedNode *big.Int `json:"latestConfirmedNode"`
+	CurrentChallenge    string   `json:"currentChallenge,omitempty"`
+	Balance             *big.Int `json:"balance"`
+	LastL1Success       int64    `json:"lastL1Success"`
+}
+
 //Struct for storing the asynchronous healthcheck calls
 type asyncDataStruct struct {
 	mu sync.Mutex
@@ -210,6 +229,9 @@ func newConfig(registry metrics.Registry) *configStruct {
 	const printRequests = false
 	const printConfigMsg = false
 
+	//Validator health configuration
+	const validatorL1Timeout = 30 * time.Minute
+
 	//Load configuration into struct
 	config.registry = registry
 
@@ -236,6 +258,8 @@ func newConfig(registry metrics.Registry) *configStruct {
 	config.printRequests = printRequests
 	config.printConfigMsg = printConfigMsg
 
+	config.validatorL1Timeout = validatorL1Timeout
+
 	return &config
 }
 
@@ -274,6 +298,9 @@ func newAsyncUpstream(state *healthState, config *configStruct) *asyncDataStruct
 	//Check how many blocks the inboxReader is behind
 	asyncData.healthchecks["inboxReaderStatus"] = checkInboxReader(config, state)
 
+	//Check the staker is keeping up with L1
+	asyncData.healthchecks["validatorStatus"] = checkValidator(config, state)
+
 	return &asyncData
 }
 
@@ -287,6 +314,10 @@ func newHealthState() *healthState {
 	state.inboxReader.arbCorePosition = new(big.Int)
 	state.inboxReader.getNextBlockToRead = new(big.Int)
 
+	state.validator.LatestBlock = new(big.Int)
+	state.validator.LatestConfirmedNode = new(big.Int)
+	state.validator.Balance = new(big.Int)
+
 	return &state
 }
 
@@ -310,6 +341,10 @@ func logger(ctx context.Context, config *configStruct, state *healthState, logMs
 			if logMessage.Comp == "InboxReader" {
 				updateInboxReader(state, logMessage)
 			}
+			//Check if the Staker is sending logs
+			if logMessage.Comp == "Staker" {
+				updateValidator(state, logMessage)
+			}
 		}
 	}
 }
@@ -341,6 +376,35 @@ func updateInboxReader(state *healthState, logMessage Log) {
 	}
 }
 
+//Update the validator state struct using a value from the health channel
+func updateValidator(state *healthState, logMessage Log) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	//Load the log into the correct struct field inside the state array
+	if logMessage.Var == "atHead" {
+		state.validator.AtHead = logMessage.ValBool
+	}
+	if logMessage.Var == "latestBlock" {
+		state.validator.LatestBlock.Set(logMessage.ValBigInt)
+	}
+	if logMessage.Var == "latestBlockHash" {
+		state.validator.LatestBlockHash = logMessage.ValStr
+	}
+	if logMessage.Var == "latestConfirmedNode" {
+		state.validator.LatestConfirmedNode.Set(logMessage.ValBigInt)
+	}
+	if logMessage.Var == "currentChallenge" {
+		state.validator.CurrentChallenge = logMessage.ValStr
+	}
+	if logMessage.Var == "balance" {
+		state.validator.Balance.Set(logMessage.ValBigInt)
+	}
+	if logMessage.Var == "lastL1Success" {
+		state.validator.LastL1Success = logMessage.ValInt
+	}
+}
+
 //Update the configurations truct using a value from the health channel
 func updateConfig(config *configStruct, logMessage Log) {
 	config.mu.Lock()
@@ -399,6 +463,12 @@ func updateConfig(config *configStruct, logMessage Log) {
 	if logMessage.Var == "disableOpenEthereumCheck" {
 		config.disableOpenEthereumCheck = logMessage.ValBool
 	}
+	if logMessage.Var == "validatorCheck" {
+		config.validatorCheck = logMessage.ValBool
+	}
+	if logMessage.Var == "validatorL1Timeout" {
+		config.validatorL1Timeout = logMessage.ValTime
+	}
 }
 
 //Resolve the IP of the OpenEthereum node and check if it can be dialed
@@ -576,37 +646,46 @@ func openEthereumPeerCount(config *configStruct, aSyncData *asyncDataStruct) hea
 	return check
 }
 
+//Copy the eth_syncing response so it can be inspected without holding the lock
+func ethSyncSnapshot(aSyncData *asyncDataStruct) OpenEthereumResponse {
+	aSyncData.mu.Lock()
+	defer aSyncData.mu.Unlock()
+	return aSyncData.ethSyncResp
+}
+
 //Check that the block OpenEthereum is on is updating at a rate faster then config.blockUpdateTimeout
 func openEthereumBlockUpdateCheck(config *configStruct, aSyncData *asyncDataStruct) healthcheck.Check {
 	check := healthcheck.Async(func() error {
-		aSyncData.mu.Lock()
-		defer aSyncData.mu.Unlock()
 		//Pause to allow request to be captured
 		time.Sleep(2 * time.Second)
 
+		//Work on copies of the response so that ethSyncCheck can update it while we wait
+		before := ethSyncSnapshot(aSyncData)
+
 		//Check if OpenEthereum is not currently syncing
-		if strings.Contains(aSyncData.ethSyncResp.respBody, `"result": false`) {
+		if strings.Contains(before.respBody, `"result": false`) {
 			return nil
 		}
 
 		//Check if the call to OpenEthereum failed
-		if strings.Contains(aSyncData.ethSyncResp.respBody, `failed`) {
+		if strings.Contains(before.respBody, `failed`) {
 			err := fmt.Errorf("GET request failed")
 			return err
 		}
 
-		//Retrieve current block from response struct
-		currentBlock := aSyncData.ethSyncResp.Result.CurrentBlock
-
-		//Retrieve snapshot status
-		warpBlock := aSyncData.ethSyncResp.Result.WarpChunksProcessed
-
 		//Wait the blockUpdateTimeout
 		time.Sleep(config.blockUpdateTimeout)
 
+		after := ethSyncSnapshot(aSyncData)
+
+		//Check if OpenEthereum finished syncing while we waited
+		if strings.Contains(after.respBody, `"result": false`) {
+			return nil
+		}
+
 		//Check if the new block is equal to the old block
-		if currentBlock == aSyncData.ethSyncResp.Result.CurrentBlock {
-			if warpBlock == aSyncData.ethSyncResp.Result.WarpChunksProcessed {
+		if before.Result.CurrentBlock == after.Result.CurrentBlock {
+			if before.Result.WarpChunksProcessed == after.Result.WarpChunksProcessed {
 				err := fmt.Errorf("currentBlock/warpBlock have not refreshed within timeout")
 				return err
 			}
@@ -754,6 +833,48 @@ func checkInboxReader(config *configStruct, state *healthState) healthcheck.Chec
 	return check
 }
 
+//Check that the staker is at head and has recently completed an action on L1
+func checkValidator(config *configStruct, state *healthState) healthcheck.Check {
+	check := healthcheck.Async(func() error {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+
+		//Check if the staker has reported yet
+		if state.validator.LastL1Success == 0 {
+			return errors.New("staker hasn't completed an action yet")
+		}
+
+		//Check if the staker has fallen behind L1
+		if !state.validator.AtHead {
+			return errors.New("staker behind L1 block " + state.validator.LatestBlock.String())
+		}
+
+		//Check if the staker's last successful L1 interaction was too long ago
+		lastSuccess := time.Unix(state.validator.LastL1Success, 0)
+		if time.Since(lastSuccess) > config.validatorL1Timeout {
+			return errors.New("staker hasn't completed an action since " + lastSuccess.String())
+		}
+
+		return nil
+	}, config.pollingRate)
+	return check
+}
+
+//Serve the staker's last reported status as json
+func validatorStatusEndpoint(state *healthState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		state.mu.Lock()
+		body, err := json.Marshal(state.validator)
+		state.mu.Unlock()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}
+}
+
 //Define which healthchecks to use for the readiness API and expose the readiness API
 func nodeReadinessChecks(health healthcheck.Handler, config *configStruct, httpMux *http.ServeMux, asyncData *asyncDataStruct) {
 	//Add healthchecks to the readiness check
@@ -769,6 +890,13 @@ func nodeReadinessChecks(health healthcheck.Handler, config *configStruct, httpM
 		"inbox_reader_status",
 		asyncData.healthchecks["inboxReaderStatus"])
 
+	//Add validator healthcheck if enabled
+	if config.validatorCheck {
+		health.AddReadinessCheck(
+			"validator_status",
+			asyncData.healthchecks["validatorStatus"])
+	}
+
 	//OpenEthereum healthchecks
 	//Add healthchecks to the readiness check if they are not disabled
 	if !config.disableOpenEthereumCheck {
@@ -856,6 +984,11 @@ func startHealthCheck(ctx context.Context, config *configStruct, state *healthSt
 	//Define which healthchecks to use for the readiness API and expose the readiness API
 	nodeReadinessChecks(health, config, httpMux, asyncUpstream)
 
+	//Create an endpoint to serve the staker's status for dashboards
+	if config.validatorCheck {
+		httpMux.HandleFunc("/validator", validatorStatusEndpoint(state))
+	}
+
 	//Create the HTTP server
 	httpServer := &http.Server{
 		Addr:        config.healthcheckRPC,
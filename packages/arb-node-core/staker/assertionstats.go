@@ -0,0 +1,211 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+// An assertion using more than this fraction of a protocol limit is logged as
+// a warning
+const assertionLimitWarning = 0.9
+
+// AssertionStats is the work done by an assertion, along with the limits the
+// rollup places on it. All counts are for the assertion alone rather than
+// totals since the start of the chain.
+type AssertionStats struct {
+	// Node is nil for a node which is being created
+	Node     *big.Int
+	NodeHash [32]byte
+	Created  bool
+
+	Gas          *big.Int
+	Steps        *big.Int
+	MessagesRead *big.Int
+	SendCount    *big.Int
+	LogCount     *big.Int
+
+	// GasLimit is the most gas the rollup accepts for the assertion, given
+	// the time since its parent was proposed
+	GasLimit  *big.Int
+	SendLimit *big.Int
+}
+
+func newAssertionStats(node *big.Int, nodeHash [32]byte, assertion *core.Assertion, steps *big.Int, gasLimit *big.Int) *AssertionStats {
+	return &AssertionStats{
+		Node:         node,
+		NodeHash:     nodeHash,
+		Created:      node == nil,
+		Gas:          new(big.Int).Sub(assertion.After.TotalGasConsumed, assertion.Before.TotalGasConsumed),
+		Steps:        steps,
+		MessagesRead: new(big.Int).Sub(assertion.After.TotalMessagesRead, assertion.Before.TotalMessagesRead),
+		SendCount:    new(big.Int).Sub(assertion.After.TotalSendCount, assertion.Before.TotalSendCount),
+		LogCount:     new(big.Int).Sub(assertion.After.TotalLogCount, assertion.Before.TotalLogCount),
+		GasLimit:     gasLimit,
+		SendLimit:    maxAssertionSendCount,
+	}
+}
+
+func limitUsage(used *big.Int, limit *big.Int) float64 {
+	if limit == nil || limit.Sign() <= 0 {
+		return 0
+	}
+	usage, _ := new(big.Float).Quo(new(big.Float).SetInt(used), new(big.Float).SetInt(limit)).Float64()
+	return usage
+}
+
+// GasLimitUsage is the fraction of the gas limit used by the assertion
+func (s *AssertionStats) GasLimitUsage() float64 {
+	return limitUsage(s.Gas, s.GasLimit)
+}
+
+// SendLimitUsage is the fraction of the send limit used by the assertion
+func (s *AssertionStats) SendLimitUsage() float64 {
+	return limitUsage(s.SendCount, s.SendLimit)
+}
+
+// AssertionListener is told about the work done by every assertion the
+// validator creates or finds to be correct. It's called from the staker's
+// thread, so it must not block.
+type AssertionListener interface {
+	AssertionProcessed(stats *AssertionStats)
+}
+
+// AssertionListenerFunc adapts a function to an AssertionListener
+type AssertionListenerFunc func(stats *AssertionStats)
+
+func (f AssertionListenerFunc) AssertionProcessed(stats *AssertionStats) {
+	f(stats)
+}
+
+// AddAssertionListener registers l to be told about later assertions
+func (v *Validator) AddAssertionListener(l AssertionListener) {
+	v.assertionListeners = append(v.assertionListeners, l)
+}
+
+// The number of node hashes remembered so that the same assertion isn't
+// reported again each time the staker acts
+const reportedAssertionsLimit = 1024
+
+// reportedAssertions is the set of the most recently reported node hashes
+type reportedAssertions struct {
+	seen  map[[32]byte]bool
+	order [][32]byte
+}
+
+// add returns false if hash was already in the set
+func (r *reportedAssertions) add(hash [32]byte) bool {
+	if r.seen == nil {
+		r.seen = make(map[[32]byte]bool)
+	}
+	if r.seen[hash] {
+		return false
+	}
+	r.seen[hash] = true
+	r.order = append(r.order, hash)
+	if len(r.order) > reportedAssertionsLimit {
+		delete(r.seen, r.order[0])
+		r.order = r.order[1:]
+	}
+	return true
+}
+
+// bufferAssertion holds the stats for an assertion the validator is creating
+// until the transaction creating it has been sent
+func (v *Validator) bufferAssertion(stats *AssertionStats) {
+	v.pendingAssertions = append(v.pendingAssertions, stats)
+}
+
+func (v *Validator) reportBufferedAssertions() {
+	for _, stats := range v.pendingAssertions {
+		v.reportAssertion(stats)
+	}
+	v.pendingAssertions = nil
+}
+
+// reportAssertion logs and notifies listeners of stats, unless the assertion
+// has already been reported
+func (v *Validator) reportAssertion(stats *AssertionStats) {
+	if !v.reportedAssertions.add(stats.NodeHash) {
+		return
+	}
+	log := logger.Info()
+	if stats.GasLimitUsage() >= assertionLimitWarning || stats.SendLimitUsage() >= assertionLimitWarning {
+		log = logger.Warn()
+	}
+	if stats.Node != nil {
+		log = log.Str("node", stats.Node.String())
+	}
+	log.
+		Hex("hash", stats.NodeHash[:]).
+		Bool("created", stats.Created).
+		Str("gas", stats.Gas.String()).
+		Str("gasLimit", stats.GasLimit.String()).
+		Str("steps", stats.Steps.String()).
+		Str("messages", stats.MessagesRead.String()).
+		Str("sends", stats.SendCount.String()).
+		Str("logs", stats.LogCount.String()).
+		Msg("assertion stats")
+	for _, l := range v.assertionListeners {
+		l.AssertionProcessed(stats)
+	}
+}
+
+// assertionMetrics records assertion stats as metrics
+type assertionMetrics struct {
+	gas            metrics.Histogram
+	steps          metrics.Histogram
+	messages       metrics.Histogram
+	sends          metrics.Histogram
+	logs           metrics.Histogram
+	gasLimitUsage  metrics.Gauge
+	sendLimitUsage metrics.Gauge
+	nearLimit      metrics.Counter
+}
+
+func newAssertionMetrics(registry metrics.Registry) *assertionMetrics {
+	newHistogram := func(name string) metrics.Histogram {
+		return metrics.NewRegisteredHistogram(name, registry, metrics.NewExpDecaySample(1028, 0.015))
+	}
+	return &assertionMetrics{
+		gas:            newHistogram("arbitrum/staker/assertion/gas"),
+		steps:          newHistogram("arbitrum/staker/assertion/steps"),
+		messages:       newHistogram("arbitrum/staker/assertion/messages"),
+		sends:          newHistogram("arbitrum/staker/assertion/sends"),
+		logs:           newHistogram("arbitrum/staker/assertion/logs"),
+		gasLimitUsage:  metrics.NewRegisteredGauge("arbitrum/staker/assertion/gas_limit_percent", registry),
+		sendLimitUsage: metrics.NewRegisteredGauge("arbitrum/staker/assertion/send_limit_percent", registry),
+		nearLimit:      metrics.NewRegisteredCounter("arbitrum/staker/assertion/near_limit", registry),
+	}
+}
+
+func (m *assertionMetrics) AssertionProcessed(stats *AssertionStats) {
+	m.gas.Update(stats.Gas.Int64())
+	m.steps.Update(stats.Steps.Int64())
+	m.messages.Update(stats.MessagesRead.Int64())
+	m.sends.Update(stats.SendCount.Int64())
+	m.logs.Update(stats.LogCount.Int64())
+	m.gasLimitUsage.Update(int64(stats.GasLimitUsage() * 100))
+	m.sendLimitUsage.Update(int64(stats.SendLimitUsage() * 100))
+	if stats.GasLimitUsage() >= assertionLimitWarning || stats.SendLimitUsage() >= assertionLimitWarning {
+		m.nearLimit.Inc(1)
+	}
+}
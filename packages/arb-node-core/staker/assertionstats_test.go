@@ -0,0 +1,77 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"math/big"
+	"testing"
+)
+
+func testAssertionStats(hash byte) *AssertionStats {
+	return &AssertionStats{
+		NodeHash:     [32]byte{hash},
+		Gas:          big.NewInt(10),
+		Steps:        big.NewInt(20),
+		MessagesRead: big.NewInt(1),
+		SendCount:    big.NewInt(0),
+		LogCount:     big.NewInt(2),
+		GasLimit:     big.NewInt(100),
+		SendLimit:    big.NewInt(100),
+	}
+}
+
+func TestReportAssertionOncePerNode(t *testing.T) {
+	v := &Validator{}
+	var reported [][32]byte
+	v.AddAssertionListener(AssertionListenerFunc(func(stats *AssertionStats) {
+		reported = append(reported, stats.NodeHash)
+	}))
+
+	v.reportAssertion(testAssertionStats(1))
+	v.reportAssertion(testAssertionStats(1))
+	v.reportAssertion(testAssertionStats(2))
+	if len(reported) != 2 || reported[0] != [32]byte{1} || reported[1] != [32]byte{2} {
+		t.Fatalf("unexpected reports %v", reported)
+	}
+
+	// A created assertion is only reported once its transaction is sent, and
+	// not again once the staker finds the node it created
+	v.bufferAssertion(testAssertionStats(3))
+	if len(reported) != 2 {
+		t.Fatal("buffered assertion reported before being sent")
+	}
+	v.reportBufferedAssertions()
+	v.reportAssertion(testAssertionStats(3))
+	if len(reported) != 3 || reported[2] != [32]byte{3} {
+		t.Fatalf("unexpected reports %v", reported)
+	}
+}
+
+func TestReportedAssertionsBounded(t *testing.T) {
+	var r reportedAssertions
+	for i := 0; i < reportedAssertionsLimit+1; i++ {
+		if !r.add([32]byte{byte(i), byte(i >> 8)}) {
+			t.Fatal("new hash treated as already reported")
+		}
+	}
+	if len(r.seen) != reportedAssertionsLimit || len(r.order) != reportedAssertionsLimit {
+		t.Fatalf("remembered %v hashes", len(r.seen))
+	}
+	if !r.add([32]byte{}) {
+		t.Error("oldest hash should have been forgotten")
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	budgetDay  = 24 * time.Hour
+	budgetWeek = 7 * budgetDay
+)
+
+// ethToWei converts an amount of ETH from the config to wei
+func ethToWei(eth float64) *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(eth), big.NewFloat(1e18)).Int(nil)
+	return wei
+}
+
+type spendingRecord struct {
+	time   time.Time
+	amount *big.Int
+}
+
+// spendingBudget records what the staker's transactions cost over the last
+// week, so that actions which can wait are deferred once the daily or weekly
+// budget is spent. The records are only kept in memory, so spending before a
+// restart isn't counted.
+type spendingBudget struct {
+	// nil for no limit
+	daily  *big.Int
+	weekly *big.Int
+
+	mutex     sync.Mutex
+	records   []spendingRecord
+	exhausted bool
+}
+
+func newSpendingBudget(daily, weekly *big.Int) *spendingBudget {
+	return &spendingBudget{daily: daily, weekly: weekly}
+}
+
+func (b *spendingBudget) record(now time.Time, amount *big.Int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.records = append(b.records, spendingRecord{time: now, amount: amount})
+	for len(b.records) > 0 && now.Sub(b.records[0].time) >= budgetWeek {
+		b.records = b.records[1:]
+	}
+}
+
+func (b *spendingBudget) spentWithin(now time.Time, period time.Duration) *big.Int {
+	spent := big.NewInt(0)
+	for _, record := range b.records {
+		if now.Sub(record.time) < period {
+			spent.Add(spent, record.amount)
+		}
+	}
+	return spent
+}
+
+// spent returns how much was spent in the last day and week
+func (b *spendingBudget) spent(now time.Time) (*big.Int, *big.Int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.spentWithin(now, budgetDay), b.spentWithin(now, budgetWeek)
+}
+
+// remaining returns how much can still be spent before either budget is
+// exceeded, or nil if there's no budget
+func (b *spendingBudget) remaining(now time.Time) *big.Int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	var remaining *big.Int
+	for _, limit := range []struct {
+		amount *big.Int
+		period time.Duration
+	}{{b.daily, budgetDay}, {b.weekly, budgetWeek}} {
+		if limit.amount == nil {
+			continue
+		}
+		left := new(big.Int).Sub(limit.amount, b.spentWithin(now, limit.period))
+		if left.Sign() < 0 {
+			left.SetInt64(0)
+		}
+		if remaining == nil || left.Cmp(remaining) < 0 {
+			remaining = left
+		}
+	}
+	return remaining
+}
+
+// setExhausted records whether the budget is too low for routine actions,
+// returning true if that just became the case
+func (b *spendingBudget) setExhausted(exhausted bool) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	newlyExhausted := exhausted && !b.exhausted
+	b.exhausted = exhausted
+	return newlyExhausted
+}
+
+// GasBudgetAlert is POSTed to the configured alert url when the staker's
+// spending budget is too low for actions which can be deferred
+type GasBudgetAlert struct {
+	Wallet        string   `json:"wallet"`
+	SpentToday    *big.Int `json:"spentToday"`
+	SpentThisWeek *big.Int `json:"spentThisWeek"`
+	Remaining     *big.Int `json:"remaining"`
+}
+
+func (s *Staker) alertBudgetExhausted(ctx context.Context, remaining *big.Int) {
+	day, week := s.spending.spent(time.Now())
+	alert := GasBudgetAlert{
+		Wallet:        s.wallet.From().Hex(),
+		SpentToday:    day,
+		SpentThisWeek: week,
+		Remaining:     remaining,
+	}
+	logger.Error().
+		Str("spentToday", day.String()).
+		Str("spentThisWeek", week.String()).
+		Str("remaining", remaining.String()).
+		Msg("gas budget exhausted, deferring actions which can wait")
+	if s.config.GasBudget.AlertURL == "" {
+		return
+	}
+	if err := postJSON(ctx, s.config.GasBudget.AlertURL, alert, nil); err != nil {
+		logger.Warn().Err(err).Msg("failed to send gas budget alert")
+	}
+}
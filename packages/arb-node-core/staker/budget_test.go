@@ -0,0 +1,70 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestSpendingBudget(t *testing.T) {
+	start := time.Unix(1_600_000_000, 0)
+
+	if newSpendingBudget(nil, nil).remaining(start) != nil {
+		t.Error("unlimited budget has a remaining amount")
+	}
+
+	budget := newSpendingBudget(big.NewInt(100), big.NewInt(250))
+	checkRemaining := func(now time.Time, expected int64) {
+		t.Helper()
+		if remaining := budget.remaining(now); remaining.Cmp(big.NewInt(expected)) != 0 {
+			t.Errorf("expected %v remaining, got %v", expected, remaining)
+		}
+	}
+	checkRemaining(start, 100)
+
+	budget.record(start, big.NewInt(60))
+	checkRemaining(start, 40)
+	budget.record(start.Add(time.Hour), big.NewInt(60))
+	checkRemaining(start.Add(time.Hour), 0)
+
+	// The daily budget recovers, but the weekly budget is now the limit
+	nextDay := start.Add(25 * time.Hour)
+	checkRemaining(nextDay, 100)
+	budget.record(nextDay, big.NewInt(100))
+	checkRemaining(nextDay.Add(24*time.Hour), 30)
+
+	day, week := budget.spent(nextDay)
+	if day.Cmp(big.NewInt(100)) != 0 || week.Cmp(big.NewInt(220)) != 0 {
+		t.Errorf("unexpected spending %v today and %v this week", day, week)
+	}
+
+	// Old records are dropped once they leave the weekly window
+	budget.record(start.Add(budgetWeek), big.NewInt(0))
+	if len(budget.records) != 3 {
+		t.Errorf("expected 3 records, got %v", len(budget.records))
+	}
+	checkRemaining(start.Add(budgetWeek+2*time.Hour), 100)
+
+	if !budget.setExhausted(true) || budget.setExhausted(true) {
+		t.Error("exhaustion should only be reported when it starts")
+	}
+	if budget.setExhausted(false) || !budget.setExhausted(true) {
+		t.Error("exhaustion should be reported again after the budget recovers")
+	}
+}
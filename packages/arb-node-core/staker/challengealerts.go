@@ -0,0 +1,159 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+)
+
+type ChallengeAlertLevel string
+
+const (
+	ChallengeAlertNone     ChallengeAlertLevel = ""
+	ChallengeAlertWarning  ChallengeAlertLevel = "warning"
+	ChallengeAlertCritical ChallengeAlertLevel = "critical"
+)
+
+func (l ChallengeAlertLevel) severity() int {
+	switch l {
+	case ChallengeAlertWarning:
+		return 1
+	case ChallengeAlertCritical:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// ChallengeDeadlineAlert is sent when our move in a challenge is getting
+// close to its deadline without having been posted
+type ChallengeDeadlineAlert struct {
+	Level           ChallengeAlertLevel `json:"level"`
+	Challenge       ethcommon.Address   `json:"challenge"`
+	DeadlineBlock   *big.Int            `json:"deadlineBlock"`
+	BlocksRemaining int64               `json:"blocksRemaining"`
+	// SecondsRemaining estimates the time left from the L1 block time
+	SecondsRemaining int64 `json:"secondsRemaining"`
+}
+
+// challengeMonitor follows the turns of the active challenge, measuring how
+// long our opponent takes to respond and alerting, at increasing levels, as
+// the deadline of our own move approaches. It's only accessed from the
+// staker's thread.
+type challengeMonitor struct {
+	config configuration.ValidatorChallengeAlerts
+
+	challenge common.Address
+	ourTurn   bool
+	// lastMoveBlock is the block of the last move we've seen, which is
+	// when the current turn started
+	lastMoveBlock *big.Int
+	alertLevel    ChallengeAlertLevel
+}
+
+func (m *challengeMonitor) levelFor(blocksRemaining int64) ChallengeAlertLevel {
+	if m.config.CriticalBlocks > 0 && blocksRemaining <= m.config.CriticalBlocks {
+		return ChallengeAlertCritical
+	}
+	if m.config.WarnBlocks > 0 && blocksRemaining <= m.config.WarnBlocks {
+		return ChallengeAlertWarning
+	}
+	return ChallengeAlertNone
+}
+
+// observeChallenge updates the challenge monitor with the state of the
+// active challenge as of currentBlock. deadline is the deadline of our move,
+// or nil if it's our opponent's turn.
+func (s *Staker) observeChallenge(ctx context.Context, deadline *big.Int, currentBlock *big.Int) {
+	m := &s.challengeMonitor
+	challengeAddress := s.activeChallenge.ChallengeAddress()
+	if m.challenge != challengeAddress {
+		*m = challengeMonitor{config: m.config, challenge: challengeAddress}
+	}
+	lastMoveBlock, err := s.activeChallenge.LastMoveBlock(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to get challenge's last move")
+		return
+	}
+	ourTurn := deadline != nil
+	newTurn := m.lastMoveBlock == nil || m.lastMoveBlock.Cmp(lastMoveBlock) != 0
+	if newTurn && ourTurn && m.lastMoveBlock != nil && !m.ourTurn {
+		responseBlocks := new(big.Int).Sub(lastMoveBlock, m.lastMoveBlock).Int64()
+		logger.Info().
+			Str("challenge", challengeAddress.String()).
+			Int64("blocks", responseBlocks).
+			Msg("opponent responded in challenge")
+		if s.metrics != nil {
+			s.metrics.opponentResponseBlocks.Update(responseBlocks)
+		}
+	}
+	if newTurn {
+		m.alertLevel = ChallengeAlertNone
+	}
+	m.ourTurn = ourTurn
+	m.lastMoveBlock = lastMoveBlock
+
+	if !ourTurn || currentBlock == nil {
+		if s.metrics != nil {
+			s.metrics.challengeBlocksRemaining.Update(0)
+		}
+		return
+	}
+	blocksRemaining := new(big.Int).Sub(deadline, currentBlock).Int64()
+	// The deadline is the last block our move can be made in
+	lastMoveEnd := common.NewTimeBlocks(deadline).Add(common.NewTimeBlocksInt(1))
+	timeRemaining := lastMoveEnd.DurationUntil(common.NewTimeBlocks(currentBlock), s.l1BlockTime())
+	if s.metrics != nil {
+		s.metrics.challengeBlocksRemaining.Update(blocksRemaining)
+	}
+	level := m.levelFor(blocksRemaining)
+	if level.severity() <= m.alertLevel.severity() {
+		return
+	}
+	alert := &ChallengeDeadlineAlert{
+		Level:            level,
+		Challenge:        challengeAddress.ToEthAddress(),
+		DeadlineBlock:    deadline,
+		BlocksRemaining:  blocksRemaining,
+		SecondsRemaining: int64(timeRemaining / time.Second),
+	}
+	event := logger.Warn()
+	if level == ChallengeAlertCritical {
+		event = logger.Error()
+	}
+	event.
+		Str("challenge", challengeAddress.String()).
+		Str("deadline", deadline.String()).
+		Int64("blocksRemaining", blocksRemaining).
+		Dur("timeRemaining", timeRemaining).
+		Msg("challenge move deadline approaching with no response posted")
+	if m.config.WebhookURL != "" {
+		if err := postJSON(ctx, m.config.WebhookURL, alert, nil); err != nil {
+			logger.Warn().Err(err).Msg("failed to send challenge deadline alert")
+			// Retry the webhook on the next action
+			return
+		}
+	}
+	m.alertLevel = level
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+)
+
+func TestChallengeAlertLevels(t *testing.T) {
+	m := &challengeMonitor{config: configuration.ValidatorChallengeAlerts{WarnBlocks: 2000, CriticalBlocks: 500}}
+	cases := []struct {
+		blocksRemaining int64
+		level           ChallengeAlertLevel
+	}{
+		{5000, ChallengeAlertNone},
+		{2001, ChallengeAlertNone},
+		{2000, ChallengeAlertWarning},
+		{501, ChallengeAlertWarning},
+		{500, ChallengeAlertCritical},
+		{-10, ChallengeAlertCritical},
+	}
+	for _, c := range cases {
+		if level := m.levelFor(c.blocksRemaining); level != c.level {
+			t.Errorf("%v blocks remaining gave level %q, expected %q", c.blocksRemaining, level, c.level)
+		}
+	}
+	if ChallengeAlertCritical.severity() <= ChallengeAlertWarning.severity() || ChallengeAlertWarning.severity() <= ChallengeAlertNone.severity() {
+		t.Error("alert levels don't escalate")
+	}
+
+	m.config.CriticalBlocks = 0
+	if level := m.levelFor(100); level != ChallengeAlertWarning {
+		t.Errorf("disabled critical level gave %q", level)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+// confirmationData is everything needed to confirm a node
+type confirmationData struct {
+	nodeInfo *core.NodeInfo
+	sends    [][]byte
+	sendSize int
+}
+
+// confirmationCache keeps the confirmation data of unresolved nodes between
+// staker actions, as looking up a node's creation event and sends is expensive
+// and would otherwise be repeated for as long as its confirmation is
+// postponed. A node's contents can only change in an L1 reorg, so entries
+// are kept until the node is resolved or a reorg is seen.
+type confirmationCache struct {
+	nodes map[uint64]*confirmationData
+}
+
+func (c *confirmationCache) get(node *big.Int) *confirmationData {
+	if !node.IsUint64() {
+		return nil
+	}
+	return c.nodes[node.Uint64()]
+}
+
+func (c *confirmationCache) add(node *big.Int, data *confirmationData) {
+	if !node.IsUint64() {
+		return
+	}
+	if c.nodes == nil {
+		c.nodes = make(map[uint64]*confirmationData)
+	}
+	c.nodes[node.Uint64()] = data
+}
+
+// prune drops the entries for resolved nodes
+func (c *confirmationCache) prune(firstUnresolved *big.Int) {
+	for node := range c.nodes {
+		if new(big.Int).SetUint64(node).Cmp(firstUnresolved) < 0 {
+			delete(c.nodes, node)
+		}
+	}
+}
+
+func (c *confirmationCache) reset() {
+	c.nodes = nil
+}
+
+func (v *Validator) lookupConfirmationData(ctx context.Context, node *big.Int) (*confirmationData, error) {
+	if data := v.confirmations.get(node); data != nil {
+		return data, nil
+	}
+	nodeInfo, err := v.rollup.RollupWatcher.LookupNode(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	sendCount := new(big.Int).Sub(nodeInfo.Assertion.After.TotalSendCount, nodeInfo.Assertion.Before.TotalSendCount)
+	sends, err := v.lookup.GetSends(nodeInfo.Assertion.Before.TotalSendCount, sendCount)
+	if err != nil {
+		return nil, errors.Wrap(err, "catching up to chain")
+	}
+	data := &confirmationData{nodeInfo: nodeInfo, sends: sends}
+	for _, send := range sends {
+		data.sendSize += 32 + len(send)
+	}
+	v.confirmations.add(node, data)
+	return data, nil
+}
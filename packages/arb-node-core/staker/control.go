@@ -0,0 +1,58 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"sync/atomic"
+)
+
+// stakerControl holds operator requests made while the staker is running
+type stakerControl struct {
+	paused       int32
+	forceConfirm int32
+}
+
+// Pause stops the staker from sending any transactions, including challenge
+// moves, until Resume is called
+func (s *Staker) Pause() {
+	if atomic.SwapInt32(&s.control.paused, 1) == 0 {
+		logger.Warn().Msg("staker paused")
+	}
+}
+
+func (s *Staker) Resume() {
+	if atomic.SwapInt32(&s.control.paused, 0) == 1 {
+		logger.Info().Msg("staker resumed")
+		s.Wake()
+	}
+}
+
+func (s *Staker) Paused() bool {
+	return atomic.LoadInt32(&s.control.paused) == 1
+}
+
+// ForceConfirmation makes the staker try to resolve the next node right away,
+// whatever its strategy and even if the gas price would otherwise postpone it
+func (s *Staker) ForceConfirmation() {
+	atomic.StoreInt32(&s.control.forceConfirm, 1)
+	s.Wake()
+}
+
+// takeForceConfirmation must only be called from the staker's thread
+func (s *Staker) takeForceConfirmation() bool {
+	return atomic.SwapInt32(&s.control.forceConfirm, 0) == 1
+}
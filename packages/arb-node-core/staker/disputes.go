@@ -0,0 +1,220 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+// DisputedNode is an unresolved node whose assertion doesn't match the local
+// database, and so needs to be challenged before it's confirmed
+type DisputedNode struct {
+	Node          *big.Int       `json:"node"`
+	Hash          ethcommon.Hash `json:"hash"`
+	DeadlineBlock *big.Int       `json:"deadlineBlock"`
+	// BlocksRemaining is how many L1 blocks are left until the node can be
+	// confirmed, which is negative once the deadline has passed
+	BlocksRemaining int64 `json:"blocksRemaining"`
+}
+
+// DisputeWatcher scans unresolved nodes for assertions the local database
+// disagrees with. It never sends transactions, so it alerts operators even
+// when the staker is only watching or has challenges disabled.
+type DisputeWatcher struct {
+	rollup *ethbridge.RollupWatcher
+	lookup core.ArbCoreLookup
+	client ethutils.EthClient
+	config configuration.ValidatorDisputeAlerts
+
+	pipeline *ValidationPipeline
+
+	// Only accessed from the watcher's thread
+	validNodes map[common.Hash]bool
+	alerted    map[common.Hash]bool
+
+	disputedNodes  metrics.Gauge
+	blocksToExpiry metrics.Gauge
+}
+
+func NewDisputeWatcher(
+	rollup *ethbridge.RollupWatcher,
+	lookup core.ArbCoreLookup,
+	client ethutils.EthClient,
+	config configuration.ValidatorDisputeAlerts,
+	validationWorkers int,
+	registry metrics.Registry,
+) *DisputeWatcher {
+	return &DisputeWatcher{
+		rollup:         rollup,
+		lookup:         lookup,
+		client:         client,
+		config:         config,
+		pipeline:       NewValidationPipeline(lookup, validationWorkers),
+		validNodes:     make(map[common.Hash]bool),
+		alerted:        make(map[common.Hash]bool),
+		disputedNodes:  metrics.NewRegisteredGauge("arbitrum/validator/disputed_nodes", registry),
+		blocksToExpiry: metrics.NewRegisteredGauge("arbitrum/validator/dispute_blocks_remaining", registry),
+	}
+}
+
+// Check returns every unresolved node which doesn't match the local database.
+// Nodes reading messages the database hasn't processed yet are left for a
+// later check.
+func (w *DisputeWatcher) Check(ctx context.Context) ([]*DisputedNode, error) {
+	firstUnresolved, err := w.rollup.FirstUnresolvedNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latestCreated, err := w.rollup.LatestNodeCreated(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currentBlock, err := w.client.BlockInfoByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var nodes []*core.NodeInfo
+	for num := new(big.Int).Set(firstUnresolved); num.Cmp(latestCreated) <= 0; num.Add(num, big.NewInt(1)) {
+		nd, err := w.rollup.LookupNode(ctx, num)
+		if err != nil {
+			return nil, err
+		}
+		if _, checked := w.validNodes[nd.NodeHash]; !checked && w.lookup.MachineMessagesRead().Cmp(nd.Assertion.After.TotalMessagesRead) < 0 {
+			break
+		}
+		nodes = append(nodes, nd)
+	}
+
+	// Check the nodes which haven't been seen before together, then merge
+	// the results into those already known
+	var unchecked []*core.NodeInfo
+	for _, nd := range nodes {
+		if _, checked := w.validNodes[nd.NodeHash]; !checked {
+			unchecked = append(unchecked, nd)
+		}
+	}
+	validations, err := w.pipeline.Validate(ctx, unchecked)
+	if err != nil {
+		return nil, err
+	}
+	validNodes := make(map[common.Hash]bool)
+	for _, validation := range validations {
+		validNodes[validation.Node.NodeHash] = validation.Valid
+	}
+
+	var disputed []*DisputedNode
+	for _, nd := range nodes {
+		valid, checked := validNodes[nd.NodeHash]
+		if !checked {
+			valid = w.validNodes[nd.NodeHash]
+			validNodes[nd.NodeHash] = valid
+		}
+		if valid {
+			continue
+		}
+		num := (*big.Int)(nd.NodeNum)
+		node, err := w.rollup.GetNode(ctx, num)
+		if err != nil {
+			return nil, err
+		}
+		deadline, err := node.DeadlineBlock(ctx)
+		if err != nil {
+			return nil, err
+		}
+		disputed = append(disputed, &DisputedNode{
+			Node:            new(big.Int).Set(num),
+			Hash:            nd.NodeHash.ToEthHash(),
+			DeadlineBlock:   deadline,
+			BlocksRemaining: new(big.Int).Sub(deadline, currentBlock.Number.ToInt()).Int64(),
+		})
+	}
+	// Forget resolved nodes
+	w.validNodes = validNodes
+	return disputed, nil
+}
+
+func (w *DisputeWatcher) update(ctx context.Context) error {
+	disputed, err := w.Check(ctx)
+	if err != nil {
+		return err
+	}
+	w.disputedNodes.Update(int64(len(disputed)))
+	blocksToExpiry := int64(0)
+	alerted := make(map[common.Hash]bool)
+	for i, nd := range disputed {
+		if i == 0 || nd.BlocksRemaining < blocksToExpiry {
+			blocksToExpiry = nd.BlocksRemaining
+		}
+		hash := common.NewHashFromEth(nd.Hash)
+		alerted[hash] = true
+		if w.alerted[hash] {
+			continue
+		}
+		logger.Error().
+			Str("node", nd.Node.String()).
+			Hex("hash", nd.Hash.Bytes()).
+			Str("deadline", nd.DeadlineBlock.String()).
+			Int64("blocksRemaining", nd.BlocksRemaining).
+			Msg("found incorrect assertion which must be challenged")
+		if err := w.sendWebhook(ctx, nd); err != nil {
+			logger.Warn().Err(err).Str("node", nd.Node.String()).Msg("failed to send dispute alert")
+			// Retry the webhook on the next check
+			delete(alerted, hash)
+		}
+	}
+	w.blocksToExpiry.Update(blocksToExpiry)
+	w.alerted = alerted
+	return nil
+}
+
+func (w *DisputeWatcher) sendWebhook(ctx context.Context, nd *DisputedNode) error {
+	if w.config.WebhookURL == "" {
+		return nil
+	}
+	return postJSON(ctx, w.config.WebhookURL, nd, nil)
+}
+
+// RunInBackground checks for disputed nodes every configured interval until
+// ctx is cancelled
+func (w *DisputeWatcher) RunInBackground(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.config.Interval)
+		defer ticker.Stop()
+		for {
+			if err := w.update(ctx); err != nil {
+				logger.Warn().Err(err).Msg("error checking for disputed nodes")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+)
+
+// EscalationRequest describes an inactive validator going active because it
+// found an incorrect assertion
+type EscalationRequest struct {
+	// Node is the node the validator will stake on or past
+	Node          *big.Int `json:"node"`
+	RequiredStake *big.Int `json:"requiredStake"`
+}
+
+// EscalationHook is asked before an inactive validator stakes because of an
+// incorrect assertion. The staker asks again on its next action if the hook
+// doesn't approve.
+type EscalationHook func(ctx context.Context, request EscalationRequest) (bool, error)
+
+// NewWebhookEscalationHook approves escalations by POSTing the request as
+// json to url, which must respond with {"approved": true}
+func NewWebhookEscalationHook(url string) EscalationHook {
+	return func(ctx context.Context, request EscalationRequest) (bool, error) {
+		var response struct {
+			Approved bool `json:"approved"`
+		}
+		if err := postJSON(ctx, url, request, &response); err != nil {
+			return false, err
+		}
+		return response.Approved, nil
+	}
+}
+
+// SetEscalationHook makes the staker ask hook before going active because of
+// an incorrect assertion
+func (s *Staker) SetEscalationHook(hook EscalationHook) {
+	s.escalationHook = hook
+}
+
+// bringActive starts staking up to node because of an incorrect assertion, if
+// the escalation hook approves
+func (s *Staker) bringActive(ctx context.Context, node *big.Int) error {
+	if s.escalationHook != nil {
+		requiredStake, err := s.rollup.CurrentRequiredStake(ctx)
+		if err != nil {
+			return err
+		}
+		approved, err := s.escalationHook(ctx, EscalationRequest{Node: copyInt(node), RequiredStake: requiredStake})
+		if err != nil {
+			logger.Warn().Err(err).Str("node", node.String()).Msg("failed to ask for approval to stake against incorrect assertion")
+			return nil
+		}
+		if !approved {
+			logger.Warn().Str("node", node.String()).Msg("staking against incorrect assertion not approved yet")
+			return nil
+		}
+	}
+	logger.Warn().Msg("bringing defensive validator online because of incorrect assertion")
+	s.bringActiveUntilNode = node
+	return nil
+}
+
+// stakeWithinLimit checks amount against the configured maximum stake
+func (s *Staker) stakeWithinLimit(amount *big.Int) bool {
+	if s.maxStake == nil || amount.Cmp(s.maxStake) <= 0 {
+		return true
+	}
+	logger.Error().Str("required", amount.String()).Str("max", s.maxStake.String()).Msg("not staking as required stake is above configured maximum")
+	return false
+}
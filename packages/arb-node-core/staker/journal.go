@@ -0,0 +1,175 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+type JournalEvent string
+
+const (
+	JournalNodeValidated     JournalEvent = "node_validated"
+	JournalNodeIncorrect     JournalEvent = "node_incorrect"
+	JournalNodeCreated       JournalEvent = "node_created"
+	JournalStakeMoved        JournalEvent = "stake_moved"
+	JournalNewStake          JournalEvent = "new_stake"
+	JournalNodeConfirmed     JournalEvent = "node_confirmed"
+	JournalNodeRejected      JournalEvent = "node_rejected"
+	JournalChallengeCreated  JournalEvent = "challenge_created"
+	JournalChallengeEntered  JournalEvent = "challenge_entered"
+	JournalTransactionMined  JournalEvent = "transaction_mined"
+	JournalTransactionFailed JournalEvent = "transaction_failed"
+)
+
+// JournalEntry records a single staker decision. Decisions which are part of a
+// transaction are recorded with its hash once it's sent, so they're only final
+// once a following transaction entry shows that transaction was mined.
+type JournalEntry struct {
+	Time time.Time `json:"time"`
+	// Block is the latest L1 block when the staker started the action
+	Block     *big.Int           `json:"block,omitempty"`
+	Event     JournalEvent       `json:"event"`
+	Node      *big.Int           `json:"node,omitempty"`
+	NodeHash  *ethcommon.Hash    `json:"nodeHash,omitempty"`
+	OtherNode *big.Int           `json:"otherNode,omitempty"`
+	Staker    *ethcommon.Address `json:"staker,omitempty"`
+	Challenge *ethcommon.Address `json:"challenge,omitempty"`
+	Amount    *big.Int           `json:"amount,omitempty"`
+	TxHash    *ethcommon.Hash    `json:"txHash,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// Journal appends staker decisions to a file, one json encoded entry per line,
+// for reconstructing what a validator did after the fact. All methods are safe
+// to call on a nil Journal, which records nothing.
+type Journal struct {
+	mutex sync.Mutex
+	file  *os.File
+	block *big.Int
+	// pending holds the decisions behind the transaction being built
+	pending []JournalEntry
+}
+
+func nodeEntry(event JournalEvent, node *big.Int, hash common.Hash) JournalEntry {
+	nodeHash := hash.ToEthHash()
+	return JournalEntry{Event: event, Node: copyInt(node), NodeHash: &nodeHash}
+}
+
+func NewJournal(filename string) (*Journal, error) {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open staker journal")
+	}
+	return &Journal{file: file}, nil
+}
+
+// setBlock sets the L1 block recorded with following entries
+func (j *Journal) setBlock(block *big.Int) {
+	if j == nil {
+		return
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.block = copyInt(block)
+}
+
+// Buffer holds entry, a decision behind the transaction being built, until
+// the transaction is sent
+func (j *Journal) Buffer(entry JournalEntry) {
+	if j == nil {
+		return
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.pending = append(j.pending, entry)
+}
+
+// Commit records the buffered entries with the hash of the transaction which
+// was sent for them
+func (j *Journal) Commit(txHash ethcommon.Hash) {
+	if j == nil {
+		return
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	for _, entry := range j.pending {
+		entry.TxHash = &txHash
+		j.write(entry)
+	}
+	j.pending = nil
+}
+
+// Discard drops the buffered entries, as their transaction won't be sent
+func (j *Journal) Discard() {
+	if j == nil {
+		return
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.pending = nil
+}
+
+// Record appends entry to the journal, filling in its time and block.
+// Failures are logged rather than returned so that a full disk doesn't stop
+// the staker from acting.
+func (j *Journal) Record(entry JournalEntry) {
+	if j == nil {
+		return
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.write(entry)
+}
+
+// write must be called with the mutex held
+func (j *Journal) write(entry JournalEntry) {
+	entry.Time = time.Now()
+	if entry.Block == nil {
+		entry.Block = j.block
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logger.Error().Err(err).Str("event", string(entry.Event)).Msg("failed to encode journal entry")
+		return
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		logger.Error().Err(err).Str("event", string(entry.Event)).Msg("failed to write journal entry")
+		return
+	}
+	if err := j.file.Sync(); err != nil {
+		logger.Error().Err(err).Str("event", string(entry.Event)).Msg("failed to sync journal")
+	}
+}
+
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return errors.WithStack(j.file.Close())
+}
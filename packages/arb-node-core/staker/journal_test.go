@@ -0,0 +1,114 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func readJournal(t *testing.T, filename string) []JournalEntry {
+	file, err := os.Open(filename)
+	test.FailIfError(t, err)
+	defer file.Close()
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry JournalEntry
+		test.FailIfError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	test.FailIfError(t, scanner.Err())
+	return entries
+}
+
+func TestJournalAppends(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "journal")
+
+	journal, err := NewJournal(filename)
+	test.FailIfError(t, err)
+	journal.setBlock(big.NewInt(100))
+	journal.Record(nodeEntry(JournalNodeValidated, big.NewInt(5), common.Hash{1}))
+	journal.Record(JournalEntry{Event: JournalNodeRejected, Block: big.NewInt(101), Node: big.NewInt(6)})
+	test.FailIfError(t, journal.Close())
+
+	// Reopening the journal must not truncate it
+	journal, err = NewJournal(filename)
+	test.FailIfError(t, err)
+	journal.Record(JournalEntry{Event: JournalNewStake, Amount: big.NewInt(1)})
+	test.FailIfError(t, journal.Close())
+
+	entries := readJournal(t, filename)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %v", len(entries))
+	}
+	if entries[0].Event != JournalNodeValidated || entries[0].Block.Cmp(big.NewInt(100)) != 0 || entries[0].Node.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("unexpected first entry %+v", entries[0])
+	}
+	if entries[0].NodeHash == nil || *entries[0].NodeHash != (common.Hash{1}).ToEthHash() {
+		t.Errorf("unexpected node hash in first entry %+v", entries[0])
+	}
+	if entries[1].Block.Cmp(big.NewInt(101)) != 0 {
+		t.Errorf("explicit block overwritten %+v", entries[1])
+	}
+	if entries[2].Event != JournalNewStake || entries[2].Block != nil || entries[2].Time.IsZero() {
+		t.Errorf("unexpected last entry %+v", entries[2])
+	}
+}
+
+func TestJournalBuffersUntilSent(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "journal")
+
+	journal, err := NewJournal(filename)
+	test.FailIfError(t, err)
+	journal.Buffer(JournalEntry{Event: JournalNewStake, Amount: big.NewInt(1)})
+	journal.Discard()
+	journal.Buffer(nodeEntry(JournalNodeConfirmed, big.NewInt(5), common.Hash{1}))
+	journal.Buffer(nodeEntry(JournalNodeConfirmed, big.NewInt(6), common.Hash{2}))
+	txHash := ethcommon.Hash{3}
+	journal.Commit(txHash)
+	test.FailIfError(t, journal.Close())
+
+	entries := readJournal(t, filename)
+	if len(entries) != 2 {
+		t.Fatalf("expected only the committed entries, got %v", entries)
+	}
+	for i, entry := range entries {
+		if entry.Event != JournalNodeConfirmed || entry.TxHash == nil || *entry.TxHash != txHash {
+			t.Errorf("unexpected entry %v %+v", i, entry)
+		}
+	}
+}
+
+func TestNilJournal(t *testing.T) {
+	var journal *Journal
+	journal.setBlock(big.NewInt(1))
+	journal.Record(JournalEntry{Event: JournalNewStake})
+	journal.Buffer(JournalEntry{Event: JournalNewStake})
+	journal.Commit(ethcommon.Hash{})
+	journal.Discard()
+	test.FailIfError(t, journal.Close())
+}
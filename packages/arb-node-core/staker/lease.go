@@ -0,0 +1,276 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/transactauth"
+)
+
+// LeaseStore lets several validator processes sharing one staking key agree
+// on which of them is the leader sending transactions. The others follow,
+// validating without acting, until the leader's lease expires.
+type LeaseStore interface {
+	// Acquire takes or renews the lease for holder until now plus duration,
+	// returning false if another holder has an unexpired lease
+	Acquire(ctx context.Context, holder string, duration time.Duration) (bool, error)
+	// Release gives up the lease if holder has it
+	Release(ctx context.Context, holder string) error
+}
+
+type lease struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+// staleLockAge is how old a lease file lock must be before it's assumed to
+// have been left behind by a crashed process
+const staleLockAge = 10 * time.Second
+
+// FileLeaseStore keeps the lease in a json file, which must be on storage
+// shared by all the validator processes
+type FileLeaseStore struct {
+	filename string
+}
+
+func NewFileLeaseStore(filename string) *FileLeaseStore {
+	return &FileLeaseStore{filename: filename}
+}
+
+// lock creates a lock file so that only one process reads and writes the
+// lease at a time, returning a function which removes it
+func (f *FileLeaseStore) lock() (func(), error) {
+	lockFilename := f.filename + ".lock"
+	lockFile, err := os.OpenFile(lockFilename, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		info, statErr := os.Stat(lockFilename)
+		if statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			logger.Warn().Str("file", lockFilename).Msg("removing stale lease lock")
+			_ = os.Remove(lockFilename)
+		}
+		return nil, errors.New("lease file is locked")
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	_ = lockFile.Close()
+	return func() {
+		_ = os.Remove(lockFilename)
+	}, nil
+}
+
+func (f *FileLeaseStore) read() (*lease, error) {
+	data, err := ioutil.ReadFile(f.filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read lease")
+	}
+	var current lease
+	if err := json.Unmarshal(data, &current); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal lease")
+	}
+	return &current, nil
+}
+
+func (f *FileLeaseStore) write(current *lease) error {
+	data, err := json.Marshal(current)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	tmpFilename := f.filename + ".tmp"
+	if err := ioutil.WriteFile(tmpFilename, data, 0600); err != nil {
+		return errors.Wrap(err, "failed to write lease")
+	}
+	return errors.Wrap(os.Rename(tmpFilename, f.filename), "failed to write lease")
+}
+
+func (f *FileLeaseStore) Acquire(_ context.Context, holder string, duration time.Duration) (bool, error) {
+	unlock, err := f.lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	current, err := f.read()
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if current != nil && current.Holder != holder && now.Before(current.Expires) {
+		return false, nil
+	}
+	return true, f.write(&lease{Holder: holder, Expires: now.Add(duration)})
+}
+
+func (f *FileLeaseStore) Release(_ context.Context, holder string) error {
+	unlock, err := f.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	current, err := f.read()
+	if err != nil || current == nil || current.Holder != holder {
+		return err
+	}
+	return errors.Wrap(os.Remove(f.filename), "failed to release lease")
+}
+
+// WebhookLeaseStore asks an external lock service for the lease by POSTing
+// {"holder", "durationSeconds", "release"} as json to url, which must respond
+// with {"acquired": bool}
+type WebhookLeaseStore struct {
+	url string
+}
+
+func NewWebhookLeaseStore(url string) *WebhookLeaseStore {
+	return &WebhookLeaseStore{url: url}
+}
+
+type leaseRequest struct {
+	Holder          string `json:"holder"`
+	DurationSeconds int64  `json:"durationSeconds"`
+	Release         bool   `json:"release"`
+}
+
+func (w *WebhookLeaseStore) Acquire(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	var response struct {
+		Acquired bool `json:"acquired"`
+	}
+	request := leaseRequest{Holder: holder, DurationSeconds: int64(duration / time.Second)}
+	if err := postJSON(ctx, w.url, request, &response); err != nil {
+		return false, err
+	}
+	return response.Acquired, nil
+}
+
+func (w *WebhookLeaseStore) Release(ctx context.Context, holder string) error {
+	return postJSON(ctx, w.url, leaseRequest{Holder: holder, Release: true}, nil)
+}
+
+// leaseCoordinator tracks whether this staker is the leader
+type leaseCoordinator struct {
+	store    LeaseStore
+	holder   string
+	duration time.Duration
+	leader   int32
+}
+
+// SetLeaseStore makes the staker only act while it holds the lease in store,
+// renewing it for duration before every action and while waiting for its
+// transaction to be mined. duration must be longer than an action takes to
+// build and send. It must be called before RunInBackground.
+func (s *Staker) SetLeaseStore(store LeaseStore, holder string, duration time.Duration) {
+	s.lease = &leaseCoordinator{store: store, holder: holder, duration: duration}
+}
+
+// IsLeader returns whether the staker sends transactions, which is always the
+// case unless it shares its key through a lease store
+func (s *Staker) IsLeader() bool {
+	return s.lease == nil || atomic.LoadInt32(&s.lease.leader) == 1
+}
+
+// acquireLease must only be called from the staker's thread
+func (s *Staker) acquireLease(ctx context.Context) bool {
+	if s.lease == nil {
+		return true
+	}
+	acquired, err := s.lease.store.Acquire(ctx, s.lease.holder, s.lease.duration)
+	if err != nil {
+		// Without knowing who holds the lease it isn't safe to act
+		logger.Warn().Err(err).Msg("failed to renew validator lease")
+		acquired = false
+	}
+	wasLeader := atomic.SwapInt32(&s.lease.leader, boolToInt32(acquired)) == 1
+	if acquired && !wasLeader {
+		// The previous leader sent transactions from the same account, so the
+		// nonce we had while following is stale
+		if err := transactauth.ResyncNonce(ctx, s.client, s.auth); err != nil {
+			logger.Warn().Err(err).Msg("failed to resync nonce after acquiring validator lease")
+			atomic.StoreInt32(&s.lease.leader, 0)
+			return false
+		}
+		logger.Warn().Str("holder", s.lease.holder).Msg("acquired validator lease, taking over as leader")
+		// Anything the previous leader left in flight is resolved on L1, so
+		// cached state from while following may be stale
+		s.HandleReorg(ctx, nil)
+	} else if !acquired && wasLeader {
+		logger.Warn().Str("holder", s.lease.holder).Msg("lost validator lease, following")
+	}
+	return acquired
+}
+
+// holdLease keeps renewing the lease, if the staker is the leader, until the
+// returned function is called. It's used while waiting for a transaction to
+// be mined, which may take longer than the lease lasts.
+func (s *Staker) holdLease(ctx context.Context) func() {
+	if s.lease == nil || !s.IsLeader() {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(s.lease.duration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			acquired, err := s.lease.store.Acquire(ctx, s.lease.holder, s.lease.duration)
+			if err != nil {
+				logger.Warn().Err(err).Msg("failed to renew validator lease while waiting for transaction")
+			} else if !acquired {
+				// Nothing can be done about the transaction already sent, but
+				// the next action won't be taken without the lease
+				atomic.StoreInt32(&s.lease.leader, 0)
+				logger.Warn().Str("holder", s.lease.holder).Msg("lost validator lease while waiting for transaction")
+				return
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (s *Staker) releaseLease(ctx context.Context) {
+	if s.lease == nil || !s.IsLeader() {
+		return
+	}
+	if err := s.lease.store.Release(ctx, s.lease.holder); err != nil {
+		logger.Warn().Err(err).Msg("failed to release validator lease")
+	}
+}
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+)
+
+type rollupMetrics struct {
+	latestNodeCreated   metrics.Gauge
+	latestConfirmedNode metrics.Gauge
+	unresolvedNodes     metrics.Gauge
+	unconfirmedNodes    metrics.Gauge
+	confirmationLag     metrics.Gauge
+	stakerCount         metrics.Gauge
+	challengeCount      metrics.Gauge
+	act                 metrics.Timer
+
+	challengeBlocksRemaining metrics.Gauge
+	opponentResponseBlocks   metrics.Gauge
+	challengeMove            metrics.Timer
+}
+
+// RegisterMetrics exports the staker's view of the rollup to registry
+func (s *Staker) RegisterMetrics(registry metrics.Registry) {
+	s.metrics = &rollupMetrics{
+		latestNodeCreated:   metrics.NewRegisteredGauge("arbitrum/rollup/nodes/latest_created", registry),
+		latestConfirmedNode: metrics.NewRegisteredGauge("arbitrum/rollup/nodes/latest_confirmed", registry),
+		unresolvedNodes:     metrics.NewRegisteredGauge("arbitrum/rollup/nodes/unresolved", registry),
+		unconfirmedNodes:    metrics.NewRegisteredGauge("arbitrum/rollup/nodes/unconfirmed", registry),
+		confirmationLag:     metrics.NewRegisteredGauge("arbitrum/rollup/confirmation_lag_blocks", registry),
+		stakerCount:         metrics.NewRegisteredGauge("arbitrum/rollup/stakers", registry),
+		challengeCount:      metrics.NewRegisteredGauge("arbitrum/rollup/challenges", registry),
+		act:                 metrics.NewRegisteredTimer("arbitrum/staker/act", registry),
+
+		challengeBlocksRemaining: metrics.NewRegisteredGauge("arbitrum/staker/challenge/blocks_remaining", registry),
+		opponentResponseBlocks:   metrics.NewRegisteredGauge("arbitrum/staker/challenge/opponent_response_blocks", registry),
+		challengeMove:            metrics.NewRegisteredTimer("arbitrum/staker/challenge/move", registry),
+	}
+	s.AddAssertionListener(newAssertionMetrics(registry))
+}
+
+// rollupNodeCounts reads the rollup's node counters, in one request if a
+// multicall contract is configured
+func (s *Staker) rollupNodeCounts(ctx context.Context) (*ethbridge.RollupNodeCounts, error) {
+	if s.multicall != nil {
+		return s.rollup.NodeCounts(ctx, s.multicall)
+	}
+	latestCreated, err := s.rollup.LatestNodeCreated(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latestConfirmed, err := s.rollup.LatestConfirmedNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	firstUnresolved, err := s.rollup.FirstUnresolvedNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ethbridge.RollupNodeCounts{
+		LatestConfirmed:     latestConfirmed,
+		FirstUnresolvedNode: firstUnresolved,
+		LatestNodeCreated:   latestCreated,
+	}, nil
+}
+
+// updateRollupMetrics refreshes the rollup gauges from L1. It's called by the
+// staker after acting rather than on every scrape so metrics don't add L1 load
+// beyond one round of calls per action.
+func (s *Staker) updateRollupMetrics(ctx context.Context) error {
+	if !metrics.Enabled || s.metrics == nil {
+		return nil
+	}
+	counts, err := s.rollupNodeCounts(ctx)
+	if err != nil {
+		return err
+	}
+	latestCreated := counts.LatestNodeCreated
+	latestConfirmed := counts.LatestConfirmed
+	firstUnresolved := counts.FirstUnresolvedNode
+	created := latestCreated.Int64()
+	s.metrics.latestNodeCreated.Update(created)
+	s.metrics.latestConfirmedNode.Update(latestConfirmed.Int64())
+	s.metrics.unconfirmedNodes.Update(created - latestConfirmed.Int64())
+	s.metrics.unresolvedNodes.Update(created - firstUnresolved.Int64() + 1)
+
+	// The confirmation lag is how many blocks ago the first unresolved node
+	// could have been resolved
+	confirmationLag := int64(0)
+	if firstUnresolved.Cmp(latestCreated) <= 0 {
+		node, err := s.rollup.GetNode(ctx, firstUnresolved)
+		if err != nil {
+			return err
+		}
+		deadline, err := node.DeadlineBlock(ctx)
+		if err != nil {
+			return err
+		}
+		latestBlock, err := s.client.BlockInfoByNumber(ctx, nil)
+		if err != nil {
+			return err
+		}
+		lag := new(big.Int).Sub(latestBlock.Number.ToInt(), deadline)
+		if lag.Sign() > 0 {
+			confirmationLag = lag.Int64()
+		}
+	}
+	s.metrics.confirmationLag.Update(confirmationLag)
+
+	stakers, err := s.validatorUtils.GetStakers(ctx)
+	if err != nil {
+		return err
+	}
+	s.metrics.stakerCount.Update(int64(len(stakers)))
+	var stakerInfos []*ethbridge.StakerInfo
+	if s.multicall != nil {
+		stakerInfos, err = s.rollup.StakerInfos(ctx, s.multicall, stakers)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, staker := range stakers {
+			info, err := s.rollup.StakerInfo(ctx, staker)
+			if err != nil {
+				return err
+			}
+			stakerInfos = append(stakerInfos, info)
+		}
+	}
+	// Each challenge is between two stakers
+	challengedStakers := int64(0)
+	for _, info := range stakerInfos {
+		if info != nil && info.CurrentChallenge != nil {
+			challengedStakers++
+		}
+	}
+	s.metrics.challengeCount.Update(challengedStakers / 2)
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+type ReplayedNode struct {
+	Node          *big.Int       `json:"node"`
+	Hash          ethcommon.Hash `json:"hash"`
+	BlockProposed *big.Int       `json:"blockProposed"`
+	Valid         bool           `json:"valid"`
+	// Skipped explains why the node couldn't be checked, in which case Valid
+	// is meaningless
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// ReplayNodes checks every node created in the rollup up to toBlock against
+// the local database, as a validator would have when the node was created.
+// It only reads from L1, so it's safe to run against any chain. Nodes reading
+// messages the local database hasn't processed yet are reported as skipped.
+// Up to workers nodes are checked in parallel.
+func ReplayNodes(ctx context.Context, rollup *ethbridge.RollupWatcher, lookup core.ArbCoreLookup, toBlock *big.Int, workers int) ([]*ReplayedNode, error) {
+	nodes, err := rollup.LookupNodesCreated(ctx, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	validations, err := NewValidationPipeline(lookup, workers).Validate(ctx, nodes)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*ReplayedNode, 0, len(validations))
+	for _, validation := range validations {
+		nd := validation.Node
+		result := &ReplayedNode{
+			Node:          nd.NodeNum,
+			Hash:          nd.NodeHash.ToEthHash(),
+			BlockProposed: nd.BlockProposed.Height.AsInt(),
+			Valid:         validation.Valid,
+		}
+		results = append(results, result)
+		if validation.Skipped {
+			result.Skipped = "local database hasn't processed the messages read by the node"
+		} else if result.Valid {
+			logger.Info().Str("node", result.Node.String()).Msg("replayed node is correct")
+		} else {
+			logger.Warn().Str("node", result.Node.String()).Msg("replayed node is incorrect")
+		}
+	}
+	return results, nil
+}
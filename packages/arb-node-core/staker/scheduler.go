@@ -0,0 +1,94 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
+)
+
+// Rough L1 gas costs of the actions the staker sends in their own transaction,
+// used to rank and budget them before they're built
+const (
+	challengeMoveGas      = 1_000_000
+	removeOldStakersGas   = 100_000
+	timeoutChallengesGas  = 100_000
+	pruneNodesGas         = 1_000_000
+	stakeAndResolutionGas = 1_500_000
+)
+
+// stakerAction is something the staker sends in a transaction of its own
+type stakerAction struct {
+	name string
+	// deadline is the last L1 block the action's transaction can be mined in,
+	// or nil if nothing is lost by delaying it
+	deadline *big.Int
+	gas      uint64
+	// run sends the action's transaction, returning nil if there turned out to
+	// be nothing to do
+	run func(ctx context.Context) (*arbtransaction.ArbTransaction, error)
+}
+
+// scheduleActions orders actions so that the one closest to its deadline comes
+// first, followed by actions without a deadline, cheapest first. gasBudget is
+// how much gas the staker can currently afford, or nil if unknown. Actions
+// without a deadline which cost more than that are dropped and reconsidered
+// the next time the staker acts, but actions with a deadline are always
+// attempted, as missing a deadline costs more than a failed transaction.
+func scheduleActions(actions []*stakerAction, gasBudget *big.Int) []*stakerAction {
+	sorted := make([]*stakerAction, 0, len(actions))
+	for _, action := range actions {
+		if action.deadline == nil && gasBudget != nil && gasBudget.Cmp(new(big.Int).SetUint64(action.gas)) < 0 {
+			logger.Warn().Str("action", action.name).Uint64("gas", action.gas).Str("budget", gasBudget.String()).Msg("deferring action the wallet can't afford")
+			continue
+		}
+		sorted = append(sorted, action)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if (a.deadline == nil) != (b.deadline == nil) {
+			return a.deadline != nil
+		}
+		if a.deadline != nil {
+			if cmp := a.deadline.Cmp(b.deadline); cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return a.gas < b.gas
+	})
+	return sorted
+}
+
+// runScheduledActions runs actions in order until one of them sends a
+// transaction, as the staker only has one transaction in flight at a time. It
+// returns the transaction along with the name of the action which sent it.
+func runScheduledActions(ctx context.Context, actions []*stakerAction, gasBudget *big.Int) (*arbtransaction.ArbTransaction, string, error) {
+	for _, action := range scheduleActions(actions, gasBudget) {
+		arbTx, err := action.run(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		if arbTx != nil {
+			logger.Debug().Str("action", action.name).Msg("sent scheduled action")
+			return arbTx, action.name, nil
+		}
+	}
+	return nil, "", nil
+}
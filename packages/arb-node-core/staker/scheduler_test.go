@@ -0,0 +1,101 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
+)
+
+func actionNames(actions []*stakerAction) []string {
+	names := make([]string, 0, len(actions))
+	for _, action := range actions {
+		names = append(names, action.name)
+	}
+	return names
+}
+
+func checkActionOrder(t *testing.T, actions []*stakerAction, expected ...string) {
+	t.Helper()
+	names := actionNames(actions)
+	if len(names) != len(expected) {
+		t.Fatalf("expected actions %v, got %v", expected, names)
+	}
+	for i := range names {
+		if names[i] != expected[i] {
+			t.Fatalf("expected actions %v, got %v", expected, names)
+		}
+	}
+}
+
+func TestScheduleActionsByDeadline(t *testing.T) {
+	actions := []*stakerAction{
+		{name: "expensive", gas: 500},
+		{name: "late", deadline: big.NewInt(200), gas: 1000},
+		{name: "cheap", gas: 100},
+		{name: "soon", deadline: big.NewInt(100), gas: 2000},
+	}
+	checkActionOrder(t, scheduleActions(actions, nil), "soon", "late", "cheap", "expensive")
+	// The input order must be left alone
+	checkActionOrder(t, actions, "expensive", "late", "cheap", "soon")
+}
+
+func TestScheduleActionsGasBudget(t *testing.T) {
+	actions := []*stakerAction{
+		{name: "expensive", gas: 500},
+		{name: "cheap", gas: 100},
+		{name: "urgent", deadline: big.NewInt(100), gas: 2000},
+	}
+	// Actions with a deadline are attempted even if the wallet can't afford them
+	checkActionOrder(t, scheduleActions(actions, big.NewInt(200)), "urgent", "cheap")
+	checkActionOrder(t, scheduleActions(actions, big.NewInt(500)), "urgent", "cheap", "expensive")
+}
+
+func TestRunScheduledActionsStopsAfterTransaction(t *testing.T) {
+	var ran []string
+	action := func(name string, sends bool, deadline *big.Int) *stakerAction {
+		return &stakerAction{
+			name:     name,
+			deadline: deadline,
+			run: func(context.Context) (*arbtransaction.ArbTransaction, error) {
+				ran = append(ran, name)
+				if sends {
+					return &arbtransaction.ArbTransaction{}, nil
+				}
+				return nil, nil
+			},
+		}
+	}
+	actions := []*stakerAction{
+		action("last", true, nil),
+		action("sends", true, big.NewInt(20)),
+		action("nothing to do", false, big.NewInt(10)),
+	}
+	arbTx, name, err := runScheduledActions(context.Background(), actions, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arbTx == nil || name != "sends" {
+		t.Fatalf("expected a transaction to be sent by sends, got %v", name)
+	}
+	if len(ran) != 2 || ran[0] != "nothing to do" || ran[1] != "sends" {
+		t.Fatalf("unexpected actions run %v", ran)
+	}
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+// StakerSnapshot captures the state a staker accumulates while running, so
+// that a restarted or standby validator can pick up where it left off instead
+// of rediscovering it from L1. It records the rollup and validator wallet it
+// was taken from, so that it isn't restored into a staker for another one.
+type StakerSnapshot struct {
+	Rollup                      ethcommon.Address  `json:"rollup"`
+	Wallet                      *ethcommon.Address `json:"wallet,omitempty"`
+	ActiveChallenge             *ethcommon.Address `json:"activeChallenge,omitempty"`
+	InactiveLastCheckedNode     *big.Int           `json:"inactiveLastCheckedNode,omitempty"`
+	InactiveLastCheckedNodeHash ethcommon.Hash     `json:"inactiveLastCheckedNodeHash"`
+	BringActiveUntilNode        *big.Int           `json:"bringActiveUntilNode,omitempty"`
+	HighGasBlocksBuffer         *big.Int           `json:"highGasBlocksBuffer"`
+}
+
+type snapshotHolder struct {
+	mutex    sync.Mutex
+	snapshot *StakerSnapshot
+	// If set, each snapshot is also saved to this file
+	filename string
+}
+
+// SaveSnapshot atomically replaces the contents of filename with snapshot
+func SaveSnapshot(filename string, snapshot *StakerSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	tmpFile, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create staker snapshot file")
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return errors.Wrap(err, "failed to write staker snapshot")
+	}
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return errors.Wrap(err, "failed to sync staker snapshot")
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmpFile.Name(), filename))
+}
+
+// LoadSnapshot reads a snapshot saved by SaveSnapshot, returning nil if there
+// isn't one
+func LoadSnapshot(filename string) (*StakerSnapshot, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read staker snapshot")
+	}
+	snapshot := &StakerSnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal staker snapshot")
+	}
+	return snapshot, nil
+}
+
+// SetSnapshotFile restores the staker from the snapshot saved in filename, if
+// there is one, and saves every following snapshot there. It must be called
+// before RunInBackground. The progress of the active challenge isn't part of
+// the snapshot, it's only resumed from the file set by
+// SetChallengeProgressFile, so that must be called first.
+func (s *Staker) SetSnapshotFile(ctx context.Context, filename string) error {
+	snapshot, err := LoadSnapshot(filename)
+	if err != nil {
+		return err
+	}
+	if snapshot != nil {
+		logger.Info().Str("file", filename).Msg("restoring staker from snapshot")
+		if err := s.RestoreSnapshot(ctx, snapshot); err != nil {
+			return errors.Wrapf(err, "can't restore staker from snapshot %v", filename)
+		}
+	}
+	s.snapshots.mutex.Lock()
+	s.snapshots.filename = filename
+	s.snapshots.mutex.Unlock()
+	return nil
+}
+
+func copyInt(x *big.Int) *big.Int {
+	if x == nil {
+		return nil
+	}
+	return new(big.Int).Set(x)
+}
+
+// Snapshot returns the staker's state as of the end of its last action, or nil
+// if it hasn't acted yet
+func (s *Staker) Snapshot() *StakerSnapshot {
+	s.snapshots.mutex.Lock()
+	defer s.snapshots.mutex.Unlock()
+	if s.snapshots.snapshot == nil {
+		return nil
+	}
+	snapshot := *s.snapshots.snapshot
+	return &snapshot
+}
+
+// recordSnapshot must only be called from the staker's thread
+func (s *Staker) recordSnapshot() {
+	snapshot := &StakerSnapshot{
+		Rollup:               s.wallet.RollupAddress().ToEthAddress(),
+		Wallet:               s.wallet.Address(),
+		BringActiveUntilNode: copyInt(s.bringActiveUntilNode),
+		HighGasBlocksBuffer:  copyInt(s.highGasBlocksBuffer),
+	}
+	if s.activeChallenge != nil {
+		addr := s.activeChallenge.ChallengeAddress().ToEthAddress()
+		snapshot.ActiveChallenge = &addr
+	}
+	if s.inactiveLastCheckedNode != nil {
+		snapshot.InactiveLastCheckedNode = copyInt(s.inactiveLastCheckedNode.id)
+		snapshot.InactiveLastCheckedNodeHash = s.inactiveLastCheckedNode.hash.ToEthHash()
+	}
+	s.snapshots.mutex.Lock()
+	defer s.snapshots.mutex.Unlock()
+	s.snapshots.snapshot = snapshot
+	if s.snapshots.filename != "" {
+		if err := SaveSnapshot(s.snapshots.filename, snapshot); err != nil {
+			logger.Error().Err(err).Msg("failed to save staker snapshot")
+		}
+	}
+}
+
+// checkSnapshotOwner returns an error if snapshot was taken from a staker for
+// a different rollup or validator wallet. A snapshot taken before the wallet
+// was created can be restored into a staker which has one since.
+func (s *Staker) checkSnapshotOwner(snapshot *StakerSnapshot) error {
+	rollup := s.wallet.RollupAddress().ToEthAddress()
+	if snapshot.Rollup != rollup {
+		return errors.Errorf("snapshot is for rollup %v, not %v", snapshot.Rollup.Hex(), rollup.Hex())
+	}
+	if snapshot.Wallet == nil {
+		return nil
+	}
+	wallet := s.wallet.Address()
+	if wallet == nil {
+		return errors.Errorf("snapshot is for validator wallet %v, but we don't have one", snapshot.Wallet.Hex())
+	}
+	if *snapshot.Wallet != *wallet {
+		return errors.Errorf("snapshot is for validator wallet %v, not %v", snapshot.Wallet.Hex(), wallet.Hex())
+	}
+	return nil
+}
+
+// RestoreSnapshot loads state captured by Snapshot into a newly created staker.
+// It must be called before RunInBackground, and fails without changing the
+// staker if the snapshot was taken from a staker for a different rollup or
+// validator wallet. The active challenge is set up again, resuming from the
+// progress saved by SetChallengeProgressFile if there is any. If that fails, it's picked up from L1 on the
+// first action instead. L1 block positions aren't part of the snapshot, as
+// they may be stale or reorged by the time it's restored, so they're read
+// from L1 again on the first action.
+func (s *Staker) RestoreSnapshot(ctx context.Context, snapshot *StakerSnapshot) error {
+	if err := s.checkSnapshotOwner(snapshot); err != nil {
+		return err
+	}
+	s.bringActiveUntilNode = copyInt(snapshot.BringActiveUntilNode)
+	if snapshot.HighGasBlocksBuffer != nil {
+		s.highGasBlocksBuffer = copyInt(snapshot.HighGasBlocksBuffer)
+	}
+	s.inactiveLastCheckedNode = nil
+	if snapshot.InactiveLastCheckedNode != nil {
+		s.inactiveLastCheckedNode = &nodeAndHash{
+			id:   copyInt(snapshot.InactiveLastCheckedNode),
+			hash: common.NewHashFromEth(snapshot.InactiveLastCheckedNodeHash),
+		}
+	}
+	s.activeChallenge = nil
+	if snapshot.ActiveChallenge != nil && s.wallet.Address() != nil {
+		challengeAddr := common.NewAddressFromEth(*snapshot.ActiveChallenge)
+		err := s.setActiveChallenge(ctx, &ethbridge.StakerInfo{CurrentChallenge: &challengeAddr})
+		if err != nil {
+			logger.Warn().Err(err).Str("challenge", challengeAddr.String()).Msg("failed to restore active challenge")
+			s.activeChallenge = nil
+		}
+	}
+	s.recordSnapshot()
+	return nil
+}
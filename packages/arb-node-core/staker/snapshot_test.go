@@ -0,0 +1,155 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/challenge"
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func checkInt(t *testing.T, name string, expected, actual *big.Int) {
+	t.Helper()
+	if (expected == nil) != (actual == nil) || (expected != nil && expected.Cmp(actual) != 0) {
+		t.Errorf("expected %v to be %v, got %v", name, expected, actual)
+	}
+}
+
+// snapshotValidator returns a validator for rollup using the given wallet,
+// which may be nil if it hasn't been created
+func snapshotValidator(t *testing.T, rollup ethcommon.Address, wallet *ethcommon.Address) *Validator {
+	t.Helper()
+	valWallet, err := ethbridge.NewValidator(wallet, ethcommon.Address{}, rollup, nil, nil, 0, 1000, nil)
+	test.FailIfError(t, err)
+	return &Validator{wallet: valWallet}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "snapshot.json")
+	rollup := ethcommon.Address{1}
+	wallet := ethcommon.Address{2}
+
+	original := &Staker{
+		Validator:            snapshotValidator(t, rollup, &wallet),
+		highGasBlocksBuffer:  big.NewInt(10),
+		lastActCalledBlock:   big.NewInt(1000),
+		bringActiveUntilNode: big.NewInt(7),
+		inactiveLastCheckedNode: &nodeAndHash{
+			id:   big.NewInt(5),
+			hash: common.Hash{5},
+		},
+	}
+	original.trigger.lastCheckedBlock = big.NewInt(999)
+	original.trigger.nextDeadline = big.NewInt(1200)
+	test.FailIfError(t, original.SetSnapshotFile(ctx, filename))
+	original.recordSnapshot()
+
+	// The active challenge is only set up again if the staker has a wallet,
+	// so it's checked through the saved file
+	saved, err := LoadSnapshot(filename)
+	test.FailIfError(t, err)
+	challengeAddr := ethcommon.Address{9}
+	saved.ActiveChallenge = &challengeAddr
+	test.FailIfError(t, SaveSnapshot(filename, saved))
+	loaded, err := LoadSnapshot(filename)
+	test.FailIfError(t, err)
+	if loaded.ActiveChallenge == nil || *loaded.ActiveChallenge != challengeAddr {
+		t.Errorf("active challenge not saved, got %v", loaded.ActiveChallenge)
+	}
+
+	// Challenge progress is only loaded from its own file
+	progress := &challenge.Progress{
+		Challenge:       common.Address{9},
+		ChallengedNode:  big.NewInt(6),
+		BisectionDegree: 400,
+	}
+	restored := &Staker{
+		Validator:      snapshotValidator(t, rollup, &wallet),
+		savedChallenge: progress,
+	}
+	loaded.ActiveChallenge = nil
+	test.FailIfError(t, restored.RestoreSnapshot(ctx, loaded))
+	checkInt(t, "high gas blocks buffer", original.highGasBlocksBuffer, restored.highGasBlocksBuffer)
+	checkInt(t, "bring active until node", original.bringActiveUntilNode, restored.bringActiveUntilNode)
+	// L1 block positions are re-read from L1 on the first action
+	if restored.lastActCalledBlock != nil || restored.trigger.lastCheckedBlock != nil || restored.trigger.nextDeadline != nil {
+		t.Errorf("L1 block positions restored, got %v %v %v", restored.lastActCalledBlock, restored.trigger.lastCheckedBlock, restored.trigger.nextDeadline)
+	}
+	if restored.inactiveLastCheckedNode == nil || restored.inactiveLastCheckedNode.hash != original.inactiveLastCheckedNode.hash {
+		t.Fatalf("inactive last checked node not restored, got %v", restored.inactiveLastCheckedNode)
+	}
+	checkInt(t, "inactive last checked node", original.inactiveLastCheckedNode.id, restored.inactiveLastCheckedNode.id)
+	if restored.savedChallenge != progress {
+		t.Errorf("challenge progress replaced by snapshot, got %+v", restored.savedChallenge)
+	}
+
+	// A missing file leaves a new staker as it is
+	fresh := &Staker{Validator: snapshotValidator(t, rollup, &wallet)}
+	test.FailIfError(t, fresh.SetSnapshotFile(ctx, filepath.Join(t.TempDir(), "missing.json")))
+	if fresh.bringActiveUntilNode != nil {
+		t.Errorf("missing snapshot restored state %v", fresh.bringActiveUntilNode)
+	}
+}
+
+func TestSnapshotRestoreChecksOwner(t *testing.T) {
+	ctx := context.Background()
+	rollup := ethcommon.Address{1}
+	wallet := ethcommon.Address{2}
+	original := &Staker{
+		Validator:            snapshotValidator(t, rollup, &wallet),
+		bringActiveUntilNode: big.NewInt(7),
+	}
+	original.recordSnapshot()
+	snapshot := original.Snapshot()
+
+	otherWallet := ethcommon.Address{3}
+	cases := []struct {
+		name   string
+		staker *Staker
+	}{
+		{"other rollup", &Staker{Validator: snapshotValidator(t, ethcommon.Address{4}, &wallet)}},
+		{"other wallet", &Staker{Validator: snapshotValidator(t, rollup, &otherWallet)}},
+		{"no wallet", &Staker{Validator: snapshotValidator(t, rollup, nil)}},
+	}
+	for _, c := range cases {
+		if err := c.staker.RestoreSnapshot(ctx, snapshot); err == nil {
+			t.Errorf("%v: snapshot restored", c.name)
+		}
+		if c.staker.bringActiveUntilNode != nil {
+			t.Errorf("%v: rejected snapshot restored state %v", c.name, c.staker.bringActiveUntilNode)
+		}
+	}
+
+	// A snapshot taken before the wallet was created still applies
+	beforeWallet := &Staker{
+		Validator:            snapshotValidator(t, rollup, nil),
+		bringActiveUntilNode: big.NewInt(5),
+	}
+	beforeWallet.recordSnapshot()
+	restored := &Staker{Validator: snapshotValidator(t, rollup, &wallet)}
+	test.FailIfError(t, restored.RestoreSnapshot(ctx, beforeWallet.Snapshot()))
+	checkInt(t, "bring active until node", big.NewInt(5), restored.bringActiveUntilNode)
+}
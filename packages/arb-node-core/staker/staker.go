@@ -19,16 +19,20 @@ package staker
 import (
 	"context"
 	"math/big"
+	"os"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/pkg/errors"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/challenge"
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/cmdhelp"
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/nodehealth"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/arblog"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
@@ -48,7 +52,8 @@ type nodeAndHash struct {
 type Staker struct {
 	*Validator
 	activeChallenge         *challenge.Challenger
-	strategy                configuration.ValidatorStrategy
+	strategy                Strategy
+	moveStrategy            challenge.MoveStrategy
 	fromBlock               int64
 	baseCallOpts            bind.CallOpts
 	auth                    transactauth.TransactAuth
@@ -59,6 +64,31 @@ type Staker struct {
 	bringActiveUntilNode    core.NodeID
 	withdrawDestination     common.Address
 	lookup                  core.ArbCoreLookup
+	reorgChan               chan bool
+	receiptCache            *transactauth.ReceiptCache
+	challengeProgressFile   string
+	savedChallenge          *challenge.Progress
+	challengeMonitor        challengeMonitor
+	trigger                 actTrigger
+	wakeChan                chan struct{}
+	snapshots               snapshotHolder
+	l1Success               l1SuccessTracker
+	healthChan              chan nodehealth.Log
+	stopChan                chan struct{}
+	stopOnce                sync.Once
+	cancelRun               context.CancelFunc
+	runDone                 chan struct{}
+	metrics                 *rollupMetrics
+	escalationHook          EscalationHook
+	control                 stakerControl
+	checkpointRetainNodes   int
+	spending                *spendingBudget
+	lease                   *leaseCoordinator
+	maxStake                *big.Int
+	// sentAction names the action which sent the last transaction from Act
+	sentAction string
+	// lastMinedBlock is the block our latest transaction was mined in
+	lastMinedBlock *big.Int
 }
 
 func NewStaker(
@@ -77,36 +107,243 @@ func NewStaker(
 	if err != nil {
 		return nil, nil, err
 	}
+	if ethcommon.IsHexAddress(config.MulticallAddress) {
+		val.multicall = ethbridge.NewMulticaller(ethcommon.HexToAddress(config.MulticallAddress), client, callOpts)
+	}
+	val.MaxConfirmationsPerTx = config.Confirm.MaxNodesPerTx
+	if config.Confirm.GasPriceLimit > 0 {
+		val.ConfirmGasPriceLimit, _ = new(big.Float).Mul(big.NewFloat(config.Confirm.GasPriceLimit), big.NewFloat(1e9)).Int(nil)
+	}
+	val.ConfirmMaxDelayBlocks = big.NewInt(config.Confirm.MaxDelayBlocks)
+	var maxStake, dailyBudget, weeklyBudget *big.Int
+	if config.MaxStake > 0 {
+		maxStake = ethToWei(config.MaxStake)
+	}
+	if config.GasBudget.Daily > 0 {
+		dailyBudget = ethToWei(config.GasBudget.Daily)
+	}
+	if config.GasBudget.Weekly > 0 {
+		weeklyBudget = ethToWei(config.GasBudget.Weekly)
+	}
+	stakerStrategy := BuiltinStrategy(strategy)
+	if strategy == configuration.WatchtowerStrategy {
+		stakerStrategy = watchtowerStrategy{escalate: config.Watchtower.EscalateToDefensive}
+	}
+	moveStrategy, err := challenge.BuiltinMoveStrategy(config.ChallengeStrategy)
+	if err != nil {
+		return nil, nil, err
+	}
 	withdrawDestination := wallet.From()
-	if ethcommon.IsHexAddress(config.WithdrawDestination) {
-		withdrawDestination = common.HexToAddress(config.WithdrawDestination)
+	if config.WithdrawDestination != "" {
+		withdrawDestination, err = common.ParseAddress(config.WithdrawDestination)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "invalid withdraw destination")
+		}
 	}
 	return &Staker{
-		Validator:           val,
-		strategy:            strategy,
-		fromBlock:           fromBlock,
-		baseCallOpts:        callOpts,
-		auth:                auth,
-		config:              config,
-		highGasBlocksBuffer: big.NewInt(config.L1PostingStrategy.HighGasDelayBlocks),
-		lastActCalledBlock:  nil,
-		withdrawDestination: withdrawDestination,
-		lookup:              lookup,
+		Validator:             val,
+		strategy:              stakerStrategy,
+		moveStrategy:          moveStrategy,
+		fromBlock:             fromBlock,
+		baseCallOpts:          callOpts,
+		auth:                  auth,
+		config:                config,
+		highGasBlocksBuffer:   big.NewInt(config.L1PostingStrategy.HighGasDelayBlocks),
+		lastActCalledBlock:    nil,
+		withdrawDestination:   withdrawDestination,
+		lookup:                lookup,
+		reorgChan:             make(chan bool, 1),
+		wakeChan:              make(chan struct{}, 1),
+		stopChan:              make(chan struct{}),
+		runDone:               make(chan struct{}),
+		maxStake:              maxStake,
+		spending:              newSpendingBudget(dailyBudget, weeklyBudget),
+		checkpointRetainNodes: 1,
+		challengeMonitor:      challengeMonitor{config: config.ChallengeAlerts},
 	}, val.delayedBridge, nil
 }
 
+// SetStrategy replaces the strategy the staker was created with. It must be
+// called before RunInBackground.
+func (s *Staker) SetStrategy(strategy Strategy) {
+	s.strategy = strategy
+}
+
+// SetMoveStrategy replaces the strategy the staker's challengers choose
+// their moves with. It must be called before RunInBackground.
+func (s *Staker) SetMoveStrategy(strategy challenge.MoveStrategy) {
+	s.moveStrategy = strategy
+}
+
+// SetCheckpointRetention sets how many confirmed nodes before the latest
+// confirmed node the staker keeps database checkpoints for
+func (s *Staker) SetCheckpointRetention(nodes int) {
+	s.checkpointRetainNodes = nodes
+}
+
+// SetReceiptCache makes the staker record the transactions it sends, and
+// wait for any left pending by a previous run before acting again
+func (s *Staker) SetReceiptCache(cache *transactauth.ReceiptCache) {
+	s.receiptCache = cache
+}
+
+// SetChallengeProgressFile makes the staker save the progress of its active
+// challenge to filename, and resume the challenge saved there, if it's
+// still active, instead of setting it up from L1 again
+func (s *Staker) SetChallengeProgressFile(filename string) error {
+	progress, err := challenge.LoadProgress(filename)
+	if err != nil {
+		return err
+	}
+	s.challengeProgressFile = filename
+	s.savedChallenge = progress
+	return nil
+}
+
+// saveChallengeProgress must only be called from the staker's thread
+func (s *Staker) saveChallengeProgress() {
+	if s.activeChallenge == nil {
+		s.savedChallenge = nil
+	} else {
+		s.savedChallenge = s.activeChallenge.Progress()
+	}
+	if s.challengeProgressFile == "" {
+		return
+	}
+	var err error
+	if s.savedChallenge == nil {
+		err = os.Remove(s.challengeProgressFile)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	} else {
+		err = challenge.SaveProgress(s.challengeProgressFile, s.savedChallenge)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to save challenge progress")
+	}
+}
+
+// SimulateChallenge plays out a challenge of assertion locally, using the
+// rollup's current bisection degree, to estimate the moves and L1 gas it
+// would take us to win it
+func (s *Staker) SimulateChallenge(ctx context.Context, assertion *core.Assertion) (*challenge.Simulation, error) {
+	degree, err := s.rollup.ChallengeExecutionBisectionDegree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !degree.IsInt64() {
+		return nil, errors.Errorf("invalid challenge bisection degree %v", degree)
+	}
+	return challenge.Simulate(ctx, s.lookup, s.sequencerInbox, assertion, int(degree.Int64()))
+}
+
+// OutstandingTransactions lists the transactions sent by the staker which
+// haven't been mined yet
+func (s *Staker) OutstandingTransactions() []transactauth.OutstandingTransaction {
+	return transactauth.OutstandingTransactions(s.auth)
+}
+
+func (s *Staker) resolvePendingTransactions(ctx context.Context) error {
+	if s.receiptCache == nil {
+		return nil
+	}
+	receipts, err := s.receiptCache.Resolve(ctx, s.auth)
+	if err != nil {
+		return err
+	}
+	for _, receipt := range receipts {
+		logger.
+			Info().
+			Hex("tx", receipt.TxHash.Bytes()).
+			Uint64("status", receipt.Status).
+			Msg("transaction sent before restart was mined")
+		s.recordMined(receipt)
+	}
+	return nil
+}
+
+func (s *Staker) recordMined(receipt *types.Receipt) {
+	if receipt.BlockNumber == nil {
+		return
+	}
+	if s.lastMinedBlock == nil || receipt.BlockNumber.Cmp(s.lastMinedBlock) > 0 {
+		s.lastMinedBlock = new(big.Int).Set(receipt.BlockNumber)
+	}
+}
+
+// waitingForOwnTransaction returns true if safe is before the block our
+// latest transaction was mined in, so state read there wouldn't reflect it
+func (s *Staker) waitingForOwnTransaction(safe *big.Int) bool {
+	return s.lastMinedBlock != nil && safe.Cmp(s.lastMinedBlock) < 0
+}
+
+func (s *Staker) waitForReceipt(ctx context.Context, arbTx *arbtransaction.ArbTransaction) error {
+	from := s.auth.From()
+	nonce := arbTx.Nonce()
+	if s.receiptCache != nil {
+		if err := s.receiptCache.RecordSent(from, arbTx, s.sentAction); err != nil {
+			logger.Warn().Err(err).Msg("failed to record sent transaction")
+		}
+	}
+	// Keep leading while the transaction and any replacements are in flight
+	stopHoldingLease := s.holdLease(ctx)
+	receipt, err := transactauth.WaitForReceiptWithResultsAndReplaceByFee(ctx, s.client, s.wallet.From().ToEthAddress(), arbTx, s.sentAction, s.auth, s.auth)
+	stopHoldingLease()
+	if receipt != nil {
+		// The gas price may have been raised by replacements, but the fee cap
+		// of the original transaction is still an upper bound for the cost
+		cost := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), arbTx.GasPrice())
+		s.spending.record(time.Now(), cost)
+		s.recordMined(receipt)
+		entry := JournalEntry{Event: JournalTransactionMined, TxHash: &receipt.TxHash}
+		if err != nil {
+			entry.Event = JournalTransactionFailed
+			entry.Error = err.Error()
+		}
+		s.journal.Record(entry)
+	}
+	if s.receiptCache != nil && receipt != nil {
+		if err := s.receiptCache.RecordReceipt(from, nonce, receipt); err != nil {
+			logger.Warn().Err(err).Msg("failed to record transaction receipt")
+		}
+	}
+	return err
+}
+
 func (s *Staker) RunInBackground(ctx context.Context, stakerDelay time.Duration) chan bool {
 	done := make(chan bool)
+	ctx, s.cancelRun = context.WithCancel(ctx)
 	go func() {
 		defer func() {
 			done <- true
 		}()
+		// Closed before done is sent, as nothing may be receiving from done
+		defer close(s.runDone)
+		defer s.cancelRun()
 		backoff := time.Second
 		for {
+			err := s.resolvePendingTransactions(ctx)
+			if err == nil {
+				break
+			}
+			logger.Warn().Err(err).Msg("error checking transactions sent before restart")
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.stopChan:
+				return
+			case <-time.After(backoff):
+			}
+		}
+		for {
+			actStart := time.Now()
 			arbTx, err := s.Act(ctx)
+			if s.metrics != nil {
+				s.metrics.act.UpdateSince(actStart)
+			}
 			if err == nil && arbTx != nil {
-				// Note: methodName isn't accurate, it's just used for logging
-				_, err = transactauth.WaitForReceiptWithResultsAndReplaceByFee(ctx, s.client, s.wallet.From().ToEthAddress(), arbTx, "for staking", s.auth, s.auth)
+				err = s.waitForReceipt(ctx, arbTx)
 				if err != nil && common.IsFatalError(err) {
 					logger.Error().Err(err).Msg("aborting staker background thread")
 					break
@@ -121,6 +358,8 @@ func (s *Staker) RunInBackground(ctx context.Context, stakerDelay time.Duration)
 				select {
 				case <-ctx.Done():
 					return
+				case <-s.stopChan:
+					return
 				case <-time.After(backoff):
 				}
 				if backoff < 60*time.Second {
@@ -130,24 +369,62 @@ func (s *Staker) RunInBackground(ctx context.Context, stakerDelay time.Duration)
 			} else {
 				backoff = time.Second
 			}
-			delay := time.After(stakerDelay)
+			s.recordL1Success()
+			s.reportHealth(ctx)
+			if err := s.updateRollupMetrics(ctx); err != nil {
+				logger.Warn().Err(err).Msg("error updating rollup metrics")
+			}
 			// Prune any stale database entries while we wait
-			err = cmdhelp.UpdatePrunePoint(ctx, s.rollup.RollupWatcher, s.lookup)
+			err = cmdhelp.UpdatePrunePoint(ctx, s.rollup.RollupWatcher, s.lookup, s.checkpointRetainNodes)
 			if err != nil {
 				logger.Error().Err(err).Msg("error pruning database")
 			}
 			// Force a GC run to clean up any execution cursors while we wait
 			runtime.GC()
-			select {
-			case <-ctx.Done():
+			s.waitForActivity(ctx, stakerDelay)
+			if ctx.Err() != nil || s.stopping() {
 				return
-			case <-delay:
 			}
 		}
 	}()
 	return done
 }
 
+// Stop asks the background thread to exit once it finishes its current action,
+// including waiting for any transaction it sent to be mined, and then closes
+// the journal. If ctx expires first the thread is cancelled, leaving the
+// transaction in the receipt cache to be resolved on the next start.
+func (s *Staker) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+	})
+	var err error
+	if s.cancelRun != nil {
+		select {
+		case <-s.runDone:
+		case <-ctx.Done():
+			s.cancelRun()
+			<-s.runDone
+			err = errors.Wrap(ctx.Err(), "waiting for staker to stop")
+		}
+	}
+	// Let a follower take over right away rather than once the lease expires
+	s.releaseLease(context.Background())
+	if closeErr := s.journal.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (s *Staker) stopping() bool {
+	select {
+	case <-s.stopChan:
+		return true
+	default:
+		return false
+	}
+}
+
 func (s *Staker) shouldAct(ctx context.Context) bool {
 	var gasPriceHigh = false
 	var gasPriceFloat float64
@@ -197,12 +474,67 @@ func (s *Staker) shouldAct(ctx context.Context) bool {
 	}
 }
 
+// safeBlock returns the block L1ConfirmationDepth blocks before latest
+func (s *Staker) safeBlock(latest *big.Int) *big.Int {
+	safe := new(big.Int).Sub(latest, big.NewInt(s.config.L1ConfirmationDepth))
+	if safe.Sign() < 0 {
+		safe.SetInt64(0)
+	}
+	return safe
+}
+
+// HandleReorg drops any state cached from L1 blocks which may no longer be
+// canonical before the next call to Act. It never blocks.
+func (s *Staker) HandleReorg(_ context.Context, _ *common.BlockId) {
+	select {
+	case s.reorgChan <- true:
+	default:
+	}
+	s.Wake()
+}
+
 func (s *Staker) Act(ctx context.Context) (*arbtransaction.ArbTransaction, error) {
-	if !s.shouldAct(ctx) {
+	if !s.acquireLease(ctx) {
+		logger.Info().Msg("following validator lease holder; not acting")
+		return nil, nil
+	}
+	select {
+	case <-s.reorgChan:
+		logger.Info().Msg("resetting cached staker state after L1 reorg")
+		s.activeChallenge = nil
+		s.setChallengeTimeout(nil)
+		s.inactiveLastCheckedNode = nil
+		s.bringActiveUntilNode = nil
+		s.lastActCalledBlock = nil
+		s.confirmations.reset()
+	default:
+	}
+	if s.Paused() {
+		logger.Info().Msg("staker paused; not acting")
+		return nil, nil
+	}
+	forceConfirm := s.takeForceConfirmation()
+	if !s.shouldAct(ctx) && !forceConfirm {
 		// The fact that we're delaying acting is alreay logged in `shouldAct`
 		return nil, nil
 	}
-	s.builder.ClearTransactions()
+	s.forceConfirmation = forceConfirm
+	if s.config.L1ConfirmationDepth > 0 && s.lastActCalledBlock != nil {
+		// Only act on rollup state which is unlikely to be reorged out
+		safe := s.safeBlock(s.lastActCalledBlock)
+		if s.waitingForOwnTransaction(safe) {
+			// The safe block doesn't include our last transaction yet, so we'd
+			// act on our own stale state and repeat what it already did
+			logger.Info().
+				Str("safeBlock", safe.String()).
+				Str("lastMinedBlock", s.lastMinedBlock.String()).
+				Msg("waiting for our last transaction to reach the confirmation depth")
+			return nil, nil
+		}
+		ctx = ethbridge.WithCallBlock(ctx, safe)
+	}
+	s.journal.setBlock(s.lastActCalledBlock)
+	s.clearTransactions()
 	var rawInfo *ethbridge.StakerInfo
 	walletAddress := s.wallet.Address()
 	var walletAddressOrZero common.Address
@@ -232,57 +564,189 @@ func (s *Staker) Act(ctx context.Context) (*arbtransaction.ArbTransaction, error
 		StakerInfo:           rawInfo,
 	}
 
-	effectiveStrategy := s.strategy
 	nodesLinear, err := s.validatorUtils.AreUnresolvedNodesLinear(ctx)
 	if err != nil {
 		return nil, err
 	}
 	if !nodesLinear {
 		logger.Warn().Msg("fork detected")
-		if effectiveStrategy == configuration.DefensiveStrategy {
-			effectiveStrategy = configuration.StakeLatestStrategy
-		}
 		s.inactiveLastCheckedNode = nil
 	}
 	if s.bringActiveUntilNode != nil {
-		if info.LatestStakedNode.Cmp(s.bringActiveUntilNode) < 0 {
-			if effectiveStrategy == configuration.DefensiveStrategy {
-				effectiveStrategy = configuration.StakeLatestStrategy
-			}
-		} else {
+		if info.LatestStakedNode.Cmp(s.bringActiveUntilNode) >= 0 {
 			logger.Info().Msg("defensive validator staked past incorrect node; waiting here")
 			s.bringActiveUntilNode = nil
 		}
 		s.inactiveLastCheckedNode = nil
 	}
+	strategyState := StrategyState{
+		Staked:                rawInfo != nil,
+		Forked:                !nodesLinear,
+		BringingActive:        s.bringActiveUntilNode != nil,
+		RequiredStakeElevated: s.isRequiredStakeElevated,
+	}
+	effectiveStrategy := s.strategy.Behavior(strategyState)
 	if !effectiveStrategy.IsActive() && s.inactiveLastCheckedNode != nil {
 		info.LatestStakedNode = s.inactiveLastCheckedNode.id
 		info.LatestStakedNodeHash = s.inactiveLastCheckedNode.hash
 	}
 
-	// Resolve nodes if either we're on the make nodes strategy,
-	// or we're on the stake latest strategy but don't have a stake
-	// (attempt to reduce the current required stake).
-	shouldResolveNodes := effectiveStrategy == configuration.MakeNodesStrategy
-	if !shouldResolveNodes && effectiveStrategy.IsActive() && rawInfo == nil {
-		shouldResolveNodes, err = s.isRequiredStakeElevated(ctx)
+	shouldResolveNodes, err := s.strategy.ShouldResolveNodes(ctx, strategyState, effectiveStrategy)
+	if err != nil {
+		return nil, err
+	}
+	if forceConfirm {
+		logger.Info().Msg("attempting confirmation as requested")
+		shouldResolveNodes = true
+	}
+	var actions []*stakerAction
+	if rawInfo != nil && rawInfo.CurrentChallenge != nil {
+		action, err := s.challengeAction(ctx, rawInfo)
 		if err != nil {
 			return nil, err
 		}
+		actions = append(actions, action)
+	} else if s.activeChallenge != nil || s.savedChallenge != nil {
+		s.activeChallenge = nil
+		s.setChallengeTimeout(nil)
+		s.saveChallengeProgress()
 	}
 	if shouldResolveNodes {
-		// Keep the stake of this validator placed if we plan on staking further
-		arbTx, err := s.removeOldStakers(ctx, effectiveStrategy.IsActive())
-		if err != nil || arbTx != nil {
-			return arbTx, err
+		actions = append(actions,
+			&stakerAction{
+				name: "remove old stakers",
+				gas:  removeOldStakersGas,
+				run: func(ctx context.Context) (*arbtransaction.ArbTransaction, error) {
+					// Keep the stake of this validator placed if we plan on staking further
+					return s.removeOldStakers(ctx, effectiveStrategy.IsActive())
+				},
+			},
+			&stakerAction{
+				name: "timeout challenges",
+				gas:  timeoutChallengesGas,
+				run:  s.resolveTimedOutChallenges,
+			},
+		)
+		if effectiveStrategy != configuration.WatchtowerStrategy {
+			actions = append(actions, &stakerAction{
+				name: "prune unreachable nodes",
+				gas:  pruneNodesGas,
+				run:  s.pruneUnreachableNodes,
+			})
 		}
-		arbTx, err = s.resolveTimedOutChallenges(ctx)
-		if err != nil || arbTx != nil {
-			return arbTx, err
+	}
+	actions = append(actions, &stakerAction{
+		name: "stake and resolve nodes",
+		gas:  stakeAndResolutionGas,
+		run: func(ctx context.Context) (*arbtransaction.ArbTransaction, error) {
+			return s.stakeAndResolveNodes(ctx, rawInfo, &info, effectiveStrategy, shouldResolveNodes)
+		},
+	})
+	arbTx, action, err := runScheduledActions(ctx, actions, s.gasBudget(ctx))
+	s.sentAction = action
+	return arbTx, err
+}
+
+// gasBudget returns how much gas the wallet can pay for at the current gas
+// price without exceeding the spending budget, or nil if that couldn't be
+// determined
+func (s *Staker) gasBudget(ctx context.Context) *big.Int {
+	gasPrice, err := s.client.SuggestGasPrice(ctx)
+	if err != nil || gasPrice.Sign() <= 0 {
+		return nil
+	}
+	var budget *big.Int
+	balance, err := s.client.BalanceAt(ctx, s.wallet.From().ToEthAddress(), nil)
+	if err != nil {
+		logger.Warn().Err(err).Msg("error getting wallet balance")
+	} else {
+		budget = new(big.Int).Div(balance, gasPrice)
+	}
+	remaining := s.spending.remaining(time.Now())
+	if remaining == nil {
+		return budget
+	}
+	spendable := new(big.Int).Div(remaining, gasPrice)
+	exhausted := spendable.Cmp(big.NewInt(stakeAndResolutionGas)) < 0
+	if s.spending.setExhausted(exhausted) {
+		s.alertBudgetExhausted(ctx, remaining)
+	}
+	if budget == nil || spendable.Cmp(budget) < 0 {
+		budget = spendable
+	}
+	return budget
+}
+
+// challengeAction prepares our next move in the current challenge. It's sent
+// in its own transaction so that it's never held up behind other actions.
+func (s *Staker) challengeAction(ctx context.Context, info *ethbridge.StakerInfo) (*stakerAction, error) {
+	if err := s.setActiveChallenge(ctx, info); err != nil {
+		return nil, err
+	}
+	deadline, err := s.activeChallenge.Deadline(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.saveChallengeProgress()
+	s.observeChallenge(ctx, deadline, s.lastActCalledBlock)
+	actionDeadline := deadline
+	if deadline == nil {
+		opponentDeadline, err := s.activeChallenge.OpponentDeadline(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.setChallengeTimeout(opponentDeadline)
+		if s.lastActCalledBlock != nil && s.lastActCalledBlock.Cmp(opponentDeadline) > 0 {
+			// Claiming the timeout wins the challenge, so schedule it ahead
+			// of anything else
+			actionDeadline = s.lastActCalledBlock
 		}
+	} else {
+		s.setChallengeTimeout(nil)
+	}
+	return &stakerAction{
+		name:     "challenge move",
+		deadline: actionDeadline,
+		gas:      challengeMoveGas,
+		run: func(ctx context.Context) (*arbtransaction.ArbTransaction, error) {
+			s.clearTransactions()
+			moveStart := time.Now()
+			_, err := s.activeChallenge.HandleConflict(ctx)
+			if s.metrics != nil && err == nil {
+				s.metrics.challengeMove.UpdateSince(moveStart)
+			}
+			s.saveChallengeProgress()
+			if err != nil {
+				return nil, err
+			}
+			if s.builder.TransactionCount() == 0 {
+				return nil, nil
+			}
+			// Challenge moves have a deadline, so bid more to get them included quickly,
+			// and they can be front-run
+			ctx = transactauth.WithUrgency(ctx, transactauth.UrgencyHigh)
+			ctx = ethbridge.WithPrivateSubmission(ctx)
+			return s.executeTransactions(ctx)
+		},
+	}, nil
+}
+
+// stakeAndResolveNodes batches node resolution with any stake changes and new
+// challenges into a single transaction
+func (s *Staker) stakeAndResolveNodes(
+	ctx context.Context,
+	rawInfo *ethbridge.StakerInfo,
+	info *OurStakerInfo,
+	effectiveStrategy configuration.ValidatorStrategy,
+	shouldResolveNodes bool,
+) (*arbtransaction.ArbTransaction, error) {
+	s.clearTransactions()
+	resolvingNode := false
+	if shouldResolveNodes {
 		if err := s.resolveNextNode(ctx, rawInfo, s.fromBlock); err != nil {
 			return nil, err
 		}
+		resolvingNode = s.builder.TransactionCount() > 0
 	}
 
 	addr := s.wallet.Address()
@@ -306,17 +770,14 @@ func (s *Staker) Act(ctx context.Context) (*arbtransaction.ArbTransaction, error
 		if err := s.newStake(ctx); err != nil {
 			return nil, err
 		}
+		// Nothing can be done without a stake if it's over the limit
+		creatingNewStake = s.builder.TransactionCount() > 0
 	}
 
-	if rawInfo != nil {
-		if err = s.handleConflict(ctx, rawInfo); err != nil {
-			return nil, err
-		}
-	}
 	if rawInfo != nil || creatingNewStake {
 		// Advance stake up to 20 times in one transaction
 		for i := 0; info.CanProgress && i < 20; i++ {
-			if err := s.advanceStake(ctx, &info, effectiveStrategy); err != nil {
+			if err := s.advanceStake(ctx, info, effectiveStrategy); err != nil {
 				return nil, err
 			}
 		}
@@ -327,6 +788,11 @@ func (s *Staker) Act(ctx context.Context) (*arbtransaction.ArbTransaction, error
 		}
 	}
 
+	if effectiveStrategy == configuration.WatchtowerStrategy {
+		// Watchtowers only validate, and may not even have a wallet
+		s.clearTransactions()
+		return nil, nil
+	}
 	txCount := s.builder.TransactionCount()
 	if creatingNewStake {
 		// Ignore our stake creation, as it's useless by itself
@@ -338,40 +804,63 @@ func (s *Staker) Act(ctx context.Context) (*arbtransaction.ArbTransaction, error
 	if creatingNewStake {
 		logger.Info().Msg("staking to execute transactions")
 	}
-	return s.wallet.ExecuteTransactions(ctx, s.builder)
+	if resolvingNode {
+		// Confirmations can be front-run
+		ctx = ethbridge.WithPrivateSubmission(ctx)
+	}
+	return s.executeTransactions(ctx)
 }
 
-func (s *Staker) handleConflict(ctx context.Context, info *ethbridge.StakerInfo) error {
-	if info.CurrentChallenge == nil {
-		s.activeChallenge = nil
+func (s *Staker) setActiveChallenge(ctx context.Context, info *ethbridge.StakerInfo) error {
+	if s.activeChallenge != nil && s.activeChallenge.ChallengeAddress() == *info.CurrentChallenge {
 		return nil
 	}
+	logger.Warn().Str("challenge", info.CurrentChallenge.String()).Msg("entered challenge")
 
-	if s.activeChallenge == nil || s.activeChallenge.ChallengeAddress() != *info.CurrentChallenge {
-		logger.Warn().Str("challenge", info.CurrentChallenge.String()).Msg("entered challenge")
-
-		challengeCon, err := ethbridge.NewChallenge(info.CurrentChallenge.ToEthAddress(), s.fromBlock, s.client, s.builder, s.baseCallOpts)
-		if err != nil {
-			return err
-		}
+	challengeCon, err := ethbridge.NewChallenge(info.CurrentChallenge.ToEthAddress(), s.fromBlock, s.client, s.builder, s.baseCallOpts)
+	if err != nil {
+		return err
+	}
 
-		challengedNode, err := s.rollup.LookupChallengedNode(ctx, *info.CurrentChallenge)
-		if err != nil {
-			return err
+	// This is safe to dereference, as we only have a challenge if we have a wallet address
+	ourAddr := common.NewAddressFromEth(*s.wallet.Address())
+	if s.savedChallenge != nil && s.savedChallenge.Challenge == *info.CurrentChallenge {
+		restored, err := challenge.RestoreChallenger(challengeCon, s.sequencerInbox, s.lookup, s.savedChallenge, ourAddr)
+		if err == nil {
+			logger.Info().Str("challenge", info.CurrentChallenge.String()).Msg("resumed saved challenge")
+			restored.SetMoveStrategy(s.moveStrategy)
+			s.activeChallenge = restored
+			return nil
 		}
+		logger.Warn().Err(err).Msg("couldn't resume saved challenge, setting it up from L1")
+	}
 
-		nodeInfo, err := s.rollup.RollupWatcher.LookupNode(ctx, challengedNode)
-		if err != nil {
-			return err
-		}
+	challengedNode, err := s.rollup.LookupChallengedNode(ctx, *info.CurrentChallenge)
+	if err != nil {
+		return err
+	}
 
-		// This is safe to dereference, as handleConflict can only be called if we have a wallet address
-		ourAddr := common.NewAddressFromEth(*s.wallet.Address())
-		s.activeChallenge = challenge.NewChallenger(challengeCon, s.sequencerInbox, s.lookup, nodeInfo.Assertion, ourAddr)
+	nodeInfo, err := s.rollup.RollupWatcher.LookupNode(ctx, challengedNode)
+	if err != nil {
+		return err
 	}
 
-	_, err := s.activeChallenge.HandleConflict(ctx)
-	return err
+	degree, err := s.rollup.ChallengeExecutionBisectionDegree(ctx)
+	if err != nil {
+		return err
+	}
+	if !degree.IsInt64() || degree.Int64() < 2 {
+		return errors.Errorf("invalid challenge bisection degree %v", degree)
+	}
+	s.activeChallenge = challenge.NewChallenger(challengeCon, s.sequencerInbox, s.lookup, nodeInfo.Assertion, ourAddr)
+	s.activeChallenge.SetBisectionDegree(int(degree.Int64()))
+	s.activeChallenge.SetMoveStrategy(s.moveStrategy)
+	s.activeChallenge.SetChallengedNode(challengedNode)
+	challengeAddress := info.CurrentChallenge.ToEthAddress()
+	entry := nodeEntry(JournalChallengeEntered, challengedNode, nodeInfo.NodeHash)
+	entry.Challenge = &challengeAddress
+	s.journal.Record(entry)
+	return nil
 }
 
 func (s *Staker) newStake(ctx context.Context) error {
@@ -389,6 +878,10 @@ func (s *Staker) newStake(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if !s.stakeWithinLimit(stakeAmount) {
+		return nil
+	}
+	s.journal.Buffer(JournalEntry{Event: JournalNewStake, Amount: stakeAmount})
 	return s.rollup.NewStake(ctx, stakeAmount)
 }
 
@@ -397,8 +890,8 @@ func (s *Staker) advanceStake(ctx context.Context, info *OurStakerInfo, effectiv
 	if err != nil {
 		return err
 	}
-	if wrongNodesExist && effectiveStrategy == configuration.WatchtowerStrategy {
-		logger.Error().Msg("found incorrect assertion in watchtower mode")
+	if wrongNodesExist && (effectiveStrategy == configuration.WatchtowerStrategy || effectiveStrategy == configuration.ResolveNodesStrategy) {
+		logger.Error().Msg("found incorrect assertion in watchtower mode; it must be challenged by another validator")
 	}
 	if action == nil {
 		info.CanProgress = false
@@ -412,26 +905,25 @@ func (s *Staker) advanceStake(ctx context.Context, info *OurStakerInfo, effectiv
 			return nil
 		}
 		if !effectiveStrategy.IsActive() {
+			info.CanProgress = false
 			if wrongNodesExist && effectiveStrategy == configuration.DefensiveStrategy {
-				logger.Warn().Msg("bringing defensive validator online because of incorrect assertion")
-				s.bringActiveUntilNode = new(big.Int).Add(info.LatestStakedNode, big.NewInt(1))
+				return s.bringActive(ctx, new(big.Int).Add(info.LatestStakedNode, big.NewInt(1)))
 			}
-			info.CanProgress = false
 			return nil
 		}
 		// Details are already logged with more details in generateNodeAction
 		info.CanProgress = false
 		info.LatestStakedNode = nil
 		info.LatestStakedNodeHash = action.hash
+		s.journal.Buffer(nodeEntry(JournalNodeCreated, nil, action.hash))
 		return s.rollup.StakeOnNewNode(ctx, action.hash, action.assertion, action.prevProposedBlock, action.prevInboxMaxCount, action.sequencerBatchProof)
 	case existingNodeAction:
 		info.LatestStakedNode = action.number
 		info.LatestStakedNodeHash = action.hash
 		if !effectiveStrategy.IsActive() {
 			if wrongNodesExist && effectiveStrategy == configuration.DefensiveStrategy {
-				logger.Warn().Msg("bringing defensive validator online because of incorrect assertion")
-				s.bringActiveUntilNode = action.number
 				info.CanProgress = false
+				return s.bringActive(ctx, action.number)
 			} else {
 				s.inactiveLastCheckedNode = &nodeAndHash{
 					id:   action.number,
@@ -441,12 +933,34 @@ func (s *Staker) advanceStake(ctx context.Context, info *OurStakerInfo, effectiv
 			return nil
 		}
 		logger.Info().Int("node", int((*big.Int)(action.number).Int64())).Msg("staking on existing node")
+		s.journal.Buffer(nodeEntry(JournalStakeMoved, action.number, action.hash))
 		return s.rollup.StakeOnExistingNode(ctx, action.number, action.hash)
 	default:
 		panic("invalid action type")
 	}
 }
 
+// estimateChallengeCost estimates the worst case cost to us of a challenge
+// between the two nodes, at the current gas price
+func (s *Staker) estimateChallengeCost(ctx context.Context, node1 *core.NodeInfo, node2 *core.NodeInfo) (*challenge.CostEstimate, error) {
+	degree, err := s.rollup.ChallengeExecutionBisectionDegree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !degree.IsInt64() {
+		return nil, errors.Errorf("invalid challenge bisection degree %v", degree)
+	}
+	gasPrice, err := s.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gasLength := node1.Assertion.GasUsed()
+	if other := node2.Assertion.GasUsed(); other.Cmp(gasLength) > 0 {
+		gasLength = other
+	}
+	return challenge.EstimateWorstCaseCost(gasLength, int(degree.Int64()), gasPrice), nil
+}
+
 func (s *Staker) createConflict(ctx context.Context, info *ethbridge.StakerInfo) error {
 	if info.CurrentChallenge != nil {
 		return nil
@@ -460,14 +974,26 @@ func (s *Staker) createConflict(ctx context.Context, info *ethbridge.StakerInfo)
 	if err != nil {
 		return err
 	}
-	// Safe to dereference as createConflict is only called when we have a wallet address
-	walletAddr := common.NewAddressFromEth(*s.wallet.Address())
-	for _, staker := range stakers {
-		stakerInfo, err := s.rollup.StakerInfo(ctx, staker)
+	var stakerInfos []*ethbridge.StakerInfo
+	if s.multicall != nil {
+		stakerInfos, err = s.rollup.StakerInfos(ctx, s.multicall, stakers)
 		if err != nil {
 			return err
 		}
-		if stakerInfo.CurrentChallenge != nil {
+	}
+	// Safe to dereference as createConflict is only called when we have a wallet address
+	walletAddr := common.NewAddressFromEth(*s.wallet.Address())
+	for i, staker := range stakers {
+		var stakerInfo *ethbridge.StakerInfo
+		if stakerInfos != nil {
+			stakerInfo = stakerInfos[i]
+		} else {
+			stakerInfo, err = s.rollup.StakerInfo(ctx, staker)
+			if err != nil {
+				return err
+			}
+		}
+		if stakerInfo == nil || stakerInfo.CurrentChallenge != nil {
 			continue
 		}
 		conflictType, node1, node2, err := s.validatorUtils.FindStakerConflict(ctx, walletAddr, staker)
@@ -496,7 +1022,37 @@ func (s *Staker) createConflict(ctx context.Context, info *ethbridge.StakerInfo)
 		if err != nil {
 			return err
 		}
+		// The estimate is only advisory, so failing to make it mustn't stop
+		// us from challenging an incorrect node
+		estimate, err := s.estimateChallengeCost(ctx, node1Info, node2Info)
+		if err != nil {
+			logger.Warn().Err(err).Str("otherStaker", staker2.String()).Msg("failed to estimate challenge cost, starting challenge anyway")
+		} else {
+			logger.Info().
+				Int("rounds", estimate.Rounds).
+				Int("moves", estimate.Moves).
+				Uint64("gas", estimate.Gas).
+				Str("cost", estimate.Cost.String()).
+				Msg("estimated worst case challenge cost")
+		}
+		if challengeStrategy, ok := s.strategy.(ChallengeStrategy); ok && estimate != nil {
+			start, err := challengeStrategy.ShouldStartChallenge(ctx, estimate)
+			if err != nil {
+				return err
+			}
+			if !start {
+				logger.Warn().Str("otherStaker", staker2.String()).Msg("strategy declined to start challenge")
+				continue
+			}
+		}
 		logger.Warn().Int("ourNode", int(node1.Int64())).Int("otherNode", int(node2.Int64())).Str("otherStaker", staker2.String()).Msg("creating challenge")
+		otherStaker := staker2.ToEthAddress()
+		s.journal.Buffer(JournalEntry{
+			Event:     JournalChallengeCreated,
+			Node:      copyInt(node1),
+			OtherNode: copyInt(node2),
+			Staker:    &otherStaker,
+		})
 		return s.rollup.CreateChallenge(
 			ctx,
 			staker1,
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/metrics"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
@@ -131,7 +132,7 @@ func requireChallengeLogs(ctx context.Context, t *testing.T, client ethutils.Eth
 	}
 }
 
-func runStakersTest(t *testing.T, faultConfig challenge.FaultConfig, maxGasPerNode *big.Int, expectedEnd ExpectedChallengeEnd) {
+func runStakersTest(t *testing.T, faultConfig challenge.FaultConfig, maxGasPerNode *big.Int, expectedEnd ExpectedChallengeEnd, config configuration.Validator) {
 	ctx := context.Background()
 
 	arbosPath, err := arbos.Path(false)
@@ -221,7 +222,7 @@ func runStakersTest(t *testing.T, faultConfig challenge.FaultConfig, maxGasPerNo
 	val2, err := ethbridge.NewValidator(nil, validatorWalletFactory, rollupAddr, client, val2Auth, 0, 1000, nil)
 	test.FailIfError(t, err)
 
-	staker, _, err := NewStaker(ctx, mon.Core, client, val, rollupBlock.Int64(), common.NewAddressFromEth(validatorUtilsAddr), configuration.MakeNodesStrategy, bind.CallOpts{}, valAuth, configuration.Validator{})
+	staker, _, err := NewStaker(ctx, mon.Core, client, val, rollupBlock.Int64(), common.NewAddressFromEth(validatorUtilsAddr), configuration.MakeNodesStrategy, bind.CallOpts{}, valAuth, config)
 	test.FailIfError(t, err)
 
 	staker.Validator.GasThreshold = big.NewInt(0)
@@ -309,6 +310,7 @@ func runStakersTest(t *testing.T, faultConfig challenge.FaultConfig, maxGasPerNo
 		healthChan,
 		sequencerFeed,
 		nodeConfig.InboxReader,
+		metrics.NewRegistry(),
 	)
 	test.FailIfError(t, err)
 
@@ -438,18 +440,45 @@ func calculateGasToFirstInbox(t *testing.T) *big.Int {
 }
 
 func TestChallengeToOSP(t *testing.T) {
-	runStakersTest(t, challenge.FaultConfig{DistortMachineAtGas: big.NewInt(1)}, big.NewInt(790), OneStepProof)
+	runStakersTest(t, challenge.FaultConfig{DistortMachineAtGas: big.NewInt(1)}, big.NewInt(790), OneStepProof, configuration.Validator{})
 }
 
 func TestChallengeToInboxOSP(t *testing.T) {
 	inboxGas := calculateGasToFirstInbox(t)
-	runStakersTest(t, challenge.FaultConfig{DistortMachineAtGas: inboxGas}, big.NewInt(745), OneStepProof)
+	runStakersTest(t, challenge.FaultConfig{DistortMachineAtGas: inboxGas}, big.NewInt(745), OneStepProof, configuration.Validator{})
 }
 
 func TestChallengeTimeout(t *testing.T) {
-	runStakersTest(t, challenge.FaultConfig{DistortMachineAtGas: big.NewInt(1)}, big.NewInt(2), Timeout)
+	runStakersTest(t, challenge.FaultConfig{DistortMachineAtGas: big.NewInt(1)}, big.NewInt(2), Timeout, configuration.Validator{})
+}
+
+func TestChallengeToOSPNoForfeit(t *testing.T) {
+	config := configuration.Validator{ChallengeStrategy: "NoForfeit"}
+	runStakersTest(t, challenge.FaultConfig{DistortMachineAtGas: big.NewInt(1)}, big.NewInt(790), OneStepProof, config)
+}
+
+func TestChallengeTimeoutNoForfeit(t *testing.T) {
+	config := configuration.Validator{ChallengeStrategy: "NoForfeit"}
+	runStakersTest(t, challenge.FaultConfig{DistortMachineAtGas: big.NewInt(1)}, big.NewInt(2), Timeout, config)
 }
 
 func TestStakersCooperative(t *testing.T) {
-	runStakersTest(t, challenge.FaultConfig{}, big.NewInt(25000), NoChallenge)
+	runStakersTest(t, challenge.FaultConfig{}, big.NewInt(25000), NoChallenge, configuration.Validator{})
+}
+
+func TestWaitForOwnTransactionBeforeActing(t *testing.T) {
+	s := &Staker{config: configuration.Validator{L1ConfirmationDepth: 5}}
+	if s.waitingForOwnTransaction(s.safeBlock(big.NewInt(100))) {
+		t.Error("waiting without having sent a transaction")
+	}
+
+	s.recordMined(&types.Receipt{BlockNumber: big.NewInt(100)})
+	// An older receipt, resolved after a restart, doesn't move it back
+	s.recordMined(&types.Receipt{BlockNumber: big.NewInt(90)})
+	if !s.waitingForOwnTransaction(s.safeBlock(big.NewInt(104))) {
+		t.Error("acting on state from before our transaction was mined")
+	}
+	if s.waitingForOwnTransaction(s.safeBlock(big.NewInt(105))) {
+		t.Error("still waiting once our transaction reached the confirmation depth")
+	}
 }
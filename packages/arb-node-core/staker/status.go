@@ -0,0 +1,152 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/nodehealth"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+// The staker is considered at head while its last successful action started
+// within this many blocks of the latest L1 block
+const statusHeadToleranceBlocks = 20
+
+type StakerStatus struct {
+	AtHead bool `json:"atHead"`
+	// Leader is false while another process sharing the staking key holds
+	// the validator lease
+	Leader          bool           `json:"leader"`
+	LatestBlock     *big.Int       `json:"latestBlock"`
+	LatestBlockHash ethcommon.Hash `json:"latestBlockHash"`
+	// SafeBlock is the block the staker reads rollup state from, which lags
+	// the latest block by the configured L1 confirmation depth
+	SafeBlock           *big.Int           `json:"safeBlock"`
+	LatestConfirmedNode *big.Int           `json:"latestConfirmedNode"`
+	LatestStakedNode    *big.Int           `json:"latestStakedNode,omitempty"`
+	CurrentChallenge    *ethcommon.Address `json:"currentChallenge,omitempty"`
+	AmountStaked        *big.Int           `json:"amountStaked,omitempty"`
+	// Balance is the L1 balance of the account paying for the staker's transactions
+	Balance *big.Int `json:"balance"`
+	// LastL1Success is when the staker last completed an action, including
+	// waiting for any transaction it sent to be mined
+	LastL1Success time.Time `json:"lastL1Success"`
+	// SpentToday and SpentThisWeek are what the staker's transactions have
+	// cost since it started, within the last day and week
+	SpentToday    *big.Int `json:"spentToday"`
+	SpentThisWeek *big.Int `json:"spentThisWeek"`
+}
+
+type l1SuccessTracker struct {
+	mutex sync.Mutex
+	block *big.Int
+	time  time.Time
+}
+
+// SetHealthChannel makes the staker report its status to the node healthcheck
+// after every action
+func (s *Staker) SetHealthChannel(healthChan chan nodehealth.Log) {
+	s.healthChan = healthChan
+}
+
+// recordL1Success must only be called from the staker's thread
+func (s *Staker) recordL1Success() {
+	s.l1Success.mutex.Lock()
+	defer s.l1Success.mutex.Unlock()
+	s.l1Success.block = copyInt(s.lastActCalledBlock)
+	s.l1Success.time = time.Now()
+}
+
+// Status queries L1 for the current state of the staker
+func (s *Staker) Status(ctx context.Context) (*StakerStatus, error) {
+	latestBlock, err := s.client.BlockInfoByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	safeBlock := s.safeBlock(latestBlock.Number.ToInt())
+	ctx = ethbridge.WithCallBlock(ctx, safeBlock)
+	latestConfirmed, err := s.rollup.LatestConfirmedNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.client.BalanceAt(ctx, s.wallet.From().ToEthAddress(), nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	status := &StakerStatus{
+		LatestBlock:         latestBlock.Number.ToInt(),
+		Leader:              s.IsLeader(),
+		LatestBlockHash:     latestBlock.Hash,
+		SafeBlock:           safeBlock,
+		LatestConfirmedNode: latestConfirmed,
+		Balance:             balance,
+	}
+	if walletAddress := s.wallet.Address(); walletAddress != nil {
+		info, err := s.rollup.StakerInfo(ctx, common.NewAddressFromEth(*walletAddress))
+		if err != nil {
+			return nil, err
+		}
+		if info != nil {
+			status.LatestStakedNode = info.LatestStakedNode
+			status.AmountStaked = info.AmountStaked
+			if info.CurrentChallenge != nil {
+				challengeAddress := info.CurrentChallenge.ToEthAddress()
+				status.CurrentChallenge = &challengeAddress
+			}
+		}
+	}
+	status.SpentToday, status.SpentThisWeek = s.spending.spent(time.Now())
+	s.l1Success.mutex.Lock()
+	status.LastL1Success = s.l1Success.time
+	lastSuccessBlock := copyInt(s.l1Success.block)
+	s.l1Success.mutex.Unlock()
+	if lastSuccessBlock != nil {
+		blocksBehind := new(big.Int).Sub(status.LatestBlock, lastSuccessBlock)
+		status.AtHead = blocksBehind.Cmp(big.NewInt(statusHeadToleranceBlocks)) <= 0
+	}
+	return status, nil
+}
+
+func (s *Staker) reportHealth(ctx context.Context) {
+	if s.healthChan == nil {
+		return
+	}
+	status, err := s.Status(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("error getting staker status")
+		return
+	}
+	var currentChallenge string
+	if status.CurrentChallenge != nil {
+		currentChallenge = status.CurrentChallenge.Hex()
+	}
+	s.healthChan <- nodehealth.Log{Comp: "Staker", Var: "atHead", ValBool: status.AtHead}
+	s.healthChan <- nodehealth.Log{Comp: "Staker", Var: "latestBlock", ValBigInt: status.LatestBlock}
+	s.healthChan <- nodehealth.Log{Comp: "Staker", Var: "latestBlockHash", ValStr: status.LatestBlockHash.Hex()}
+	s.healthChan <- nodehealth.Log{Comp: "Staker", Var: "latestConfirmedNode", ValBigInt: status.LatestConfirmedNode}
+	s.healthChan <- nodehealth.Log{Comp: "Staker", Var: "currentChallenge", ValStr: currentChallenge}
+	s.healthChan <- nodehealth.Log{Comp: "Staker", Var: "balance", ValBigInt: status.Balance}
+	s.healthChan <- nodehealth.Log{Comp: "Staker", Var: "lastL1Success", ValInt: status.LastL1Success.Unix()}
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/challenge"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+)
+
+// StrategyState is what a Strategy is told about the rollup when the staker
+// acts
+type StrategyState struct {
+	// Staked is whether the staker's wallet currently has a stake
+	Staked bool
+	// Forked is whether the unresolved nodes have more than one branch
+	Forked bool
+	// BringingActive is whether an incorrect node was found and the staker
+	// hasn't yet staked past it
+	BringingActive bool
+	// RequiredStakeElevated checks L1 for whether the stake currently required
+	// is above the rollup's base stake
+	RequiredStakeElevated func(ctx context.Context) (bool, error)
+}
+
+// Strategy decides when and where the staker places or moves its stake
+type Strategy interface {
+	// Behavior picks the built in behavior the staker follows for one action.
+	// Watchtower and Defensive never stake, StakeLatest stakes on the latest
+	// correct node, and MakeNodes also creates nodes.
+	Behavior(state StrategyState) configuration.ValidatorStrategy
+	// ShouldResolveNodes decides whether the staker confirms and rejects nodes
+	// and removes old stakes for one action, given the behavior it's following
+	ShouldResolveNodes(ctx context.Context, state StrategyState, behavior configuration.ValidatorStrategy) (bool, error)
+}
+
+// ChallengeStrategy can be implemented by a Strategy to decide whether to
+// start a challenge against a conflicting staker, given the worst case cost
+// of playing it out. The built in strategies always start challenges. It
+// isn't consulted if the cost couldn't be estimated, in which case the
+// challenge is started regardless.
+type ChallengeStrategy interface {
+	ShouldStartChallenge(ctx context.Context, estimate *challenge.CostEstimate) (bool, error)
+}
+
+// watchtowerStrategy validates nodes without staking. If escalate is set it
+// acts defensively, staking once it finds an incorrect node, but unlike the
+// defensive strategy it stays inactive when the rollup forks.
+type watchtowerStrategy struct {
+	escalate bool
+}
+
+func (w watchtowerStrategy) Behavior(state StrategyState) configuration.ValidatorStrategy {
+	if !w.escalate {
+		return configuration.WatchtowerStrategy
+	}
+	if state.BringingActive {
+		return configuration.StakeLatestStrategy
+	}
+	return configuration.DefensiveStrategy
+}
+
+func (w watchtowerStrategy) ShouldResolveNodes(ctx context.Context, state StrategyState, behavior configuration.ValidatorStrategy) (bool, error) {
+	if !w.escalate {
+		return false, nil
+	}
+	return stakeLatestStrategy{}.ShouldResolveNodes(ctx, state, behavior)
+}
+
+// defensiveStrategy stays inactive unless the rollup forks or an incorrect
+// node is found, when it stakes on the latest correct node
+type defensiveStrategy struct{}
+
+func (defensiveStrategy) Behavior(state StrategyState) configuration.ValidatorStrategy {
+	if state.Forked || state.BringingActive {
+		return configuration.StakeLatestStrategy
+	}
+	return configuration.DefensiveStrategy
+}
+
+func (defensiveStrategy) ShouldResolveNodes(ctx context.Context, state StrategyState, behavior configuration.ValidatorStrategy) (bool, error) {
+	return stakeLatestStrategy{}.ShouldResolveNodes(ctx, state, behavior)
+}
+
+type stakeLatestStrategy struct{}
+
+func (stakeLatestStrategy) Behavior(StrategyState) configuration.ValidatorStrategy {
+	return configuration.StakeLatestStrategy
+}
+
+// ShouldResolveNodes resolves nodes before placing a new stake if the
+// required stake is elevated, in an attempt to reduce it
+func (stakeLatestStrategy) ShouldResolveNodes(ctx context.Context, state StrategyState, behavior configuration.ValidatorStrategy) (bool, error) {
+	if !behavior.IsActive() || state.Staked {
+		return false, nil
+	}
+	return state.RequiredStakeElevated(ctx)
+}
+
+type makeNodesStrategy struct{}
+
+func (makeNodesStrategy) Behavior(StrategyState) configuration.ValidatorStrategy {
+	return configuration.MakeNodesStrategy
+}
+
+func (makeNodesStrategy) ShouldResolveNodes(context.Context, StrategyState, configuration.ValidatorStrategy) (bool, error) {
+	return true, nil
+}
+
+// resolveNodesStrategy never stakes, but confirms and rejects nodes and
+// removes old stakes so that the rollup keeps progressing
+type resolveNodesStrategy struct{}
+
+func (resolveNodesStrategy) Behavior(StrategyState) configuration.ValidatorStrategy {
+	return configuration.ResolveNodesStrategy
+}
+
+func (resolveNodesStrategy) ShouldResolveNodes(context.Context, StrategyState, configuration.ValidatorStrategy) (bool, error) {
+	return true, nil
+}
+
+// BuiltinStrategy returns the implementation of one of the strategies
+// selectable in the config, or nil for UnknownStrategy
+func BuiltinStrategy(strategy configuration.ValidatorStrategy) Strategy {
+	switch strategy {
+	case configuration.WatchtowerStrategy:
+		return watchtowerStrategy{}
+	case configuration.DefensiveStrategy:
+		return defensiveStrategy{}
+	case configuration.StakeLatestStrategy:
+		return stakeLatestStrategy{}
+	case configuration.MakeNodesStrategy:
+		return makeNodesStrategy{}
+	case configuration.ResolveNodesStrategy:
+		return resolveNodesStrategy{}
+	default:
+		return nil
+	}
+}
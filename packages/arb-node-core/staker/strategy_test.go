@@ -0,0 +1,97 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestStrategyBehavior(t *testing.T) {
+	quiet := StrategyState{}
+	forked := StrategyState{Forked: true}
+	incorrectNode := StrategyState{BringingActive: true}
+
+	cases := []struct {
+		name     string
+		strategy Strategy
+		state    StrategyState
+		expected configuration.ValidatorStrategy
+	}{
+		{"watchtower", watchtowerStrategy{}, incorrectNode, configuration.WatchtowerStrategy},
+		{"escalating watchtower", watchtowerStrategy{escalate: true}, quiet, configuration.DefensiveStrategy},
+		{"escalating watchtower fork", watchtowerStrategy{escalate: true}, forked, configuration.DefensiveStrategy},
+		{"escalated watchtower", watchtowerStrategy{escalate: true}, incorrectNode, configuration.StakeLatestStrategy},
+		{"defensive", defensiveStrategy{}, quiet, configuration.DefensiveStrategy},
+		{"defensive fork", defensiveStrategy{}, forked, configuration.StakeLatestStrategy},
+		{"defensive incorrect node", defensiveStrategy{}, incorrectNode, configuration.StakeLatestStrategy},
+		{"make nodes", makeNodesStrategy{}, forked, configuration.MakeNodesStrategy},
+		{"resolve nodes", resolveNodesStrategy{}, incorrectNode, configuration.ResolveNodesStrategy},
+	}
+	for _, c := range cases {
+		if behavior := c.strategy.Behavior(c.state); behavior != c.expected {
+			t.Errorf("%v: expected behavior %v, got %v", c.name, c.expected, behavior)
+		}
+	}
+}
+
+func TestStrategyShouldResolveNodes(t *testing.T) {
+	ctx := context.Background()
+	elevatedChecked := false
+	state := StrategyState{
+		RequiredStakeElevated: func(context.Context) (bool, error) {
+			elevatedChecked = true
+			return true, nil
+		},
+	}
+
+	resolve, err := stakeLatestStrategy{}.ShouldResolveNodes(ctx, state, configuration.StakeLatestStrategy)
+	test.FailIfError(t, err)
+	if !resolve || !elevatedChecked {
+		t.Error("unstaked validator should resolve nodes while the required stake is elevated")
+	}
+
+	elevatedChecked = false
+	state.Staked = true
+	resolve, err = stakeLatestStrategy{}.ShouldResolveNodes(ctx, state, configuration.StakeLatestStrategy)
+	test.FailIfError(t, err)
+	if resolve || elevatedChecked {
+		t.Error("staked validator shouldn't resolve nodes")
+	}
+
+	state.Staked = false
+	resolve, err = defensiveStrategy{}.ShouldResolveNodes(ctx, state, configuration.DefensiveStrategy)
+	test.FailIfError(t, err)
+	if resolve {
+		t.Error("inactive defensive validator shouldn't resolve nodes")
+	}
+
+	resolve, err = watchtowerStrategy{}.ShouldResolveNodes(ctx, state, configuration.WatchtowerStrategy)
+	test.FailIfError(t, err)
+	if resolve {
+		t.Error("watchtower shouldn't resolve nodes")
+	}
+
+	resolve, err = resolveNodesStrategy{}.ShouldResolveNodes(ctx, state, configuration.ResolveNodesStrategy)
+	test.FailIfError(t, err)
+	if !resolve {
+		t.Error("resolve nodes strategy should resolve nodes")
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+// How often to check L1 for changes to the rollup between staker actions if
+// not configured
+const defaultActivityPollInterval = 10 * time.Second
+
+// The average time between L1 blocks if not configured
+const defaultL1BlockTime = 13 * time.Second
+
+// actTrigger tracks the L1 state the staker last acted on, so that it can act
+// as soon as something relevant happens instead of on a fixed schedule
+type actTrigger struct {
+	// The last L1 block checked for rollup activity
+	lastCheckedBlock *big.Int
+	// The block at which the first unresolved node can be resolved
+	nextDeadline *big.Int
+	// The first block in which our opponent in the active challenge can be
+	// timed out, or nil if it's our turn or there's no active challenge
+	challengeTimeout *big.Int
+}
+
+// setChallengeTimeout records when our opponent's deadline in the active
+// challenge expires, so that the staker wakes up to claim the timeout as soon
+// as it can rather than on its next scheduled action
+func (s *Staker) setChallengeTimeout(opponentDeadline *big.Int) {
+	if opponentDeadline == nil {
+		s.trigger.challengeTimeout = nil
+		return
+	}
+	s.trigger.challengeTimeout = new(big.Int).Add(opponentDeadline, big.NewInt(1))
+}
+
+// l1BlockTime returns the configured average time between L1 blocks, which
+// is used to estimate when block deadlines will pass
+func (s *Staker) l1BlockTime() time.Duration {
+	if s.config.L1BlockTime <= 0 {
+		return defaultL1BlockTime
+	}
+	return s.config.L1BlockTime
+}
+
+// Wake makes the staker act immediately rather than waiting for rollup activity
+func (s *Staker) Wake() {
+	select {
+	case s.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// updateTrigger records the current L1 block and the deadline of the first
+// unresolved node after the staker acts
+func (s *Staker) updateTrigger(ctx context.Context) error {
+	latest, err := s.client.BlockInfoByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	s.trigger.lastCheckedBlock = latest.Number.ToInt()
+	s.trigger.nextDeadline = nil
+	counts, err := s.rollupNodeCounts(ctx)
+	if err != nil {
+		return err
+	}
+	firstUnresolved := counts.FirstUnresolvedNode
+	if firstUnresolved.Cmp(counts.LatestNodeCreated) > 0 {
+		// No nodes to resolve
+		return nil
+	}
+	node, err := s.rollup.GetNode(ctx, firstUnresolved)
+	if err != nil {
+		return err
+	}
+	s.trigger.nextDeadline, err = node.DeadlineBlock(ctx)
+	if err != nil {
+		return err
+	}
+	logger.Debug().
+		Str("node", firstUnresolved.String()).
+		Str("deadline", s.trigger.nextDeadline.String()).
+		Dur("timeUntilDeadline", common.NewTimeBlocks(s.trigger.nextDeadline).DurationUntil(common.NewTimeBlocks(s.trigger.lastCheckedBlock), s.l1BlockTime())).
+		Msg("waiting for next node deadline")
+	return nil
+}
+
+// shouldWake checks whether the rollup has changed, a node's deadline has
+// passed, or our challenge opponent can be timed out since the staker last
+// acted
+func (s *Staker) shouldWake(ctx context.Context) (bool, error) {
+	if s.trigger.lastCheckedBlock == nil {
+		return true, nil
+	}
+	latest, err := s.client.BlockInfoByNumber(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	latestBlock := latest.Number.ToInt()
+	if s.trigger.nextDeadline != nil && latestBlock.Cmp(s.trigger.nextDeadline) >= 0 {
+		return true, nil
+	}
+	if s.trigger.challengeTimeout != nil && latestBlock.Cmp(s.trigger.challengeTimeout) >= 0 {
+		logger.Info().Str("block", latestBlock.String()).Msg("opponent's challenge deadline passed, claiming timeout")
+		return true, nil
+	}
+	if latestBlock.Cmp(s.trigger.lastCheckedBlock) <= 0 {
+		return false, nil
+	}
+	fromBlock := new(big.Int).Add(s.trigger.lastCheckedBlock, big.NewInt(1))
+	active, err := s.rollup.HasActivity(ctx, fromBlock, latestBlock)
+	if err != nil {
+		return false, err
+	}
+	s.trigger.lastCheckedBlock = latestBlock
+	return active, nil
+}
+
+// waitForActivity blocks until the staker should act again, which is when the
+// rollup changes, a node's or challenge opponent's deadline passes, Wake is
+// called, or maxDelay elapses
+func (s *Staker) waitForActivity(ctx context.Context, maxDelay time.Duration) {
+	if err := s.updateTrigger(ctx); err != nil {
+		logger.Warn().Err(err).Msg("error checking rollup state, falling back to fixed delay")
+		s.trigger.lastCheckedBlock = nil
+	}
+	s.recordSnapshot()
+	pollInterval := s.config.Confirm.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultActivityPollInterval
+	}
+	deadline := time.After(maxDelay)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			return
+		case <-s.wakeChan:
+			return
+		case <-s.stopChan:
+			return
+		case <-time.After(pollInterval):
+		}
+		if s.trigger.lastCheckedBlock == nil {
+			// Without a known starting point, only the fixed delay applies
+			continue
+		}
+		wake, err := s.shouldWake(ctx)
+		if err != nil {
+			logger.Warn().Err(err).Msg("error checking for rollup activity")
+			continue
+		}
+		if wake {
+			return
+		}
+	}
+}
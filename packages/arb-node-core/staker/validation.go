@@ -0,0 +1,185 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+// NodeValidation is the result of checking a node's assertion against the
+// local database
+type NodeValidation struct {
+	Node  *core.NodeInfo
+	Valid bool
+	// Skipped is set if the local database hasn't processed the messages
+	// read by the node, in which case Valid is meaningless
+	Skipped bool
+}
+
+// validationJob is a set of sibling nodes, which share a start state and so
+// are checked with one execution tracker. Jobs with different start states
+// execute separate ranges of the chain and can be checked in parallel.
+type validationJob struct {
+	indexes   []int
+	inboxAccs []common.Hash
+}
+
+// ValidationPipeline checks many nodes at once, such as when catching up
+// with the rollup. Inbox accumulators are looked up ahead of execution, and
+// nodes with different start states are executed on separate workers.
+type ValidationPipeline struct {
+	lookup  core.ArbCoreLookup
+	workers int
+}
+
+func NewValidationPipeline(lookup core.ArbCoreLookup, workers int) *ValidationPipeline {
+	if workers < 1 {
+		workers = 1
+	}
+	return &ValidationPipeline{
+		lookup:  lookup,
+		workers: workers,
+	}
+}
+
+// groupByStartState splits nodes into jobs of siblings, ordered by the first
+// node of each job
+func groupByStartState(nodes []*core.NodeInfo) []*validationJob {
+	var jobs []*validationJob
+	jobsByStart := make(map[common.Hash]*validationJob)
+	for i, nd := range nodes {
+		start := nd.Assertion.Before.CutHash()
+		job, ok := jobsByStart[start]
+		if !ok {
+			job = &validationJob{}
+			jobsByStart[start] = job
+			jobs = append(jobs, job)
+		}
+		job.indexes = append(job.indexes, i)
+	}
+	return jobs
+}
+
+// Validate checks every node, returning the results in the same order as
+// nodes. The first error encountered stops the pipeline.
+func (p *ValidationPipeline) Validate(ctx context.Context, nodes []*core.NodeInfo) ([]*NodeValidation, error) {
+	results := make([]*NodeValidation, len(nodes))
+	for i, nd := range nodes {
+		results[i] = &NodeValidation{Node: nd}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var errOnce sync.Once
+	var firstErr error
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	jobs := make(chan *validationJob, p.workers)
+	go func() {
+		defer close(jobs)
+		for _, job := range groupByStartState(nodes) {
+			if err := p.prefetch(job, results); err != nil {
+				fail(err)
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := p.execute(job, results); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return results, nil
+}
+
+// prefetch looks up the inbox accumulators the job's nodes are checked
+// against, marking nodes the database can't check yet as skipped
+func (p *ValidationPipeline) prefetch(job *validationJob, results []*NodeValidation) error {
+	job.inboxAccs = make([]common.Hash, len(job.indexes))
+	for i, index := range job.indexes {
+		nd := results[index].Node
+		if p.lookup.MachineMessagesRead().Cmp(nd.Assertion.After.TotalMessagesRead) < 0 {
+			results[index].Skipped = true
+			continue
+		}
+		inboxAcc, err := assertionInboxAcc(p.lookup, nd)
+		if err != nil {
+			return err
+		}
+		job.inboxAccs[i] = inboxAcc
+	}
+	return nil
+}
+
+func (p *ValidationPipeline) execute(job *validationJob, results []*NodeValidation) error {
+	var gasesUsed []*big.Int
+	for _, index := range job.indexes {
+		if !results[index].Skipped {
+			gasesUsed = append(gasesUsed, results[index].Node.Assertion.After.TotalGasConsumed)
+		}
+	}
+	if len(gasesUsed) == 0 {
+		return nil
+	}
+	execTracker := core.NewExecutionTracker(p.lookup, false, gasesUsed, true)
+	for i, index := range job.indexes {
+		result := results[index]
+		if result.Skipped {
+			continue
+		}
+		valid, err := core.IsAssertionValid(result.Node.Assertion, execTracker, job.inboxAccs[i])
+		if err != nil {
+			return err
+		}
+		result.Valid = valid
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package staker
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+func executionStateAtGas(gas int64) *core.ExecutionState {
+	return &core.ExecutionState{
+		TotalMessagesRead: big.NewInt(0),
+		TotalGasConsumed:  big.NewInt(gas),
+		TotalSendCount:    big.NewInt(0),
+		TotalLogCount:     big.NewInt(0),
+	}
+}
+
+func nodeFromGas(num int64, before int64, after int64) *core.NodeInfo {
+	return &core.NodeInfo{
+		NodeNum: big.NewInt(num),
+		Assertion: &core.Assertion{
+			Before: executionStateAtGas(before),
+			After:  executionStateAtGas(after),
+		},
+	}
+}
+
+func TestGroupByStartState(t *testing.T) {
+	nodes := []*core.NodeInfo{
+		nodeFromGas(1, 0, 100),
+		nodeFromGas(2, 100, 200),
+		// A sibling of node 2 with a different assertion
+		nodeFromGas(3, 100, 250),
+		nodeFromGas(4, 200, 300),
+	}
+	jobs := groupByStartState(nodes)
+	expected := [][]int{{0}, {1, 2}, {3}}
+	if len(jobs) != len(expected) {
+		t.Fatalf("expected %v jobs, got %v", len(expected), len(jobs))
+	}
+	for i, job := range jobs {
+		if len(job.indexes) != len(expected[i]) {
+			t.Fatalf("job %v: expected nodes %v, got %v", i, expected[i], job.indexes)
+		}
+		for j := range job.indexes {
+			if job.indexes[j] != expected[i][j] {
+				t.Fatalf("job %v: expected nodes %v, got %v", i, expected[i], job.indexes)
+			}
+		}
+	}
+}
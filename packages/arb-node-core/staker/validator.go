@@ -27,9 +27,54 @@ type Validator struct {
 	lookup         core.ArbCoreLookup
 	builder        *ethbridge.BuilderBackend
 	wallet         *ethbridge.ValidatorWallet
+	multicall      *ethbridge.Multicaller
 	GasThreshold   *big.Int
 	SendThreshold  *big.Int
 	BlockThreshold *big.Int
+
+	// MaxConfirmationsPerTx limits how many nodes are confirmed together
+	MaxConfirmationsPerTx int
+	// Confirmations are postponed while the gas price is above
+	// ConfirmGasPriceLimit, unless the node's deadline passed more than
+	// ConfirmMaxDelayBlocks ago. A nil limit disables postponing.
+	ConfirmGasPriceLimit  *big.Int
+	ConfirmMaxDelayBlocks *big.Int
+
+	journal            *Journal
+	assertionListeners []AssertionListener
+	reportedAssertions reportedAssertions
+	pendingAssertions  []*AssertionStats
+	confirmations      confirmationCache
+	// forceConfirmation skips postponing confirmations for the current action
+	forceConfirmation bool
+}
+
+// SetJournal makes the validator record its decisions to journal
+func (v *Validator) SetJournal(journal *Journal) {
+	v.journal = journal
+}
+
+// clearTransactions starts building a new transaction, dropping any decisions
+// and assertion stats buffered for the previous one
+func (v *Validator) clearTransactions() {
+	v.builder.ClearTransactions()
+	v.journal.Discard()
+	v.pendingAssertions = nil
+}
+
+// executeTransactions sends the transaction built so far, journaling the
+// decisions behind it and reporting the assertions it creates only once it's
+// been sent
+func (v *Validator) executeTransactions(ctx context.Context) (*arbtransaction.ArbTransaction, error) {
+	arbTx, err := v.wallet.ExecuteTransactions(ctx, v.builder)
+	if err != nil || arbTx == nil {
+		v.journal.Discard()
+		v.pendingAssertions = nil
+		return arbTx, err
+	}
+	v.journal.Commit(arbTx.Hash())
+	v.reportBufferedAssertions()
+	return arbTx, nil
 }
 
 func NewValidator(
@@ -87,6 +132,9 @@ func NewValidator(
 		GasThreshold:   big.NewInt(100_000_000_000),
 		SendThreshold:  big.NewInt(5),
 		BlockThreshold: big.NewInt(960),
+
+		MaxConfirmationsPerTx: 10,
+		ConfirmMaxDelayBlocks: big.NewInt(0),
 	}, nil
 }
 
@@ -127,6 +175,93 @@ func (v *Validator) resolveTimedOutChallenges(ctx context.Context) (*arbtransact
 	return v.wallet.TimeoutChallenges(ctx, challengesToEliminate)
 }
 
+// maxPrunedNodesPerTx limits how many unreachable nodes are rejected in one
+// transaction
+const maxPrunedNodesPerTx = 20
+
+// pruneUnreachableNodes rejects unresolved nodes which can never be confirmed
+// because they don't descend from the latest confirmed node, freeing their
+// storage on chain, and drops them from the local confirmation cache. Nodes
+// are resolved in order, so only the run of such nodes starting at the first
+// unresolved node can be rejected, which the contract allows without checking
+// deadlines or stakers. Zombies which are only staked on resolved nodes are
+// removed in the same transaction. Nothing is sent if there's nothing to prune.
+func (v *Validator) pruneUnreachableNodes(ctx context.Context) (*arbtransaction.ArbTransaction, error) {
+	walletAddr := v.wallet.Address()
+	if walletAddr == nil {
+		return nil, nil
+	}
+	firstUnresolved, err := v.rollup.FirstUnresolvedNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latestConfirmed, err := v.rollup.LatestConfirmedNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latestCreated, err := v.rollup.LatestNodeCreated(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v.clearTransactions()
+	if err := v.removeOldZombies(ctx, firstUnresolved); err != nil {
+		return nil, err
+	}
+	next := new(big.Int).Set(firstUnresolved)
+	pruned := 0
+	for pruned < maxPrunedNodesPerTx && next.Cmp(latestCreated) <= 0 {
+		node, err := v.rollup.GetNode(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		prev, err := node.Prev(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if prev.Cmp(latestConfirmed) == 0 {
+			break
+		}
+		if err := v.rollup.RejectNextNode(ctx, *walletAddr); err != nil {
+			return nil, err
+		}
+		v.journal.Buffer(JournalEntry{Event: JournalNodeRejected, Node: copyInt(next)})
+		pruned++
+		next = new(big.Int).Add(next, big.NewInt(1))
+	}
+	if v.builder.TransactionCount() == 0 {
+		return nil, nil
+	}
+	if pruned > 0 {
+		logger.Info().
+			Str("first", firstUnresolved.String()).
+			Int("count", pruned).
+			Msg("Rejecting nodes unreachable from the latest confirmed node")
+		v.confirmations.prune(next)
+	}
+	return v.executeTransactions(ctx)
+}
+
+// removeOldZombies removes zombies staked only on nodes before
+// firstUnresolved, the same condition the contract checks
+func (v *Validator) removeOldZombies(ctx context.Context, firstUnresolved *big.Int) error {
+	zombieNodes, err := v.rollup.ZombieLatestStakedNodes(ctx)
+	if err != nil {
+		return err
+	}
+	oldZombies := 0
+	for _, node := range zombieNodes {
+		if node.Cmp(firstUnresolved) < 0 {
+			oldZombies++
+		}
+	}
+	if oldZombies == 0 {
+		return nil
+	}
+	logger.Info().Int("count", oldZombies).Msg("Removing old zombies")
+	return v.rollup.RemoveOldZombies(ctx, big.NewInt(0))
+}
+
 func (v *Validator) resolveNextNode(ctx context.Context, info *ethbridge.StakerInfo, fromBlock int64) error {
 	confirmType, err := v.validatorUtils.CheckDecidableNextNode(ctx)
 	if err != nil {
@@ -144,6 +279,7 @@ func (v *Validator) resolveNextNode(ctx context.Context, info *ethbridge.StakerI
 	if err != nil {
 		return err
 	}
+	v.confirmations.prune(unresolvedNodeIndex)
 	switch confirmType {
 	case ethbridge.CONFIRM_TYPE_INVALID:
 		addr := v.wallet.Address()
@@ -152,16 +288,45 @@ func (v *Validator) resolveNextNode(ctx context.Context, info *ethbridge.StakerI
 			return nil
 		}
 		logger.Info().Int("node", int(unresolvedNodeIndex.Int64())).Msg("Rejecting node")
+		v.journal.Buffer(JournalEntry{Event: JournalNodeRejected, Node: copyInt(unresolvedNodeIndex)})
 		return v.rollup.RejectNextNode(ctx, *addr)
 	case ethbridge.CONFIRM_TYPE_VALID:
-		confCount := 1
-		if shuttingDownForNitro {
+		confCount := v.MaxConfirmationsPerTx
+		if confCount < 1 {
+			confCount = 1
+		}
+		if shuttingDownForNitro && confCount < 10 {
 			confCount = 10
 		}
+		postpone, err := v.shouldPostponeConfirmation(ctx, unresolvedNodeIndex)
+		if err != nil {
+			return err
+		}
+		if postpone && !v.forceConfirmation {
+			return nil
+		}
+		var latestBlock *big.Int
+		if confCount > 1 {
+			latestBlockInfo, err := v.client.BlockInfoByNumber(ctx, nil)
+			if err != nil {
+				return err
+			}
+			latestBlock = latestBlockInfo.Number.ToInt()
+		}
 		totalSendSize := 0
 		var lastStakerCount *big.Int
 		for i := 0; i < confCount && unresolvedNodeIndex.Cmp(latestNodeCreated) <= 0; i++ {
-			nodeInfo, err := v.rollup.RollupWatcher.LookupNode(ctx, unresolvedNodeIndex)
+			if i > 0 {
+				// Only the first node was checked by CheckDecidableNextNode
+				confirmable, err := v.isConfirmableAfterPrev(ctx, unresolvedNodeIndex, latestBlock)
+				if err != nil {
+					return err
+				}
+				if !confirmable {
+					break
+				}
+			}
+			confData, err := v.lookupConfirmationData(ctx, unresolvedNodeIndex)
 			if err != nil {
 				return err
 			}
@@ -181,22 +346,16 @@ func (v *Validator) resolveNextNode(ctx context.Context, info *ethbridge.StakerI
 			} else {
 				lastStakerCount = stakerCount
 			}
-			sendCount := new(big.Int).Sub(nodeInfo.Assertion.After.TotalSendCount, nodeInfo.Assertion.Before.TotalSendCount)
-			sends, err := v.lookup.GetSends(nodeInfo.Assertion.Before.TotalSendCount, sendCount)
-			if err != nil {
-				return errors.Wrap(err, "catching up to chain")
-			}
-			for _, send := range sends {
-				totalSendSize += 32 + len(send)
-			}
+			totalSendSize += confData.sendSize
 			if i > 0 && totalSendSize >= 64*1024 {
 				break
 			}
 			logger.Info().Int("node", int(unresolvedNodeIndex.Int64())).Msg("Confirming node")
-			err = v.rollup.ConfirmNextNode(ctx, nodeInfo.Assertion, sends)
+			err = v.rollup.ConfirmNextNode(ctx, confData.nodeInfo.Assertion, confData.sends)
 			if err != nil {
 				return err
 			}
+			v.journal.Buffer(nodeEntry(JournalNodeConfirmed, unresolvedNodeIndex, confData.nodeInfo.NodeHash))
 			unresolvedNodeIndex.Add(unresolvedNodeIndex, big.NewInt(1))
 		}
 		return nil
@@ -205,6 +364,67 @@ func (v *Validator) resolveNextNode(ctx context.Context, info *ethbridge.StakerI
 	}
 }
 
+// isConfirmableAfterPrev checks whether node can be confirmed in the same
+// transaction as the node before it. Staker counts are compared separately by
+// the caller.
+func (v *Validator) isConfirmableAfterPrev(ctx context.Context, node *big.Int, latestBlock *big.Int) (bool, error) {
+	nodeWatcher, err := v.rollup.GetNode(ctx, node)
+	if err != nil {
+		return false, err
+	}
+	prev, err := nodeWatcher.Prev(ctx)
+	if err != nil {
+		return false, err
+	}
+	if new(big.Int).Add(prev, big.NewInt(1)).Cmp(node) != 0 {
+		// Node isn't a child of the node being confirmed before it
+		return false, nil
+	}
+	deadline, err := nodeWatcher.DeadlineBlock(ctx)
+	if err != nil {
+		return false, err
+	}
+	return latestBlock.Cmp(deadline) >= 0, nil
+}
+
+// shouldPostponeConfirmation returns true if confirming node can wait for the
+// gas price to come down
+func (v *Validator) shouldPostponeConfirmation(ctx context.Context, node core.NodeID) (bool, error) {
+	if v.ConfirmGasPriceLimit == nil {
+		return false, nil
+	}
+	gasPrice, err := v.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return false, err
+	}
+	if gasPrice.Cmp(v.ConfirmGasPriceLimit) <= 0 {
+		return false, nil
+	}
+	nodeWatcher, err := v.rollup.GetNode(ctx, node)
+	if err != nil {
+		return false, err
+	}
+	deadline, err := nodeWatcher.DeadlineBlock(ctx)
+	if err != nil {
+		return false, err
+	}
+	latest, err := v.client.BlockInfoByNumber(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	maxDelayBlock := new(big.Int).Add(deadline, v.ConfirmMaxDelayBlocks)
+	if latest.Number.ToInt().Cmp(maxDelayBlock) >= 0 {
+		return false, nil
+	}
+	logger.
+		Info().
+		Str("node", (*big.Int)(node).String()).
+		Str("gasPrice", gasPrice.String()).
+		Str("confirmBy", maxDelayBlock.String()).
+		Msg("postponing confirmation as gas price is high")
+	return true, nil
+}
+
 func (v *Validator) isRequiredStakeElevated(ctx context.Context) (bool, error) {
 	requiredStake, err := v.rollup.CurrentRequiredStake(ctx)
 	if err != nil {
@@ -350,6 +570,7 @@ func (v *Validator) generateNodeAction(ctx context.Context, stakerInfo *OurStake
 		gasesUsed = append(gasesUsed, maximumGasTarget)
 	}
 
+	startSteps := cursor.TotalSteps()
 	execTracker := core.NewExecutionTrackerWithInitialCursor(v.lookup, false, gasesUsed, cursor, true)
 
 	var correctNode nodeAction
@@ -363,20 +584,9 @@ func (v *Validator) generateNodeAction(ctx context.Context, stakerInfo *OurStake
 			break
 		}
 		if correctNode == nil {
-			var batchItemEndAcc common.Hash
-			if nd.Assertion.After.TotalMessagesRead.Cmp(nd.AfterInboxBatchEndCount) == 0 {
-				batchItemEndAcc = nd.AfterInboxBatchAcc
-			} else if nd.Assertion.After.TotalMessagesRead.Cmp(big.NewInt(0)) > 0 {
-				var haveBatchEndAcc common.Hash
-				index1 := new(big.Int).Sub(nd.Assertion.After.TotalMessagesRead, big.NewInt(1))
-				index2 := new(big.Int).Sub(nd.AfterInboxBatchEndCount, big.NewInt(1))
-				batchItemEndAcc, haveBatchEndAcc, err = v.lookup.GetInboxAccPair(index1, index2)
-				if err != nil {
-					return nil, false, err
-				}
-				if haveBatchEndAcc != nd.AfterInboxBatchAcc {
-					return nil, false, errors.New("inbox reorg detected by batch end acc mismatch")
-				}
+			batchItemEndAcc, err := assertionInboxAcc(v.lookup, nd)
+			if err != nil {
+				return nil, false, err
 			}
 			valid, err := core.IsAssertionValid(nd.Assertion, execTracker, batchItemEndAcc)
 			if err != nil {
@@ -384,6 +594,7 @@ func (v *Validator) generateNodeAction(ctx context.Context, stakerInfo *OurStake
 			}
 			if valid {
 				logger.Info().Int("node", int((*big.Int)(nd.NodeNum).Int64())).Msg("found correct node")
+				v.journal.Record(nodeEntry(JournalNodeValidated, nd.NodeNum, nd.NodeHash))
 				correctNode = existingNodeAction{
 					number: nd.NodeNum,
 					hash:   nd.NodeHash,
@@ -392,6 +603,13 @@ func (v *Validator) generateNodeAction(ctx context.Context, stakerInfo *OurStake
 				if err != nil {
 					return nil, false, err
 				}
+				if stakerInfo.latestExecutionCursor != nil {
+					timeSinceParent := new(big.Int).Sub(nd.BlockProposed.Height.AsInt(), startState.ProposedBlock)
+					gasLimit := new(big.Int).Mul(timeSinceParent, arbGasSpeedLimitPerBlock)
+					gasLimit.Mul(gasLimit, big.NewInt(4))
+					steps := new(big.Int).Sub(stakerInfo.latestExecutionCursor.TotalSteps(), startSteps)
+					v.reportAssertion(newAssertionStats(nd.NodeNum, nd.NodeHash, nd.Assertion, steps, gasLimit))
+				}
 				if nodeI != len(successorNodes)-1 && stakerInfo.latestExecutionCursor != nil {
 					// We will need to use this execution tracker more, so we need to clone this cursor
 					stakerInfo.latestExecutionCursor = stakerInfo.latestExecutionCursor.Clone()
@@ -399,6 +617,7 @@ func (v *Validator) generateNodeAction(ctx context.Context, stakerInfo *OurStake
 				continue
 			} else {
 				logger.Warn().Int("node", int((*big.Int)(nd.NodeNum).Int64())).Msg("found node with incorrect assertion")
+				v.journal.Record(nodeEntry(JournalNodeIncorrect, nd.NodeNum, nd.NodeHash))
 			}
 		} else {
 			logger.Warn().Int("node", int((*big.Int)(nd.NodeNum).Int64())).Msg("found younger sibling to correct node")
@@ -407,7 +626,7 @@ func (v *Validator) generateNodeAction(ctx context.Context, stakerInfo *OurStake
 		wrongNodesExist = true
 	}
 
-	if strategy == configuration.WatchtowerStrategy || correctNode != nil || (strategy != configuration.MakeNodesStrategy && !wrongNodesExist) {
+	if strategy == configuration.WatchtowerStrategy || strategy == configuration.ResolveNodesStrategy || correctNode != nil || (strategy != configuration.MakeNodesStrategy && !wrongNodesExist) {
 		return correctNode, wrongNodesExist, nil
 	}
 
@@ -513,8 +732,12 @@ func (v *Validator) generateNodeAction(ctx context.Context, stakerInfo *OurStake
 		seqBatchProof = append(seqBatchProof, proofPart...)
 	}
 
+	steps := new(big.Int).Sub(cursor.TotalSteps(), startSteps)
+	gasLimit := new(big.Int).Mul(minimumGasToConsume, big.NewInt(4))
+
 	executionHash := assertion.ExecutionHash()
 	newNodeHash := hashing.SoliditySHA3(hasSiblingByte[:], lastHash[:], executionHash[:], batchEndAcc[:])
+	v.bufferAssertion(newAssertionStats(nil, newNodeHash, assertion, steps, gasLimit))
 
 	action := createNodeAction{
 		assertion:           assertion,
@@ -557,6 +780,28 @@ func (v *Validator) generateBatchEndProof(count *big.Int) ([]byte, error) {
 	return proof, nil
 }
 
+// assertionInboxAcc returns the inbox accumulator after the last message read
+// by the node's assertion, checking that the local inbox agrees with the
+// batch accumulator the node was created with
+func assertionInboxAcc(lookup core.ArbCoreLookup, nd *core.NodeInfo) (common.Hash, error) {
+	if nd.Assertion.After.TotalMessagesRead.Cmp(nd.AfterInboxBatchEndCount) == 0 {
+		return nd.AfterInboxBatchAcc, nil
+	}
+	if nd.Assertion.After.TotalMessagesRead.Sign() == 0 {
+		return common.Hash{}, nil
+	}
+	index1 := new(big.Int).Sub(nd.Assertion.After.TotalMessagesRead, big.NewInt(1))
+	index2 := new(big.Int).Sub(nd.AfterInboxBatchEndCount, big.NewInt(1))
+	batchItemEndAcc, haveBatchEndAcc, err := lookup.GetInboxAccPair(index1, index2)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if haveBatchEndAcc != nd.AfterInboxBatchAcc {
+		return common.Hash{}, errors.New("inbox reorg detected by batch end acc mismatch")
+	}
+	return batchItemEndAcc, nil
+}
+
 func getBlockID(ctx context.Context, client ethutils.EthClient, number *big.Int) (*common.BlockId, error) {
 	blockInfo, err := client.BlockInfoByNumber(ctx, number)
 	if err != nil {
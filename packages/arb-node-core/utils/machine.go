@@ -0,0 +1,42 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/gomachine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+)
+
+// NewMachine loads the executable in codeFile into a machine run by the given
+// engine, which is "cpp" for arb-avm-cpp or "go" for the Go interpreter. An
+// empty engine selects DefaultMachineEngine. Binaries built with the
+// nocmachine tag don't link arb-avm-cpp, so they only support "go".
+func NewMachine(engine string, codeFile string) (machine.Machine, error) {
+	if engine == "" {
+		engine = DefaultMachineEngine
+	}
+	switch engine {
+	case "cpp":
+		return newCMachine(codeFile)
+	case "go":
+		return gomachine.New(codeFile)
+	default:
+		return nil, errors.Errorf("unknown machine engine %v", engine)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	ethcore "github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -260,6 +261,28 @@ func (m *Server) GetLogs(_ context.Context, blockHash ethcommon.Hash) ([][]*type
 	return logs, nil
 }
 
+// FilterLogs returns the logs in blocks start to end inclusive emitted by one
+// of addresses, with each topic matching one of the topics listed for its
+// position. Empty lists match anything. Sections of blocks which the log index
+// shows can't match are skipped, and the remaining blocks are filtered by
+// their header blooms before their logs are loaded.
+func (m *Server) FilterLogs(ctx context.Context, start, end uint64, addresses []ethcommon.Address, topics [][]ethcommon.Hash) ([]*types.Log, error) {
+	ranges, err := m.db.LogRanges(start, end, addresses, topics)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]*types.Log, 0)
+	for _, blockRange := range ranges {
+		filter := filters.NewRangeFilter(m, int64(blockRange.Start), int64(blockRange.End), addresses, topics)
+		rangeLogs, err := filter.Logs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, rangeLogs...)
+	}
+	return logs, nil
+}
+
 func (m *Server) PendingBlockAndReceipts() (*types.Block, types.Receipts) {
 	return nil, nil
 }
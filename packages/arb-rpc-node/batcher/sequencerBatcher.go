@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 
@@ -85,7 +87,10 @@ type SequencerBatcher struct {
 	signer  types.Signer
 	txQueue chan txQueueItem
 
+	// latestChainTime is only written by the main loop, which holds
+	// chainTimeMutex while doing so, so that other goroutines can read it
 	latestChainTime        inbox.ChainTime
+	chainTimeMutex         sync.RWMutex
 	lastCreatedBatchAt     *big.Int
 	lastSequencedDelayedAt *big.Int
 	// 1 if we've published a batch to the L1 mempool,
@@ -94,6 +99,11 @@ type SequencerBatcher struct {
 	// The total estimate of unpublished transactions' gas usage.
 	// Added to every time something is sequenced, zeroed when batch posted.
 	pendingBatchGasEstimateAtomic int64
+
+	pendingSnapshotMutex      sync.Mutex
+	pendingSnapshot           *snapshot.Snapshot
+	pendingSnapshotGas        *big.Int
+	rebuildingPendingSnapshot bool
 }
 
 var refundGasCostsDeniedEventID ethcommon.Hash
@@ -131,6 +141,7 @@ func NewSequencerBatcher(
 	broadcaster *broadcaster.Broadcaster,
 	config *configuration.Config,
 	walletConfig *configuration.Wallet,
+	metricsRegistry metrics.Registry,
 ) (*SequencerBatcher, error) {
 	chainTime, err := getChainTime(ctx, client)
 	if err != nil {
@@ -161,6 +172,12 @@ func NewSequencerBatcher(
 	if err != nil {
 		return nil, err
 	}
+	gasPriceOracle, err := transactauth.NewGasPriceOracle(client, config.GasPriceOracle)
+	if err != nil {
+		return nil, err
+	}
+	transactauth.SetGasPriceOracle(transactAuth, gasPriceOracle)
+	transactauth.SetMetricsRegistry(transactAuth, metricsRegistry)
 
 	maxDelayBlocks, err := sequencerInbox.MaxDelayBlocks(callOpts)
 	if err != nil {
@@ -580,9 +597,76 @@ func (b *SequencerBatcher) SendTransaction(startCtx context.Context, startTx *ty
 	return <-startResultChan
 }
 
-func (b *SequencerBatcher) PendingSnapshot(_ context.Context) (*snapshot.Snapshot, error) {
-	// TODO: return latest machine state?
-	return nil, nil
+// PendingSnapshot runs on a fork of the core's latest machine, so that calls
+// see sequenced transactions without waiting for the core machine to be idle
+// or affecting it. The snapshot is reused until the core executes more, with
+// a pool of machines kept ready for calls against it. Once the core has moved
+// on, the previous snapshot is still served while a new one is built in the
+// background, so requests never wait for a rebuild after the first.
+func (b *SequencerBatcher) PendingSnapshot(ctx context.Context) (*snapshot.Snapshot, error) {
+	gasUsed, err := b.db.GetLastMachineTotalGas()
+	if err != nil {
+		return nil, err
+	}
+	b.pendingSnapshotMutex.Lock()
+	snap := b.pendingSnapshot
+	current := snap != nil && b.pendingSnapshotGas.Cmp(gasUsed) == 0
+	startRebuild := snap != nil && !current && !b.rebuildingPendingSnapshot
+	if startRebuild {
+		b.rebuildingPendingSnapshot = true
+	}
+	b.pendingSnapshotMutex.Unlock()
+
+	if current {
+		return snap, nil
+	}
+	if snap == nil {
+		return b.buildPendingSnapshot(ctx)
+	}
+	if startRebuild {
+		go func() {
+			if _, err := b.buildPendingSnapshot(context.Background()); err != nil {
+				logger.Warn().Err(err).Msg("failed to rebuild pending snapshot")
+			}
+			b.pendingSnapshotMutex.Lock()
+			b.rebuildingPendingSnapshot = false
+			b.pendingSnapshotMutex.Unlock()
+		}()
+	}
+	return snap, nil
+}
+
+// buildPendingSnapshot builds a snapshot of the core's latest machine and
+// makes it the pending snapshot, unless one of the same machine was built
+// meanwhile
+func (b *SequencerBatcher) buildPendingSnapshot(ctx context.Context) (*snapshot.Snapshot, error) {
+	gasUsed, err := b.db.GetLastMachineTotalGas()
+	if err != nil {
+		return nil, err
+	}
+	mach, err := b.db.GetLastMachine()
+	if err != nil {
+		return nil, err
+	}
+	b.chainTimeMutex.RLock()
+	chainTime := b.latestChainTime.Clone()
+	b.chainTimeMutex.RUnlock()
+	snap, err := snapshot.NewSnapshot(ctx, mach, chainTime, big.NewInt(1<<60))
+	if err != nil {
+		return nil, err
+	}
+	b.pendingSnapshotMutex.Lock()
+	defer b.pendingSnapshotMutex.Unlock()
+	if b.pendingSnapshot != nil && b.pendingSnapshotGas.Cmp(gasUsed) == 0 {
+		return b.pendingSnapshot, nil
+	}
+	if b.pendingSnapshot != nil {
+		b.pendingSnapshot.StopMachinePool()
+	}
+	snap.StartMachinePool(b.config.Node.Cache.MachinePoolSize)
+	b.pendingSnapshot = snap
+	b.pendingSnapshotGas = gasUsed
+	return snap, nil
 }
 
 func (b *SequencerBatcher) Aggregator() *common.Address {
@@ -1232,7 +1316,9 @@ MainLoop:
 		targetUpdateTime := new(big.Int).Add(b.latestChainTime.BlockNum.AsInt(), b.updateTimestampInterval)
 		if blockNum.Cmp(targetUpdateTime) >= 0 || creatingBatch || sequencedDelayed {
 			b.inboxReader.MessageDeliveryMutex.Lock()
+			b.chainTimeMutex.Lock()
 			b.latestChainTime = chainTime
+			b.chainTimeMutex.Unlock()
 			// Avoid inefficency of publishing something that just got put in this timestamp
 			dontPublishBlockNum = b.latestChainTime.BlockNum.AsInt()
 			b.inboxReader.MessageDeliveryMutex.Unlock()
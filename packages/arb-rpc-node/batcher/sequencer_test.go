@@ -30,6 +30,7 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/metrics"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
@@ -229,6 +230,7 @@ func TestSequencerBatcher(t *testing.T) {
 		nil,
 		dummySequencerFeed,
 		config.Node.InboxReader,
+		metrics.NewRegistry(),
 	)
 	test.FailIfError(t, err)
 
@@ -241,6 +243,7 @@ func TestSequencerBatcher(t *testing.T) {
 		nil,
 		dummySequencerFeed,
 		config.Node.InboxReader,
+		metrics.NewRegistry(),
 	)
 	test.FailIfError(t, err)
 
@@ -257,6 +260,7 @@ func TestSequencerBatcher(t *testing.T) {
 		nil,
 		&config,
 		&config.Wallet,
+		nil,
 	)
 	test.FailIfError(t, err)
 	batcher.logBatchGasCosts = true
@@ -37,9 +37,11 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/pkg/errors"
 
-	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arboscontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/nodegraph"
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/utils"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
@@ -72,14 +74,14 @@ type upgrade struct {
 }
 
 func upgradeArbOS(upgradeFile string, targetMexe string, startMexe *string) error {
-	targetMach, err := cmachine.New(targetMexe)
+	targetMach, err := utils.NewMachine("", targetMexe)
 	if err != nil {
 		return err
 	}
 
 	var startHash common.Hash
 	if startMexe != nil {
-		startMach, err := cmachine.New(*startMexe)
+		startMach, err := utils.NewMachine("", *startMexe)
 		if err != nil {
 			return err
 		}
@@ -304,6 +306,36 @@ func spam() error {
 	}
 }
 
+// nodeGraph writes the rollup's node graph in DOT format, to be rendered with
+// GraphViz. The cli's rpc url must point to L1 for this command.
+func nodeGraph(rollupAddress, validatorUtilsAddress ethcommon.Address, filename string) error {
+	ctx := context.Background()
+	rollup, err := ethbridge.NewRollupWatcher(rollupAddress, 0, config.client, bind.CallOpts{})
+	if err != nil {
+		return err
+	}
+	validatorUtils, err := ethbridge.NewValidatorUtils(validatorUtilsAddress, rollupAddress, config.client, bind.CallOpts{})
+	if err != nil {
+		return err
+	}
+	graph, err := nodegraph.Load(ctx, config.client, rollup, validatorUtils)
+	if err != nil {
+		return err
+	}
+	if filename == "" {
+		return graph.ExportDOT(os.Stdout)
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := graph.ExportDOT(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
 func handleCommand(fields []string) error {
 	switch fields[0] {
 	case "enable-fees":
@@ -350,6 +382,15 @@ func handleCommand(fields []string) error {
 			source = &fields[3]
 		}
 		return upgradeArbOS(fields[1], fields[2], source)
+	case "node-graph":
+		if len(fields) != 3 && len(fields) != 4 {
+			return errors.New("Expected rollup and validator utils address arguments, and optionally an output file")
+		}
+		filename := ""
+		if len(fields) == 4 {
+			filename = fields[3]
+		}
+		return nodeGraph(ethcommon.HexToAddress(fields[1]), ethcommon.HexToAddress(fields[2]), filename)
 	case "version":
 		return version()
 	case "spam":
@@ -33,16 +33,17 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	gethlog "github.com/ethereum/go-ethereum/log"
+	gethmetrics "github.com/ethereum/go-ethereum/metrics"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/rs/zerolog/pkgerrors"
 
-	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/cmdhelp"
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/monitor"
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/utils"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/dev"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/rpc"
@@ -96,6 +97,7 @@ func startup() error {
 	privKeyString := fs.String("privkey", "979f020f6f6f71577c09db93ba944c89945f10fade64cfc7eb26137d5816fb76", "funded private key")
 	//fundedAccount := fs.String("account", "0x9a6C04fBf4108E2c1a1306534A126381F99644cf", "account to fund")
 	chainId64 := fs.Uint64("chainId", 68799, "chain id of chain")
+	machineEngine := fs.String("machine-engine", "cpp", "AVM implementation to create the initial machine with: 'cpp' or 'go'")
 
 	config := configuration.Config{
 		Core: *configuration.DefaultCoreSettingsMaxExecution(),
@@ -142,11 +144,13 @@ func startup() error {
 		return errors.Wrap(err, "error running NewRPcEthClient")
 	}
 
+	config.Core.MachineEngine = *machineEngine
+
 	arbosPath, err := arbos.Path(false)
 	if err != nil {
 		return err
 	}
-	initialMachine, err := cmachine.New(arbosPath)
+	initialMachine, err := utils.NewMachine(config.Core.MachineEngine, arbosPath)
 	if err != nil {
 		return err
 	}
@@ -226,7 +230,7 @@ func startup() error {
 	}
 
 	logger.Debug().Str("chainid", l1ChainId.String()).Msg("connected to l1 chain")
-	logger.Info().Hex("chainaddress", rollupAddress.Bytes()).Str("chainid", l2ChainId.String()).Msg("Launching arbitrum node")
+	logger.Info().Str("chainaddress", rollupAddress.Hex()).Str("chainid", l2ChainId.String()).Msg("Launching arbitrum node")
 
 	dbPath, err := ioutil.TempDir(".", "arbitrum")
 	if err != nil {
@@ -258,6 +262,7 @@ func startup() error {
 			nil,
 			dummySequencerFeed,
 			config.Node.InboxReader,
+			gethmetrics.NewRegistry(),
 		)
 		if err == nil {
 			break
@@ -274,7 +279,7 @@ func startup() error {
 		time.Sleep(time.Second * 5)
 	}
 
-	logger.Info().Hex("from", seqAuth.From.Bytes()).Msg("Arbitrum node submitting batches")
+	logger.Info().Str("from", seqAuth.From.Hex()).Msg("Arbitrum node submitting batches")
 
 	nonce, err := ethclint.PendingNonceAt(ctx, deployer.From)
 	if err != nil {
@@ -315,7 +320,7 @@ func startup() error {
 		InboxReader: inboxReader,
 	}
 
-	db, txDBErrChan, err := txdb.New(ctx, mon.Core, mon.Storage.GetNodeStore(), &config.Node)
+	db, txDBErrChan, err := txdb.New(ctx, mon.Core, mon.Storage.GetNodeStore(), nil, &config.Node)
 	if err != nil {
 		return errors.Wrap(err, "error opening txdb")
 	}
@@ -336,6 +341,7 @@ func startup() error {
 		signer,
 		&config,
 		&config.Wallet,
+		nil,
 	)
 	if err != nil {
 		return err
@@ -29,6 +29,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
@@ -39,18 +40,23 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/transactauth"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/ethclient"
 	gethlog "github.com/ethereum/go-ethereum/log"
+	gethmetrics "github.com/ethereum/go-ethereum/metrics"
 	"github.com/pkg/errors"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/pkgerrors"
 
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/assertions"
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/checkpointing"
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/cmdhelp"
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/metrics"
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/monitor"
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/nodehealth"
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/utils"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/batcher"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/nitroexport"
@@ -73,6 +79,7 @@ const (
 	failLimit            = 6
 	checkFrequency       = time.Second * 30
 	blockCheckCountDelay = 5
+	shutdownTimeout      = time.Minute
 )
 
 func init() {
@@ -119,7 +126,7 @@ func startup() error {
 	ctx, cancelFunc, cancelChan := cmdhelp.CreateLaunchContext()
 	defer cancelFunc()
 
-	config, walletConfig, l1Client, l1ChainId, err := configuration.ParseNode(ctx)
+	config, walletConfig, rpcL1Client, l1ChainId, err := configuration.ParseNode(ctx)
 	if err != nil || len(config.Persistent.GlobalConfig) == 0 || len(config.L1.URL) == 0 ||
 		len(config.Rollup.Address) == 0 || len(config.BridgeUtilsAddress) == 0 ||
 		((config.Node.Type() != configuration.SequencerNodeType) && len(config.Node.Sequencer.Lockout.Redis) != 0) ||
@@ -138,8 +145,20 @@ func startup() error {
 		return cmdhelp.PrintDatabaseMetadata(config.GetDatabasePath(), &config.Core)
 	}
 
+	metricsConfig := metrics.NewMetricsConfig(config.MetricsServer, &config.Healthcheck.MetricsPrefix)
+
+	// Metrics are recorded below the rate limiter so that every retry is counted
+	l1Client, err := ethbridge.NewRateLimitedClient(
+		ethbridge.NewMetricsClient(rpcL1Client, metricsConfig.Registry),
+		config.L1.RateLimit,
+		config.L1.CallTimeout,
+	)
+	if err != nil {
+		return err
+	}
+
 	var validatorAuth *bind.TransactOpts
-	if config.Node.Type() == configuration.ValidatorNodeType && config.Validator.Strategy() != configuration.WatchtowerStrategy {
+	if config.Node.Type() == configuration.ValidatorNodeType && config.Validator.NeedsWallet() {
 		// Create key if needed before opening database
 		validatorAuth, _, err = getKeystore(config, walletConfig, l1ChainId, false)
 		if err != nil {
@@ -148,7 +167,7 @@ func startup() error {
 
 		if config.Validator.OnlyCreateWalletContract {
 			// Just create validator smart wallet if needed then exit
-			_, err := startValidator(ctx, config, walletConfig, l1Client, validatorAuth, nil)
+			_, err := startValidator(ctx, config, walletConfig, l1Client, validatorAuth, nil, metricsConfig.Registry)
 			if err != nil {
 				return err
 			}
@@ -169,6 +188,10 @@ func startup() error {
 	if config.Rollup.Address == "" {
 		return errors.Errorf("Missing --rollup.address")
 	}
+	rollupAddress, err := common.ParseAddress(config.Rollup.Address)
+	if err != nil {
+		return errors.Wrap(err, "invalid --rollup.address")
+	}
 	if config.Node.ChainID == 0 {
 		return errors.Errorf("Missing --node.chain-id")
 	}
@@ -176,6 +199,14 @@ func startup() error {
 		return errors.Errorf("Missing --rollup.machine.filename")
 	}
 
+	rollupCodeHash := ethcommon.HexToHash(config.Rollup.CodeHash)
+	if err := ethbridge.VerifyContractCode(ctx, l1Client, "rollup", rollupAddress.ToEthAddress(), rollupCodeHash); err != nil {
+		return err
+	}
+	if err := ethbridge.VerifyContractCode(ctx, l1Client, "bridge utils", ethcommon.HexToAddress(config.BridgeUtilsAddress), ethcommon.Hash{}); err != nil {
+		return err
+	}
+
 	rpcMode := config.Node.Forwarder.RpcMode()
 	if config.Node.Type() == configuration.ForwarderNodeType {
 		if rpcMode == configuration.NonMutatingRpcMode {
@@ -198,9 +229,9 @@ func startup() error {
 		config.WaitToCatchUp = true
 	} else if config.Node.Type() == configuration.ValidatorNodeType {
 		if config.Validator.StrategyImpl == "" {
-			return errors.New("Missing --validator.strategy, should be Watchtower, Defensive, StakeLatest, or MakeNodes")
+			return errors.New("Missing --validator.strategy, should be Watchtower, Defensive, StakeLatest, MakeNodes, or ResolveNodes")
 		} else if config.Validator.Strategy() == configuration.UnknownStrategy {
-			return errors.Errorf("Unrecognized --validator.strategy %s, should be Watchtower, Defensive, StakeLatest, or MakeNodes", config.Validator.StrategyImpl)
+			return errors.Errorf("Unrecognized --validator.strategy %s, should be Watchtower, Defensive, StakeLatest, MakeNodes, or ResolveNodes", config.Validator.StrategyImpl)
 		}
 	} else {
 		return errors.Errorf("Unrecognized node type %s", config.Node.TypeImpl)
@@ -227,9 +258,8 @@ func startup() error {
 	}
 
 	l2ChainId := new(big.Int).SetUint64(config.Node.ChainID)
-	rollupAddress := common.HexToAddress(config.Rollup.Address)
 	logger.Info().
-		Hex("chainaddress", rollupAddress.Bytes()).
+		Str("chainaddress", rollupAddress.Hex()).
 		Hex("chainid", l2ChainId.Bytes()).
 		Str("type", config.Node.TypeImpl).
 		Int64("fromBlock", config.Rollup.FromBlock).
@@ -245,6 +275,18 @@ func startup() error {
 		config.Core.CheckpointMaxExecutionGas = 0
 	}
 
+	fastSynced := false
+	if config.Core.Database.SnapshotURL != "" {
+		if _, err := os.Stat(config.GetDatabasePath()); os.IsNotExist(err) {
+			if err := cmdhelp.FastSync(ctx, config.Core.Database.SnapshotURL, config.Core.Database.SnapshotSigner, config.Persistent.Chain); err != nil {
+				return errors.Wrap(err, "error syncing from snapshot")
+			}
+			fastSynced = true
+		} else {
+			logger.Info().Msg("database already exists, ignoring snapshot url")
+		}
+	}
+
 	mon, err := monitor.NewMonitorWithFinalBlock(config.GetDatabasePath(), &config.Core, config.L2.FinalClassicBlock)
 	if err != nil {
 		return err
@@ -256,8 +298,27 @@ func startup() error {
 		return err
 	}
 	defer mon.Close()
-
-	metricsConfig := metrics.NewMetricsConfig(config.MetricsServer, &config.Healthcheck.MetricsPrefix)
+	if err := checkMachineEngine(mon, config.Core.MachineEngine, config.Rollup.Machine.Filename); err != nil {
+		return err
+	}
+	if config.Core.Profile {
+		mon.Core.SetProfiling(true)
+	}
+	if config.Core.Database.ReplicateTo != "" {
+		if config.Core.Database.SaveInterval == 0 || config.Core.Database.SaveIncremental {
+			logger.Warn().Msg("database replication only copies full saves, set core.database.save-interval and disable core.database.save-incremental")
+		}
+		replicator, err := checkpointing.NewReplicator(config.Core.Database.SavePath, config.Core.Database.ReplicateTo, config.Core.Database.ReplicateInterval)
+		if err != nil {
+			return errors.Wrap(err, "error creating database replicator")
+		}
+		replicator.Start(ctx)
+	}
+	if fastSynced {
+		if err := cmdhelp.VerifyConfirmedState(ctx, rollup, mon.Core); err != nil {
+			return errors.Wrapf(err, "snapshot doesn't match confirmed state, delete %v before restarting", config.GetDatabasePath())
+		}
+	}
 
 	var healthChan chan nodehealth.Log
 	if config.Healthcheck.Enable {
@@ -265,6 +326,7 @@ func startup() error {
 		healthChan <- nodehealth.Log{Config: true, Var: "healthcheckMetrics", ValBool: config.Healthcheck.Metrics}
 		healthChan <- nodehealth.Log{Config: true, Var: "disablePrimaryCheck", ValBool: !config.Healthcheck.Sequencer}
 		healthChan <- nodehealth.Log{Config: true, Var: "disableOpenEthereumCheck", ValBool: !config.Healthcheck.L1Node}
+		healthChan <- nodehealth.Log{Config: true, Var: "validatorCheck", ValBool: config.Node.Type() == configuration.ValidatorNodeType}
 		healthChan <- nodehealth.Log{Config: true, Var: "healthcheckRPC", ValStr: config.Healthcheck.Addr + ":" + config.Healthcheck.Port}
 
 		if config.Node.Type() == configuration.ForwarderNodeType {
@@ -315,12 +377,13 @@ func startup() error {
 		inboxReader, inboxReaderDone, err = mon.StartInboxReader(
 			ctx,
 			l1Client,
-			common.HexToAddress(config.Rollup.Address),
+			rollupAddress,
 			config.Rollup.FromBlock,
 			common.HexToAddress(config.BridgeUtilsAddress),
 			healthChan,
 			sequencerFeed,
 			config.Node.InboxReader,
+			metricsConfig.Registry,
 		)
 		if err == nil {
 			break
@@ -343,8 +406,13 @@ func startup() error {
 		}
 	}
 
+	if config.Node.Type() == configuration.ValidatorNodeType && config.Validator.ReplayToBlock > 0 {
+		// Replay before pruning, as checking old nodes needs their checkpoints
+		return replayNodes(ctx, rollup, inboxReader, mon, big.NewInt(config.Validator.ReplayToBlock), config.Validator.ValidationWorkers)
+	}
+
 	if config.Core.CheckpointPruningMode != "off" {
-		if err := cmdhelp.UpdatePrunePoint(ctx, rollup, mon.Core); err != nil {
+		if err := cmdhelp.UpdatePrunePoint(ctx, rollup, mon.Core, config.Core.CheckpointRetainNodes); err != nil {
 			logger.Error().Err(err).Msg("error pruning database")
 		}
 	}
@@ -353,10 +421,21 @@ func startup() error {
 	var batcherMode rpc.BatcherMode
 	var stakerManager *staker.Staker
 	if config.Node.Type() == configuration.ValidatorNodeType {
-		stakerManager, err = startValidator(ctx, config, walletConfig, l1Client, validatorAuth, mon)
+		stakerManager, err = startValidator(ctx, config, walletConfig, l1Client, validatorAuth, mon, metricsConfig.Registry)
 		if err != nil {
 			return err
 		}
+		if healthChan != nil {
+			stakerManager.SetHealthChannel(healthChan)
+		}
+		stakerManager.RegisterMetrics(metricsConfig.Registry)
+		// Validators following a lease holder rely on the dispute watcher to
+		// keep checking assertions
+		leaseEnabled := config.Validator.Lease.File != "" || config.Validator.Lease.URL != ""
+		if config.Validator.DisputeAlerts.Enable || leaseEnabled || config.Validator.Strategy() == configuration.WatchtowerStrategy {
+			disputeWatcher := staker.NewDisputeWatcher(rollup, mon.Core, l1Client, config.Validator.DisputeAlerts, config.Validator.ValidationWorkers, metricsConfig.Registry)
+			disputeWatcher.RunInBackground(ctx)
+		}
 		batcherMode = rpc.ErrorBatcherMode{Error: errors.New("validator doesn't support transactions")}
 	} else if config.Node.Type() == configuration.ForwarderNodeType {
 		logger.Info().Str("forwardTxURL", config.Node.Forwarder.Target).Msg("Arbitrum node starting in forwarder mode")
@@ -369,9 +448,9 @@ func startup() error {
 		}
 
 		if config.Node.Sequencer.Dangerous.DisableBatchPosting {
-			logger.Info().Hex("from", auth.From.Bytes()).Msg("Arbitrum node with disabled batch posting")
+			logger.Info().Str("from", auth.From.Hex()).Msg("Arbitrum node with disabled batch posting")
 		} else {
-			logger.Info().Hex("from", auth.From.Bytes()).Msg("Arbitrum node submitting batches")
+			logger.Info().Str("from", auth.From.Hex()).Msg("Arbitrum node submitting batches")
 		}
 
 		if err := ethbridge.WaitForBalance(
@@ -400,9 +479,26 @@ func startup() error {
 	}
 
 	nodeStore := mon.Storage.GetNodeStore()
+	if config.Core.VerifyOnStart {
+		report, err := checkpointing.Verify(ctx, mon.Core, nodeStore)
+		if err != nil {
+			return errors.Wrap(err, "error verifying database")
+		}
+		for _, problem := range report.BlockProblems {
+			logger.Error().Str("problem", problem).Msg("block index corrupt")
+		}
+		if err := report.Err(); err != nil {
+			return err
+		}
+	}
 	metricsConfig.RegisterNodeStoreMetrics(nodeStore)
 	metricsConfig.RegisterArbCoreMetrics(mon.Core)
-	db, txDBErrChan, err := txdb.New(ctx, mon.Core, nodeStore, &config.Node)
+	logIndexDB, err := rawdb.NewLevelDBDatabase(path.Join(config.Persistent.Chain, "logindex"), 0, 0, "", false)
+	if err != nil {
+		return errors.Wrap(err, "error opening log index")
+	}
+	defer logIndexDB.Close()
+	db, txDBErrChan, err := txdb.New(ctx, mon.Core, nodeStore, logIndexDB, &config.Node)
 	if err != nil {
 		return errors.Wrap(err, "error opening txdb")
 	}
@@ -428,6 +524,7 @@ func startup() error {
 				dataSigner,
 				config,
 				walletConfig,
+				metricsConfig.Registry,
 			)
 			lockoutConf := config.Node.Sequencer.Lockout
 			if err == nil {
@@ -464,6 +561,21 @@ func startup() error {
 	}
 
 	plugins := make(map[string]interface{})
+	if stakerManager != nil {
+		plugins["validator"] = web3.NewValidatorAPI(stakerManager)
+		if config.Node.RPC.EnableValidatorAdmin {
+			plugins["validatoradmin"] = web3.NewValidatorAdminAPI(stakerManager, mon.Core)
+		}
+	}
+	var history *assertions.History
+	if config.Node.RPC.EnableL1Calls {
+		history, err = assertions.NewHistoryWithLog(rollup, mon.Core, path.Join(config.Persistent.Chain, "assertions.log"))
+		if err != nil {
+			return errors.Wrap(err, "error opening assertion history")
+		}
+		defer history.Close()
+		plugins["assertions"] = web3.NewAssertionsAPI(history)
+	}
 	if config.Node.RPC.NitroExport.Enable {
 		basedir := config.Node.RPC.NitroExport.BaseDir
 		if basedir == "" {
@@ -481,10 +593,11 @@ func startup() error {
 
 	srv := aggregator.NewServer(batch, l2ChainId, db)
 	serverConfig := web3.ServerConfig{
-		Mode:          rpcMode,
-		MaxCallAVMGas: config.Node.RPC.MaxCallGas * 100, // Multiply by 100 for arb gas to avm gas conversion
-		Tracing:       config.Node.RPC.Tracing,
-		DevopsStubs:   config.Node.RPC.EnableDevopsStubs,
+		Mode:             rpcMode,
+		MaxCallAVMGas:    config.Node.RPC.MaxCallGas * 100, // Multiply by 100 for arb gas to avm gas conversion
+		Tracing:          config.Node.RPC.Tracing,
+		DevopsStubs:      config.Node.RPC.EnableDevopsStubs,
+		MaxLogBlockRange: config.Node.RPC.MaxLogBlockRange,
 	}
 	web3Server, err := web3.GenerateWeb3Server(srv, nil, serverConfig, mon.CoreConfig, plugins, web3InboxReaderRef)
 	if err != nil {
@@ -535,12 +648,17 @@ func startup() error {
 		}()
 	}
 
+	// Background work that uses mon.Core, which shutdown waits for before the
+	// database is closed
+	var coreUsers sync.WaitGroup
 	if config.Core.CheckpointPruningMode != "off" {
 		ticker := time.NewTicker(time.Minute)
+		coreUsers.Add(1)
 		go func() {
+			defer coreUsers.Done()
 			defer ticker.Stop()
 			for {
-				if err := cmdhelp.UpdatePrunePoint(ctx, rollup, mon.Core); err != nil {
+				if err := cmdhelp.UpdatePrunePoint(ctx, rollup, mon.Core, config.Core.CheckpointRetainNodes); err != nil {
 					logger.Error().Err(err).Msg("error pruning database")
 				}
 				select {
@@ -554,27 +672,109 @@ func startup() error {
 
 	var stakerDone chan bool
 	if stakerManager != nil {
-		stakerDone = stakerManager.RunInBackground(ctx, config.Validator.StakerDelay)
+		if mon.ReorgDetector != nil {
+			mon.ReorgDetector.OnReorg(stakerManager.HandleReorg)
+		}
+		// The staker is stopped by shutdown below rather than by the launch
+		// context, so it can wait for any transaction it has in flight
+		stakerDone = stakerManager.RunInBackground(context.Background(), config.Validator.StakerDelay)
 	} else {
 		stakerDone = make(chan bool)
 	}
 
 	select {
-	case err := <-txDBErrChan:
-		return err
-	case err := <-broadcasterErrChan:
-		return err
-	case err := <-errChan:
-		return err
-	case err := <-broadcastClientErrChan:
-		return err
+	case err = <-txDBErrChan:
+	case err = <-broadcasterErrChan:
+	case err = <-errChan:
+	case err = <-broadcastClientErrChan:
 	case <-stakerDone:
-		return nil
 	case <-inboxReaderDone:
-		return nil
 	case <-cancelChan:
+	}
+	shutdown(cancelFunc, stakerManager, mon, db, history, &coreUsers)
+	return err
+}
+
+// shutdown stops everything using the monitor's core before the monitor
+// itself. The core's storage is only freed by the deferred mon.Close, after
+// the remaining deferred closes have run.
+func shutdown(
+	cancelLaunch func(),
+	stakerManager *staker.Staker,
+	mon *monitor.Monitor,
+	db *txdb.TxDB,
+	history *assertions.History,
+	coreUsers *sync.WaitGroup,
+) {
+	logger.Info().Msg("shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if stakerManager != nil {
+		if err := stakerManager.Stop(ctx); err != nil {
+			logger.Warn().Err(err).Msg("staker didn't shut down cleanly")
+		}
+	}
+	// Stops the RPC server, the batcher and the pruning loop
+	cancelLaunch()
+	coreUsers.Wait()
+	db.Close()
+	if history != nil {
+		if err := history.Close(); err != nil {
+			logger.Warn().Err(err).Msg("error closing assertion history")
+		}
+	}
+	if err := mon.Stop(ctx); err != nil {
+		logger.Warn().Err(err).Msg("monitor didn't shut down cleanly")
+	}
+}
+
+// checkMachineEngine makes sure engine can run the chain's machine. ArbCore
+// always runs on arb-avm-cpp, so any other engine must compute the same
+// initial machine hash as ArbCore before it's trusted with standalone machines.
+func checkMachineEngine(mon *monitor.Monitor, engine string, contractFile string) error {
+	if engine == "" || engine == "cpp" {
 		return nil
 	}
+	mach, err := utils.NewMachine(engine, contractFile)
+	if err != nil {
+		return err
+	}
+	cursor, err := mon.Core.GetExecutionCursor(big.NewInt(0), true)
+	if err != nil {
+		return errors.Wrap(err, "error loading initial ArbCore machine")
+	}
+	if mach.Hash() != cursor.MachineHash() {
+		return errors.Errorf("%v machine engine computed initial machine hash %v but ArbCore computed %v", engine, mach.Hash(), cursor.MachineHash())
+	}
+	logger.Info().Str("engine", engine).Msg("machine engine matches ArbCore's initial machine")
+	return nil
+}
+
+func replayNodes(ctx context.Context, rollup *ethbridge.RollupWatcher, inboxReader *monitor.InboxReader, mon *monitor.Monitor, toBlock *big.Int, workers int) error {
+	logger.Info().Str("toBlock", toBlock.String()).Msg("waiting for inbox reader to catch up before replaying nodes")
+	inboxReader.WaitToCatchUp(ctx)
+	results, err := staker.ReplayNodes(ctx, rollup, mon.Core, toBlock, workers)
+	if err != nil {
+		return err
+	}
+	var invalid, skipped int
+	for _, result := range results {
+		if result.Skipped != "" {
+			skipped++
+		} else if !result.Valid {
+			invalid++
+		}
+	}
+	logger.
+		Info().
+		Int("nodes", len(results)).
+		Int("invalid", invalid).
+		Int("skipped", skipped).
+		Msg("finished replaying nodes")
+	if invalid > 0 {
+		return errors.Errorf("found %v incorrect nodes", invalid)
+	}
+	return nil
 }
 
 func checkBlockHash(ctx context.Context, clnt *ethclient.Client, db *txdb.TxDB) (bool, error) {
@@ -618,6 +818,7 @@ func startValidator(
 	l1Client ethutils.EthClient,
 	auth *bind.TransactOpts,
 	mon *monitor.Monitor,
+	metricsRegistry gethmetrics.Registry,
 ) (*staker.Staker, error) {
 	if len(config.Validator.UtilsAddress) == 0 ||
 		len(config.Validator.WalletFactoryAddress) == 0 || config.Validator.Strategy() == configuration.UnknownStrategy {
@@ -627,6 +828,12 @@ func startValidator(
 	rollupAddr := ethcommon.HexToAddress(config.Rollup.Address)
 	validatorUtilsAddr := ethcommon.HexToAddress(config.Validator.UtilsAddress)
 	validatorWalletFactoryAddr := ethcommon.HexToAddress(config.Validator.WalletFactoryAddress)
+	if err := ethbridge.VerifyContractCode(ctx, l1Client, "validator utils", validatorUtilsAddr, ethcommon.Hash{}); err != nil {
+		return nil, err
+	}
+	if err := ethbridge.VerifyContractCode(ctx, l1Client, "validator wallet factory", validatorWalletFactoryAddr, ethcommon.Hash{}); err != nil {
+		return nil, err
+	}
 
 	chainState := ChainState{}
 	if config.Validator.ContractWalletAddress != "" {
@@ -664,6 +871,23 @@ func startValidator(
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating wallet auth")
 	}
+	gasPriceOracle, err := transactauth.NewGasPriceOracle(l1Client, config.GasPriceOracle)
+	if err != nil {
+		return nil, err
+	}
+	transactauth.SetGasPriceOracle(valAuth, gasPriceOracle)
+	if err := transactauth.SetSubmissionPolicy(valAuth, transactauth.NewSubmissionPolicy(config.TxSubmission, metricsRegistry)); err != nil {
+		return nil, err
+	}
+	transactauth.SetTransactionTracker(valAuth, transactauth.NewTransactionTracker())
+	transactauth.SetMetricsRegistry(valAuth, metricsRegistry)
+	if config.Validator.PrivateRelay.URL != "" {
+		relay, err := ethbridge.NewPrivateRelay(l1Client, config.Validator.PrivateRelay)
+		if err != nil {
+			return nil, err
+		}
+		transactauth.SetTransactionSender(valAuth, relay)
+	}
 	var validatorAddress *ethcommon.Address
 	if chainState.ValidatorWallet != "" {
 		logger.Info().Str("address", chainState.ValidatorWallet).Msg("validator using smart contract wallet")
@@ -683,6 +907,8 @@ func startValidator(
 		}
 	} else if config.Validator.OnlyCreateWalletContract {
 		logger.Info().Msg("only creating validator smart contract and exiting")
+	} else if !config.Validator.NeedsWallet() {
+		logger.Info().Msg("watchtower running without a validator smart contract wallet")
 	} else {
 		return nil, errors.New("validator smart contract wallet not present, add --validator.only-create-wallet-contract to create")
 	}
@@ -732,6 +958,51 @@ func startValidator(
 	if err != nil {
 		return nil, errors.Wrap(err, "error setting up staker")
 	}
+	if config.Validator.ReceiptCacheFilename != "" {
+		receiptCache, err := transactauth.NewReceiptCache(config.Validator.ReceiptCacheFilename)
+		if err != nil {
+			return nil, err
+		}
+		stakerManager.SetReceiptCache(receiptCache)
+	}
+	if config.Validator.JournalFilename != "" {
+		journal, err := staker.NewJournal(config.Validator.JournalFilename)
+		if err != nil {
+			return nil, err
+		}
+		stakerManager.SetJournal(journal)
+	}
+	if config.Validator.ChallengeProgressFilename != "" {
+		if err := stakerManager.SetChallengeProgressFile(config.Validator.ChallengeProgressFilename); err != nil {
+			return nil, err
+		}
+	}
+	if config.Validator.SnapshotFilename != "" {
+		if err := stakerManager.SetSnapshotFile(ctx, config.Validator.SnapshotFilename); err != nil {
+			return nil, err
+		}
+	}
+	stakerManager.SetCheckpointRetention(config.Core.CheckpointRetainNodes)
+	if config.Validator.Lease.File != "" || config.Validator.Lease.URL != "" {
+		var leaseStore staker.LeaseStore
+		if config.Validator.Lease.URL != "" {
+			leaseStore = staker.NewWebhookLeaseStore(config.Validator.Lease.URL)
+		} else {
+			leaseStore = staker.NewFileLeaseStore(config.Validator.Lease.File)
+		}
+		holder := config.Validator.Lease.Holder
+		if holder == "" {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return nil, errors.Wrap(err, "error getting hostname for validator lease")
+			}
+			holder = fmt.Sprintf("%v-%v", hostname, os.Getpid())
+		}
+		stakerManager.SetLeaseStore(leaseStore, holder, config.Validator.Lease.Duration)
+	}
+	if config.Validator.EscalationApprovalURL != "" {
+		stakerManager.SetEscalationHook(staker.NewWebhookEscalationHook(config.Validator.EscalationApprovalURL))
+	}
 
 	logger.Info().Str("strategy", config.Validator.StrategyImpl).Msg("Initialized validator")
 	return stakerManager, nil
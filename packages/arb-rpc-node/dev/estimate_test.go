@@ -7,13 +7,62 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
 )
 
+func TestGasEstimationIsMinimal(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+	_, owner := OptsAddressPair(t, nil)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, owner)
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+
+	simpleAddr, _, simple, err := arbostestcontracts.DeploySimple(auth, client)
+	test.FailIfError(t, err)
+
+	call := ethereum.CallMsg{
+		From: auth.From,
+		To:   &simpleAddr,
+		Data: []byte{0x47, 0xf6, 0xac, 0x11}, // call arrayPush()
+	}
+	gas, err := client.EstimateGas(ctx, call)
+	test.FailIfError(t, err)
+
+	// One less than the estimate isn't enough
+	call.Gas = gas - 1
+	if _, err := client.EstimateGas(ctx, call); err == nil {
+		t.Fatal("estimate succeeded below the minimum gas limit")
+	}
+
+	auth.GasLimit = gas
+	tx, err := simple.ArrayPush(auth)
+	test.FailIfError(t, err)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatal("transaction failed with the estimated gas limit")
+	}
+}
+
 func TestZeroPriceGasEstimationEmpty(t *testing.T) {
 	ctx := context.Background()
 	_, _, client, _, aggAuth, _, _, _, cancel := setupFeeChain(t, ctx)
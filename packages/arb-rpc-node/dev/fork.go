@@ -59,7 +59,7 @@ func NewForkNode(
 		return returnErr(err, "error opening monitor")
 	}
 
-	db, errChan, err := txdb.New(ctx, mon.Core, mon.Storage.GetNodeStore(), nodeConfig)
+	db, errChan, err := txdb.New(ctx, mon.Core, mon.Storage.GetNodeStore(), nil, nodeConfig)
 	if err != nil {
 		mon.Close()
 		return returnErr(err, "error opening txdb")
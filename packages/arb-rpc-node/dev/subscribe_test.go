@@ -0,0 +1,84 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+	_, owner := OptsAddressPair(t, nil)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, owner)
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	headers := make(chan *types.Header, 10)
+	headSub, err := client.SubscribeNewHead(ctx, headers)
+	test.FailIfError(t, err)
+	defer headSub.Unsubscribe()
+
+	txHashes := make(chan ethcommon.Hash, 10)
+	txSub, err := client.SubscribePendingTransactions(ctx, txHashes)
+	test.FailIfError(t, err)
+	defer txSub.Unsubscribe()
+
+	_, tx, _, err := arbostestcontracts.DeployTransfer(auth, client)
+	test.FailIfError(t, err)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+
+	timeout := time.After(10 * time.Second)
+	for seenHead, seenTx := false, false; !seenHead || !seenTx; {
+		select {
+		case header := <-headers:
+			if header.Number.Cmp(receipt.BlockNumber) == 0 {
+				seenHead = true
+			}
+		case txHash := <-txHashes:
+			if txHash == tx.Hash() {
+				seenTx = true
+			}
+		case <-timeout:
+			t.Fatal("didn't receive the block and transaction containing the deployment")
+		}
+	}
+}
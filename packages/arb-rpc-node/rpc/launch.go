@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/pkg/errors"
 
@@ -88,6 +89,7 @@ func SetupBatcher(
 	dataSigner func([]byte) ([]byte, error),
 	config *configuration.Config,
 	walletConfig *configuration.Wallet,
+	metricsRegistry metrics.Registry,
 ) (batcher.TransactionBatcher, chan error, error) {
 	switch batcherMode := batcherMode.(type) {
 	case ForwarderBatcherMode:
@@ -109,6 +111,12 @@ func SetupBatcher(
 		if err != nil {
 			return nil, nil, err
 		}
+		gasPriceOracle, err := transactauth.NewGasPriceOracle(client, config.GasPriceOracle)
+		if err != nil {
+			return nil, nil, err
+		}
+		transactauth.SetGasPriceOracle(auth, gasPriceOracle)
+		transactauth.SetMetricsRegistry(auth, metricsRegistry)
 		inbox, err := ethbridge.NewStandardInbox(batcherMode.InboxAddress.ToEthAddress(), client, auth)
 		if err != nil {
 			return nil, nil, err
@@ -129,6 +137,12 @@ func SetupBatcher(
 		if err != nil {
 			return nil, nil, err
 		}
+		gasPriceOracle, err := transactauth.NewGasPriceOracle(client, config.GasPriceOracle)
+		if err != nil {
+			return nil, nil, err
+		}
+		transactauth.SetGasPriceOracle(auth, gasPriceOracle)
+		transactauth.SetMetricsRegistry(auth, metricsRegistry)
 		inbox, err := ethbridge.NewStandardInbox(batcherMode.InboxAddress.ToEthAddress(), client, auth)
 		if err != nil {
 			return nil, nil, err
@@ -165,7 +179,8 @@ func SetupBatcher(
 			dataSigner,
 			feedBroadcaster,
 			config,
-			walletConfig)
+			walletConfig,
+			metricsRegistry)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -181,6 +196,7 @@ func SetupBatcher(
 }
 
 func LaunchPublicServer(ctx context.Context, web3Server *rpc.Server, rpc configuration.RPC, ws configuration.WS) error {
+	rpcHandler := utils2.NewBatchHandler(web3Server, rpc.MaxBatchSize, rpc.BatchConcurrency)
 	if rpc.Port == ws.Port && rpc.Port != "" {
 		if rpc.Addr != ws.Addr {
 			return errors.New("if serving on same port, rpc and ws addreses must be the same")
@@ -188,13 +204,13 @@ func LaunchPublicServer(ctx context.Context, web3Server *rpc.Server, rpc configu
 		if rpc.Path == ws.Path {
 			return errors.New("if serving on same port, ws and rpc path must be different")
 		}
-		return utils2.LaunchRPCAndWS(ctx, web3Server, rpc.Addr, rpc.Port, rpc.Path, ws.Path)
+		return utils2.LaunchRPCAndWS(ctx, rpcHandler, web3Server, rpc.Addr, rpc.Port, rpc.Path, ws.Path)
 	}
 
 	errChan := make(chan error, 1)
 	if rpc.Port != "" {
 		go func() {
-			errChan <- utils2.LaunchRPC(ctx, web3Server, rpc.Addr, rpc.Port, rpc.Path)
+			errChan <- utils2.LaunchRPC(ctx, rpcHandler, rpc.Addr, rpc.Port, rpc.Path)
 		}()
 	}
 	if ws.Port != "" {
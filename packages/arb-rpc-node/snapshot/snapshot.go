@@ -20,6 +20,7 @@ import (
 	"context"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/arblog"
 	"math/big"
+	"sync"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -46,6 +47,9 @@ type Snapshot struct {
 	chainId               *big.Int
 	arbosVersion          uint64
 	arbosRemappingEnabled bool
+
+	poolMutex sync.Mutex
+	pool      *machine.Pool
 }
 
 func NewSnapshot(ctx context.Context, mach machine.Machine, time inbox.ChainTime, lastInboxSeq *big.Int) (*Snapshot, error) {
@@ -93,6 +97,44 @@ func NewSnapshot(ctx context.Context, mach machine.Machine, time inbox.ChainTime
 	return snap, nil
 }
 
+// StartMachinePool keeps size clones of the snapshot's machine ready for
+// calls against it. The pool should be stopped once the snapshot is no longer
+// expected to be called, such as when a newer block arrives.
+func (s *Snapshot) StartMachinePool(size int) {
+	if size <= 0 {
+		return
+	}
+	s.poolMutex.Lock()
+	defer s.poolMutex.Unlock()
+	if s.pool != nil {
+		return
+	}
+	s.pool = machine.NewPool(s.mach.Clone(), size)
+}
+
+// StopMachinePool releases the machines held for calls against the snapshot
+func (s *Snapshot) StopMachinePool() {
+	s.poolMutex.Lock()
+	pool := s.pool
+	s.pool = nil
+	s.poolMutex.Unlock()
+	if pool != nil {
+		pool.Close()
+	}
+}
+
+// cloneMachine returns a copy of the snapshot's machine, taking it from the
+// machine pool if there is one
+func (s *Snapshot) cloneMachine() machine.Machine {
+	s.poolMutex.Lock()
+	pool := s.pool
+	s.poolMutex.Unlock()
+	if pool != nil {
+		return pool.Get()
+	}
+	return s.mach.Clone()
+}
+
 func (s *Snapshot) ArbosVersion() uint64 {
 	return s.arbosVersion
 }
@@ -134,6 +176,8 @@ func (s *Snapshot) addMessage(
 		Timestamp: big.NewInt(0),
 	}
 	inboxMsg := message.NewInboxMessage(msg, sender, s.nextInboxSeqNum, big.NewInt(0), chainTime)
+	// Pooled machines are clones of the state before the message
+	s.StopMachinePool()
 	res, debugPrints, err := runTxUnchecked(ctx, s.mach, inboxMsg, maxAVMGas, trace)
 	if err != nil {
 		return nil, nil, err
@@ -156,7 +200,7 @@ func (s *Snapshot) Clone() *Snapshot {
 		chainId = new(big.Int).Set(s.chainId)
 	}
 	return &Snapshot{
-		mach: s.mach.Clone(),
+		mach: s.cloneMachine(),
 		time: inbox.ChainTime{
 			BlockNum:  s.time.BlockNum.Clone(),
 			Timestamp: new(big.Int).Set(s.time.Timestamp),
@@ -205,7 +249,7 @@ func (s *Snapshot) EstimateGas(
 			targetHash = hashing.SoliditySHA3(hashing.Bytes32(targetHash), hashing.Uint256(big.NewInt(0)))
 		}
 		inboxMsg := s.makeInboxMessage(gasEstimationMessage, sender)
-		return runTx(ctx, s.mach.Clone(), inboxMsg, targetHash, maxAVMGas, trace)
+		return runTx(ctx, s.cloneMachine(), inboxMsg, targetHash, maxAVMGas, trace)
 	}
 }
 
@@ -251,22 +295,12 @@ func (s *Snapshot) EstimateRetryableGas(
 	}
 	inboxMsg2 := message.NewInboxMessage(gasEstimationMessage, redeemer, estimateSeqNum, big.NewInt(0), s.time)
 
-	mach := s.mach.Clone()
-	assertion, _, _, err := mach.ExecuteAssertionAdvanced(
-		ctx,
-		maxAVMGas,
-		false,
-		nil,
-		[]inbox.InboxMessage{inboxMsg2, inboxMsg1},
-		true,
-		false,
-		false,
-	)
+	execRes, err := machine.ExecuteSideloaded(ctx, s.cloneMachine(), []inbox.InboxMessage{inboxMsg1, inboxMsg2}, maxAVMGas, false)
 	if err != nil {
 		return nil, err
 	}
 
-	avmLogs := assertion.Logs
+	avmLogs := execRes.Logs
 	if len(avmLogs) == 0 {
 		return nil, errors.New("no logs emitted processing retryable")
 	}
@@ -323,7 +357,7 @@ func (s *Snapshot) Call(
 		sender = message.L1RemapAccount(sender)
 	}
 	inboxMsg := s.makeInboxMessage(message.NewSafeL2Message(msg), sender)
-	return runTx(ctx, s.mach.Clone(), inboxMsg, targetHash, maxAVMGas, trace)
+	return runTx(ctx, s.cloneMachine(), inboxMsg, targetHash, maxAVMGas, trace)
 }
 
 type EthCallOverride struct {
@@ -410,15 +444,30 @@ func runTx(
 	maxAVMGas uint64,
 	trace bool,
 ) (*evm.TxResult, []value.Value, error) {
-	res, debugPrints, err := runTxUnchecked(ctx, mach, inboxMsg, maxAVMGas, trace)
+	var emptyHash common.Hash
+	if targetHash == emptyHash {
+		return runTxUnchecked(ctx, mach, inboxMsg, maxAVMGas, trace)
+	}
+	execRes, err := machine.ExecuteSideloaded(ctx, mach, []inbox.InboxMessage{inboxMsg}, maxAVMGas, trace)
 	if err != nil {
 		return nil, nil, err
 	}
-	var emptyHash common.Hash
-	if targetHash != emptyHash && res.IncomingRequest.MessageID != targetHash {
+	res, err := lastTxResult(execRes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if res.IncomingRequest.MessageID != targetHash {
+		// ArbOS may log other results while handling the message, so look
+		// for the message's own result before giving up
+		for _, avmLog := range execRes.Logs {
+			otherRes, err := evm.NewTxResultFromValue(avmLog)
+			if err == nil && otherRes.IncomingRequest.MessageID == targetHash {
+				return otherRes, execRes.DebugPrints, nil
+			}
+		}
 		return nil, nil, errors.Errorf("call got unexpected result %v instead of %v", res.IncomingRequest.MessageID, targetHash)
 	}
-	return res, debugPrints, nil
+	return res, execRes.DebugPrints, nil
 }
 
 func (s *Snapshot) basicCallUnsafe(ctx context.Context, data []byte, dest common.Address) (*evm.TxResult, error) {
@@ -432,7 +481,7 @@ func (s *Snapshot) basicCallUnsafe(ctx context.Context, data []byte, dest common
 		},
 	}
 	inboxMsg := message.NewInboxMessage(message.NewSafeL2Message(msg), common.Address{}, s.nextInboxSeqNum, big.NewInt(0), s.time)
-	res, _, err := runTxUnchecked(ctx, s.mach.Clone(), inboxMsg, 1000000000, false)
+	res, _, err := runTxUnchecked(ctx, s.cloneMachine(), inboxMsg, 1000000000, false)
 	if err != nil {
 		return nil, err
 	}
@@ -607,24 +656,19 @@ func runTxUnchecked(
 	maxAVMGas uint64,
 	trace bool,
 ) (*evm.TxResult, []value.Value, error) {
-	assertion, debugPrints, steps, err := mach.ExecuteAssertionAdvanced(ctx, maxAVMGas, false, nil, []inbox.InboxMessage{msg}, true, false, trace)
+	execRes, err := machine.ExecuteSideloaded(ctx, mach, []inbox.InboxMessage{msg}, maxAVMGas, trace)
 	if err != nil {
 		return nil, nil, err
 	}
+	res, err := lastTxResult(execRes)
+	return res, execRes.DebugPrints, err
+}
 
-	// If the machine wasn't able to run and it reports that it is currently
-	// blocked, return the block reason to give the client more information
-	// as opposed to just returning a general "call produced no output"
-	if br := mach.IsBlocked(true); steps == 0 && br != nil {
-		return nil, debugPrints, errors.Errorf("can't produce solution since machine is blocked %v", br)
-	}
-
-	avmLogs := assertion.Logs
+func lastTxResult(execRes *machine.SideloadResult) (*evm.TxResult, error) {
+	avmLogs := execRes.Logs
 	if len(avmLogs) == 0 {
-		logger.Info().Uint64("gasused", assertion.NumGas).Msg("Running message didn't produce log")
-		return nil, debugPrints, errors.New("transaction ran out of gas")
+		logger.Info().Uint64("gasused", execRes.GasUsed).Msg("Running message didn't produce log")
+		return nil, errors.New("transaction ran out of gas")
 	}
-
-	res, err := evm.NewTxResultFromValue(avmLogs[len(avmLogs)-1])
-	return res, debugPrints, err
+	return evm.NewTxResultFromValue(avmLogs[len(avmLogs)-1])
 }
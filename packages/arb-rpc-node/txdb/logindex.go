@@ -0,0 +1,295 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txdb
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+)
+
+// LogIndexSectionSize is the number of blocks whose blooms are combined into
+// each entry of the log index
+const LogIndexSectionSize = 4096
+
+var (
+	logIndexSectionCountKey = []byte{0x01}
+	logIndexSectionPrefix   = []byte{0x02}
+)
+
+func logIndexSectionKey(section uint64) []byte {
+	key := make([]byte, len(logIndexSectionPrefix)+8)
+	copy(key, logIndexSectionPrefix)
+	binary.BigEndian.PutUint64(key[len(logIndexSectionPrefix):], section)
+	return key
+}
+
+// BlockRange is an inclusive range of block numbers
+type BlockRange struct {
+	Start uint64
+	End   uint64
+}
+
+// LogIndex stores the union of the header blooms of every complete section of
+// LogIndexSectionSize blocks. Log queries use it to skip whole sections which
+// can't contain a match rather than checking every block's header.
+type LogIndex struct {
+	as      machine.NodeStore
+	updates chan struct{}
+
+	mutex    sync.Mutex
+	db       ethdb.KeyValueStore
+	sections uint64
+	closed   bool
+	// reorgs counts calls to Reorg, so that a section read while one
+	// happened isn't saved
+	reorgs uint64
+}
+
+func NewLogIndex(db ethdb.KeyValueStore, as machine.NodeStore) (*LogIndex, error) {
+	var sections uint64
+	has, err := db.Has(logIndexSectionCountKey)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if has {
+		data, err := db.Get(logIndexSectionCountKey)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(data) != 8 {
+			return nil, errors.New("log index section count corrupt")
+		}
+		sections = binary.BigEndian.Uint64(data)
+	}
+
+	// The node store may have been reorged after the index was last written
+	blockCount, err := as.BlockCount()
+	if err != nil {
+		return nil, err
+	}
+	if sections > blockCount/LogIndexSectionSize {
+		sections = blockCount / LogIndexSectionSize
+	}
+	return &LogIndex{
+		as:       as,
+		updates:  make(chan struct{}, 1),
+		db:       db,
+		sections: sections,
+	}, nil
+}
+
+func (li *LogIndex) setSections(sections uint64) error {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, sections)
+	if err := li.db.Put(logIndexSectionCountKey, data); err != nil {
+		return errors.Wrap(err, "failed to save log index section count")
+	}
+	li.sections = sections
+	return nil
+}
+
+// Sections returns the number of sections which have been indexed
+func (li *LogIndex) Sections() uint64 {
+	li.mutex.Lock()
+	defer li.mutex.Unlock()
+	return li.sections
+}
+
+// Start indexes sections in the background until ctx is done, catching up
+// with the node store first and then whenever Notify is called
+func (li *LogIndex) Start(ctx context.Context) {
+	go func() {
+		for {
+			if err := li.Update(ctx); err != nil {
+				logger.Error().Err(err).Msg("failed to update log index")
+			}
+			select {
+			case <-li.updates:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Notify tells the background updater that blocks have been added
+func (li *LogIndex) Notify() {
+	select {
+	case li.updates <- struct{}{}:
+	default:
+	}
+}
+
+// Update indexes every complete section of blocks in the node store which
+// hasn't been indexed yet
+func (li *LogIndex) Update(ctx context.Context) error {
+	for ctx.Err() == nil {
+		indexed, err := li.indexNextSection()
+		if err != nil || !indexed {
+			return err
+		}
+	}
+	return nil
+}
+
+func (li *LogIndex) indexNextSection() (bool, error) {
+	li.mutex.Lock()
+	closed, section, reorgs := li.closed, li.sections, li.reorgs
+	li.mutex.Unlock()
+	if closed {
+		return false, nil
+	}
+	blockCount, err := li.as.BlockCount()
+	if err != nil {
+		return false, err
+	}
+	if (section+1)*LogIndexSectionSize > blockCount {
+		return false, nil
+	}
+
+	// Reading a section's blocks is slow, so it's done without holding the
+	// lock to avoid blocking log queries
+	var bloom types.Bloom
+	start := section * LogIndexSectionSize
+	for height := start; height < start+LogIndexSectionSize; height++ {
+		info, err := li.as.GetBlockInfo(height)
+		if err != nil {
+			return false, err
+		}
+		if info == nil {
+			// Reorged while indexing, so try again later
+			return false, nil
+		}
+		for i := range bloom {
+			bloom[i] |= info.Header.Bloom[i]
+		}
+	}
+
+	li.mutex.Lock()
+	defer li.mutex.Unlock()
+	if li.closed || li.sections != section || li.reorgs != reorgs {
+		// The blocks read may have been reorged out, so try again
+		return !li.closed, nil
+	}
+	if err := li.db.Put(logIndexSectionKey(section), bloom.Bytes()); err != nil {
+		return false, errors.Wrapf(err, "failed to save log index section %v", section)
+	}
+	if err := li.setSections(section + 1); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Reorg removes every section containing a block at or after height
+func (li *LogIndex) Reorg(height uint64) error {
+	li.mutex.Lock()
+	defer li.mutex.Unlock()
+	li.reorgs++
+	if li.closed || li.sections <= height/LogIndexSectionSize {
+		return nil
+	}
+	return li.setSections(height / LogIndexSectionSize)
+}
+
+// Close stops any further updates to the index. The underlying database is
+// owned by the caller.
+func (li *LogIndex) Close() {
+	li.mutex.Lock()
+	defer li.mutex.Unlock()
+	li.closed = true
+}
+
+// MatchingRanges returns the parts of the range from start to end inclusive
+// which may contain logs matching addresses and topics. Only indexed sections
+// are ever excluded, so the remaining blocks must still be checked.
+func (li *LogIndex) MatchingRanges(start, end uint64, addresses []ethcommon.Address, topics [][]ethcommon.Hash) ([]BlockRange, error) {
+	li.mutex.Lock()
+	defer li.mutex.Unlock()
+	var ranges []BlockRange
+	addRange := func(rangeStart, rangeEnd uint64) {
+		if len(ranges) > 0 && ranges[len(ranges)-1].End+1 == rangeStart {
+			ranges[len(ranges)-1].End = rangeEnd
+			return
+		}
+		ranges = append(ranges, BlockRange{Start: rangeStart, End: rangeEnd})
+	}
+	for current := start; current <= end; {
+		section := current / LogIndexSectionSize
+		sectionEnd := (section+1)*LogIndexSectionSize - 1
+		if sectionEnd > end {
+			sectionEnd = end
+		}
+		matches := true
+		if section < li.sections {
+			data, err := li.db.Get(logIndexSectionKey(section))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to load log index section %v", section)
+			}
+			matches = BloomMatches(types.BytesToBloom(data), addresses, topics)
+		}
+		if matches {
+			addRange(current, sectionEnd)
+		}
+		if sectionEnd == end {
+			break
+		}
+		current = sectionEnd + 1
+	}
+	return ranges, nil
+}
+
+// BloomMatches returns whether bloom may contain a log emitted by one of
+// addresses, with each position matching one of the topics listed for it.
+// Empty address and topic lists act as wildcards.
+func BloomMatches(bloom types.Bloom, addresses []ethcommon.Address, topics [][]ethcommon.Hash) bool {
+	if len(addresses) > 0 {
+		found := false
+		for _, addr := range addresses {
+			if types.BloomLookup(bloom, addr) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, sub := range topics {
+		if len(sub) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range sub {
+			if types.BloomLookup(bloom, topic) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
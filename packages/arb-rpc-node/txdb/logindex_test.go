@@ -0,0 +1,112 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txdb
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+// fakeNodeStore holds the blocks the log index reads in memory
+type fakeNodeStore struct {
+	machine.NodeStore
+	blocks []*machine.BlockInfo
+}
+
+func (f *fakeNodeStore) BlockCount() (uint64, error) {
+	return uint64(len(f.blocks)), nil
+}
+
+func (f *fakeNodeStore) GetBlockInfo(height uint64) (*machine.BlockInfo, error) {
+	if height >= uint64(len(f.blocks)) {
+		return nil, nil
+	}
+	return f.blocks[height], nil
+}
+
+func (f *fakeNodeStore) Reorg(height uint64) error {
+	f.blocks = f.blocks[:height]
+	return nil
+}
+
+func TestLogIndex(t *testing.T) {
+	ctx := context.Background()
+	emitter := ethcommon.Address{1}
+	topic := ethcommon.Hash{2}
+	missingTopic := ethcommon.Hash{3}
+	emittingBlock := uint64(LogIndexSectionSize + 10)
+	blockCount := uint64(2*LogIndexSectionSize + 10)
+
+	store := &fakeNodeStore{}
+	for height := uint64(0); height < blockCount; height++ {
+		header := &types.Header{
+			Number:     new(big.Int).SetUint64(height),
+			Difficulty: big.NewInt(0),
+		}
+		if height == emittingBlock {
+			header.Bloom.Add(emitter.Bytes())
+			header.Bloom.Add(topic.Bytes())
+		}
+		store.blocks = append(store.blocks, &machine.BlockInfo{Header: header})
+	}
+
+	db := memorydb.New()
+	index, err := NewLogIndex(db, store)
+	test.FailIfError(t, err)
+	test.FailIfError(t, index.Update(ctx))
+	if index.Sections() != 2 {
+		t.Fatalf("expected 2 sections indexed, got %v", index.Sections())
+	}
+
+	checkRanges := func(start uint64, addresses []ethcommon.Address, topics [][]ethcommon.Hash, expected []BlockRange) {
+		t.Helper()
+		ranges, err := index.MatchingRanges(start, blockCount-1, addresses, topics)
+		test.FailIfError(t, err)
+		if !reflect.DeepEqual(ranges, expected) {
+			t.Fatalf("expected ranges %v, got %v", expected, ranges)
+		}
+	}
+	// The partial section at the end is never skipped
+	tail := BlockRange{Start: 2 * LogIndexSectionSize, End: blockCount - 1}
+	checkRanges(10, nil, nil, []BlockRange{{Start: 10, End: blockCount - 1}})
+	checkRanges(0, []ethcommon.Address{emitter}, nil, []BlockRange{{Start: LogIndexSectionSize, End: blockCount - 1}})
+	checkRanges(0, []ethcommon.Address{{4}}, nil, []BlockRange{tail})
+	checkRanges(0, nil, [][]ethcommon.Hash{nil, {missingTopic, topic}}, []BlockRange{{Start: LogIndexSectionSize, End: blockCount - 1}})
+	checkRanges(0, nil, [][]ethcommon.Hash{{topic}, {missingTopic}}, []BlockRange{tail})
+
+	test.FailIfError(t, index.Reorg(emittingBlock))
+	if index.Sections() != 1 {
+		t.Fatalf("expected 1 section after reorg, got %v", index.Sections())
+	}
+
+	// Reopening the index drops sections past a node store reorg
+	test.FailIfError(t, store.Reorg(LogIndexSectionSize-1))
+	index, err = NewLogIndex(db, store)
+	test.FailIfError(t, err)
+	if index.Sections() != 0 {
+		t.Fatalf("expected no sections after node store reorg, got %v", index.Sections())
+	}
+}
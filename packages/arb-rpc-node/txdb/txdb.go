@@ -22,12 +22,15 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/arblog"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethcore "github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/trie"
 	lru "github.com/hashicorp/golang-lru"
@@ -51,6 +54,7 @@ type TxDB struct {
 	allowSlowLookup bool
 	as              machine.NodeStore
 	logReader       *core.LogReader
+	logIndex        *LogIndex
 
 	newTxsFeed      event.Feed
 	rmLogsFeed      event.Feed
@@ -64,14 +68,30 @@ type TxDB struct {
 	snapshotLRUCache   *lru.Cache
 	blockInfoLRUCache  *lru.Cache
 	snapshotTimedCache *blockcache.BlockCache
+
+	// The latest snapshot keeps a pool of machines ready for calls, which is
+	// stopped when a newer snapshot replaces it
+	machinePoolSize     int
+	pooledSnapshotMutex sync.Mutex
+	pooledSnapshot      *snapshot.Snapshot
+
+	closeOnce sync.Once
 }
 
 func New(
 	ctx context.Context,
 	arbCore core.ArbCore,
 	as machine.NodeStore,
+	logIndexDB ethdb.KeyValueStore,
 	nodeConfig *configuration.Node,
 ) (*TxDB, <-chan error, error) {
+	if logIndexDB == nil {
+		logIndexDB = memorydb.New()
+	}
+	logIndex, err := NewLogIndex(logIndexDB, as)
+	if err != nil {
+		return nil, nil, err
+	}
 	var snapshotLRUCache *lru.Cache
 	var blockInfoLRUCache *lru.Cache
 	if nodeConfig.Cache.LRUSize > 0 {
@@ -95,19 +115,28 @@ func New(
 	db := &TxDB{
 		Lookup:             arbCore,
 		as:                 as,
+		logIndex:           logIndex,
 		snapshotLRUCache:   snapshotLRUCache,
 		blockInfoLRUCache:  blockInfoLRUCache,
 		snapshotTimedCache: snapshotTimedCache,
 		allowSlowLookup:    nodeConfig.Cache.AllowSlowLookup,
+		machinePoolSize:    nodeConfig.Cache.MachinePoolSize,
 	}
+	logIndex.Start(ctx)
 	logReader := core.NewLogReader(db, arbCore, big.NewInt(0), big.NewInt(int64(nodeConfig.LogProcessCount)), nodeConfig.LogIdleSleep)
 	errChan := logReader.Start(ctx)
 	db.logReader = logReader
 	return db, errChan, nil
 }
 
+// Close may be called more than once, such as during shutdown and again when
+// the caller's deferred Close runs
 func (db *TxDB) Close() {
-	db.logReader.Stop()
+	db.closeOnce.Do(func() {
+		db.logReader.Stop()
+		db.logIndex.Close()
+		db.setPooledSnapshot(nil)
+	})
 }
 
 func (db *TxDB) GetBlockResults(block *machine.BlockInfo) (*evm.BlockInfo, []*evm.TxResult, error) {
@@ -202,6 +231,12 @@ func (db *TxDB) AddLogs(initialLogIndex *big.Int, avmLogs []core.ValueAndInbox)
 	}
 
 	if lastBlockAdded != nil {
+		// The pooled snapshot is no longer the latest, so its machines won't
+		// be needed. The next lookup of the latest snapshot starts a new pool.
+		db.setPooledSnapshot(nil)
+
+		db.logIndex.Notify()
+
 		log := logger.Info().
 			Str("l2Block", lastBlockAdded.BlockNum.String()).
 			Str("l1Block", lastBlockAdded.L1BlockNum.String()).
@@ -220,6 +255,8 @@ func (db *TxDB) AddLogs(initialLogIndex *big.Int, avmLogs []core.ValueAndInbox)
 
 func (db *TxDB) DeleteLogs(avmLogs []core.ValueAndInbox) error {
 	logger.Info().Int("count", len(avmLogs)).Msg("deleting logs")
+	// The pooled snapshot may be for a block being reorged out
+	db.setPooledSnapshot(nil)
 	oldHeight, err := db.BlockCount()
 	if err != nil {
 		return err
@@ -270,6 +307,9 @@ func (db *TxDB) DeleteLogs(avmLogs []core.ValueAndInbox) error {
 		if err != nil {
 			return err
 		}
+		if err := db.logIndex.Reorg(reorgBlockHeight); err != nil {
+			return err
+		}
 
 		if db.snapshotLRUCache != nil {
 			for i := oldHeight; i > reorgBlockHeight; i-- {
@@ -409,6 +449,12 @@ func (db *TxDB) handleBlockReceipt(blockInfo *evm.BlockInfo) (*types.Header, err
 	return header, nil
 }
 
+// LogRanges returns the parts of the range from start to end inclusive which
+// may contain logs matching addresses and topics, according to the log index
+func (db *TxDB) LogRanges(start, end uint64, addresses []ethcommon.Address, topics [][]ethcommon.Hash) ([]BlockRange, error) {
+	return db.logIndex.MatchingRanges(start, end, addresses, topics)
+}
+
 func (db *TxDB) GetMessageBatch(index *big.Int) (*evm.MerkleRootResult, error) {
 	logIndex := db.as.GetMessageBatch(index)
 	if logIndex == nil {
@@ -583,10 +629,36 @@ func (db *TxDB) LatestSnapshot(ctx context.Context) (*snapshot.Snapshot, error)
 
 		return nil, err
 	}
+	db.setPooledSnapshot(snap)
 
 	return snap, nil
 }
 
+// setPooledSnapshot moves the machine pool to snap, stopping the pool of the
+// previous latest snapshot. A snapshot older than the pooled one is ignored,
+// since it was looked up before a newer block arrived.
+func (db *TxDB) setPooledSnapshot(snap *snapshot.Snapshot) {
+	if db.machinePoolSize <= 0 {
+		return
+	}
+	db.pooledSnapshotMutex.Lock()
+	defer db.pooledSnapshotMutex.Unlock()
+	old := db.pooledSnapshot
+	if snap == old {
+		return
+	}
+	if snap != nil && old != nil && snap.Height().Cmp(old.Height()) < 0 {
+		return
+	}
+	if old != nil {
+		old.StopMachinePool()
+	}
+	if snap != nil {
+		snap.StartMachinePool(db.machinePoolSize)
+	}
+	db.pooledSnapshot = snap
+}
+
 func (db *TxDB) SubscribeNewTxsEvent(ch chan<- ethcore.NewTxsEvent) event.Subscription {
 	return db.newTxsFeed.Subscribe(ch)
 }
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Batches larger than this are rejected, the same as the limit the rpc server
+// applies to any request
+const maxBatchContentLength = 5 * 1024 * 1024
+
+const invalidRequestCode = -32600
+
+type batchHandler struct {
+	handler     http.Handler
+	maxSize     int
+	concurrency int
+}
+
+// NewBatchHandler splits JSON-RPC batch requests into their individual calls,
+// executes up to concurrency of them at a time with handler and returns the
+// responses as a batch in the same order. Batches with more than maxSize calls
+// are rejected, unless maxSize is 0. Anything that isn't a batch is passed
+// straight to handler, which executes a batch's calls one at a time.
+func NewBatchHandler(handler http.Handler, maxSize int, concurrency int) http.Handler {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &batchHandler{
+		handler:     handler,
+		maxSize:     maxSize,
+		concurrency: concurrency,
+	}
+}
+
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+func errorResponse(id json.RawMessage, message string) []byte {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]interface{}{
+			"code":    invalidRequestCode,
+			"message": message,
+		},
+	})
+	return data
+}
+
+func (h *batchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.Body == nil {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchContentLength))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("content length too large (max %d)", maxBatchContentLength), http.StatusRequestEntityTooLarge)
+		return
+	}
+	var calls []json.RawMessage
+	if !isBatch(body) || json.Unmarshal(body, &calls) != nil || len(calls) == 0 {
+		// Let the rpc server respond, including with any parse error
+		h.forward(w, r, body)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if h.maxSize > 0 && len(calls) > h.maxSize {
+		_, _ = w.Write(errorResponse(nil, fmt.Sprintf("batch of %v requests is too large, the maximum is %v", len(calls), h.maxSize)))
+		return
+	}
+
+	responses := make([][]byte, len(calls))
+	sem := make(chan struct{}, h.concurrency)
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, call json.RawMessage) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			responses[i] = h.execute(r, call)
+		}(i, call)
+	}
+	wg.Wait()
+
+	// Notifications don't get a response
+	results := make([][]byte, 0, len(responses))
+	for _, response := range responses {
+		if len(response) > 0 {
+			results = append(results, response)
+		}
+	}
+	if len(results) == 0 {
+		return
+	}
+	var out bytes.Buffer
+	out.WriteByte('[')
+	out.Write(bytes.Join(results, []byte{','}))
+	out.WriteByte(']')
+	_, _ = w.Write(out.Bytes())
+}
+
+func (h *batchHandler) forward(w http.ResponseWriter, r *http.Request, body []byte) {
+	req := r.Clone(r.Context())
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	h.handler.ServeHTTP(w, req)
+}
+
+// execute runs a single call from a batch, returning its JSON response or
+// nothing if it's a notification
+func (h *batchHandler) execute(r *http.Request, call json.RawMessage) []byte {
+	rec := newBufferedResponse()
+	h.forward(rec, r, call)
+	response := bytes.TrimSpace(rec.body.Bytes())
+	if rec.status == http.StatusOK {
+		return response
+	}
+	// The rpc server rejected the call before decoding it, so reply with a
+	// JSON error in its place
+	var msg struct {
+		ID json.RawMessage `json:"id"`
+	}
+	_ = json.Unmarshal(call, &msg)
+	return errorResponse(msg.ID, string(response))
+}
+
+// bufferedResponse collects the response to one call of a batch
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{
+		header: make(http.Header),
+		status: http.StatusOK,
+	}
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
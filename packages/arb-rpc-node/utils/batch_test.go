@@ -0,0 +1,153 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// echoHandler replies to each call with its id as the result, tracking how
+// many calls it's handling at once
+type echoHandler struct {
+	mutex   sync.Mutex
+	running int
+	maxSeen int
+}
+
+func (h *echoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	h.running++
+	if h.running > h.maxSeen {
+		h.maxSeen = h.running
+	}
+	h.mutex.Unlock()
+	defer func() {
+		h.mutex.Lock()
+		h.running--
+		h.mutex.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	var call struct {
+		ID json.RawMessage `json:"id"`
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	if err := json.Unmarshal(body, &call); err != nil {
+		http.Error(w, "bad call", http.StatusBadRequest)
+		return
+	}
+	if len(call.ID) == 0 {
+		// Notification
+		return
+	}
+	_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(call.ID) + `,"result":` + string(call.ID) + "}\n"))
+}
+
+func postBatch(t *testing.T, handler http.Handler, body string, response interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if err := json.Unmarshal(rec.Body.Bytes(), response); err != nil {
+		t.Fatalf("failed to parse response %q: %v", rec.Body.String(), err)
+	}
+}
+
+func TestBatchHandler(t *testing.T) {
+	inner := &echoHandler{}
+	handler := NewBatchHandler(inner, 5, 2)
+
+	var responses []map[string]interface{}
+	postBatch(t, handler, `[{"id":1},{"method":"notify"},{"id":2},{"id":3},{"id":4}]`, &responses)
+	if len(responses) != 4 {
+		t.Fatalf("expected 4 responses, got %v", len(responses))
+	}
+	for i, response := range responses {
+		if response["result"] != float64(i+1) {
+			t.Errorf("response %v out of order: %v", i, response)
+		}
+	}
+	if inner.maxSeen != 2 {
+		t.Errorf("expected 2 calls at once, got %v", inner.maxSeen)
+	}
+
+	// Oversized batches get a single error, like an empty batch
+	var rejection map[string]interface{}
+	postBatch(t, handler, `[1,2,3,4,5,6]`, &rejection)
+	if rejection["error"] == nil {
+		t.Errorf("expected oversized batch to be rejected, got %v", rejection)
+	}
+
+	responses = nil
+	postBatch(t, handler, `[{"id":7},"bad"]`, &responses)
+	if len(responses) != 2 || responses[0]["result"] != float64(7) || responses[1]["error"] == nil {
+		t.Errorf("expected result and error, got %v", responses)
+	}
+}
+
+// TestBatchRejectionMatchesServer checks that an oversized batch is rejected
+// with the same status and error shape as the rpc server gives an empty one
+func TestBatchRejectionMatchesServer(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	handler := NewBatchHandler(server, 2, 1)
+
+	post := func(body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var response map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to parse response %q: %v", rec.Body.String(), err)
+		}
+		return rec, response
+	}
+	expectedRec, expected := post(`[]`)
+	rec, rejection := post(`[{"jsonrpc":"2.0","id":1,"method":"rpc_modules"},{"jsonrpc":"2.0","id":2,"method":"rpc_modules"},{"jsonrpc":"2.0","id":3,"method":"rpc_modules"}]`)
+
+	if rec.Code != expectedRec.Code {
+		t.Errorf("rejected batch got status %v, server gives %v", rec.Code, expectedRec.Code)
+	}
+	if rec.Header().Get("Content-Type") != expectedRec.Header().Get("Content-Type") {
+		t.Errorf("rejected batch got content type %q, server gives %q", rec.Header().Get("Content-Type"), expectedRec.Header().Get("Content-Type"))
+	}
+	if rejection["jsonrpc"] != expected["jsonrpc"] || rejection["id"] != expected["id"] {
+		t.Errorf("rejected batch got response %v, server gives %v", rejection, expected)
+	}
+	rejectionErr, ok := rejection["error"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("rejected batch got no error: %v", rejection)
+	}
+	expectedErr := expected["error"].(map[string]interface{})
+	if rejectionErr["code"] != expectedErr["code"] {
+		t.Errorf("rejected batch got error code %v, server gives %v", rejectionErr["code"], expectedErr["code"])
+	}
+	if message, _ := rejectionErr["message"].(string); message == "" {
+		t.Error("rejected batch got no error message")
+	}
+}
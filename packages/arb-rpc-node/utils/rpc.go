@@ -66,7 +66,7 @@ func LaunchWS(ctx context.Context, server *rpc.Server, addr, port, path string)
 	return launchServer(ctx, r, addr, port, "websocket")
 }
 
-func LaunchRPCAndWS(ctx context.Context, server *rpc.Server, addr, port, rpcPath, wsPath string) error {
+func LaunchRPCAndWS(ctx context.Context, handler http.Handler, server *rpc.Server, addr, port, rpcPath, wsPath string) error {
 	r := mux.NewRouter()
 	rpcRoutes, err := setupPaths(r, rpcPath)
 	if err != nil {
@@ -77,7 +77,7 @@ func LaunchRPCAndWS(ctx context.Context, server *rpc.Server, addr, port, rpcPath
 		return err
 	}
 	for _, route := range rpcRoutes {
-		route.Handler(server).Methods("GET", "POST", "OPTIONS")
+		route.Handler(handler).Methods("GET", "POST", "OPTIONS")
 	}
 	wsHandler := server.WebsocketHandler([]string{"*"})
 	for _, route := range wsRoutes {
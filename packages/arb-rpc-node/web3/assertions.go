@@ -0,0 +1,51 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web3
+
+import (
+	"context"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/assertions"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+// AssertionsAPI looks up past rollup assertions for explorers and support
+// tooling
+type AssertionsAPI struct {
+	history *assertions.History
+}
+
+func NewAssertionsAPI(history *assertions.History) *AssertionsAPI {
+	return &AssertionsAPI{history: history}
+}
+
+func (a *AssertionsAPI) GetByNodeHash(ctx context.Context, hash ethcommon.Hash) (*assertions.Assertion, error) {
+	return a.history.ByNodeHash(ctx, common.NewHashFromEth(hash))
+}
+
+func (a *AssertionsAPI) GetByL1Block(ctx context.Context, block *hexutil.Big) ([]*assertions.Assertion, error) {
+	return a.history.AtL1Block(ctx, block.ToInt())
+}
+
+// GetByMessageRange returns the assertions which read any of the messages
+// from start inclusive to end exclusive
+func (a *AssertionsAPI) GetByMessageRange(ctx context.Context, start, end *hexutil.Big) ([]*assertions.Assertion, error) {
+	return a.history.ByMessageRange(ctx, start.ToInt(), end.ToInt())
+}
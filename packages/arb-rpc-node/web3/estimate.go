@@ -0,0 +1,98 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web3
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/snapshot"
+	arbcommon "github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+// The first gas limit tried is this much more than the gas used, which is
+// enough for most transactions
+const gasSearchCushion = 10000
+
+// searchGasLimit finds the lowest gas limit with which the transaction
+// succeeds, executing it on a fresh clone of the snapshot's machine for each
+// limit tried. gasUsed is the gas the transaction used without a limit, but
+// it may need a higher limit than that due to refunds and the gas withheld
+// from calls.
+func (s *Server) searchGasLimit(
+	ctx context.Context,
+	snap *snapshot.Snapshot,
+	args CallTxArgs,
+	agg arbcommon.Address,
+	gasUsed uint64,
+) (uint64, error) {
+	succeeds := func(gas uint64) (bool, error) {
+		from, tx := buildTransactionImpl(args, gas)
+		res, _, err := snap.EstimateGas(ctx, tx, agg, from, s.maxAVMGas, false)
+		if err != nil {
+			return false, err
+		}
+		return res.ResultCode == evm.ReturnCode, nil
+	}
+
+	limit := uint64(maxGas)
+	if args.Gas != nil && uint64(*args.Gas) > 0 && uint64(*args.Gas) < limit {
+		limit = uint64(*args.Gas)
+	}
+	// Every limit up to lo fails and hi succeeds
+	var lo uint64
+	if gasUsed > 0 {
+		lo = gasUsed - 1
+	}
+	hi := gasUsed + gasSearchCushion
+	if hi > limit {
+		hi = limit
+	}
+	ok, err := succeeds(hi)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		lo = hi
+		hi = limit
+		if hi > lo {
+			ok, err = succeeds(hi)
+			if err != nil {
+				return 0, err
+			}
+		}
+		if !ok {
+			return 0, errors.Errorf("gas required exceeds allowance (%v)", limit)
+		}
+	}
+
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+		ok, err := succeeds(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi, nil
+}
@@ -292,8 +292,15 @@ func (s *Server) EstimateGas(ctx context.Context, args CallTxArgs, optBlockNum *
 	}
 
 	if res.FeeStats.Price.L2Computation.Cmp(big.NewInt(0)) == 0 {
-		return hexutil.Uint64(res.GasUsed.Uint64() + 10000), nil
+		gas, err := s.searchGasLimit(ctx, snap, args, agg, res.GasUsed.Uint64())
+		if err != nil {
+			return 0, err
+		}
+		return hexutil.Uint64(gas), nil
 	} else {
+		// The gas limit also pays for L1 resources at prices which may change
+		// before the transaction is included, so leave a margin rather than
+		// searching for the exact limit
 		extraCalldataUnits := (len(res.FeeStats.GasUsed().Bytes()) + len(ApplyGasPriceBidFactor(res.FeeStats.Price.L2Computation).Bytes()) + gasEstimationCushion) * 16
 		// Adjust calldata units used for calldata from gas limit
 		res.FeeStats.UnitsUsed.L1Calldata = res.FeeStats.UnitsUsed.L1Calldata.Add(res.FeeStats.UnitsUsed.L1Calldata, big.NewInt(int64(extraCalldataUnits)))
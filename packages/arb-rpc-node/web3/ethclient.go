@@ -19,7 +19,6 @@ package web3
 import (
 	"context"
 	"math/big"
-	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -39,7 +38,7 @@ import (
 type EthClient struct {
 	srv    *Server
 	events *filters.EventSystem
-	filter *filters.PublicFilterAPI
+	logs   *Logs
 }
 
 func NewEthClient(srv *aggregator.Server, ganacheMode bool) *EthClient {
@@ -59,7 +58,7 @@ func NewEthClient(srv *aggregator.Server, ganacheMode bool) *EthClient {
 	return &EthClient{
 		srv:    NewServer(srv, config, nil),
 		events: filters.NewEventSystem(srv, false),
-		filter: filters.NewPublicFilterAPI(srv, false, 2*time.Minute),
+		logs:   NewLogs(srv, 0),
 	}
 }
 
@@ -164,7 +163,7 @@ func (c *EthClient) SendTransaction(ctx context.Context, tx *types.Transaction)
 }
 
 func (c *EthClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
-	logs, err := c.filter.GetLogs(ctx, filters.FilterCriteria(query))
+	logs, err := c.logs.GetLogs(ctx, filters.FilterCriteria(query))
 	if err != nil {
 		return nil, err
 	}
@@ -192,6 +191,53 @@ func (c *EthClient) SubscribeFilterLogs(_ context.Context, query ethereum.Filter
 	return sub, nil
 }
 
+// SubscribeNewHead notifies ch of the header of each new L2 block, the same
+// as an eth_subscribe newHeads subscription
+func (c *EthClient) SubscribeNewHead(_ context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	headers := make(chan *types.Header)
+	sub := c.events.SubscribeNewHeads(headers)
+	go func() {
+		for {
+			select {
+			case header := <-headers:
+				select {
+				case ch <- header:
+				case <-sub.Err():
+					return
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// SubscribePendingTransactions notifies ch of the hash of each transaction
+// the node sees, the same as an eth_subscribe newPendingTransactions
+// subscription. Transactions are seen once they're sequenced.
+func (c *EthClient) SubscribePendingTransactions(_ context.Context, ch chan<- common.Hash) (ethereum.Subscription, error) {
+	hashes := make(chan []common.Hash)
+	sub := c.events.SubscribePendingTxs(hashes)
+	go func() {
+		for {
+			select {
+			case txHashes := <-hashes:
+				for _, txHash := range txHashes {
+					select {
+					case ch <- txHash:
+					case <-sub.Err():
+						return
+					}
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
 func (c *EthClient) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
 	res, block, _, _, err := c.srv.getTransactionInfoByHash(txHash.Bytes())
 	if err != nil || res == nil {
@@ -39,6 +39,9 @@ type ServerConfig struct {
 	MaxCallAVMGas uint64
 	Tracing       configuration.Tracing
 	DevopsStubs   bool
+	// MaxLogBlockRange limits the number of blocks an eth_getLogs query may
+	// cover, or is 0 for no limit
+	MaxLogBlockRange uint64
 }
 
 func GenerateWeb3Server(server *aggregator.Server, privateKeys []*ecdsa.PrivateKey, config ServerConfig, coreConfig *configuration.Core, plugins map[string]interface{}, inboxReader *monitor.InboxReader) (*rpc.Server, error) {
@@ -65,6 +68,11 @@ func GenerateWeb3Server(server *aggregator.Server, privateKeys []*ecdsa.PrivateK
 			return nil, err
 		}
 
+		// Registered after the filter api to replace its eth_getLogs
+		if err := s.RegisterName("eth", NewLogs(server, config.MaxLogBlockRange)); err != nil {
+			return nil, err
+		}
+
 		if err := s.RegisterName("eth", NewAccounts(ethServer, privateKeys, config.Mode == configuration.NonMutatingRpcMode)); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web3
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+)
+
+// Logs serves eth_getLogs from the node's log index, replacing the version in
+// the filter api which checks every block in the requested range
+type Logs struct {
+	srv           *aggregator.Server
+	maxBlockRange uint64
+}
+
+// NewLogs limits queries to maxBlockRange blocks, or doesn't limit them if
+// maxBlockRange is 0
+func NewLogs(srv *aggregator.Server, maxBlockRange uint64) *Logs {
+	return &Logs{
+		srv:           srv,
+		maxBlockRange: maxBlockRange,
+	}
+}
+
+func (l *Logs) blockNum(block *big.Int, latest uint64) uint64 {
+	if block == nil || block.Sign() < 0 {
+		// Unset, latest or pending
+		return latest
+	}
+	if !block.IsUint64() {
+		return latest + 1
+	}
+	return block.Uint64()
+}
+
+func (l *Logs) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([]*types.Log, error) {
+	if crit.BlockHash != nil {
+		logs, err := filters.NewBlockFilter(l.srv, *crit.BlockHash, crit.Addresses, crit.Topics).Logs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if logs == nil {
+			logs = make([]*types.Log, 0)
+		}
+		return logs, nil
+	}
+
+	latestNum := rpc.LatestBlockNumber
+	latest, err := l.srv.BlockNum(&latestNum)
+	if err != nil {
+		return nil, err
+	}
+	start := l.blockNum(crit.FromBlock, latest)
+	end := l.blockNum(crit.ToBlock, latest)
+	if end > latest {
+		end = latest
+	}
+	if start > end {
+		return make([]*types.Log, 0), nil
+	}
+	if l.maxBlockRange > 0 && end-start >= l.maxBlockRange {
+		return nil, errors.Errorf("block range of %v is too large, the maximum is %v", end-start+1, l.maxBlockRange)
+	}
+	return l.srv.FilterLogs(ctx, start, end, crit.Addresses, crit.Topics)
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web3
+
+import (
+	"context"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/staker"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/transactauth"
+)
+
+// ValidatorAPI exposes the state of the validator's L1 actions for operators
+type ValidatorAPI struct {
+	staker *staker.Staker
+}
+
+func NewValidatorAPI(staker *staker.Staker) *ValidatorAPI {
+	return &ValidatorAPI{staker: staker}
+}
+
+// OutstandingTransactions lists the validator's transactions which have been
+// sent but not yet mined
+func (v *ValidatorAPI) OutstandingTransactions() []transactauth.OutstandingTransaction {
+	return v.staker.OutstandingTransactions()
+}
+
+// Snapshot returns the validator's staker state, which can be used to restore
+// a standby validator
+func (v *ValidatorAPI) Snapshot() *staker.StakerSnapshot {
+	return v.staker.Snapshot()
+}
+
+// Status reports whether the validator is keeping up with L1 along with the
+// state of its stake
+func (v *ValidatorAPI) Status(ctx context.Context) (*staker.StakerStatus, error) {
+	return v.staker.Status(ctx)
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web3
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/challenge"
+	"github.com/offchainlabs/arbitrum/packages/arb-node-core/staker"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+)
+
+// ValidatorAdminAPI lets operators control a running validator. Unlike
+// ValidatorAPI it changes the validator's behavior, so it must only be enabled
+// on RPC endpoints that aren't exposed publicly.
+type ValidatorAdminAPI struct {
+	staker *staker.Staker
+	lookup core.ArbCoreLookup
+}
+
+func NewValidatorAdminAPI(staker *staker.Staker, lookup core.ArbCoreLookup) *ValidatorAdminAPI {
+	return &ValidatorAdminAPI{staker: staker, lookup: lookup}
+}
+
+// Pause stops the validator from sending transactions until Resume is called
+func (v *ValidatorAdminAPI) Pause() {
+	v.staker.Pause()
+}
+
+func (v *ValidatorAdminAPI) Resume() {
+	v.staker.Resume()
+}
+
+func (v *ValidatorAdminAPI) Paused() bool {
+	return v.staker.Paused()
+}
+
+// ForceConfirmation makes the validator try to resolve the next node now
+func (v *ValidatorAdminAPI) ForceConfirmation() {
+	v.staker.ForceConfirmation()
+}
+
+// SaveCheckpoint saves a rocksdb checkpoint of the database
+func (v *ValidatorAdminAPI) SaveCheckpoint() {
+	v.lookup.SaveRocksdbCheckpoint()
+}
+
+// SimulateChallenge plays out a challenge, without touching L1, of an
+// assertion starting at startGas and claiming claimedAfter as its after
+// state, returning the moves it would take and their estimated gas. It
+// executes the whole assertion, so it can take a long time.
+func (v *ValidatorAdminAPI) SimulateChallenge(ctx context.Context, startGas hexutil.Big, claimedAfter *core.ExecutionState) (*challenge.Simulation, error) {
+	if claimedAfter == nil || claimedAfter.TotalGasConsumed == nil || claimedAfter.TotalMessagesRead == nil ||
+		claimedAfter.TotalSendCount == nil || claimedAfter.TotalLogCount == nil {
+		return nil, errors.New("incomplete claimed after state")
+	}
+	if claimedAfter.TotalGasConsumed.Cmp(startGas.ToInt()) <= 0 {
+		return nil, errors.New("claimed after state must be after the start")
+	}
+	cursor, err := v.lookup.GetExecutionCursor(startGas.ToInt(), true)
+	if err != nil {
+		return nil, err
+	}
+	before, err := core.NewExecutionState(cursor)
+	if err != nil {
+		return nil, err
+	}
+	return v.staker.SimulateChallenge(ctx, &core.Assertion{Before: before, After: claimedAfter})
+}
+
+// SetLogLevel changes the log level of the whole node, returning the previous
+// level
+func (v *ValidatorAdminAPI) SetLogLevel(level string) (string, error) {
+	parsed, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	previous := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(parsed)
+	return previous.String(), nil
+}
+
+// SetProfiling turns profiling of the core machine's execution on or off.
+// Turning it on discards the previous profile.
+func (v *ValidatorAdminAPI) SetProfiling(enabled bool) {
+	v.lookup.SetProfiling(enabled)
+}
+
+type ExecutionProfile struct {
+	TotalGas      hexutil.Uint64             `json:"totalGas"`
+	Opcodes       []machine.OpcodeProfile    `json:"opcodes"`
+	HotCodePoints []machine.CodePointProfile `json:"hotCodePoints"`
+}
+
+// Profile returns the opcodes the core machine has spent its gas on, and the
+// maxCodePoints codepoints it spent the most on, since profiling was last
+// turned on
+func (v *ValidatorAdminAPI) Profile(maxCodePoints int) (*ExecutionProfile, error) {
+	profile, err := v.lookup.Profile()
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, errors.New("profiling has not been enabled")
+	}
+	return &ExecutionProfile{
+		TotalGas:      hexutil.Uint64(profile.TotalGas()),
+		Opcodes:       profile.OpcodesByGas(),
+		HotCodePoints: profile.HotCodePoints(maxCodePoints),
+	}, nil
+}
@@ -67,6 +67,11 @@ func NewFireblocksArbTransaction(tx *types.Transaction, details *fireblocks.Tran
 	}, nil
 }
 
+// Transaction returns the signed transaction, which is nil for mock transactions
+func (t *ArbTransaction) Transaction() *types.Transaction {
+	return t.tx
+}
+
 func (t *ArbTransaction) Id() string {
 	return t.id
 }
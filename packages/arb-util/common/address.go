@@ -18,9 +18,10 @@ package common
 
 import (
 	"math/big"
+	"strings"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
 )
 
 type Address [20]byte
@@ -79,14 +80,42 @@ func (a Address) ToEthAddress() ethcommon.Address {
 	return ethcommon.Address(a)
 }
 
+// Hex returns the EIP-55 checksummed hex encoding of a
 func (a Address) Hex() string {
-	return hexutil.Encode(a[:])
+	return a.ToEthAddress().Hex()
 }
 
 func (a Address) MarshalText() ([]byte, error) {
 	return []byte(a.Hex()), nil
 }
 
+// UnmarshalText accepts any hex encoded address, checksummed or not, so that
+// existing files can still be read
+func (a *Address) UnmarshalText(input []byte) error {
+	var addr ethcommon.Address
+	if err := addr.UnmarshalText(input); err != nil {
+		return err
+	}
+	*a = NewAddressFromEth(addr)
+	return nil
+}
+
+// ParseAddress parses a 0x prefixed hex encoded address. If it has mixed
+// case letters, it must have a valid EIP-55 checksum, which catches most
+// mistyped addresses. All lower or upper case addresses have no checksum and
+// are accepted as is.
+func ParseAddress(hex string) (Address, error) {
+	if !strings.HasPrefix(hex, "0x") || !ethcommon.IsHexAddress(hex) {
+		return Address{}, errors.Errorf("invalid address %v", hex)
+	}
+	addr := HexToAddress(hex)
+	digits := hex[2:]
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && addr.Hex() != hex {
+		return Address{}, errors.Errorf("invalid checksum for address %v, expected %v", hex, addr.Hex())
+	}
+	return addr, nil
+}
+
 func HexToAddress(hex string) Address {
 	return NewAddressFromEth(ethcommon.HexToAddress(hex))
 }
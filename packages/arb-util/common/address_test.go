@@ -0,0 +1,69 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseAddress(t *testing.T) {
+	// Example from EIP-55
+	checksummed := "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	addr, err := ParseAddress(checksummed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr.Hex() != checksummed {
+		t.Errorf("expected checksummed address %v, got %v", checksummed, addr.Hex())
+	}
+
+	for _, valid := range []string{strings.ToLower(checksummed), "0x" + strings.ToUpper(checksummed[2:])} {
+		parsed, err := ParseAddress(valid)
+		if err != nil {
+			t.Errorf("failed to parse %v: %v", valid, err)
+		} else if parsed != addr {
+			t.Errorf("parsed %v as %v", valid, parsed)
+		}
+	}
+
+	badChecksum := "0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	for _, invalid := range []string{badChecksum, checksummed[2:], checksummed[:40], ""} {
+		if _, err := ParseAddress(invalid); err == nil {
+			t.Errorf("parsed invalid address %q", invalid)
+		}
+	}
+}
+
+func TestAddressJSON(t *testing.T) {
+	addr := HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	data, err := json.Marshal(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"` {
+		t.Errorf("address not checksummed in json: %v", string(data))
+	}
+	var decoded Address
+	if err := json.Unmarshal([]byte(`"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != addr {
+		t.Error("wrong address decoded from json")
+	}
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"math/big"
+	"sync"
+)
+
+var bigIntPool = sync.Pool{
+	New: func() interface{} {
+		return new(big.Int)
+	},
+}
+
+// GetBigInt returns a zeroed big.Int from a pool for use as a temporary in
+// arithmetic on hot paths. It must be returned with PutBigInt once nothing
+// references it.
+func GetBigInt() *big.Int {
+	return bigIntPool.Get().(*big.Int).SetInt64(0)
+}
+
+// PutBigInt returns a big.Int taken with GetBigInt to the pool
+func PutBigInt(x *big.Int) {
+	bigIntPool.Put(x)
+}
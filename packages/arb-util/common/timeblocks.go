@@ -18,7 +18,9 @@ package common
 
 import (
 	"encoding/json"
+	"math"
 	"math/big"
+	"time"
 )
 
 type TimeBlocks big.Int
@@ -31,6 +33,32 @@ func NewTimeBlocksInt(val int64) *TimeBlocks {
 	return NewTimeBlocks(big.NewInt(val))
 }
 
+// TimeBlocksFromDuration returns how many blocks are produced in d, rounded
+// up, when a block is produced every blockTime, which must be positive
+func TimeBlocksFromDuration(d time.Duration, blockTime time.Duration) *TimeBlocks {
+	blocks := d / blockTime
+	if d%blockTime > 0 {
+		blocks++
+	}
+	return NewTimeBlocksInt(int64(blocks))
+}
+
+// MinTimeBlocks returns the smaller of tb1 and tb2
+func MinTimeBlocks(tb1 *TimeBlocks, tb2 *TimeBlocks) *TimeBlocks {
+	if tb1.Cmp(tb2) <= 0 {
+		return tb1
+	}
+	return tb2
+}
+
+// MaxTimeBlocks returns the larger of tb1 and tb2
+func MaxTimeBlocks(tb1 *TimeBlocks, tb2 *TimeBlocks) *TimeBlocks {
+	if tb1.Cmp(tb2) >= 0 {
+		return tb1
+	}
+	return tb2
+}
+
 func (tb *TimeBlocks) Clone() *TimeBlocks {
 	return NewTimeBlocks(new(big.Int).Set(tb.AsInt()))
 }
@@ -43,6 +71,38 @@ func (tb *TimeBlocks) Cmp(tb2 *TimeBlocks) int {
 	return (*big.Int)(tb).Cmp((*big.Int)(tb2))
 }
 
+func (tb *TimeBlocks) Add(tb2 *TimeBlocks) *TimeBlocks {
+	return NewTimeBlocks(new(big.Int).Add(tb.AsInt(), tb2.AsInt()))
+}
+
+func (tb *TimeBlocks) Sub(tb2 *TimeBlocks) *TimeBlocks {
+	return NewTimeBlocks(new(big.Int).Sub(tb.AsInt(), tb2.AsInt()))
+}
+
+// Clamp returns tb limited to the range [min, max]
+func (tb *TimeBlocks) Clamp(min *TimeBlocks, max *TimeBlocks) *TimeBlocks {
+	return MinTimeBlocks(MaxTimeBlocks(tb, min), max)
+}
+
+// Duration returns how long it takes to produce tb blocks when a block is
+// produced every blockTime, saturating rather than overflowing
+func (tb *TimeBlocks) Duration(blockTime time.Duration) time.Duration {
+	blocks := tb.AsInt()
+	if blocks.Sign() <= 0 || blockTime <= 0 {
+		return 0
+	}
+	if !blocks.IsInt64() || blocks.Int64() > math.MaxInt64/int64(blockTime) {
+		return math.MaxInt64
+	}
+	return time.Duration(blocks.Int64()) * blockTime
+}
+
+// DurationUntil estimates how long it is until block tb is reached from the
+// current block, or 0 if it already has been
+func (tb *TimeBlocks) DurationUntil(current *TimeBlocks, blockTime time.Duration) time.Duration {
+	return tb.Sub(current).Duration(blockTime)
+}
+
 func (tb *TimeBlocks) String() string {
 	return tb.AsInt().String()
 }
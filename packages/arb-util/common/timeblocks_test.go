@@ -0,0 +1,65 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestTimeBlocksDuration(t *testing.T) {
+	blockTime := 13 * time.Second
+	if blocks := TimeBlocksFromDuration(time.Minute, blockTime); blocks.Cmp(NewTimeBlocksInt(5)) != 0 {
+		t.Errorf("expected a minute to round up to 5 blocks, got %v", blocks)
+	}
+	if blocks := TimeBlocksFromDuration(26*time.Second, blockTime); blocks.Cmp(NewTimeBlocksInt(2)) != 0 {
+		t.Errorf("expected 2 blocks, got %v", blocks)
+	}
+	if d := NewTimeBlocksInt(5).Duration(blockTime); d != 65*time.Second {
+		t.Errorf("wrong duration %v", d)
+	}
+	if d := NewTimeBlocksInt(-5).Duration(blockTime); d != 0 {
+		t.Errorf("expected negative blocks to take no time, got %v", d)
+	}
+	huge := NewTimeBlocks(new(big.Int).Lsh(big.NewInt(1), 100))
+	if d := huge.Duration(blockTime); d != math.MaxInt64 {
+		t.Errorf("expected duration to saturate, got %v", d)
+	}
+
+	deadline := NewTimeBlocksInt(110)
+	if d := deadline.DurationUntil(NewTimeBlocksInt(100), blockTime); d != 130*time.Second {
+		t.Errorf("wrong duration until deadline %v", d)
+	}
+	if d := deadline.DurationUntil(NewTimeBlocksInt(120), blockTime); d != 0 {
+		t.Errorf("expected passed deadline to have no time left, got %v", d)
+	}
+}
+
+func TestTimeBlocksClamp(t *testing.T) {
+	min := NewTimeBlocksInt(10)
+	max := NewTimeBlocksInt(20)
+	for _, c := range []struct{ val, expected int64 }{{5, 10}, {15, 15}, {25, 20}} {
+		if clamped := NewTimeBlocksInt(c.val).Clamp(min, max); clamped.Cmp(NewTimeBlocksInt(c.expected)) != 0 {
+			t.Errorf("expected %v clamped to %v, got %v", c.val, c.expected, clamped)
+		}
+	}
+	if MinTimeBlocks(min, max) != min || MaxTimeBlocks(min, max) != max {
+		t.Error("wrong min or max")
+	}
+}
@@ -58,14 +58,29 @@ type Conf struct {
 }
 
 type Database struct {
-	Compact       bool          `koanf:"compact"`
-	ExitAfter     bool          `koanf:"exit-after"`
-	Metadata      bool          `koanf:"metadata"`
-	L0Files       int           `koanf:"l0-files"`
-	SaveInterval  time.Duration `koanf:"save-interval"`
-	SaveOnStartup bool          `koanf:"save-on-startup"`
-	SavePath      string        `koanf:"save-path"`
-	Threads       int           `koanf:"threads"`
+	CheckpointKeyEnv  string        `koanf:"checkpoint-key-env"`
+	CheckpointKeyFile string        `koanf:"checkpoint-key-file"`
+	CheckpointKeyURL  string        `koanf:"checkpoint-key-url"`
+	Compact           bool          `koanf:"compact"`
+	Compression       string        `koanf:"compression"`
+	ExitAfter         bool          `koanf:"exit-after"`
+	Export            string        `koanf:"export"`
+	Import            string        `koanf:"import"`
+	Metadata          bool          `koanf:"metadata"`
+	L0Files           int           `koanf:"l0-files"`
+	ListCheckpoints   uint64        `koanf:"list-checkpoints"`
+	ReplicateInterval time.Duration `koanf:"replicate-interval"`
+	ReplicateTo       string        `koanf:"replicate-to"`
+	RestoreBackup     string        `koanf:"restore-backup"`
+	SaveInterval      time.Duration `koanf:"save-interval"`
+	SaveIncremental   bool          `koanf:"save-incremental"`
+	SaveMaxBackups    int           `koanf:"save-max-backups"`
+	SaveOnStartup     bool          `koanf:"save-on-startup"`
+	SaveQueueSize     int           `koanf:"save-queue-size"`
+	SavePath          string        `koanf:"save-path"`
+	SnapshotSigner    string        `koanf:"snapshot-signer"`
+	SnapshotURL       string        `koanf:"snapshot-url"`
+	Threads           int           `koanf:"threads"`
 }
 
 type Core struct {
@@ -78,16 +93,23 @@ type Core struct {
 	CheckpointLoadGasFactor        int           `koanf:"checkpoint-load-gas-factor"`
 	CheckpointMaxExecutionGas      int           `koanf:"checkpoint-max-execution-gas"`
 	CheckpointMaxToPrune           int           `koanf:"checkpoint-max-to-prune"`
+	CheckpointOnShutdown           bool          `koanf:"checkpoint-on-shutdown"`
+	CheckpointPruningAge           time.Duration `koanf:"checkpoint-pruning-age"`
 	CheckpointPruningMode          string        `koanf:"checkpoint-pruning-mode"`
 	CheckpointPruneOnStartup       bool          `koanf:"checkpoint-prune-on-startup"`
+	CheckpointRetainInterval       int           `koanf:"checkpoint-retain-interval"`
+	CheckpointRetainNodes          int           `koanf:"checkpoint-retain-nodes"`
 	Database                       Database      `koanf:"database"`
 	Debug                          bool          `koanf:"debug"`
 	DebugTiming                    bool          `koanf:"debug-timing"`
 	IdleSleep                      time.Duration `koanf:"idle-sleep"`
 	LazyLoadCoreMachine            bool          `koanf:"lazy-load-core-machine"`
 	LazyLoadArchiveQueries         bool          `koanf:"lazy-load-archive-queries"`
+	MachineEngine                  string        `koanf:"machine-engine"`
 	MessageProcessCount            int           `koanf:"message-process-count"`
+	Profile                        bool          `koanf:"profile"`
 	Test                           CoreTest      `koanf:"test"`
+	VerifyOnStart                  bool          `koanf:"verify-on-start"`
 	YieldInstructionCount          int           `koanf:"yield-instruction-count"`
 }
 
@@ -186,14 +208,18 @@ type NitroExport struct {
 }
 
 type RPC struct {
-	Addr              string      `koanf:"addr"`
-	Port              string      `koanf:"port"`
-	Path              string      `koanf:"path"`
-	EnableL1Calls     bool        `koanf:"enable-l1-calls"`
-	Tracing           Tracing     `koanf:"tracing"`
-	NitroExport       NitroExport `koanf:"nitroexport"`
-	MaxCallGas        uint64      `koanf:"max-call-gas"`
-	EnableDevopsStubs bool        `koanf:"enable-devops-stubs"`
+	Addr                 string      `koanf:"addr"`
+	Port                 string      `koanf:"port"`
+	Path                 string      `koanf:"path"`
+	EnableL1Calls        bool        `koanf:"enable-l1-calls"`
+	Tracing              Tracing     `koanf:"tracing"`
+	NitroExport          NitroExport `koanf:"nitroexport"`
+	MaxCallGas           uint64      `koanf:"max-call-gas"`
+	MaxLogBlockRange     uint64      `koanf:"max-log-block-range"`
+	MaxBatchSize         int         `koanf:"max-batch-size"`
+	BatchConcurrency     int         `koanf:"batch-concurrency"`
+	EnableDevopsStubs    bool        `koanf:"enable-devops-stubs"`
+	EnableValidatorAdmin bool        `koanf:"enable-validator-admin"`
 }
 
 type S3 struct {
@@ -270,6 +296,8 @@ func (f *Forwarder) RpcMode() RpcMode {
 type InboxReader struct {
 	DelayBlocks              int64         `koanf:"delay-blocks"`
 	Paranoid                 bool          `koanf:"paranoid"`
+	ReorgDetectionDepth      int           `koanf:"reorg-detection-depth"`
+	ReorgDetectionInterval   time.Duration `koanf:"reorg-detection-interval"`
 	SequencerSignatureExpiry time.Duration `koanf:"sequencer-signature-expiry"`
 }
 
@@ -315,6 +343,7 @@ type NodeCache struct {
 	AllowSlowLookup  bool          `koanf:"allow-slow-lookup"`
 	LRUSize          int           `koanf:"lru-size"`
 	BlockInfoLRUSize int           `koanf:"block-info-lru-size"`
+	MachinePoolSize  int           `koanf:"machine-pool-size"`
 	TimedInitialSize int           `koanf:"timed-initial-size"`
 	TimedExpire      time.Duration `koanf:"timed-expire"`
 }
@@ -324,8 +353,15 @@ type Persistent struct {
 	GlobalConfig string `koanf:"global-config"`
 }
 
+type Replay struct {
+	Gas      uint64 `koanf:"gas"`
+	Messages uint64 `koanf:"messages"`
+	MaxSteps uint64 `koanf:"max-steps"`
+}
+
 type Rollup struct {
 	Address         string `koanf:"address"`
+	CodeHash        string `koanf:"code-hash"`
 	FromBlock       int64  `koanf:"from-block"`
 	BlockSearchSize int64  `koanf:"block-search-size"`
 	Machine         struct {
@@ -334,17 +370,78 @@ type Rollup struct {
 	} `koanf:"machine"`
 }
 
+type PrivateRelay struct {
+	FallbackDelay time.Duration `koanf:"fallback-delay"`
+	SigningKey    string        `koanf:"signing-key"`
+	URL           string        `koanf:"url"`
+}
+
+type ValidatorConfirm struct {
+	PollInterval   time.Duration `koanf:"poll-interval"`
+	MaxNodesPerTx  int           `koanf:"max-nodes-per-tx"`
+	GasPriceLimit  float64       `koanf:"gas-price-limit"`
+	MaxDelayBlocks int64         `koanf:"max-delay-blocks"`
+}
+
+type ValidatorDisputeAlerts struct {
+	Enable     bool          `koanf:"enable"`
+	Interval   time.Duration `koanf:"interval"`
+	WebhookURL string        `koanf:"webhook-url"`
+}
+
+type ValidatorChallengeAlerts struct {
+	WarnBlocks     int64  `koanf:"warn-blocks"`
+	CriticalBlocks int64  `koanf:"critical-blocks"`
+	WebhookURL     string `koanf:"webhook-url"`
+}
+
+type ValidatorWatchtower struct {
+	EscalateToDefensive bool `koanf:"escalate-to-defensive"`
+}
+
+type ValidatorGasBudget struct {
+	Daily    float64 `koanf:"daily"`
+	Weekly   float64 `koanf:"weekly"`
+	AlertURL string  `koanf:"alert-url"`
+}
+
+type ValidatorLease struct {
+	File     string        `koanf:"file"`
+	URL      string        `koanf:"url"`
+	Holder   string        `koanf:"holder"`
+	Duration time.Duration `koanf:"duration"`
+}
+
 type Validator struct {
-	StrategyImpl                  string            `koanf:"strategy"`
-	UtilsAddress                  string            `koanf:"utils-address"`
-	StakerDelay                   time.Duration     `koanf:"staker-delay"`
-	WalletFactoryAddress          string            `koanf:"wallet-factory-address"`
-	L1PostingStrategy             L1PostingStrategy `koanf:"l1-posting-strategy"`
-	DontChallenge                 bool              `koanf:"dont-challenge"`
-	WithdrawDestination           string            `koanf:"withdraw-destination"`
-	OnlyCreateWalletContract      bool              `koanf:"only-create-wallet-contract"`
-	ContractWalletAddress         string            `koanf:"contract-wallet-address"`
-	ContractWalletAddressFilename string            `koanf:"contract-wallet-address-filename"`
+	StrategyImpl                  string                   `koanf:"strategy"`
+	UtilsAddress                  string                   `koanf:"utils-address"`
+	StakerDelay                   time.Duration            `koanf:"staker-delay"`
+	WalletFactoryAddress          string                   `koanf:"wallet-factory-address"`
+	L1PostingStrategy             L1PostingStrategy        `koanf:"l1-posting-strategy"`
+	MulticallAddress              string                   `koanf:"multicall-address"`
+	DontChallenge                 bool                     `koanf:"dont-challenge"`
+	WithdrawDestination           string                   `koanf:"withdraw-destination"`
+	OnlyCreateWalletContract      bool                     `koanf:"only-create-wallet-contract"`
+	ContractWalletAddress         string                   `koanf:"contract-wallet-address"`
+	ContractWalletAddressFilename string                   `koanf:"contract-wallet-address-filename"`
+	ReceiptCacheFilename          string                   `koanf:"receipt-cache-filename"`
+	JournalFilename               string                   `koanf:"journal-filename"`
+	ChallengeProgressFilename     string                   `koanf:"challenge-progress-filename"`
+	ChallengeStrategy             string                   `koanf:"challenge-strategy"`
+	SnapshotFilename              string                   `koanf:"snapshot-filename"`
+	ReplayToBlock                 int64                    `koanf:"replay-to-block"`
+	ValidationWorkers             int                      `koanf:"validation-workers"`
+	PrivateRelay                  PrivateRelay             `koanf:"private-relay"`
+	Confirm                       ValidatorConfirm         `koanf:"confirm"`
+	DisputeAlerts                 ValidatorDisputeAlerts   `koanf:"dispute-alerts"`
+	ChallengeAlerts               ValidatorChallengeAlerts `koanf:"challenge-alerts"`
+	Watchtower                    ValidatorWatchtower      `koanf:"watchtower"`
+	MaxStake                      float64                  `koanf:"max-stake"`
+	GasBudget                     ValidatorGasBudget       `koanf:"gas-budget"`
+	Lease                         ValidatorLease           `koanf:"lease"`
+	EscalationApprovalURL         string                   `koanf:"escalation-approval-url"`
+	L1ConfirmationDepth           int64                    `koanf:"l1-confirmation-depth"`
+	L1BlockTime                   time.Duration            `koanf:"l1-block-time"`
 }
 
 type ValidatorStrategy uint8
@@ -355,6 +452,7 @@ const (
 	DefensiveStrategy
 	StakeLatestStrategy
 	MakeNodesStrategy
+	ResolveNodesStrategy
 )
 
 func (s ValidatorStrategy) IsActive() bool {
@@ -374,16 +472,36 @@ func (c *Validator) Strategy() ValidatorStrategy {
 		return StakeLatestStrategy
 	} else if strings.EqualFold(c.StrategyImpl, "MakeNodes") {
 		return MakeNodesStrategy
+	} else if strings.EqualFold(c.StrategyImpl, "ResolveNodes") {
+		return ResolveNodesStrategy
 	} else {
 		return UnknownStrategy
 	}
 }
 
+// NeedsWallet returns whether the validator may send transactions, and so
+// needs a key and a validator smart contract wallet
+func (c *Validator) NeedsWallet() bool {
+	return c.Strategy() != WatchtowerStrategy || c.Watchtower.EscalateToDefensive
+}
+
 type Wallet struct {
+	External   WalletExternal   `koanf:"external"`
 	Fireblocks WalletFireblocks `koanf:"fireblocks"`
+	Hardware   WalletHardware   `koanf:"hardware"`
 	Local      WalletLocal      `koanf:"local"`
 }
 
+type WalletHardware struct {
+	DerivationPath string `koanf:"derivation-path"`
+	Type           string `koanf:"type"`
+}
+
+type WalletExternal struct {
+	Address string `koanf:"address"`
+	URL     string `koanf:"url"`
+}
+
 type WalletFireblocks struct {
 	APIKey               string     `koanf:"api-key,omitempty"`
 	AssetId              string     `koanf:"asset-id,omitempty"`
@@ -427,6 +545,31 @@ func (w WalletLocal) Password() *string {
 	return &w.PasswordImpl
 }
 
+type GasPriceOracle struct {
+	MaxFeeGwei       float64 `koanf:"max-fee-gwei"`
+	Percentile       int     `koanf:"percentile"`
+	PercentileBlocks int     `koanf:"percentile-blocks"`
+	Strategy         string  `koanf:"strategy"`
+	UrgentTipPercent int     `koanf:"urgent-tip-percent"`
+}
+
+type L1RateLimit struct {
+	RequestsPerSecond float64       `koanf:"requests-per-second"`
+	Burst             int           `koanf:"burst"`
+	MethodBudgets     []string      `koanf:"method-budgets"`
+	MaxRetries        int           `koanf:"max-retries"`
+	InitialBackoff    time.Duration `koanf:"initial-backoff"`
+	MaxBackoff        time.Duration `koanf:"max-backoff"`
+}
+
+type TxSubmission struct {
+	MaxAttempts     int           `koanf:"max-attempts"`
+	RetryDelay      time.Duration `koanf:"retry-delay"`
+	BreakerFailures int           `koanf:"breaker-failures"`
+	BreakerCooldown time.Duration `koanf:"breaker-cooldown"`
+	MaxTxFeeEth     float64       `koanf:"max-tx-fee-eth"`
+}
+
 type Log struct {
 	RPC  string `koanf:"rpc"`
 	Core string `koanf:"core"`
@@ -438,28 +581,33 @@ type Metrics struct {
 }
 
 type Config struct {
-	BridgeUtilsAddress string      `koanf:"bridge-utils-address"`
-	Conf               Conf        `koanf:"conf"`
-	Core               Core        `koanf:"core"`
-	Feed               Feed        `koanf:"feed"`
-	GasPrice           float64     `koanf:"gas-price"`
-	Healthcheck        Healthcheck `koanf:"healthcheck"`
+	BridgeUtilsAddress string         `koanf:"bridge-utils-address"`
+	Conf               Conf           `koanf:"conf"`
+	Core               Core           `koanf:"core"`
+	Feed               Feed           `koanf:"feed"`
+	GasPrice           float64        `koanf:"gas-price"`
+	GasPriceOracle     GasPriceOracle `koanf:"gas-price-oracle"`
+	Healthcheck        Healthcheck    `koanf:"healthcheck"`
 	L1                 struct {
-		ChainID uint64 `koanf:"chain-id"`
-		URL     string `koanf:"url"`
+		CallTimeout time.Duration `koanf:"call-timeout"`
+		ChainID     uint64        `koanf:"chain-id"`
+		RateLimit   L1RateLimit   `koanf:"rate-limit"`
+		URL         string        `koanf:"url"`
 	} `koanf:"l1"`
 	L2 struct {
 		FinalClassicBlock uint64 `koanf:"final-classic-block"`
 		DisableUpstream   bool   `koanf:"disable-upstream"`
 	} `koanf:"l2"`
-	Log           Log        `koanf:"log"`
-	Node          Node       `koanf:"node"`
-	Persistent    Persistent `koanf:"persistent"`
-	PProfEnable   bool       `koanf:"pprof-enable"`
-	Rollup        Rollup     `koanf:"rollup"`
-	Validator     Validator  `koanf:"validator"`
-	WaitToCatchUp bool       `koanf:"wait-to-catch-up"`
-	Wallet        Wallet     `koanf:"wallet"`
+	Log           Log          `koanf:"log"`
+	Node          Node         `koanf:"node"`
+	Persistent    Persistent   `koanf:"persistent"`
+	PProfEnable   bool         `koanf:"pprof-enable"`
+	Replay        Replay       `koanf:"replay"`
+	Rollup        Rollup       `koanf:"rollup"`
+	TxSubmission  TxSubmission `koanf:"tx-submission"`
+	Validator     Validator    `koanf:"validator"`
+	WaitToCatchUp bool         `koanf:"wait-to-catch-up"`
+	Wallet        Wallet       `koanf:"wallet"`
 
 	// The following field needs to be top level for compatibility with the underlying go-ethereum lib
 	Metrics       bool    `koanf:"metrics"`
@@ -480,6 +628,7 @@ func DefaultCoreSettingsNoMaxExecution() *Core {
 		CheckpointLoadGasFactor:   4,
 		CheckpointMaxExecutionGas: 0,
 		CheckpointPruningMode:     "default",
+		MachineEngine:             "cpp",
 		MessageProcessCount:       10,
 		YieldInstructionCount:     1_000_000,
 	}
@@ -499,6 +648,7 @@ func DefaultCoreSettingsMaxExecution() *Core {
 		CheckpointLoadGasFactor:   4,
 		CheckpointMaxExecutionGas: 1_000_000_000,
 		CheckpointPruningMode:     "default",
+		MachineEngine:             "cpp",
 		MessageProcessCount:       10,
 		YieldInstructionCount:     1_000_000,
 	}
@@ -510,6 +660,7 @@ func DefaultNodeSettings() *Node {
 		Cache: NodeCache{
 			AllowSlowLookup: true,
 			LRUSize:         1000,
+			MachinePoolSize: 4,
 			TimedExpire:     20 * time.Minute,
 		},
 		InboxReader: InboxReader{
@@ -559,6 +710,30 @@ func ParseDBTool() (*Config, error) {
 	return out, err
 }
 
+func ParseReplayTool() (*Config, error) {
+	f := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+
+	AddPersistent(f)
+	AddCore(f, 0)
+
+	f.Uint64("replay.gas", 0, "start from the machine after executing this much gas")
+	f.Uint64("replay.messages", 1, "number of inbox messages following the machine's position to make available to it")
+	f.Uint64("replay.max-steps", 100_000_000, "maximum number of instructions to execute for a single command")
+
+	k, err := beginCommonParse(f)
+	if err != nil {
+		return nil, err
+	}
+
+	out, wallet, err := endCommonParse(k)
+	if err != nil {
+		return nil, err
+	}
+
+	err = resolveDirectoryNames(out, wallet)
+	return out, err
+}
+
 func AddL1PostingStrategyOptions(f *flag.FlagSet, prefix string) {
 	f.Float64(prefix+"l1-posting-strategy.high-gas-threshold", 150, "gwei threshold at which to consider gas price high and delay batch posting")
 	f.Int64(prefix+"l1-posting-strategy.high-gas-delay-blocks", 270, "wait up to this many more blocks when gas costs are high")
@@ -580,7 +755,40 @@ func ParseNode(ctx context.Context) (*Config, *Wallet, *ethutils.RPCEthClient, *
 	f.Duration("validator.staker-delay", 60*time.Second, "delay between updating stake")
 	f.String("validator.wallet-factory-address", "", "strategy for validator to use")
 	f.Bool("validator.dont-challenge", false, "don't challenge any other validators' assertions")
+	f.String("validator.multicall-address", "", "address of a Multicall2 compatible contract used to batch L1 reads (optional)")
 	f.String("validator.withdraw-destination", "", "the address to withdraw funds to (defaults to the wallet address)")
+	f.String("validator.private-relay.url", "", "private relay to submit challenge and confirmation transactions to instead of the public mempool (optional)")
+	f.String("validator.private-relay.signing-key", "", "hex encoded private key used to sign private relay requests (random if not set)")
+	f.Duration("validator.private-relay.fallback-delay", 2*time.Minute, "time to wait for a privately submitted transaction before also sending it to the public mempool")
+	f.Duration("validator.confirm.poll-interval", 10*time.Second, "how often to check L1 for rollup activity or confirmable nodes between staker actions")
+	f.Int("validator.confirm.max-nodes-per-tx", 10, "maximum number of nodes to confirm in a single transaction")
+	f.Float64("validator.confirm.gas-price-limit", 0, "gas price in gwei above which confirmations are postponed (0 = no limit)")
+	f.Int64("validator.confirm.max-delay-blocks", 1000, "maximum number of blocks past a node's deadline to postpone its confirmation because of the gas price limit")
+	f.String("validator.receipt-cache-filename", "validatorReceipts.json", "json file that transactions sent by the validator and their receipts are stored in (empty to disable)")
+	f.String("validator.journal-filename", "", "file to append a record of every validator decision to (optional)")
+	f.String("validator.challenge-strategy", "Default", "strategy for choosing challenge moves, Default or NoForfeit (never claims the timeout of its own move)")
+	f.String("validator.challenge-progress-filename", "validatorChallenge.json", "json file that the progress of the validator's active challenge is saved to, so that it's resumed after a restart (empty to disable)")
+	f.String("validator.snapshot-filename", "", "json file that the staker's state is saved to after each action and restored from on startup (optional)")
+	f.Int64("validator.replay-to-block", 0, "check every node created up to this L1 block against the local database and exit without sending transactions (0 to disable)")
+	f.Int("validator.validation-workers", 4, "number of nodes with different start states to execute in parallel when checking many nodes at once, such as when replaying or scanning for disputes")
+	f.Bool("validator.dispute-alerts.enable", false, "scan unresolved nodes for assertions the local database disagrees with and alert on them, whatever the strategy")
+	f.Duration("validator.dispute-alerts.interval", time.Minute, "how often to scan unresolved nodes for disputed assertions")
+	f.String("validator.dispute-alerts.webhook-url", "", "url to POST a json alert to when a disputed assertion is found (optional)")
+	f.Int64("validator.challenge-alerts.warn-blocks", 2000, "warn when this many L1 blocks remain to make our challenge move and it hasn't been posted (0 = disabled)")
+	f.Int64("validator.challenge-alerts.critical-blocks", 500, "raise a critical alert when this many L1 blocks remain to make our challenge move and it hasn't been posted (0 = disabled)")
+	f.String("validator.challenge-alerts.webhook-url", "", "url to POST a json alert to when a challenge move deadline alert is raised (optional)")
+	f.Bool("validator.watchtower.escalate-to-defensive", false, "when running as a watchtower, stake defensively once an incorrect assertion is found (requires a validator wallet)")
+	f.Float64("validator.gas-budget.daily", 0, "maximum ETH to spend on validator transactions in any 24 hours before deferring actions which can wait (0 = no limit)")
+	f.Float64("validator.gas-budget.weekly", 0, "maximum ETH to spend on validator transactions in any 7 days before deferring actions which can wait (0 = no limit)")
+	f.String("validator.gas-budget.alert-url", "", "url to POST a json alert to when the gas budget is exhausted (optional)")
+	f.String("validator.lease.file", "", "json file on storage shared by validators using the same staking key, holding the lease for which of them acts (optional)")
+	f.String("validator.lease.url", "", "url of a lock service to POST lease requests to, for validators sharing the same staking key (optional)")
+	f.String("validator.lease.holder", "", "name identifying this validator to the lease (defaults to hostname and process id)")
+	f.Duration("validator.lease.duration", 10*time.Minute, "how long the lease lasts after each renewal, which must be longer than the staker takes to act (the lease is also renewed while waiting for transactions to be mined)")
+	f.Float64("validator.max-stake", 0, "maximum stake in ETH the validator will place (0 = no limit)")
+	f.String("validator.escalation-approval-url", "", "url to POST to for approval before an inactive validator stakes against an incorrect assertion, which must respond with {\"approved\": true} (optional)")
+	f.Int64("validator.l1-confirmation-depth", 0, "number of L1 blocks behind the latest block to read rollup state from when acting, so that shallow L1 reorgs don't affect the staker, and to wait for after each of its transactions before acting again (0 to use the latest block)")
+	f.Duration("validator.l1-block-time", 13*time.Second, "average time between L1 blocks, used to estimate when block deadlines will pass")
 
 	f.String("node.aggregator.inbox-address", "", "address of the inbox contract")
 	f.Int("node.aggregator.max-batch-time", 10, "max-batch-time=NumSeconds")
@@ -589,6 +797,7 @@ func ParseNode(ctx context.Context) (*Config, *Wallet, *ethutils.RPCEthClient, *
 	f.Bool("node.cache.allow-slow-lookup", false, "load L2 block from disk if not in memory cache")
 	f.Int("node.cache.lru-size", 1000, "number of recently used L2 blocks to hold in lru memory cache")
 	f.Int("node.cache.block-info-lru-size", 100_000, "number of recently used L2 block info to hold in lru memory cache")
+	f.Int("node.cache.machine-pool-size", 4, "number of copies of the latest and pending machines to keep ready for calls and gas estimation")
 	f.Duration("node.cache.timed-expire", 20*time.Minute, "length of time to hold L2 blocks in timed memory cache")
 
 	f.Uint64("node.chain-id", 42161, "chain id of the arbitrum chain")
@@ -598,6 +807,8 @@ func ParseNode(ctx context.Context) (*Config, *Wallet, *ethutils.RPCEthClient, *
 
 	f.Int64("node.inbox-reader.delay-blocks", 4, "number of L1 blocks to wait for confirmation before updating L2 state")
 	f.Bool("node.inbox-reader.paranoid", false, "if enabled, check for reorgs before searching for messages")
+	f.Int("node.inbox-reader.reorg-detection-depth", 64, "number of recent L1 block hashes to track for reorg detection (0 to disable)")
+	f.Duration("node.inbox-reader.reorg-detection-interval", 15*time.Second, "how often to check recent L1 blocks for reorgs")
 	f.Duration("node.inbox-reader.sequencer-signature-expiry", 10*time.Minute, "length of time between verifying sequencer feed signing address on-chain")
 
 	f.Duration("node.log-idle-sleep", 100*time.Millisecond, "milliseconds for log reader to sleep between reading logs")
@@ -606,11 +817,15 @@ func ParseNode(ctx context.Context) (*Config, *Wallet, *ethutils.RPCEthClient, *
 	f.String("node.rpc.addr", "0.0.0.0", "RPC address")
 	f.Int("node.rpc.port", 8547, "RPC port")
 	f.String("node.rpc.path", "/", "RPC path")
-	f.Bool("node.rpc.enable-l1-calls", false, "If RPC calls which query the L1 node indirectly should be allowed")
+	f.Bool("node.rpc.enable-l1-calls", false, "If RPC calls which query the L1 node indirectly should be allowed, including the assertions rpcs")
 	f.Bool("node.rpc.tracing.enable", false, "enable tracing api")
 	f.String("node.rpc.tracing.namespace", "arbtrace", "rpc namespace for tracing api")
 	f.Uint64("node.rpc.max-call-gas", 5000000, "Max computational arbgas limit when processing eth_call and eth_estimateGas")
+	f.Uint64("node.rpc.max-log-block-range", 0, "Max number of blocks an eth_getLogs query may cover (0 for no limit)")
+	f.Int("node.rpc.max-batch-size", 0, "Max number of requests in a JSON-RPC batch sent over HTTP, larger batches being rejected (0 for no limit)")
+	f.Int("node.rpc.batch-concurrency", 4, "Number of requests from a JSON-RPC batch to execute at once")
 	f.Bool("node.rpc.enable-devops-stubs", false, "Enable fake versions of eth_syncing and eth_netPeers")
+	f.Bool("node.rpc.enable-validator-admin", false, "Enable the validatoradmin rpcs to pause the validator, force confirmations, save checkpoints, profile execution and change the log level (don't expose publicly)")
 
 	f.Bool("node.rpc.nitroexport.enable", false, "Enable rpcs for nitro export (stored locally on node)")
 	f.String("node.rpc.nitroexport.basedir", "", "Base dir for nitro export")
@@ -644,15 +859,38 @@ func ParseNonRelay(ctx context.Context, f *flag.FlagSet, defaultWalletPathname s
 	f.String("bridge-utils-address", "", "bridgeutils contract address")
 
 	f.Float64("gas-price", 0, "float of gas price to use in gwei (0 = use L1 node's recommended value)")
+	f.String("gas-price-oracle.strategy", "node", "how to pick the priority fee when gas-price isn't set, either node or percentile")
+	f.Int("gas-price-oracle.percentile", 60, "percentile of recent priority fees to use with the percentile strategy")
+	f.Int("gas-price-oracle.percentile-blocks", 5, "number of recent blocks to sample with the percentile strategy")
+	f.Int("gas-price-oracle.urgent-tip-percent", 200, "percentage of the suggested priority fee to pay for urgent transactions such as challenge moves")
+	f.Float64("gas-price-oracle.max-fee-gwei", 0, "maximum fee per gas in gwei to ever pay (0 = no limit)")
+
+	f.Int("tx-submission.max-attempts", 3, "number of times to attempt sending a validator L1 transaction which failed with a transient error")
+	f.Duration("tx-submission.retry-delay", 2*time.Second, "delay before retrying a failed validator L1 transaction submission")
+	f.Int("tx-submission.breaker-failures", 5, "consecutive failed validator L1 transaction submissions before pausing the validator's on-chain actions (0 = never pause)")
+	f.Duration("tx-submission.breaker-cooldown", 10*time.Minute, "how long to pause the validator's on-chain actions after too many failures")
+	f.Float64("tx-submission.max-tx-fee-eth", 1, "refuse to send a validator L1 transaction whose maximum fee exceeds this many ETH (0 = no limit)")
 
 	f.String("l1.url", "", "layer 1 ethereum node RPC URL")
 	f.Uint64("l1.chain-id", 0, "if set other than 0, will be used to validate database and L1 connection")
+	f.Duration("l1.call-timeout", time.Minute, "maximum time to wait for a single L1 request before giving up (0 = no timeout)")
+	f.Float64("l1.rate-limit.requests-per-second", 0, "maximum requests per second to the L1 node (0 = unlimited)")
+	f.Int("l1.rate-limit.burst", 10, "number of L1 requests allowed to burst above the rate limit")
+	f.StringSlice("l1.rate-limit.method-budgets", []string{}, "per method requests per second limits, e.g. eth_getLogs=2")
+	f.Int("l1.rate-limit.max-retries", 5, "number of times to retry an L1 request which was throttled or hit a server error")
+	f.Duration("l1.rate-limit.initial-backoff", 500*time.Millisecond, "initial delay before retrying a throttled L1 request")
+	f.Duration("l1.rate-limit.max-backoff", 30*time.Second, "maximum delay before retrying a throttled L1 request")
 
 	f.String("rollup.address", "", "layer 2 rollup contract address")
 	f.Int64("rollup.from-block", 0, "layer 2 rollup contract creation block")
+	f.String("rollup.code-hash", "", "expected hash of the rollup contract's code, checked on startup (optional)")
 	f.Int64("rollup.block-search-size", 0, "number of blocks to search at a time when looking for validator smart contract wallet creation, 0 to search all blocks at once")
 	f.String("rollup.machine.filename", "", "file to load machine from")
 
+	f.String("wallet.external.url", "", "URL of external signer (e.g. clef) to sign transactions with instead of a local key")
+	f.String("wallet.external.address", "", "address of account to use from external signer, required if it manages more than one")
+	f.String("wallet.hardware.type", "", "sign transactions with a connected hardware wallet, either ledger or trezor")
+	f.String("wallet.hardware.derivation-path", "m/44'/60'/0'/0/0", "derivation path of hardware wallet account")
 	f.Bool("wallet.local.only-create-key", false, "create new wallet and exit")
 	f.String("wallet.local.pathname", defaultWalletPathname, "path to store wallet in")
 	f.String("wallet.local.password", PASSWORD_NOT_SET, "password for wallet")
@@ -887,6 +1125,18 @@ func resolveDirectoryNames(out *Config, wallet *Wallet) error {
 		out.Validator.ContractWalletAddressFilename = path.Join(out.Persistent.Chain, out.Validator.ContractWalletAddressFilename)
 	}
 
+	// Make validator state files relative to chain directory if set and not already absolute
+	for _, filename := range []*string{
+		&out.Validator.ReceiptCacheFilename,
+		&out.Validator.JournalFilename,
+		&out.Validator.ChallengeProgressFilename,
+		&out.Validator.SnapshotFilename,
+	} {
+		if len(*filename) > 0 && !filepath.IsAbs(*filename) {
+			*filename = path.Join(out.Persistent.Chain, *filename)
+		}
+	}
+
 	return nil
 }
 
@@ -941,15 +1191,34 @@ func AddCore(f *flag.FlagSet, maxExecutionGas int) {
 	f.Int("core.checkpoint-load-gas-factor", 4, "factor to weight difference in database checkpoint vs cache checkpoint")
 	f.Int("core.checkpoint-max-execution-gas", maxExecutionGas, "maximum amount of gas any given checkpoint is allowed to execute")
 	f.Int("core.checkpoint-max-to-prune", 2, "number of checkpoints to delete at a time, 0 for no limit")
+	f.Bool("core.checkpoint-on-shutdown", false, "save a rocksdb checkpoint when shutting down gracefully")
 	f.Bool("core.checkpoint-prune-on-startup", false, "perform full database pruning on startup")
+	f.Duration("core.checkpoint-pruning-age", 0, "never prune checkpoints for inbox messages newer than this, 0 to disable")
 	f.String("core.checkpoint-pruning-mode", "default", "Prune old checkpoints: 'on', 'off', or 'default'")
+	f.Int("core.checkpoint-retain-interval", 0, "when pruning, keep the first checkpoint of every interval of this many L2 blocks, 0 to prune all old checkpoints")
+	f.Int("core.checkpoint-retain-nodes", 1, "number of confirmed nodes before the latest confirmed node to keep checkpoints for when pruning (use pruning mode 'off' for an archive node)")
 
+	f.String("core.database.checkpoint-key-env", "", "environment variable holding a hex encoded AES-256 key to encrypt checkpoints with")
+	f.String("core.database.checkpoint-key-file", "", "file holding a hex encoded AES-256 key to encrypt checkpoints with")
+	f.String("core.database.checkpoint-key-url", "", "key management service URL returning a hex encoded AES-256 key to encrypt checkpoints with")
 	f.Bool("core.database.compact", false, "perform database compaction")
+	f.String("core.database.compression", "default", "database block compression (default, none, snappy, lz4 or zstd), run with core.database.compact to rewrite existing data")
 	f.Bool("core.database.exit-after", false, "exit after loading or manipulating database")
 	f.Bool("core.database.metadata", false, "just print database metadata and exit")
+	f.Uint64("core.database.list-checkpoints", 0, "print this many of the latest checkpoints without loading their machines and exit (arb-db only)")
 	f.Duration("core.database.save-interval", 0, "duration between saving database backups, 0 to disable")
 	f.Bool("core.database.save-on-startup", false, "save database backup on start")
+	f.Int("core.database.save-queue-size", 1, "number of database backups to queue for the background writer, further backups are skipped while it's full (0 to save on the core thread)")
 	f.String("core.database.save-path", "db_checkpoints", "path to save database backups in")
+	f.Bool("core.database.save-incremental", false, "save incremental backups in the incremental directory of the save path, only copying files changed since the previous backup, instead of full copies")
+	f.Int("core.database.save-max-backups", 0, "number of incremental backups to keep, deleting files only used by older ones (0 to keep all)")
+	f.String("core.database.export", "", "write the database to a portable archive at this path and exit, node must be stopped (arb-db only)")
+	f.String("core.database.import", "", "verify and extract an archive created with core.database.export into a new database before starting (arb-db only)")
+	f.String("core.database.snapshot-url", "", "http(s) or public s3:// URL of an archive created with core.database.export to bootstrap a new database from")
+	f.String("core.database.snapshot-signer", "", "address whose signature of the snapshot, downloaded from core.database.snapshot-url with .sig appended, is required")
+	f.String("core.database.replicate-to", "", "directory or http(s) URL accepting PUT requests to copy each database save to as an archive")
+	f.Duration("core.database.replicate-interval", time.Minute, "how often to check for new database saves to replicate")
+	f.String("core.database.restore-backup", "", "directory of incremental backups to restore the latest one from into a new database before starting (arb-db only)")
 
 	f.Bool("core.debug", false, "print extra debug messages in arbcore")
 	f.Bool("core.debug-timing", false, "print extra debug timing messages in arbcore")
@@ -959,8 +1228,12 @@ func AddCore(f *flag.FlagSet, maxExecutionGas int) {
 	f.Bool("core.lazy-load-core-machine", false, "if the core machine should be loaded as it's run")
 	f.Bool("core.lazy-load-archive-queries", true, "if the archive queries should be loaded as they're run")
 
+	f.String("core.machine-engine", "cpp", "AVM implementation to run standalone machines with: 'cpp' or 'go' (ArbCore always uses cpp, and arb-node checks that go computes the same initial machine hash; binaries built with the nocmachine tag only support go)")
+
 	f.Int("core.message-process-count", 100, "maximum number of messages to process at a time")
 
+	f.Bool("core.profile", false, "record which opcodes and codepoints the core machine spends its gas on, readable through the validatoradmin_profile rpc which can also toggle it")
+
 	f.Int("core.test.load-count", 0, "number of snapshots to load from database for profile test, zero to disable")
 	f.Int("core.test.reorg-to.l1-block", 0, "reorg to snapshot with given L1 block or before, zero to disable")
 	f.Int("core.test.reorg-to.l2-block", 0, "reorg to snapshot with given L2 block or before, zero to disable")
@@ -969,6 +1242,7 @@ func AddCore(f *flag.FlagSet, maxExecutionGas int) {
 	f.Bool("core.test.reset-all-except-inbox", false, "remove all database info except for inbox")
 	f.Int("core.test.run-until", 0, "run until gas is reached for profile test, zero to disable")
 
+	f.Bool("core.verify-on-start", false, "verify every checkpoint and the block index on startup, exiting if corruption is found")
 	f.Int("core.yield-instruction-count", 50_000_000, "number of instructions to for core thread to run between calling yield")
 
 }
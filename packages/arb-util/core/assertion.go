@@ -84,12 +84,12 @@ func BisectionChunkHash(
 	startHash common.Hash,
 	endHash common.Hash,
 ) common.Hash {
-	return hashing.SoliditySHA3(
-		hashing.Uint256(segmentStart),
-		hashing.Uint256(segmentLength),
-		hashing.Bytes32(startHash),
-		hashing.Bytes32(endHash),
-	)
+	data := make([]byte, 0, 4*32)
+	data = hashing.AppendUint256(data, segmentStart)
+	data = hashing.AppendUint256(data, segmentLength)
+	data = append(data, startHash[:]...)
+	data = append(data, endHash[:]...)
+	return hashing.SoliditySHA3(data)
 }
 
 func (a *Assertion) BeforeExecutionHash() common.Hash {
@@ -101,12 +101,15 @@ func (a *Assertion) AfterExecutionHash() common.Hash {
 }
 
 func (a *Assertion) ExecutionHash() common.Hash {
-	return BisectionChunkHash(
+	gasUsed := common.GetBigInt().Sub(a.After.TotalGasConsumed, a.Before.TotalGasConsumed)
+	hash := BisectionChunkHash(
 		a.Before.TotalGasConsumed,
-		new(big.Int).Sub(a.After.TotalGasConsumed, a.Before.TotalGasConsumed),
+		gasUsed,
 		a.BeforeExecutionHash(),
 		a.AfterExecutionHash(),
 	)
+	common.PutBigInt(gasUsed)
+	return hash
 }
 
 func (a *Assertion) GasUsed() *big.Int {
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
+)
+
+func randomExecutionState(gas int64) *ExecutionState {
+	return &ExecutionState{
+		MachineHash:       common.RandHash(),
+		TotalMessagesRead: big.NewInt(1000),
+		TotalGasConsumed:  big.NewInt(gas),
+		TotalSendCount:    big.NewInt(20),
+		TotalLogCount:     big.NewInt(300),
+		SendAcc:           common.RandHash(),
+		LogAcc:            common.RandHash(),
+	}
+}
+
+func TestExecutionHash(t *testing.T) {
+	assertion := &Assertion{
+		Before: randomExecutionState(1 << 40),
+		After:  randomExecutionState(1 << 41),
+	}
+	cutHash := func(e *ExecutionState) common.Hash {
+		return hashing.SoliditySHA3(
+			hashing.Uint256(e.TotalGasConsumed),
+			hashing.Bytes32(hashing.SoliditySHA3(
+				hashing.Uint256(e.TotalMessagesRead),
+				hashing.Bytes32(e.MachineHash),
+				hashing.Bytes32(e.SendAcc),
+				hashing.Uint256(e.TotalSendCount),
+				hashing.Bytes32(e.LogAcc),
+				hashing.Uint256(e.TotalLogCount),
+			)),
+		)
+	}
+	expected := hashing.SoliditySHA3(
+		hashing.Uint256(assertion.Before.TotalGasConsumed),
+		hashing.Uint256(big.NewInt(1<<40)),
+		hashing.Bytes32(cutHash(assertion.Before)),
+		hashing.Bytes32(cutHash(assertion.After)),
+	)
+	if hash := assertion.ExecutionHash(); hash != expected {
+		t.Errorf("wrong execution hash %v, expected %v", hash, expected)
+	}
+	if assertion.After.TotalGasConsumed.Cmp(big.NewInt(1<<41)) != 0 {
+		t.Error("execution hash modified assertion")
+	}
+}
+
+// BenchmarkNodeHash covers the hashing done to build the node hash a node
+// is created and confirmed with
+func BenchmarkNodeHash(b *testing.B) {
+	assertion := &Assertion{
+		Before: randomExecutionState(1 << 40),
+		After:  randomExecutionState(1 << 41),
+	}
+	prevNodeHash := common.RandHash()
+	batchEndAcc := common.RandHash()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		executionHash := assertion.ExecutionHash()
+		hashing.SoliditySHA3([]byte{0}, prevNodeHash[:], executionHash[:], batchEndAcc[:])
+	}
+}
@@ -113,12 +113,71 @@ type ArbCoreLookup interface {
 	// will be pruned
 	UpdateCheckpointPruningGas(gas *big.Int)
 
+	// CheckpointPruningStats reports what checkpoint pruning has reclaimed
+	// since startup
+	CheckpointPruningStats() CheckpointPruningStats
+
+	// CheckpointStats reports checkpoint save and restore timings since
+	// startup and how the database size is split between record types
+	CheckpointStats() CheckpointStats
+
+	// VerifyCheckpoints reloads the machine state of every stored checkpoint
+	// and returns how many checkpoints were checked and how many are corrupt
+	VerifyCheckpoints() (uint64, uint64, error)
+
+	// CheckpointSummaries returns up to maxCount checkpoints starting at
+	// fromGas, read without loading their machines
+	CheckpointSummaries(fromGas *big.Int, maxCount uint64) ([]CheckpointSummary, error)
+
 	// SaveRocksdbCheckpoint tells rocksdb to save a copy of the current database state
 	SaveRocksdbCheckpoint()
 
+	// SetProfiling turns profiling of the core machine's execution on or off.
+	// Turning it on starts a new profile.
+	SetProfiling(enabled bool)
+
+	// Profile returns the profile of the core machine's execution since
+	// profiling was last turned on, or nil if it never has been
+	Profile() (*machine.Profile, error)
+
 	DumpArbosState(mach machine.Machine, blockNum uint64, dirname string) error
 }
 
+type CheckpointPruningStats struct {
+	// PrunedCheckpoints is the number of checkpoints deleted
+	PrunedCheckpoints uint64
+	// PrunedBytes is the size of the deleted checkpoint records, not
+	// including machine state they were the last reference to
+	PrunedBytes uint64
+	// DatabaseSize is the current size of the database files on disk
+	DatabaseSize uint64
+}
+
+type CheckpointSummary struct {
+	TotalGasConsumed  *big.Int
+	L2BlockNumber     *big.Int
+	TotalMessagesRead *big.Int
+	TotalSendCount    *big.Int
+	TotalLogCount     *big.Int
+	// MachineHash is nil if the checkpoint no longer holds its machine
+	MachineHash *common.Hash
+}
+
+type CheckpointStats struct {
+	SavedCheckpoints uint64
+	// SavedBytes is the size of the checkpoint records written, not
+	// including the machine state they reference
+	SavedBytes      uint64
+	SaveTime        time.Duration
+	LastSaveTime    time.Duration
+	LastRestoreTime time.Duration
+	// The database files on disk holding machine state, checkpoint records
+	// and inbox messages
+	MachineSize    uint64
+	CheckpointSize uint64
+	InboxSize      uint64
+}
+
 type ArbCoreInbox interface {
 	DeliverMessages(previousMessageCount *big.Int, previousSeqBatchAcc common.Hash, seqBatchItems []inbox.SequencerBatchItem, delayedMessages []inbox.DelayedMessage, reorgSeqBatchItemCount *big.Int) bool
 	MessagesStatus() (MessageStatus, error)
@@ -266,21 +325,24 @@ func (e *ExecutionState) IsPermanentlyBlocked() bool {
 }
 
 func (e *ExecutionState) RestHash() [32]byte {
-	return hashing.SoliditySHA3(
-		hashing.Uint256(e.TotalMessagesRead),
-		hashing.Bytes32(e.MachineHash),
-		hashing.Bytes32(e.SendAcc),
-		hashing.Uint256(e.TotalSendCount),
-		hashing.Bytes32(e.LogAcc),
-		hashing.Uint256(e.TotalLogCount),
-	)
+	// The preimage is built in one buffer as this is hashed for every cut of
+	// every bisection
+	data := make([]byte, 0, 6*32)
+	data = hashing.AppendUint256(data, e.TotalMessagesRead)
+	data = append(data, e.MachineHash[:]...)
+	data = append(data, e.SendAcc[:]...)
+	data = hashing.AppendUint256(data, e.TotalSendCount)
+	data = append(data, e.LogAcc[:]...)
+	data = hashing.AppendUint256(data, e.TotalLogCount)
+	return hashing.SoliditySHA3(data)
 }
 
 func (e *ExecutionState) CutHash() common.Hash {
-	return hashing.SoliditySHA3(
-		hashing.Uint256(e.TotalGasConsumed),
-		hashing.Bytes32(e.RestHash()),
-	)
+	restHash := e.RestHash()
+	data := make([]byte, 0, 2*32)
+	data = hashing.AppendUint256(data, e.TotalGasConsumed)
+	data = append(data, restHash[:]...)
+	return hashing.SoliditySHA3(data)
 }
 
 type InboxState struct {
@@ -298,6 +298,25 @@ type SimulatedEthClient struct {
 	*backends.SimulatedBackend
 }
 
+// AutoMiningEthClient is a SimulatedEthClient which mines a block as soon as
+// each transaction is sent, for tests which wait on receipts and can't call
+// Commit themselves
+type AutoMiningEthClient struct {
+	*SimulatedEthClient
+}
+
+func NewAutoMiningEthClient(backend *backends.SimulatedBackend) *AutoMiningEthClient {
+	return &AutoMiningEthClient{SimulatedEthClient: &SimulatedEthClient{SimulatedBackend: backend}}
+}
+
+func (r *AutoMiningEthClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := r.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	r.Commit()
+	return nil
+}
+
 func (r *SimulatedEthClient) BlockInfoByNumber(ctx context.Context, number *big.Int) (*BlockInfo, error) {
 	header, err := r.SimulatedBackend.HeaderByNumber(ctx, number)
 	if err != nil {
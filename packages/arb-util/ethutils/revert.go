@@ -0,0 +1,76 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethutils
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// TransactionTracer is implemented by clients which can recover the return
+// data of an already mined transaction
+type TransactionTracer interface {
+	TraceTransactionOutput(ctx context.Context, txHash common.Hash) ([]byte, error)
+}
+
+type callTraceResult struct {
+	Output hexutil.Bytes `json:"output"`
+	Error  string        `json:"error"`
+}
+
+// TraceTransactionOutput uses debug_traceTransaction to fetch the return data
+// of the top level call of the given transaction. Not every node exposes the
+// debug namespace, so errors here are not counted towards reconnecting.
+func (r *RPCEthClient) TraceTransactionOutput(ctx context.Context, txHash common.Hash) ([]byte, error) {
+	var trace callTraceResult
+	r.RLock()
+	err := r.rpc.CallContext(ctx, &trace, "debug_traceTransaction", txHash, map[string]interface{}{"tracer": "callTracer"})
+	r.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return trace.Output, nil
+}
+
+// RevertReason attempts to decode an ABI encoded revert string from either the
+// return data of a call or the data attached to the error returned by it
+func RevertReason(data []byte, callErr error) (string, bool) {
+	if callErr != nil {
+		dataErr, ok := callErr.(rpc.DataError)
+		if !ok {
+			return "", false
+		}
+		errData, ok := dataErr.ErrorData().(string)
+		if !ok {
+			return "", false
+		}
+		decoded, err := hexutil.Decode(errData)
+		if err != nil {
+			return "", false
+		}
+		data = decoded
+	}
+	reason, err := abi.UnpackRevert(data)
+	if err != nil {
+		return "", false
+	}
+	return reason, true
+}
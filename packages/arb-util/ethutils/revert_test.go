@@ -0,0 +1,71 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ethutils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type testDataError struct {
+	data string
+}
+
+func (e testDataError) Error() string {
+	return "execution reverted"
+}
+
+func (e testDataError) ErrorData() interface{} {
+	return e.data
+}
+
+func packRevert(t *testing.T, reason string) []byte {
+	stringType, err := abi.NewType("string", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed, err := abi.Arguments{{Type: stringType}}.Pack(reason)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append([]byte{0x08, 0xc3, 0x79, 0xa0}, packed...)
+}
+
+func TestRevertReason(t *testing.T) {
+	revertData := packRevert(t, "NOT_STAKED")
+
+	reason, found := RevertReason(revertData, nil)
+	if !found || reason != "NOT_STAKED" {
+		t.Errorf("expected NOT_STAKED from return data, got %v %v", reason, found)
+	}
+
+	reason, found = RevertReason(nil, testDataError{data: hexutil.Encode(revertData)})
+	if !found || reason != "NOT_STAKED" {
+		t.Errorf("expected NOT_STAKED from error data, got %v %v", reason, found)
+	}
+
+	if _, found := RevertReason(nil, errors.New("connection refused")); found {
+		t.Error("should not find reason in plain error")
+	}
+
+	if _, found := RevertReason([]byte{1, 2, 3}, nil); found {
+		t.Error("should not find reason in malformed data")
+	}
+}
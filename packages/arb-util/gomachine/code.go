@@ -0,0 +1,209 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gomachine
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// codeSegment is a sequence of operations stored the way the AVM stores
+// them: the error operation is at pc 0, and executing an operation moves to
+// the pc below it, so each codepoint's next hash is the hash of the one
+// before it.
+type codeSegment struct {
+	id     uint64
+	ops    []value.Operation
+	hashes []common.Hash
+}
+
+func newCodeSegment(id uint64) *codeSegment {
+	seg := &codeSegment{id: id}
+	// The error codepoint can't fail to hash since it has no immediate
+	_ = seg.addOperation(value.BasicOperation{Op: opError})
+	return seg
+}
+
+func (s *codeSegment) addOperation(op value.Operation) error {
+	var prevHash common.Hash
+	if len(s.hashes) > 0 {
+		prevHash = s.hashes[len(s.hashes)-1]
+	}
+	h, err := value.CodePointValue{Op: op, NextHash: prevHash}.Hash()
+	if err != nil {
+		return err
+	}
+	s.ops = append(s.ops, op)
+	s.hashes = append(s.hashes, h)
+	return nil
+}
+
+func (s *codeSegment) stub(pc uint64) value.CodePointStub {
+	return value.NewCodePointStub(s.id, pc, s.hashes[pc])
+}
+
+func (s *codeSegment) lastStub() value.CodePointStub {
+	return s.stub(uint64(len(s.ops) - 1))
+}
+
+// view returns a segment sharing s's operations whose capacity is capped at
+// their current length. Adding an operation to either one then never writes
+// to memory the other one reads, since the view's first addition reallocates.
+func (s *codeSegment) view() *codeSegment {
+	return &codeSegment{
+		id:     s.id,
+		ops:    s.ops[:len(s.ops):len(s.ops)],
+		hashes: s.hashes[:len(s.hashes):len(s.hashes)],
+	}
+}
+
+// subset copies the segment up to and including pc into a new segment
+func (s *codeSegment) subset(id uint64, pc uint64) *codeSegment {
+	return &codeSegment{
+		id:     id,
+		ops:    append([]value.Operation(nil), s.ops[:pc+1]...),
+		hashes: append([]common.Hash(nil), s.hashes[:pc+1]...),
+	}
+}
+
+var errCodePointHash = newCodeSegment(0).hashes[0]
+
+type jsonOperation struct {
+	Opcode    json.RawMessage `json:"opcode"`
+	Immediate json.RawMessage `json:"immediate"`
+}
+
+type jsonExecutable struct {
+	Code      []jsonOperation `json:"code"`
+	StaticVal json.RawMessage `json:"static_val"`
+}
+
+// loadExecutable parses an executable in the format the compiler outputs
+// into code segment 0 and the static value
+func loadExecutable(data []byte) (*codeSegment, value.Value, error) {
+	var exe jsonExecutable
+	if err := json.Unmarshal(data, &exe); err != nil {
+		return nil, nil, errors.Wrap(err, "error parsing executable")
+	}
+	opCount := uint64(len(exe.Code))
+	seg := newCodeSegment(0)
+	for i := len(exe.Code) - 1; i >= 0; i-- {
+		op, err := parseOperation(exe.Code[i], opCount, seg)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error parsing operation %v", i)
+		}
+		if err := seg.addOperation(op); err != nil {
+			return nil, nil, err
+		}
+	}
+	staticVal, err := parseExecutableValue(exe.StaticVal, opCount, seg)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error parsing static value")
+	}
+	return seg, staticVal, nil
+}
+
+func parseOperation(op jsonOperation, opCount uint64, seg *codeSegment) (value.Operation, error) {
+	var opcode struct {
+		AVMOpcode *uint8
+	}
+	var rawOpcode uint8
+	if err := json.Unmarshal(op.Opcode, &rawOpcode); err != nil {
+		if err := json.Unmarshal(op.Opcode, &opcode); err != nil || opcode.AVMOpcode == nil {
+			return nil, errors.Errorf("invalid opcode %s", op.Opcode)
+		}
+		rawOpcode = *opcode.AVMOpcode
+	}
+	if len(op.Immediate) == 0 || bytes.Equal(op.Immediate, []byte("null")) {
+		return value.BasicOperation{Op: value.Opcode(rawOpcode)}, nil
+	}
+	imm, err := parseExecutableValue(op.Immediate, opCount, seg)
+	if err != nil {
+		return nil, err
+	}
+	return value.ImmediateOperation{Op: value.Opcode(rawOpcode), Val: imm}, nil
+}
+
+type jsonExecutableValue struct {
+	Int       *string
+	Tuple     []json.RawMessage
+	CodePoint *struct {
+		Internal json.Number
+	}
+	Buffer *string
+}
+
+func parseExecutableValue(data json.RawMessage, opCount uint64, seg *codeSegment) (value.Value, error) {
+	var val jsonExecutableValue
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&val); err != nil {
+		return nil, err
+	}
+	switch {
+	case val.Int != nil:
+		i, ok := new(big.Int).SetString(*val.Int, 16)
+		if !ok || i.Sign() < 0 || i.BitLen() > 256 {
+			return nil, errors.Errorf("invalid int %v", *val.Int)
+		}
+		return value.NewIntValue(i), nil
+	case val.Tuple != nil:
+		if len(val.Tuple) > value.MaxTupleSize {
+			return nil, errors.New("tuple must contain array of size less than 9")
+		}
+		contents := make([]value.Value, 0, len(val.Tuple))
+		for _, item := range val.Tuple {
+			v, err := parseExecutableValue(item, opCount, seg)
+			if err != nil {
+				return nil, err
+			}
+			contents = append(contents, v)
+		}
+		return value.NewTupleFromOwnedSlice(contents)
+	case val.CodePoint != nil:
+		offset, err := strconv.ParseUint(val.CodePoint.Internal.String(), 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid codepoint offset")
+		}
+		// The compiler marks the error codepoint with the max offset
+		pc := uint64(0)
+		if offset != math.MaxUint64 {
+			pc = opCount - offset
+		}
+		if pc >= uint64(len(seg.ops)) {
+			return nil, errors.Errorf("codepoint offset %v refers to code which hasn't been loaded", offset)
+		}
+		return seg.stub(pc), nil
+	case val.Buffer != nil:
+		data, err := hex.DecodeString(*val.Buffer)
+		if err != nil {
+			return nil, errors.Wrap(err, "buffer must be hex")
+		}
+		return value.NewBuffer(trimBuffer(data)), nil
+	default:
+		return nil, errors.New("invalid value type")
+	}
+}
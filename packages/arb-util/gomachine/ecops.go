@@ -0,0 +1,112 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gomachine
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/bn256"
+)
+
+// The curve operations follow EIP-196 and EIP-197, as the C++ engine does,
+// so a point at infinity is (0, 0) and coordinates must be below the field
+// modulus. G2 points are given with the real part of each coordinate first,
+// while go-ethereum expects the imaginary part first.
+
+func ecRecover(r, s, recovery, message *big.Int) *big.Int {
+	if recovery.Cmp(big.NewInt(1)) > 0 {
+		return new(big.Int)
+	}
+	sig := append(math.PaddedBigBytes(r, 32), math.PaddedBigBytes(s, 32)...)
+	sig = append(sig, byte(recovery.Uint64()))
+	pubKey, err := crypto.Ecrecover(math.PaddedBigBytes(message, 32), sig)
+	if err != nil {
+		return new(big.Int)
+	}
+	return new(big.Int).SetBytes(crypto.Keccak256(pubKey[1:])[12:])
+}
+
+func unmarshalG1(x, y *big.Int) (*bn256.G1, bool) {
+	data := append(math.PaddedBigBytes(x, 32), math.PaddedBigBytes(y, 32)...)
+	p := new(bn256.G1)
+	if _, err := p.Unmarshal(data); err != nil {
+		return nil, false
+	}
+	return p, true
+}
+
+func unmarshalG2(x0, x1, y0, y1 *big.Int) (*bn256.G2, bool) {
+	var data []byte
+	for _, coord := range []*big.Int{x1, x0, y1, y0} {
+		data = append(data, math.PaddedBigBytes(coord, 32)...)
+	}
+	p := new(bn256.G2)
+	if _, err := p.Unmarshal(data); err != nil {
+		return nil, false
+	}
+	return p, true
+}
+
+func marshalG1(p *bn256.G1) (*big.Int, *big.Int) {
+	data := p.Marshal()
+	return new(big.Int).SetBytes(data[:32]), new(big.Int).SetBytes(data[32:])
+}
+
+func ecAdd(ax, ay, bx, by *big.Int) (*big.Int, *big.Int, bool) {
+	a, ok := unmarshalG1(ax, ay)
+	if !ok {
+		return nil, nil, false
+	}
+	b, ok := unmarshalG1(bx, by)
+	if !ok {
+		return nil, nil, false
+	}
+	x, y := marshalG1(new(bn256.G1).Add(a, b))
+	return x, y, true
+}
+
+func ecMul(x, y, factor *big.Int) (*big.Int, *big.Int, bool) {
+	p, ok := unmarshalG1(x, y)
+	if !ok {
+		return nil, nil, false
+	}
+	rx, ry := marshalG1(new(bn256.G1).ScalarMult(p, factor))
+	return rx, ry, true
+}
+
+// ecPairingPoint holds a G1 point's coordinates followed by a G2 point's
+type ecPairingPoint [6]*big.Int
+
+func ecPairing(points []ecPairingPoint) (bool, bool) {
+	g1s := make([]*bn256.G1, 0, len(points))
+	g2s := make([]*bn256.G2, 0, len(points))
+	for _, point := range points {
+		g1, ok := unmarshalG1(point[0], point[1])
+		if !ok {
+			return false, false
+		}
+		g2, ok := unmarshalG2(point[2], point[3], point[4], point[5])
+		if !ok {
+			return false, false
+		}
+		g1s = append(g1s, g1)
+		g2s = append(g2s, g2)
+	}
+	return bn256.PairingCheck(g1s, g2s), true
+}
@@ -0,0 +1,604 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package gomachine is an AVM interpreter written in Go. It's a reference
+// implementation which lets a node run without arb-avm-cpp, and which can be
+// compared against it, rather than a replacement for it: it's much slower,
+// and it can't produce proofs.
+package gomachine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// contextCheckInterval is how many steps are executed between checks for
+// whether an assertion's context has been cancelled
+const contextCheckInterval = 10000
+
+var maxGasRemaining = new(big.Int).Set(tt256m1)
+
+// execution holds the state of the assertion being executed
+type execution struct {
+	inbox            []inbox.InboxMessage
+	messagesConsumed uint64
+	sideloads        []inbox.InboxMessage
+	stopOnSideload   bool
+	stopOnBreakpoint bool
+	firstInstruction bool
+
+	sends       [][]byte
+	logs        []value.Value
+	debugPrints []value.Value
+
+	profile    *machine.Profile
+	stepTracer machine.StepTracer
+}
+
+type Machine struct {
+	// segments is indexed by segment id. Clones get views of them, so that
+	// adding operations in one machine is never seen by another.
+	segments []*codeSegment
+
+	pc           machine.CodePointRef
+	errPC        value.CodePointStub
+	register     value.Value
+	static       value.Value
+	stack        datastack
+	auxstack     datastack
+	gasRemaining *big.Int
+	status       machine.Status
+
+	exec *execution
+
+	debugMutex sync.Mutex
+	profiling  bool
+	profile    *machine.Profile
+	stepTracer machine.StepTracer
+}
+
+func New(codeFile string) (*Machine, error) {
+	data, err := ioutil.ReadFile(codeFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating machine from file %s", codeFile)
+	}
+	m, err := NewFromExecutable(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating machine from file %s", codeFile)
+	}
+	return m, nil
+}
+
+// NewFromExecutable creates a machine from an executable in the JSON format
+// the compiler outputs
+func NewFromExecutable(data []byte) (*Machine, error) {
+	seg, static, err := loadExecutable(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Machine{
+		segments:     []*codeSegment{seg},
+		pc:           machine.CodePointRef{Segment: 0, PC: uint64(len(seg.ops) - 1)},
+		errPC:        seg.stub(0),
+		register:     value.NewEmptyTuple(),
+		static:       static,
+		gasRemaining: new(big.Int).Set(maxGasRemaining),
+		status:       machine.Extensive,
+	}, nil
+}
+
+func (m *Machine) String() string {
+	return fmt.Sprintf(
+		"Machine(status: %v, pc: %v, stack size: %v, aux stack size: %v)",
+		m.status,
+		m.pc,
+		m.stack.size(),
+		m.auxstack.size(),
+	)
+}
+
+func (m *Machine) Hash() common.Hash {
+	switch m.status {
+	case machine.Halt:
+		return common.Hash{}
+	case machine.ErrorStop:
+		var h common.Hash
+		h[31] = 1
+		return h
+	}
+	stackHash, err := m.stack.hash()
+	if err != nil {
+		// This should never occur
+		panic(fmt.Sprintf("machine hash failed: %v", err))
+	}
+	auxStackHash, err := m.auxstack.hash()
+	if err != nil {
+		panic(fmt.Sprintf("machine hash failed: %v", err))
+	}
+	registerHash, err := value.HashValue(m.register)
+	if err != nil {
+		panic(fmt.Sprintf("machine hash failed: %v", err))
+	}
+	staticHash, err := value.HashValue(m.static)
+	if err != nil {
+		panic(fmt.Sprintf("machine hash failed: %v", err))
+	}
+	return hashing.SoliditySHA3(
+		hashing.Bytes32(m.CodePointHash()),
+		hashing.Bytes32(stackHash),
+		hashing.Bytes32(auxStackHash),
+		hashing.Bytes32(registerHash),
+		hashing.Bytes32(staticHash),
+		hashing.Uint256(m.gasRemaining),
+		hashing.Bytes32(m.errPC.Hash()),
+	)
+}
+
+func (m *Machine) CodePointHash() common.Hash {
+	return m.segments[m.pc.Segment].hashes[m.pc.PC]
+}
+
+// Clone only reads m, so several clones of it may be made concurrently
+func (m *Machine) Clone() machine.Machine {
+	segments := make([]*codeSegment, 0, len(m.segments))
+	for _, seg := range m.segments {
+		segments = append(segments, seg.view())
+	}
+	return &Machine{
+		segments:     segments,
+		pc:           m.pc,
+		errPC:        m.errPC,
+		register:     m.register,
+		static:       m.static,
+		stack:        m.stack.clone(),
+		auxstack:     m.auxstack.clone(),
+		gasRemaining: new(big.Int).Set(m.gasRemaining),
+		status:       m.status,
+	}
+}
+
+func (m *Machine) CurrentStatus() machine.Status {
+	return m.status
+}
+
+func (m *Machine) IsBlocked(newMessages bool) machine.BlockReason {
+	switch m.status {
+	case machine.ErrorStop:
+		return machine.ErrorBlocked{}
+	case machine.Halt:
+		return machine.HaltBlocked{}
+	}
+	switch m.currentOperation().GetOp() {
+	case opBreakpoint:
+		return machine.BreakpointBlocked{}
+	case opInbox:
+		if !newMessages {
+			return machine.InboxBlocked{}
+		}
+	}
+	return nil
+}
+
+func (m *Machine) ExecuteAssertion(
+	ctx context.Context,
+	maxGas uint64,
+	goOverGas bool,
+	messages []inbox.InboxMessage,
+	trace bool,
+) (*protocol.ExecutionAssertion, []value.Value, uint64, error) {
+	return m.ExecuteAssertionAdvanced(
+		ctx,
+		maxGas,
+		goOverGas,
+		messages,
+		nil,
+		false,
+		false,
+		trace,
+	)
+}
+
+func (m *Machine) ExecuteAssertionAdvanced(
+	ctx context.Context,
+	maxGas uint64,
+	goOverGas bool,
+	messages []inbox.InboxMessage,
+	sideloads []inbox.InboxMessage,
+	stopOnSideload bool,
+	stopOnBreakpoint bool,
+	trace bool,
+) (*protocol.ExecutionAssertion, []value.Value, uint64, error) {
+	// Results are added to the profile which was current when execution
+	// started, even if profiling is restarted in the meantime
+	m.debugMutex.Lock()
+	var profile *machine.Profile
+	if m.profiling {
+		profile = m.profile
+	}
+	stepTracer := m.stepTracer
+	m.debugMutex.Unlock()
+
+	m.exec = &execution{
+		inbox:            messages,
+		sideloads:        append([]inbox.InboxMessage(nil), sideloads...),
+		stopOnSideload:   stopOnSideload,
+		stopOnBreakpoint: stopOnBreakpoint,
+		firstInstruction: true,
+		stepTracer:       stepTracer,
+	}
+	defer func() {
+		m.exec = nil
+	}()
+	if profile != nil {
+		m.exec.profile = machine.NewProfile()
+	}
+
+	var gasUsed uint64
+	var steps uint64
+	for {
+		if maxGas != 0 {
+			if goOverGas {
+				if gasUsed >= maxGas {
+					break
+				}
+			} else if m.nextGasCost()+gasUsed > maxGas {
+				break
+			}
+		}
+		if steps%contextCheckInterval == 0 {
+			select {
+			case <-ctx.Done():
+				m.status = machine.ErrorStop
+				return nil, nil, 0, ctx.Err()
+			default:
+			}
+		}
+		gas, blocked, err := m.runOne()
+		if err != nil {
+			m.status = machine.ErrorStop
+			return nil, nil, 0, err
+		}
+		gasUsed += gas
+		if blocked != notBlocked {
+			break
+		}
+		steps++
+	}
+
+	if profile != nil {
+		m.debugMutex.Lock()
+		profile.Merge(m.exec.profile)
+		m.debugMutex.Unlock()
+	}
+
+	assertion := &protocol.ExecutionAssertion{
+		NumGas:                gasUsed,
+		InboxMessagesConsumed: m.exec.messagesConsumed,
+		Sends:                 m.exec.sends,
+		Logs:                  m.exec.logs,
+	}
+	var debugPrints []value.Value
+	if trace {
+		debugPrints = m.exec.debugPrints
+	}
+	return assertion, debugPrints, steps, nil
+}
+
+// nextGasCost is the gas the next instruction will use if it doesn't fail
+func (m *Machine) nextGasCost() uint64 {
+	op := m.currentOperation()
+	info, ok := opInfos[op.GetOp()]
+	if !ok {
+		return errorGasCost
+	}
+	gas := info.gas
+	if op.GetOp() == opEcPairing {
+		gas += ecPairingGasCost(&m.stack)
+	}
+	return gas
+}
+
+// runOne executes a single instruction, returning the gas it used and why
+// the machine is blocked if it couldn't execute
+func (m *Machine) runOne() (uint64, blockReason, error) {
+	switch m.status {
+	case machine.ErrorStop:
+		return 0, errorBlocked, nil
+	case machine.Halt:
+		return 0, haltBlocked, nil
+	}
+
+	startPC := m.pc
+	op := m.currentOperation()
+	opcode := op.GetOp()
+	if immOp, ok := op.(value.ImmediateOperation); ok {
+		m.stack.push(immOp.Val)
+	}
+
+	var step machine.ExecutionStep
+	traceSteps := m.exec.stepTracer != nil
+	if traceSteps {
+		step = machine.ExecutionStep{CodePointRef: startPC, Opcode: opcode}
+		if m.stack.size() > 0 {
+			top := m.stack.peek(0)
+			if i, ok := top.(value.IntValue); ok {
+				step.StackTop = new(big.Int).Set(i.BigInt())
+			} else {
+				h, err := value.HashValue(top)
+				if err != nil {
+					return 0, notBlocked, err
+				}
+				step.StackTopHash = &h
+			}
+		}
+	}
+
+	startStackSize := m.stack.size()
+	startAuxStackSize := m.auxstack.size()
+	info, valid := opInfos[opcode]
+
+	var gas uint64
+	var blocked blockReason
+	if m.stack.size() < info.stackArgs || m.auxstack.size() < info.auxStackArgs {
+		m.status = machine.ErrorStop
+		gas = m.chargeGas(errorGasCost)
+	} else {
+		cost := errorGasCost
+		if valid {
+			cost = info.gas
+			if opcode == opEcPairing {
+				cost += ecPairingGasCost(&m.stack)
+			}
+		}
+		gas = m.chargeGas(cost)
+		if m.status != machine.ErrorStop {
+			if !valid || operations[opcode] == nil {
+				m.status = machine.ErrorStop
+			} else {
+				var err error
+				blocked, err = operations[opcode](m)
+				if err == errMachine {
+					m.status = machine.ErrorStop
+				} else if err != nil {
+					return 0, notBlocked, err
+				}
+				if blocked != notBlocked {
+					m.gasRemaining.Add(m.gasRemaining, new(big.Int).SetUint64(gas))
+					if _, ok := op.(value.ImmediateOperation); ok {
+						m.stack.pop()
+					}
+					return 0, blocked, nil
+				}
+			}
+		}
+	}
+
+	if m.exec.profile != nil {
+		m.exec.profile.Opcodes[opcode] = addStats(m.exec.profile.Opcodes[opcode], gas)
+		m.exec.profile.CodePoints[startPC] = addStats(m.exec.profile.CodePoints[startPC], gas)
+	}
+	if traceSteps {
+		step.Gas = gas
+		m.exec.stepTracer.TraceStep(step)
+	}
+
+	if m.status == machine.ErrorStop {
+		// Clean up the instruction's arguments before moving to the error
+		// handler
+		for startStackSize-m.stack.size() < info.stackArgs && m.stack.size() > 0 {
+			m.stack.pop()
+		}
+		for startAuxStackSize-m.auxstack.size() < info.auxStackArgs && m.auxstack.size() > 0 {
+			m.auxstack.pop()
+		}
+		if m.errPC.Hash() != errCodePointHash {
+			m.pc = machine.CodePointRef{Segment: m.errPC.Segment, PC: m.errPC.PC}
+			m.status = machine.Extensive
+		}
+	}
+	m.exec.firstInstruction = false
+	return gas, notBlocked, nil
+}
+
+// chargeGas deducts cost from the machine's remaining gas and returns how
+// much gas was used. Running out of gas puts the machine in the error state,
+// using the gas cost of an error, and resets the remaining gas.
+func (m *Machine) chargeGas(cost uint64) uint64 {
+	costInt := new(big.Int).SetUint64(cost)
+	if m.gasRemaining.Cmp(costInt) < 0 {
+		m.gasRemaining.Set(maxGasRemaining)
+		if m.status != machine.ErrorStop {
+			m.status = machine.ErrorStop
+			return cost + errorGasCost
+		}
+		return cost
+	}
+	m.gasRemaining.Sub(m.gasRemaining, costInt)
+	return cost
+}
+
+func addStats(stats machine.ExecutionStats, gas uint64) machine.ExecutionStats {
+	stats.Count++
+	stats.Gas += gas
+	return stats
+}
+
+func (m *Machine) currentOperation() value.Operation {
+	return m.segments[m.pc.Segment].ops[m.pc.PC]
+}
+
+func (m *Machine) currentStub() value.CodePointStub {
+	return m.segments[m.pc.Segment].stub(m.pc.PC)
+}
+
+// next moves to the following instruction, which is the one before the
+// current one in its segment
+func (m *Machine) next() {
+	m.pc.PC--
+}
+
+func (m *Machine) jumpTo(target value.CodePointStub) error {
+	if target.Segment >= uint64(len(m.segments)) {
+		return errors.Errorf("jump to unknown segment %v", target.Segment)
+	}
+	seg := m.segments[target.Segment]
+	if target.PC >= uint64(len(seg.ops)) || seg.hashes[target.PC] != target.Hash() {
+		return errors.Errorf("jump to unknown codepoint %v in segment %v", target.PC, target.Segment)
+	}
+	m.pc = machine.CodePointRef{Segment: target.Segment, PC: target.PC}
+	return nil
+}
+
+func (m *Machine) newSegment() *codeSegment {
+	seg := newCodeSegment(uint64(len(m.segments)))
+	m.segments = append(m.segments, seg)
+	return seg
+}
+
+// addOperation adds op after target, returning the stub of the codepoint it
+// creates. The segment is extended if target is its last codepoint, and
+// otherwise copied into a new one.
+func (m *Machine) addOperation(target value.CodePointStub, op value.Operation) (value.CodePointStub, error) {
+	if target.Segment >= uint64(len(m.segments)) {
+		return value.CodePointStub{}, errors.Errorf("codepoint in unknown segment %v", target.Segment)
+	}
+	seg := m.segments[target.Segment]
+	if target.PC >= uint64(len(seg.ops)) {
+		return value.CodePointStub{}, errors.Errorf("unknown codepoint %v in segment %v", target.PC, target.Segment)
+	}
+	if target.PC != uint64(len(seg.ops)-1) {
+		seg = seg.subset(uint64(len(m.segments)), target.PC)
+		m.segments = append(m.segments, seg)
+	}
+	if err := seg.addOperation(op); err != nil {
+		return value.CodePointStub{}, err
+	}
+	return seg.lastStub(), nil
+}
+
+func (m *Machine) SetProfiling(enabled bool) {
+	m.debugMutex.Lock()
+	defer m.debugMutex.Unlock()
+	if enabled && !m.profiling {
+		m.profile = machine.NewProfile()
+	}
+	m.profiling = enabled
+}
+
+func (m *Machine) SetStepTracer(tracer machine.StepTracer) {
+	m.debugMutex.Lock()
+	defer m.debugMutex.Unlock()
+	m.stepTracer = tracer
+}
+
+func (m *Machine) Profile() *machine.Profile {
+	m.debugMutex.Lock()
+	defer m.debugMutex.Unlock()
+	if m.profile == nil {
+		return nil
+	}
+	profile := machine.NewProfile()
+	profile.Merge(m.profile)
+	return profile
+}
+
+func (m *Machine) MarshalForProof() ([]byte, []byte, error) {
+	return nil, nil, errors.New("the Go machine doesn't support proofs")
+}
+
+// MarshalState serializes the machine's state the way the C++ engine does,
+// so that the states of the two can be compared
+func (m *Machine) MarshalState() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(m.CodePointHash().Bytes())
+	for _, stack := range []*datastack{&m.stack, &m.auxstack} {
+		preImage, err := stack.hashPreImage()
+		if err != nil {
+			return nil, err
+		}
+		if err := preImage.Marshal(&buf); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.marshalStateValue(&buf, m.register); err != nil {
+		return nil, err
+	}
+	if err := m.marshalStateValue(&buf, m.static); err != nil {
+		return nil, err
+	}
+	buf.Write(hashing.Uint256(m.gasRemaining))
+	buf.Write(m.errPC.Hash().Bytes())
+	return buf.Bytes(), nil
+}
+
+// marshalStateValue writes the top level of val, with tuples and buffers
+// given by their hashes
+func (m *Machine) marshalStateValue(buf *bytes.Buffer, val value.Value) error {
+	switch val := val.(type) {
+	case value.IntValue:
+		buf.WriteByte(value.TypeCodeInt)
+		buf.Write(hashing.Uint256(val.BigInt()))
+	case *value.TupleValue:
+		preImage, err := val.HashPreImage()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(value.TypeCodeHashPreImage)
+		return preImage.Marshal(buf)
+	case value.HashPreImage:
+		buf.WriteByte(value.TypeCodeHashPreImage)
+		return val.Marshal(buf)
+	case *value.Buffer:
+		buf.WriteByte(value.TypeCodeBuffer)
+		buf.Write(val.Hash().Bytes())
+	case value.CodePointStub:
+		if val.Segment >= uint64(len(m.segments)) || val.PC >= uint64(len(m.segments[val.Segment].ops)) {
+			return errors.Errorf("unknown codepoint %v in segment %v", val.PC, val.Segment)
+		}
+		seg := m.segments[val.Segment]
+		var nextHash common.Hash
+		if val.PC > 0 {
+			nextHash = seg.hashes[val.PC-1]
+		}
+		buf.WriteByte(value.TypeCodeCodePoint)
+		op := seg.ops[val.PC]
+		if imm, ok := op.(value.ImmediateOperation); ok {
+			buf.Write([]byte{1, byte(imm.Op)})
+			if err := m.marshalStateValue(buf, imm.Val); err != nil {
+				return err
+			}
+		} else {
+			buf.Write([]byte{0, byte(op.GetOp())})
+		}
+		buf.Write(nextHash.Bytes())
+	default:
+		return errors.Errorf("can't serialize value of type %v", val.TypeCode())
+	}
+	return nil
+}
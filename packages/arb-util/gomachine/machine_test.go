@@ -0,0 +1,301 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gomachine
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+func executeToEnd(t *testing.T, executable string) (*Machine, []value.Value) {
+	t.Helper()
+	m, err := NewFromExecutable([]byte(executable))
+	test.FailIfError(t, err)
+	assertion, _, _, err := m.ExecuteAssertion(context.Background(), 0, false, nil, false)
+	test.FailIfError(t, err)
+	return m, assertion.Logs
+}
+
+func checkIntLogs(t *testing.T, logs []value.Value, expected ...int64) {
+	t.Helper()
+	if len(logs) != len(expected) {
+		t.Fatalf("expected %v logs but got %v", len(expected), len(logs))
+	}
+	for i, log := range logs {
+		val, ok := log.(value.IntValue)
+		if !ok || val.BigInt().Cmp(big.NewInt(expected[i])) != 0 {
+			t.Errorf("expected log %v to be %v but got %v", i, expected[i], log)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	m, logs := executeToEnd(t, `{
+		"code": [
+			{"opcode": 59, "immediate": {"Int": "4"}},
+			{"opcode": 1, "immediate": {"Int": "3"}},
+			{"opcode": 97},
+			{"opcode": 59, "immediate": {"Int": "2"}},
+			{"opcode": 3, "immediate": {"Int": "5"}},
+			{"opcode": 97},
+			{"opcode": 116}
+		],
+		"static_val": {"Tuple": []}
+	}`)
+	if m.CurrentStatus() != machine.Halt {
+		t.Fatal("machine should have halted")
+	}
+	checkIntLogs(t, logs, 7, 3)
+}
+
+func TestErrorHandler(t *testing.T) {
+	m, logs := executeToEnd(t, `{
+		"code": [
+			{"opcode": 61, "immediate": {"CodePoint": {"Internal": 4}}},
+			{"opcode": 59, "immediate": {"Int": "0"}},
+			{"opcode": 4, "immediate": {"Int": "1"}},
+			{"opcode": 116},
+			{"opcode": 97, "immediate": {"Int": "2a"}},
+			{"opcode": 116}
+		],
+		"static_val": {"Tuple": []}
+	}`)
+	if m.CurrentStatus() != machine.Halt {
+		t.Fatal("machine should have halted")
+	}
+	checkIntLogs(t, logs, 42)
+	if m.stack.size() != 0 {
+		t.Error("division arguments should have been removed from the stack")
+	}
+}
+
+func TestInboxBlocked(t *testing.T) {
+	m, err := NewFromExecutable([]byte(`{
+		"code": [{"opcode": 114}, {"opcode": 116}],
+		"static_val": {"Tuple": []}
+	}`))
+	test.FailIfError(t, err)
+	startHash := m.Hash()
+	if _, ok := m.IsBlocked(false).(machine.InboxBlocked); !ok {
+		t.Fatal("machine should be blocked on the inbox")
+	}
+	assertion, _, steps, err := m.ExecuteAssertion(context.Background(), 0, false, nil, false)
+	test.FailIfError(t, err)
+	if steps != 0 || assertion.NumGas != 0 {
+		t.Error("blocked machine shouldn't have executed")
+	}
+	if m.Hash() != startHash {
+		t.Error("blocked machine shouldn't have changed")
+	}
+}
+
+func TestKeccakF(t *testing.T) {
+	// Run the permutation on the padded empty message, so the first lanes
+	// hold keccak256("")
+	m, logs := executeToEnd(t, `{
+		"code": [
+			{"opcode": 35, "immediate": {"Tuple": [
+				{"Int": "1"}, {"Int": "0"}, {"Int": "0"}, {"Int": "0"},
+				{"Int": "8000000000000000"}, {"Int": "0"}, {"Int": "0"}
+			]}},
+			{"opcode": 97},
+			{"opcode": 116}
+		],
+		"static_val": {"Tuple": []}
+	}`)
+	if m.CurrentStatus() != machine.Halt || len(logs) != 1 {
+		t.Fatal("machine should have logged the state and halted")
+	}
+	state, ok := logs[0].(*value.TupleValue)
+	if !ok || state.Len() != 7 {
+		t.Fatal("keccakf should return a 7-tuple")
+	}
+	lanes := hashing.Uint256(state.Contents()[0].(value.IntValue).BigInt())
+	hash := make([]byte, 32)
+	for i := range lanes {
+		hash[i] = lanes[len(lanes)-1-i]
+	}
+	expected := "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"
+	if hex.EncodeToString(hash) != expected {
+		t.Errorf("expected hash %v but got %x", expected, hash)
+	}
+}
+
+func TestSha256Block(t *testing.T) {
+	var digest [32]byte
+	initial, _ := hex.DecodeString("6a09e667bb67ae853c6ef372a54ff53a510e527f9b05688c1f83d9ab5be0cd19")
+	copy(digest[:], initial)
+	var block [64]byte
+	copy(block[:], "abc")
+	block[3] = 0x80
+	block[63] = 24
+	res := sha256Block(digest, block)
+	expected := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if hex.EncodeToString(res[:]) != expected {
+		t.Errorf("expected digest %v but got %x", expected, res)
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	m, err := NewFromExecutable([]byte(`{
+		"code": [
+			{"opcode": 59, "immediate": {"Int": "1"}},
+			{"opcode": 97},
+			{"opcode": 116}
+		],
+		"static_val": {"Tuple": []}
+	}`))
+	test.FailIfError(t, err)
+	startHash := m.Hash()
+	clone := m.Clone()
+	_, _, _, err = clone.ExecuteAssertion(context.Background(), 0, false, nil, false)
+	test.FailIfError(t, err)
+	if clone.CurrentStatus() != machine.Halt {
+		t.Error("clone should have halted")
+	}
+	if m.Hash() != startHash || m.CurrentStatus() != machine.Extensive {
+		t.Error("executing the clone changed the original machine")
+	}
+}
+
+func TestCloneLeavesSourceUntouched(t *testing.T) {
+	m, err := NewFromExecutable([]byte(`{
+		"code": [{"opcode": 116}],
+		"static_val": {"Tuple": []}
+	}`))
+	test.FailIfError(t, err)
+	last := m.segments[0].lastStub()
+
+	// Clones are made concurrently from snapshots, so this must not race
+	var wg sync.WaitGroup
+	clones := make([]*Machine, 4)
+	for i := range clones {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clones[i] = m.Clone().(*Machine)
+		}(i)
+	}
+	wg.Wait()
+
+	// Operations added to the end of a shared segment by one machine must
+	// not be seen by any other
+	sourceStub, err := m.addOperation(last, value.BasicOperation{Op: opNop})
+	test.FailIfError(t, err)
+	cloneStub, err := clones[0].addOperation(last, value.BasicOperation{Op: opPop})
+	test.FailIfError(t, err)
+	if sourceStub.PC != cloneStub.PC || sourceStub.Hash() == cloneStub.Hash() {
+		t.Fatal("source and clone should have added different operations at the same pc")
+	}
+	if m.segments[0].hashes[sourceStub.PC] != sourceStub.Hash() {
+		t.Error("clone's operation overwrote the source's")
+	}
+	for _, clone := range clones[1:] {
+		if len(clone.segments[0].ops) != int(last.PC)+1 {
+			t.Error("operation added to one machine was seen by another clone")
+		}
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	m, err := NewFromExecutable([]byte(`{
+		"code": [
+			{"opcode": 59, "immediate": {"Int": "1"}},
+			{"opcode": 51},
+			{"opcode": 116}
+		],
+		"static_val": {"Tuple": []}
+	}`))
+	test.FailIfError(t, err)
+	clone := m.Clone()
+	diffs, err := machine.DiffStates(m, clone)
+	test.FailIfError(t, err)
+	if len(diffs) != 0 {
+		t.Fatal("clone shouldn't differ from the original", diffs)
+	}
+
+	// Execute the nop, pushing its immediate
+	_, _, _, err = clone.ExecuteAssertion(context.Background(), opInfos[opNop].gas, false, nil, false)
+	test.FailIfError(t, err)
+	diffs, err = machine.DiffStates(m, clone)
+	test.FailIfError(t, err)
+	expected := []string{"codepoint", "stack", "gas remaining"}
+	if len(diffs) != len(expected) {
+		t.Fatal("unexpected differences", diffs)
+	}
+	for i, diff := range diffs {
+		if !strings.HasPrefix(diff, expected[i]+":") {
+			t.Errorf("expected difference in %v but got %v", expected[i], diff)
+		}
+	}
+
+	// Execute the rset, moving the immediate into the register
+	_, _, _, err = clone.ExecuteAssertion(context.Background(), opInfos[opRset].gas, false, nil, false)
+	test.FailIfError(t, err)
+	state, err := machine.GetState(clone)
+	test.FailIfError(t, err)
+	if state.Register.Int == nil || state.Register.Int.Cmp(big.NewInt(1)) != 0 {
+		t.Error("unexpected register", state.Register)
+	}
+	diffs, err = machine.DiffStates(m, clone)
+	test.FailIfError(t, err)
+	if len(diffs) != 3 || !strings.HasPrefix(diffs[1], "register:") {
+		t.Error("unexpected differences", diffs)
+	}
+}
+
+func TestExecuteSideloaded(t *testing.T) {
+	m, err := NewFromExecutable([]byte(`{
+		"code": [
+			{"opcode": 123, "immediate": {"Int": "0"}},
+			{"opcode": 97},
+			{"opcode": 123, "immediate": {"Int": "0"}},
+			{"opcode": 97},
+			{"opcode": 123, "immediate": {"Int": "0"}},
+			{"opcode": 116}
+		],
+		"static_val": {"Tuple": []}
+	}`))
+	test.FailIfError(t, err)
+	messages := []inbox.InboxMessage{inbox.NewRandomInboxMessage(), inbox.NewRandomInboxMessage()}
+	res, err := machine.ExecuteSideloaded(context.Background(), m, messages, 0, false)
+	test.FailIfError(t, err)
+	if len(res.Logs) != len(messages) {
+		t.Fatalf("expected %v logs but got %v", len(messages), len(res.Logs))
+	}
+	for i, msg := range messages {
+		if !value.Eq(res.Logs[i], msg.AsValue()) {
+			t.Errorf("log %v should be message %v", i, i)
+		}
+	}
+	if m.CurrentStatus() != machine.Extensive {
+		t.Error("machine should have stopped at the last sideload instead of halting")
+	}
+	if res.Steps != 4 {
+		t.Errorf("expected 4 steps but got %v", res.Steps)
+	}
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gomachine
+
+import "github.com/offchainlabs/arbitrum/packages/arb-util/value"
+
+// The opcodes and their costs match avm_values/opcodes.hpp in arb-avm-cpp
+const (
+	opError value.Opcode = 0x00
+
+	opAdd        value.Opcode = 0x01
+	opMul        value.Opcode = 0x02
+	opSub        value.Opcode = 0x03
+	opDiv        value.Opcode = 0x04
+	opSdiv       value.Opcode = 0x05
+	opMod        value.Opcode = 0x06
+	opSmod       value.Opcode = 0x07
+	opAddmod     value.Opcode = 0x08
+	opMulmod     value.Opcode = 0x09
+	opExp        value.Opcode = 0x0a
+	opSignExtend value.Opcode = 0x0b
+
+	opLt         value.Opcode = 0x10
+	opGt         value.Opcode = 0x11
+	opSlt        value.Opcode = 0x12
+	opSgt        value.Opcode = 0x13
+	opEq         value.Opcode = 0x14
+	opIszero     value.Opcode = 0x15
+	opBitwiseAnd value.Opcode = 0x16
+	opBitwiseOr  value.Opcode = 0x17
+	opBitwiseXor value.Opcode = 0x18
+	opBitwiseNot value.Opcode = 0x19
+	opByte       value.Opcode = 0x1a
+	opShl        value.Opcode = 0x1b
+	opShr        value.Opcode = 0x1c
+	opSar        value.Opcode = 0x1d
+
+	opHash     value.Opcode = 0x20
+	opType     value.Opcode = 0x21
+	opEthhash2 value.Opcode = 0x22
+	opKeccakF  value.Opcode = 0x23
+	opSha256F  value.Opcode = 0x24
+
+	opPop           value.Opcode = 0x30
+	opSpush         value.Opcode = 0x31
+	opRpush         value.Opcode = 0x32
+	opRset          value.Opcode = 0x33
+	opJump          value.Opcode = 0x34
+	opCjump         value.Opcode = 0x35
+	opStackEmpty    value.Opcode = 0x36
+	opPCPush        value.Opcode = 0x37
+	opAuxPush       value.Opcode = 0x38
+	opAuxPop        value.Opcode = 0x39
+	opAuxStackEmpty value.Opcode = 0x3a
+	opNop           value.Opcode = 0x3b
+	opErrPush       value.Opcode = 0x3c
+	opErrSet        value.Opcode = 0x3d
+
+	opDup0  value.Opcode = 0x40
+	opDup1  value.Opcode = 0x41
+	opDup2  value.Opcode = 0x42
+	opSwap1 value.Opcode = 0x43
+	opSwap2 value.Opcode = 0x44
+
+	opTget value.Opcode = 0x50
+	opTset value.Opcode = 0x51
+	opTlen value.Opcode = 0x52
+	opXget value.Opcode = 0x53
+	opXset value.Opcode = 0x54
+
+	opBreakpoint value.Opcode = 0x60
+	opLog        value.Opcode = 0x61
+
+	opSend         value.Opcode = 0x70
+	opInbox        value.Opcode = 0x72
+	opErrorOp      value.Opcode = 0x73
+	opHalt         value.Opcode = 0x74
+	opSetGas       value.Opcode = 0x75
+	opPushGas      value.Opcode = 0x76
+	opErrCodePoint value.Opcode = 0x77
+	opPushInsn     value.Opcode = 0x78
+	opPushInsnImm  value.Opcode = 0x79
+	opSideload     value.Opcode = 0x7b
+
+	opEcRecover value.Opcode = 0x80
+	opEcAdd     value.Opcode = 0x81
+	opEcMul     value.Opcode = 0x82
+	opEcPairing value.Opcode = 0x83
+
+	opDebugPrint value.Opcode = 0x90
+
+	opNewBuffer    value.Opcode = 0xa0
+	opGetBuffer8   value.Opcode = 0xa1
+	opGetBuffer64  value.Opcode = 0xa2
+	opGetBuffer256 value.Opcode = 0xa3
+	opSetBuffer8   value.Opcode = 0xa4
+	opSetBuffer64  value.Opcode = 0xa5
+	opSetBuffer256 value.Opcode = 0xa6
+)
+
+const (
+	sendSizeLimit       = 10000
+	maxEcPairingPoints  = 30
+	ecPairingPointCost  = 500_000
+	maxBufferAllocation = 1 << 32
+)
+
+type opInfo struct {
+	gas          uint64
+	stackArgs    int
+	auxStackArgs int
+}
+
+// opInfos holds the opcodes the AVM defines. Any other opcode is invalid
+// and puts the machine into the error state.
+var opInfos = map[value.Opcode]opInfo{
+	opAdd:        {gas: 3, stackArgs: 2},
+	opMul:        {gas: 3, stackArgs: 2},
+	opSub:        {gas: 3, stackArgs: 2},
+	opDiv:        {gas: 4, stackArgs: 2},
+	opSdiv:       {gas: 7, stackArgs: 2},
+	opMod:        {gas: 4, stackArgs: 2},
+	opSmod:       {gas: 7, stackArgs: 2},
+	opAddmod:     {gas: 4, stackArgs: 3},
+	opMulmod:     {gas: 4, stackArgs: 3},
+	opExp:        {gas: 25, stackArgs: 2},
+	opSignExtend: {gas: 7, stackArgs: 2},
+
+	opLt:         {gas: 2, stackArgs: 2},
+	opGt:         {gas: 2, stackArgs: 2},
+	opSlt:        {gas: 2, stackArgs: 2},
+	opSgt:        {gas: 2, stackArgs: 2},
+	opEq:         {gas: 2, stackArgs: 2},
+	opIszero:     {gas: 1, stackArgs: 1},
+	opBitwiseAnd: {gas: 2, stackArgs: 2},
+	opBitwiseOr:  {gas: 2, stackArgs: 2},
+	opBitwiseXor: {gas: 2, stackArgs: 2},
+	opBitwiseNot: {gas: 1, stackArgs: 1},
+	opByte:       {gas: 4, stackArgs: 2},
+	opShl:        {gas: 4, stackArgs: 2},
+	opShr:        {gas: 4, stackArgs: 2},
+	opSar:        {gas: 4, stackArgs: 2},
+
+	opHash:     {gas: 7, stackArgs: 1},
+	opType:     {gas: 3, stackArgs: 1},
+	opEthhash2: {gas: 8, stackArgs: 2},
+	opKeccakF:  {gas: 600, stackArgs: 1},
+	opSha256F:  {gas: 250, stackArgs: 3},
+
+	opPop:           {gas: 1, stackArgs: 1},
+	opSpush:         {gas: 1},
+	opRpush:         {gas: 1},
+	opRset:          {gas: 2, stackArgs: 1},
+	opJump:          {gas: 4, stackArgs: 1},
+	opCjump:         {gas: 4, stackArgs: 2},
+	opStackEmpty:    {gas: 2},
+	opPCPush:        {gas: 1},
+	opAuxPush:       {gas: 1, stackArgs: 1},
+	opAuxPop:        {gas: 1, auxStackArgs: 1},
+	opAuxStackEmpty: {gas: 2},
+	opNop:           {gas: 1},
+	opErrPush:       {gas: 1},
+	opErrSet:        {gas: 1, stackArgs: 1},
+
+	opDup0:  {gas: 1, stackArgs: 1},
+	opDup1:  {gas: 1, stackArgs: 2},
+	opDup2:  {gas: 1, stackArgs: 3},
+	opSwap1: {gas: 1, stackArgs: 2},
+	opSwap2: {gas: 1, stackArgs: 3},
+
+	opTget: {gas: 2, stackArgs: 2},
+	opTset: {gas: 40, stackArgs: 3},
+	opTlen: {gas: 2, stackArgs: 1},
+	opXget: {gas: 3, stackArgs: 1, auxStackArgs: 1},
+	opXset: {gas: 41, stackArgs: 2, auxStackArgs: 1},
+
+	opBreakpoint: {gas: 100},
+	opLog:        {gas: 100, stackArgs: 1},
+
+	opSend:         {gas: 100, stackArgs: 2},
+	opInbox:        {gas: 40},
+	opErrorOp:      {gas: 5},
+	opHalt:         {gas: 10},
+	opSetGas:       {gas: 1, stackArgs: 1},
+	opPushGas:      {gas: 1},
+	opErrCodePoint: {gas: 25},
+	opPushInsn:     {gas: 25, stackArgs: 2},
+	opPushInsnImm:  {gas: 25, stackArgs: 3},
+	opSideload:     {gas: 10, stackArgs: 1},
+
+	opEcRecover: {gas: 20000, stackArgs: 4},
+	opEcAdd:     {gas: 3500, stackArgs: 4},
+	opEcMul:     {gas: 82000, stackArgs: 3},
+	opEcPairing: {gas: 1000, stackArgs: 1},
+
+	opDebugPrint: {gas: 1, stackArgs: 1},
+
+	opNewBuffer:    {gas: 1},
+	opGetBuffer8:   {gas: 10, stackArgs: 2},
+	opGetBuffer64:  {gas: 10, stackArgs: 2},
+	opGetBuffer256: {gas: 10, stackArgs: 2},
+	opSetBuffer8:   {gas: 100, stackArgs: 3},
+	opSetBuffer64:  {gas: 100, stackArgs: 3},
+	opSetBuffer256: {gas: 100, stackArgs: 3},
+}
+
+var errorGasCost = opInfos[opErrorOp].gas
@@ -0,0 +1,921 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gomachine
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// errMachine is returned by an operation to put the machine into the error
+// state. Any other error stops execution, since it means the interpreter
+// can't continue.
+var errMachine = errors.New("machine error")
+
+type blockReason int
+
+const (
+	notBlocked blockReason = iota
+	haltBlocked
+	errorBlocked
+	breakpointBlocked
+	inboxBlocked
+	sideloadBlocked
+)
+
+// An operation runs an opcode after its gas has been charged. It must check
+// its arguments before modifying the machine, so that the arguments are all
+// that's left to clean up if it fails.
+type operation func(m *Machine) (blockReason, error)
+
+var (
+	tt256   = new(big.Int).Lsh(big.NewInt(1), 256)
+	tt256m1 = new(big.Int).Sub(tt256, big.NewInt(1))
+	tt255   = new(big.Int).Lsh(big.NewInt(1), 255)
+)
+
+// newInt wraps x, which is modified, into the range of an AVM int
+func newInt(x *big.Int) value.IntValue {
+	return value.NewIntValue(x.And(x, tt256m1))
+}
+
+func boolInt(b bool) value.IntValue {
+	if b {
+		return value.NewInt64Value(1)
+	}
+	return value.NewInt64Value(0)
+}
+
+func toSigned(x *big.Int) *big.Int {
+	if x.Cmp(tt255) < 0 {
+		return new(big.Int).Set(x)
+	}
+	return new(big.Int).Sub(x, tt256)
+}
+
+func asInt(val value.Value) (*big.Int, error) {
+	i, ok := val.(value.IntValue)
+	if !ok {
+		return nil, errMachine
+	}
+	return i.BigInt(), nil
+}
+
+func asUint64(val value.Value) (uint64, error) {
+	i, err := asInt(val)
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsUint64() {
+		return 0, errMachine
+	}
+	return i.Uint64(), nil
+}
+
+func asTuple(val value.Value) (*value.TupleValue, error) {
+	tup, ok := val.(*value.TupleValue)
+	if !ok {
+		return nil, errMachine
+	}
+	return tup, nil
+}
+
+func asBuffer(val value.Value) (*value.Buffer, error) {
+	buf, ok := val.(*value.Buffer)
+	if !ok {
+		return nil, errMachine
+	}
+	return buf, nil
+}
+
+func asCodePoint(val value.Value) (value.CodePointStub, error) {
+	cp, ok := val.(value.CodePointStub)
+	if !ok {
+		return value.CodePointStub{}, errMachine
+	}
+	return cp, nil
+}
+
+// intArgs returns the top count values of the stack, which must all be ints
+func (m *Machine) intArgs(count int) ([]*big.Int, error) {
+	args := make([]*big.Int, 0, count)
+	for i := 0; i < count; i++ {
+		arg, err := asInt(m.stack.peek(i))
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// replaceArgs pops the top count values of the stack and pushes results,
+// the first of them ending up on top
+func (m *Machine) replaceArgs(count int, results ...value.Value) {
+	for i := 0; i < count; i++ {
+		m.stack.pop()
+	}
+	for i := len(results) - 1; i >= 0; i-- {
+		m.stack.push(results[i])
+	}
+}
+
+// intOp is an operation which takes argCount ints and replaces them with a
+// single int. If f returns nil, like the AVM does for division by zero, the
+// machine moves to the next instruction and into the error state.
+func intOp(argCount int, f func(args []*big.Int) *big.Int) operation {
+	return func(m *Machine) (blockReason, error) {
+		args, err := m.intArgs(argCount)
+		if err != nil {
+			return notBlocked, err
+		}
+		res := f(args)
+		m.next()
+		if res == nil {
+			return notBlocked, errMachine
+		}
+		m.replaceArgs(argCount, newInt(res))
+		return notBlocked, nil
+	}
+}
+
+func simpleOp(f func(m *Machine) error) operation {
+	return func(m *Machine) (blockReason, error) {
+		if err := f(m); err != nil {
+			return notBlocked, err
+		}
+		m.next()
+		return notBlocked, nil
+	}
+}
+
+var operations [256]operation
+
+func init() {
+	operations[opAdd] = intOp(2, func(a []*big.Int) *big.Int { return new(big.Int).Add(a[0], a[1]) })
+	operations[opMul] = intOp(2, func(a []*big.Int) *big.Int { return new(big.Int).Mul(a[0], a[1]) })
+	operations[opSub] = intOp(2, func(a []*big.Int) *big.Int { return new(big.Int).Sub(a[0], a[1]) })
+	operations[opDiv] = intOp(2, func(a []*big.Int) *big.Int {
+		if a[1].Sign() == 0 {
+			return nil
+		}
+		return new(big.Int).Div(a[0], a[1])
+	})
+	operations[opSdiv] = intOp(2, func(a []*big.Int) *big.Int {
+		if a[1].Sign() == 0 {
+			return nil
+		}
+		return new(big.Int).Quo(toSigned(a[0]), toSigned(a[1]))
+	})
+	operations[opMod] = intOp(2, func(a []*big.Int) *big.Int {
+		if a[1].Sign() == 0 {
+			return nil
+		}
+		return new(big.Int).Mod(a[0], a[1])
+	})
+	operations[opSmod] = intOp(2, func(a []*big.Int) *big.Int {
+		if a[1].Sign() == 0 {
+			return nil
+		}
+		return new(big.Int).Rem(toSigned(a[0]), toSigned(a[1]))
+	})
+	operations[opAddmod] = intOp(3, func(a []*big.Int) *big.Int {
+		if a[2].Sign() == 0 {
+			return nil
+		}
+		sum := new(big.Int).Add(a[0], a[1])
+		return sum.Mod(sum, a[2])
+	})
+	operations[opMulmod] = intOp(3, func(a []*big.Int) *big.Int {
+		if a[2].Sign() == 0 {
+			return nil
+		}
+		prod := new(big.Int).Mul(a[0], a[1])
+		return prod.Mod(prod, a[2])
+	})
+	operations[opExp] = intOp(2, func(a []*big.Int) *big.Int { return new(big.Int).Exp(a[0], a[1], tt256) })
+	operations[opSignExtend] = intOp(2, func(a []*big.Int) *big.Int {
+		if a[0].Cmp(big.NewInt(31)) >= 0 {
+			return new(big.Int).Set(a[1])
+		}
+		signBit := uint(a[0].Uint64()*8 + 7)
+		valueMask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), signBit), big.NewInt(1))
+		if a[1].Bit(int(signBit)) == 1 {
+			return new(big.Int).Or(a[1], new(big.Int).Xor(tt256m1, valueMask))
+		}
+		return new(big.Int).And(a[1], valueMask)
+	})
+
+	operations[opLt] = intOp(2, func(a []*big.Int) *big.Int { return boolInt(a[0].Cmp(a[1]) < 0).BigInt() })
+	operations[opGt] = intOp(2, func(a []*big.Int) *big.Int { return boolInt(a[0].Cmp(a[1]) > 0).BigInt() })
+	operations[opSlt] = intOp(2, func(a []*big.Int) *big.Int {
+		return boolInt(toSigned(a[0]).Cmp(toSigned(a[1])) < 0).BigInt()
+	})
+	operations[opSgt] = intOp(2, func(a []*big.Int) *big.Int {
+		return boolInt(toSigned(a[0]).Cmp(toSigned(a[1])) > 0).BigInt()
+	})
+	operations[opEq] = eq
+	operations[opIszero] = intOp(1, func(a []*big.Int) *big.Int { return boolInt(a[0].Sign() == 0).BigInt() })
+	operations[opBitwiseAnd] = intOp(2, func(a []*big.Int) *big.Int { return new(big.Int).And(a[0], a[1]) })
+	operations[opBitwiseOr] = intOp(2, func(a []*big.Int) *big.Int { return new(big.Int).Or(a[0], a[1]) })
+	operations[opBitwiseXor] = intOp(2, func(a []*big.Int) *big.Int { return new(big.Int).Xor(a[0], a[1]) })
+	operations[opBitwiseNot] = intOp(1, func(a []*big.Int) *big.Int { return new(big.Int).Xor(a[0], tt256m1) })
+	operations[opByte] = intOp(2, func(a []*big.Int) *big.Int {
+		if a[0].Cmp(big.NewInt(32)) >= 0 {
+			return new(big.Int)
+		}
+		shifted := new(big.Int).Rsh(a[1], uint(248-8*a[0].Uint64()))
+		return shifted.And(shifted, big.NewInt(0xff))
+	})
+	operations[opShl] = intOp(2, func(a []*big.Int) *big.Int {
+		if a[0].Cmp(big.NewInt(256)) >= 0 {
+			return new(big.Int)
+		}
+		return new(big.Int).Lsh(a[1], uint(a[0].Uint64()))
+	})
+	operations[opShr] = intOp(2, func(a []*big.Int) *big.Int {
+		if a[0].Cmp(big.NewInt(256)) >= 0 {
+			return new(big.Int)
+		}
+		return new(big.Int).Rsh(a[1], uint(a[0].Uint64()))
+	})
+	operations[opSar] = intOp(2, func(a []*big.Int) *big.Int {
+		shift := uint(256)
+		if a[0].IsUint64() && a[0].Uint64() < 256 {
+			shift = uint(a[0].Uint64())
+		}
+		// Shifting a negative big.Int right rounds towards negative infinity,
+		// so it fills with ones like an arithmetic shift
+		return toSigned(a[1]).Rsh(toSigned(a[1]), shift)
+	})
+
+	operations[opHash] = hashOp
+	operations[opType] = typeOp
+	operations[opEthhash2] = intOp(2, func(a []*big.Int) *big.Int {
+		h := hashing.SoliditySHA3(hashing.Uint256(a[0]), hashing.Uint256(a[1]))
+		return new(big.Int).SetBytes(h[:])
+	})
+	operations[opKeccakF] = keccakF
+	operations[opSha256F] = intOp(3, func(a []*big.Int) *big.Int {
+		var digest [32]byte
+		var block [64]byte
+		copy(digest[:], hashing.Uint256(a[0]))
+		copy(block[:32], hashing.Uint256(a[1]))
+		copy(block[32:], hashing.Uint256(a[2]))
+		res := sha256Block(digest, block)
+		return new(big.Int).SetBytes(res[:])
+	})
+
+	operations[opPop] = simpleOp(func(m *Machine) error {
+		m.stack.pop()
+		return nil
+	})
+	operations[opSpush] = simpleOp(func(m *Machine) error {
+		m.stack.push(m.static)
+		return nil
+	})
+	operations[opRpush] = simpleOp(func(m *Machine) error {
+		m.stack.push(m.register)
+		return nil
+	})
+	operations[opRset] = simpleOp(func(m *Machine) error {
+		m.register = m.stack.pop()
+		return nil
+	})
+	operations[opJump] = jump
+	operations[opCjump] = cjump
+	operations[opStackEmpty] = simpleOp(func(m *Machine) error {
+		m.stack.push(boolInt(m.stack.size() == 0))
+		return nil
+	})
+	operations[opPCPush] = simpleOp(func(m *Machine) error {
+		m.stack.push(m.currentStub())
+		return nil
+	})
+	operations[opAuxPush] = simpleOp(func(m *Machine) error {
+		m.auxstack.push(m.stack.pop())
+		return nil
+	})
+	operations[opAuxPop] = simpleOp(func(m *Machine) error {
+		m.stack.push(m.auxstack.pop())
+		return nil
+	})
+	operations[opAuxStackEmpty] = simpleOp(func(m *Machine) error {
+		m.stack.push(boolInt(m.auxstack.size() == 0))
+		return nil
+	})
+	operations[opNop] = simpleOp(func(m *Machine) error { return nil })
+	operations[opErrPush] = simpleOp(func(m *Machine) error {
+		m.stack.push(m.errPC)
+		return nil
+	})
+	operations[opErrSet] = errSet
+
+	operations[opDup0] = dup(0)
+	operations[opDup1] = dup(1)
+	operations[opDup2] = dup(2)
+	operations[opSwap1] = swap(1)
+	operations[opSwap2] = swap(2)
+
+	operations[opTget] = tget
+	operations[opTset] = tset
+	operations[opTlen] = simpleOp(func(m *Machine) error {
+		tup, err := asTuple(m.stack.peek(0))
+		if err != nil {
+			return err
+		}
+		m.stack.set(0, value.NewInt64Value(tup.Len()))
+		return nil
+	})
+	operations[opXget] = xget
+	operations[opXset] = xset
+
+	operations[opBreakpoint] = breakpoint
+	operations[opLog] = simpleOp(func(m *Machine) error {
+		m.exec.logs = append(m.exec.logs, m.stack.pop())
+		return nil
+	})
+
+	operations[opSend] = send
+	operations[opInbox] = inboxOp
+	operations[opErrorOp] = func(m *Machine) (blockReason, error) {
+		return notBlocked, errMachine
+	}
+	operations[opHalt] = func(m *Machine) (blockReason, error) {
+		m.status = machine.Halt
+		return notBlocked, nil
+	}
+	operations[opSetGas] = simpleOp(func(m *Machine) error {
+		gas, err := asInt(m.stack.peek(0))
+		if err != nil {
+			return err
+		}
+		m.gasRemaining.Set(gas)
+		m.stack.pop()
+		return nil
+	})
+	operations[opPushGas] = simpleOp(func(m *Machine) error {
+		m.stack.push(value.NewIntValue(new(big.Int).Set(m.gasRemaining)))
+		return nil
+	})
+	operations[opErrCodePoint] = simpleOp(func(m *Machine) error {
+		m.stack.push(m.newSegment().stub(0))
+		return nil
+	})
+	operations[opPushInsn] = pushInsn
+	operations[opPushInsnImm] = pushInsnImm
+	operations[opSideload] = sideload
+
+	operations[opEcRecover] = intOp(4, func(a []*big.Int) *big.Int {
+		return ecRecover(a[0], a[1], a[2], a[3])
+	})
+	operations[opEcAdd] = ecAddOp
+	operations[opEcMul] = ecMulOp
+	operations[opEcPairing] = ecPairingOp
+
+	operations[opDebugPrint] = simpleOp(func(m *Machine) error {
+		m.exec.debugPrints = append(m.exec.debugPrints, m.stack.pop())
+		return nil
+	})
+
+	operations[opNewBuffer] = simpleOp(func(m *Machine) error {
+		m.stack.push(value.NewBuffer(nil))
+		return nil
+	})
+	operations[opGetBuffer8] = getBuffer(1)
+	operations[opGetBuffer64] = getBuffer(8)
+	operations[opGetBuffer256] = getBuffer(32)
+	operations[opSetBuffer8] = setBuffer(1)
+	operations[opSetBuffer64] = setBuffer(8)
+	operations[opSetBuffer256] = setBuffer(32)
+}
+
+func eq(m *Machine) (blockReason, error) {
+	a, b := m.stack.peek(0), m.stack.peek(1)
+	var equal bool
+	aInt, aIsInt := a.(value.IntValue)
+	bInt, bIsInt := b.(value.IntValue)
+	if aIsInt && bIsInt {
+		equal = aInt.BigInt().Cmp(bInt.BigInt()) == 0
+	} else {
+		aHash, err := value.HashValue(a)
+		if err != nil {
+			return notBlocked, err
+		}
+		bHash, err := value.HashValue(b)
+		if err != nil {
+			return notBlocked, err
+		}
+		equal = aHash == bHash
+	}
+	m.replaceArgs(2, boolInt(equal))
+	m.next()
+	return notBlocked, nil
+}
+
+func hashOp(m *Machine) (blockReason, error) {
+	h, err := value.HashValue(m.stack.peek(0))
+	if err != nil {
+		return notBlocked, err
+	}
+	m.stack.set(0, value.NewIntValue(new(big.Int).SetBytes(h[:])))
+	m.next()
+	return notBlocked, nil
+}
+
+func typeOp(m *Machine) (blockReason, error) {
+	var typeCode uint8
+	switch m.stack.peek(0).(type) {
+	case value.IntValue:
+		typeCode = value.TypeCodeInt
+	case value.CodePointStub, value.CodePointValue:
+		typeCode = value.TypeCodeCodePoint
+	case *value.TupleValue, value.HashPreImage:
+		typeCode = value.TypeCodeTuple
+	case *value.Buffer:
+		typeCode = value.TypeCodeBuffer
+	default:
+		return notBlocked, errors.Errorf("unknown value type %v", m.stack.peek(0).TypeCode())
+	}
+	m.stack.set(0, value.NewInt64Value(int64(typeCode)))
+	m.next()
+	return notBlocked, nil
+}
+
+// keccakF runs the keccak permutation on a state held as a 7-tuple, the
+// first 6 ints holding 4 lanes each, least significant first, and the last
+// holding the final lane
+func keccakF(m *Machine) (blockReason, error) {
+	tup, err := asTuple(m.stack.peek(0))
+	if err != nil {
+		return notBlocked, err
+	}
+	if tup.Len() != 7 {
+		return notBlocked, errMachine
+	}
+	var state [25]uint64
+	mask := new(big.Int).SetUint64(^uint64(0))
+	for i, val := range tup.Contents() {
+		x, err := asInt(val)
+		if err != nil {
+			return notBlocked, err
+		}
+		if i == 6 {
+			state[24] = new(big.Int).And(x, mask).Uint64()
+			continue
+		}
+		for j := 0; j < 4; j++ {
+			lane := new(big.Int).Rsh(x, uint(64*j))
+			state[i*4+j] = lane.And(lane, mask).Uint64()
+		}
+	}
+	keccakF1600(&state)
+	contents := make([]value.Value, 0, 7)
+	for i := 0; i < 6; i++ {
+		x := new(big.Int)
+		for j := 3; j >= 0; j-- {
+			x.Lsh(x, 64)
+			x.Or(x, new(big.Int).SetUint64(state[i*4+j]))
+		}
+		contents = append(contents, value.NewIntValue(x))
+	}
+	contents = append(contents, value.NewIntValue(new(big.Int).SetUint64(state[24])))
+	res, err := value.NewTupleFromOwnedSlice(contents)
+	if err != nil {
+		return notBlocked, err
+	}
+	m.stack.set(0, res)
+	m.next()
+	return notBlocked, nil
+}
+
+func jump(m *Machine) (blockReason, error) {
+	target, err := asCodePoint(m.stack.peek(0))
+	if err != nil {
+		return notBlocked, err
+	}
+	if err := m.jumpTo(target); err != nil {
+		return notBlocked, err
+	}
+	m.stack.pop()
+	return notBlocked, nil
+}
+
+func cjump(m *Machine) (blockReason, error) {
+	target, err := asCodePoint(m.stack.peek(0))
+	if err != nil {
+		return notBlocked, err
+	}
+	cond, err := asInt(m.stack.peek(1))
+	if err != nil {
+		return notBlocked, err
+	}
+	if cond.Sign() != 0 {
+		if err := m.jumpTo(target); err != nil {
+			return notBlocked, err
+		}
+	} else {
+		m.next()
+	}
+	m.replaceArgs(2)
+	return notBlocked, nil
+}
+
+func errSet(m *Machine) (blockReason, error) {
+	m.next()
+	cp, err := asCodePoint(m.stack.peek(0))
+	if err != nil {
+		return notBlocked, err
+	}
+	m.errPC = cp
+	m.stack.pop()
+	return notBlocked, nil
+}
+
+func dup(i int) operation {
+	return simpleOp(func(m *Machine) error {
+		m.stack.push(m.stack.peek(i))
+		return nil
+	})
+}
+
+func swap(i int) operation {
+	return simpleOp(func(m *Machine) error {
+		top := m.stack.peek(0)
+		m.stack.set(0, m.stack.peek(i))
+		m.stack.set(i, top)
+		return nil
+	})
+}
+
+func tupleIndex(val value.Value, tup *value.TupleValue) (int64, error) {
+	index, err := asUint64(val)
+	if err != nil {
+		return 0, err
+	}
+	if index >= uint64(tup.Len()) {
+		return 0, errMachine
+	}
+	return int64(index), nil
+}
+
+func setTupleElement(tup *value.TupleValue, index int64, val value.Value) (*value.TupleValue, error) {
+	contents := append([]value.Value(nil), tup.Contents()...)
+	contents[index] = val
+	return value.NewTupleFromOwnedSlice(contents)
+}
+
+func tget(m *Machine) (blockReason, error) {
+	tup, err := asTuple(m.stack.peek(1))
+	if err != nil {
+		return notBlocked, err
+	}
+	index, err := tupleIndex(m.stack.peek(0), tup)
+	if err != nil {
+		return notBlocked, err
+	}
+	m.replaceArgs(2, tup.Contents()[index])
+	m.next()
+	return notBlocked, nil
+}
+
+func tset(m *Machine) (blockReason, error) {
+	tup, err := asTuple(m.stack.peek(1))
+	if err != nil {
+		return notBlocked, err
+	}
+	index, err := tupleIndex(m.stack.peek(0), tup)
+	if err != nil {
+		return notBlocked, err
+	}
+	res, err := setTupleElement(tup, index, m.stack.peek(2))
+	if err != nil {
+		return notBlocked, err
+	}
+	m.replaceArgs(3, res)
+	m.next()
+	return notBlocked, nil
+}
+
+func xget(m *Machine) (blockReason, error) {
+	tup, err := asTuple(m.auxstack.peek(0))
+	if err != nil {
+		return notBlocked, err
+	}
+	index, err := tupleIndex(m.stack.peek(0), tup)
+	if err != nil {
+		return notBlocked, err
+	}
+	m.stack.set(0, tup.Contents()[index])
+	m.next()
+	return notBlocked, nil
+}
+
+func xset(m *Machine) (blockReason, error) {
+	tup, err := asTuple(m.auxstack.peek(0))
+	if err != nil {
+		return notBlocked, err
+	}
+	index, err := tupleIndex(m.stack.peek(0), tup)
+	if err != nil {
+		return notBlocked, err
+	}
+	res, err := setTupleElement(tup, index, m.stack.peek(1))
+	if err != nil {
+		return notBlocked, err
+	}
+	m.auxstack.set(0, res)
+	m.replaceArgs(2)
+	m.next()
+	return notBlocked, nil
+}
+
+func breakpoint(m *Machine) (blockReason, error) {
+	if m.exec.stopOnBreakpoint && !m.exec.firstInstruction {
+		return breakpointBlocked, nil
+	}
+	m.next()
+	return notBlocked, nil
+}
+
+func send(m *Machine) (blockReason, error) {
+	size, err := asUint64(m.stack.peek(0))
+	if err != nil {
+		return notBlocked, err
+	}
+	buf, err := asBuffer(m.stack.peek(1))
+	if err != nil {
+		return notBlocked, err
+	}
+	data := buf.Data()
+	if size > sendSizeLimit || size == 0 || uint64(len(data)) > size {
+		return notBlocked, errMachine
+	}
+	msg := make([]byte, size)
+	copy(msg, data)
+	m.exec.sends = append(m.exec.sends, msg)
+	m.replaceArgs(2)
+	m.next()
+	return notBlocked, nil
+}
+
+func inboxOp(m *Machine) (blockReason, error) {
+	if len(m.exec.inbox) == 0 {
+		return inboxBlocked, nil
+	}
+	msg := m.exec.inbox[0]
+	m.exec.inbox = m.exec.inbox[1:]
+	m.exec.messagesConsumed++
+	m.stack.push(msg.AsValue())
+	m.next()
+	return notBlocked, nil
+}
+
+func pushInsn(m *Machine) (blockReason, error) {
+	opcode, err := asInt(m.stack.peek(0))
+	if err != nil {
+		return notBlocked, err
+	}
+	target, err := asCodePoint(m.stack.peek(1))
+	if err != nil {
+		return notBlocked, err
+	}
+	op := value.BasicOperation{Op: value.Opcode(opcode.Uint64())}
+	stub, err := m.addOperation(target, op)
+	if err != nil {
+		return notBlocked, err
+	}
+	m.replaceArgs(2, stub)
+	m.next()
+	return notBlocked, nil
+}
+
+func pushInsnImm(m *Machine) (blockReason, error) {
+	opcode, err := asInt(m.stack.peek(0))
+	if err != nil {
+		return notBlocked, err
+	}
+	target, err := asCodePoint(m.stack.peek(2))
+	if err != nil {
+		return notBlocked, err
+	}
+	op := value.ImmediateOperation{Op: value.Opcode(opcode.Uint64()), Val: m.stack.peek(1)}
+	stub, err := m.addOperation(target, op)
+	if err != nil {
+		return notBlocked, err
+	}
+	m.replaceArgs(3, stub)
+	m.next()
+	return notBlocked, nil
+}
+
+// sideload gives the machine the sideloaded messages last first, and an
+// empty tuple once there are none left
+func sideload(m *Machine) (blockReason, error) {
+	if _, err := asInt(m.stack.peek(0)); err != nil {
+		return notBlocked, err
+	}
+	if count := len(m.exec.sideloads); count > 0 {
+		m.stack.set(0, m.exec.sideloads[count-1].AsValue())
+		m.exec.sideloads = m.exec.sideloads[:count-1]
+	} else {
+		if m.exec.stopOnSideload && !m.exec.firstInstruction {
+			return sideloadBlocked, nil
+		}
+		m.stack.set(0, value.NewEmptyTuple())
+	}
+	m.next()
+	return notBlocked, nil
+}
+
+func ecAddOp(m *Machine) (blockReason, error) {
+	args, err := m.intArgs(4)
+	if err != nil {
+		return notBlocked, err
+	}
+	x, y, ok := ecAdd(args[0], args[1], args[2], args[3])
+	if !ok {
+		return notBlocked, errMachine
+	}
+	m.replaceArgs(4, value.NewIntValue(x), value.NewIntValue(y))
+	m.next()
+	return notBlocked, nil
+}
+
+func ecMulOp(m *Machine) (blockReason, error) {
+	args, err := m.intArgs(3)
+	if err != nil {
+		return notBlocked, err
+	}
+	x, y, ok := ecMul(args[0], args[1], args[2])
+	if !ok {
+		return notBlocked, errMachine
+	}
+	m.replaceArgs(3, value.NewIntValue(x), value.NewIntValue(y))
+	m.next()
+	return notBlocked, nil
+}
+
+// ecPairingOp takes the points as a linked list of 2-tuples, each holding a
+// 6-tuple with a G1 and a G2 point and the rest of the list
+func ecPairingOp(m *Machine) (blockReason, error) {
+	list, err := asTuple(m.stack.peek(0))
+	if err != nil {
+		return notBlocked, err
+	}
+	var points []ecPairingPoint
+	for i := 0; i < maxEcPairingPoints && list.Len() != 0; i++ {
+		if list.Len() != 2 {
+			return notBlocked, errMachine
+		}
+		next, err := asTuple(list.Contents()[0])
+		if err != nil {
+			return notBlocked, err
+		}
+		list, err = asTuple(list.Contents()[1])
+		if err != nil {
+			return notBlocked, err
+		}
+		if next.Len() != 6 {
+			return notBlocked, errMachine
+		}
+		var point ecPairingPoint
+		for j, val := range next.Contents() {
+			point[j], err = asInt(val)
+			if err != nil {
+				return notBlocked, err
+			}
+		}
+		points = append(points, point)
+	}
+	if list.Len() != 0 {
+		return notBlocked, errMachine
+	}
+	res, ok := ecPairing(points)
+	if !ok {
+		return notBlocked, errMachine
+	}
+	m.stack.set(0, boolInt(res))
+	m.next()
+	return notBlocked, nil
+}
+
+// ecPairingGasCost is the gas ecpairing uses on top of its base cost, which
+// depends on how many points are on top of the stack
+func ecPairingGasCost(stack *datastack) uint64 {
+	if stack.size() == 0 {
+		return 0
+	}
+	var gas uint64
+	val := stack.peek(0)
+	for i := 0; i < maxEcPairingPoints; i++ {
+		tup, ok := val.(*value.TupleValue)
+		if !ok || tup.Len() != 2 {
+			break
+		}
+		val = tup.Contents()[1]
+		gas += ecPairingPointCost
+	}
+	return gas
+}
+
+func getBuffer(size uint64) operation {
+	return func(m *Machine) (blockReason, error) {
+		offset, err := asUint64(m.stack.peek(0))
+		if err != nil {
+			return notBlocked, err
+		}
+		buf, err := asBuffer(m.stack.peek(1))
+		if err != nil {
+			return notBlocked, err
+		}
+		if offset+size-1 < offset {
+			return notBlocked, errMachine
+		}
+		res := make([]byte, size)
+		if data := buf.Data(); offset < uint64(len(data)) {
+			copy(res, data[offset:])
+		}
+		m.replaceArgs(2, value.NewIntValue(new(big.Int).SetBytes(res)))
+		m.next()
+		return notBlocked, nil
+	}
+}
+
+func setBuffer(size uint64) operation {
+	return func(m *Machine) (blockReason, error) {
+		offset, err := asUint64(m.stack.peek(0))
+		if err != nil {
+			return notBlocked, err
+		}
+		val, err := asInt(m.stack.peek(1))
+		if err != nil {
+			return notBlocked, err
+		}
+		if val.BitLen() > int(size*8) {
+			return notBlocked, errMachine
+		}
+		buf, err := asBuffer(m.stack.peek(2))
+		if err != nil {
+			return notBlocked, err
+		}
+		if offset+size-1 < offset {
+			return notBlocked, errMachine
+		}
+		var raw [32]byte
+		copy(raw[:], hashing.Uint256(val))
+		res, err := writeBuffer(buf.Data(), offset, raw[32-size:])
+		if err != nil {
+			return notBlocked, err
+		}
+		m.replaceArgs(3, value.NewBuffer(res))
+		m.next()
+		return notBlocked, nil
+	}
+}
+
+// writeBuffer returns a copy of data with b written at offset. Buffers are
+// kept without trailing zeroes, as the AVM treats them as if they were
+// infinitely long and zero filled.
+func writeBuffer(data []byte, offset uint64, b []byte) ([]byte, error) {
+	b = trimBuffer(b)
+	end := offset + uint64(len(b))
+	if len(b) == 0 || end <= uint64(len(data)) {
+		res := append([]byte(nil), data...)
+		if offset < uint64(len(res)) {
+			copy(res[offset:], b)
+		}
+		return trimBuffer(res), nil
+	}
+	if end > maxBufferAllocation {
+		return nil, errors.Errorf("writing a buffer past %v bytes isn't supported", uint64(maxBufferAllocation))
+	}
+	res := make([]byte, end)
+	copy(res, data)
+	copy(res[offset:], b)
+	return trimBuffer(res), nil
+}
+
+func trimBuffer(data []byte) []byte {
+	return bytes.TrimRight(data, "\x00")
+}
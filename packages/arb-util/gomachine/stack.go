@@ -0,0 +1,98 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gomachine
+
+import (
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+var emptyTuplePreImage, _ = value.NewEmptyTuple().HashPreImage()
+
+// datastack holds its values bottom first. It hashes the way the AVM does,
+// as a linked list of 2-tuples from the top of the stack down, and keeps the
+// preimage of each prefix so only values pushed since the last hash need to
+// be hashed.
+type datastack struct {
+	values    []value.Value
+	preImages []value.HashPreImage
+}
+
+func (s *datastack) clone() datastack {
+	return datastack{
+		values:    append([]value.Value(nil), s.values...),
+		preImages: append([]value.HashPreImage(nil), s.preImages...),
+	}
+}
+
+func (s *datastack) size() int {
+	return len(s.values)
+}
+
+func (s *datastack) push(val value.Value) {
+	s.values = append(s.values, val)
+}
+
+func (s *datastack) pop() value.Value {
+	val := s.values[len(s.values)-1]
+	s.values[len(s.values)-1] = nil
+	s.values = s.values[:len(s.values)-1]
+	if len(s.preImages) > len(s.values) {
+		s.preImages = s.preImages[:len(s.values)]
+	}
+	return val
+}
+
+// peek returns the value i below the top of the stack
+func (s *datastack) peek(i int) value.Value {
+	return s.values[len(s.values)-1-i]
+}
+
+// set replaces the value i below the top of the stack
+func (s *datastack) set(i int, val value.Value) {
+	idx := len(s.values) - 1 - i
+	s.values[idx] = val
+	if len(s.preImages) > idx {
+		s.preImages = s.preImages[:idx]
+	}
+}
+
+func (s *datastack) hashPreImage() (value.HashPreImage, error) {
+	for len(s.preImages) < len(s.values) {
+		prev := emptyTuplePreImage
+		if len(s.preImages) > 0 {
+			prev = s.preImages[len(s.preImages)-1]
+		}
+		preImage, err := value.NewTuple2(s.values[len(s.preImages)], prev).HashPreImage()
+		if err != nil {
+			return value.HashPreImage{}, err
+		}
+		s.preImages = append(s.preImages, preImage)
+	}
+	if len(s.preImages) == 0 {
+		return emptyTuplePreImage, nil
+	}
+	return s.preImages[len(s.preImages)-1], nil
+}
+
+func (s *datastack) hash() (common.Hash, error) {
+	preImage, err := s.hashPreImage()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return preImage.Hash(), nil
+}
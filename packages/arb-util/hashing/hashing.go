@@ -17,7 +17,9 @@
 package hashing
 
 import (
+	"hash"
 	"math/big"
+	"sync"
 
 	"golang.org/x/crypto/sha3"
 
@@ -28,20 +30,68 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 )
 
-func SoliditySHA3(data ...[]byte) common.Hash {
-	var ret common.Hash
-	hash := sha3.NewLegacyKeccak256()
+// keccakState is implemented by the keccak hasher, which can be read from
+// to get the hash without copying its state like Sum does
+type keccakState interface {
+	hash.Hash
+	Read([]byte) (int, error)
+}
+
+var keccakPool = sync.Pool{
+	New: func() interface{} {
+		return sha3.NewLegacyKeccak256().(keccakState)
+	},
+}
+
+func getKeccak() keccakState {
+	return keccakPool.Get().(keccakState)
+}
+
+func putKeccak(hasher keccakState) {
+	hasher.Reset()
+	keccakPool.Put(hasher)
+}
+
+// sum256 writes the keccak256 hash of the concatenation of data to ret
+func sum256(hasher keccakState, ret *common.Hash, data ...[]byte) {
 	for _, b := range data {
-		_, err := hash.Write(b)
+		_, err := hasher.Write(b)
 		if err != nil {
 			// This code should never be reached
 			panic("Error writing SoliditySHA3 data")
 		}
 	}
-	hash.Sum(ret[:0])
+	if _, err := hasher.Read(ret[:]); err != nil {
+		// This code should never be reached
+		panic("Error reading SoliditySHA3 hash")
+	}
+}
+
+// SoliditySHA3 returns the keccak256 hash of the concatenation of data. It
+// reuses hashers from a pool, so it's cheap to call on hot paths.
+func SoliditySHA3(data ...[]byte) common.Hash {
+	var ret common.Hash
+	hasher := getKeccak()
+	sum256(hasher, &ret, data...)
+	putKeccak(hasher)
 	return ret
 }
 
+// Sum256Batch returns the keccak256 hash of each of inputs, using a single
+// hasher for all of them
+func Sum256Batch(inputs [][]byte) []common.Hash {
+	hashes := make([]common.Hash, len(inputs))
+	hasher := getKeccak()
+	for i, input := range inputs {
+		if i > 0 {
+			hasher.Reset()
+		}
+		sum256(hasher, &hashes[i], input)
+	}
+	putKeccak(hasher)
+	return hashes
+}
+
 func SoliditySHA3WithPrefix(data []byte) common.Hash {
 	var ret common.Hash
 	copy(ret[:], solsha3.SoliditySHA3WithPrefix(data))
@@ -69,17 +119,44 @@ func Bool(input bool) []byte {
 }
 
 func TimeBlocks(input *common.TimeBlocks) []byte {
-	return solsha3.Uint128(input.AsInt())
+	return Uint128(input.AsInt())
+}
+
+var (
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	maxUint128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+)
+
+// appendUint appends input to dst as a size byte big endian integer. Like
+// the EVM, input is taken modulo 2^(size*8), so negative values are encoded
+// in two's complement. Only values out of that range need a temporary.
+func appendUint(dst []byte, input *big.Int, size int, mask *big.Int) []byte {
+	start := len(dst)
+	dst = append(dst, make([]byte, size)...)
+	if input.Sign() >= 0 && input.BitLen() <= size*8 {
+		input.FillBytes(dst[start:])
+		return dst
+	}
+	wrapped := common.GetBigInt().And(input, mask)
+	wrapped.FillBytes(dst[start:])
+	common.PutBigInt(wrapped)
+	return dst
+}
+
+// AppendUint256 appends the packed ABI encoding of input to dst, which lets
+// a preimage be built in a single buffer
+func AppendUint256(dst []byte, input *big.Int) []byte {
+	return appendUint(dst, input, 32, maxUint256)
 }
 
 // Uint256 converts input to its packed ABI encoding
 func Uint256(input *big.Int) []byte {
-	return solsha3.Uint256(new(big.Int).Set(input))
+	return AppendUint256(make([]byte, 0, 32), input)
 }
 
 // Uint128 converts input to its packed ABI encoding
 func Uint128(input *big.Int) []byte {
-	return solsha3.Uint128(new(big.Int).Set(input))
+	return appendUint(make([]byte, 0, 16), input, 16, maxUint128)
 }
 
 // Uint256Array converts input to its packed ABI encoding
@@ -74,12 +74,12 @@ func BufOffsetAndLengthToBytes(sizeInt, offsetInt *big.Int, contents *value.Buff
 func StackValueToList(val value.Value) ([]value.Value, error) {
 	tupVal, ok := val.(*value.TupleValue)
 	if !ok {
-		return nil, errors.Wrap(errTupleSize2, val.String())
+		return nil, errors.Wrap(errTupleSize2, value.Format(val, value.DefaultFormatOptions))
 	}
 	values := make([]value.Value, 0)
 	for tupVal.Len() != 0 {
 		if tupVal.Len() != 2 {
-			return nil, errors.Wrap(errTupleSize2, val.String())
+			return nil, errors.Wrap(errTupleSize2, value.Format(val, value.DefaultFormatOptions))
 		}
 
 		// Tuple size already verified above, so error can be ignored
@@ -88,7 +88,7 @@ func StackValueToList(val value.Value) ([]value.Value, error) {
 
 		tupVal, ok = val.(*value.TupleValue)
 		if !ok {
-			return nil, errors.Wrap(errTupleSize2, val.String())
+			return nil, errors.Wrap(errTupleSize2, value.Format(val, value.DefaultFormatOptions))
 		}
 
 		values = append(values, member)
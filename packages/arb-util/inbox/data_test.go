@@ -45,6 +45,30 @@ func TestListToStackValue(t *testing.T) {
 	}
 }
 
+func TestListToStackValueNested(t *testing.T) {
+	vals := make([]value.Value, 0)
+	for i := int64(0); i < 5; i++ {
+		inner := ListToStackValue([]value.Value{value.NewInt64Value(i), value.NewEmptyTuple()})
+		vals = append(vals, value.NewTuple2(value.NewInt64Value(i), inner))
+	}
+	stackVal := ListToStackValue(vals)
+
+	vals2, err := StackValueToList(stackVal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vals) != len(vals2) {
+		t.Fatal("wrong val count")
+	}
+
+	for i, val := range vals {
+		if differences := value.Diff(val, vals2[i], 5); len(differences) > 0 {
+			t.Fatal("val not equal:", differences)
+		}
+	}
+}
+
 func TestStackValueToListFailures(t *testing.T) {
 	intVal := value.NewInt64Value(0)
 	if _, err := StackValueToList(intVal); err == nil {
@@ -220,7 +220,7 @@ func (im InboxMessage) String() string {
 
 func (im InboxMessage) AsValue() value.Value {
 	// Static slice correct size, so error can be ignored
-	tup, _ := value.NewTupleFromSlice([]value.Value{
+	tup, _ := value.NewTupleFromOwnedSlice([]value.Value{
 		value.NewInt64Value(int64(im.Kind)),
 		value.NewIntValue(im.ChainTime.BlockNum.AsInt()),
 		value.NewIntValue(im.ChainTime.Timestamp),
@@ -234,15 +234,16 @@ func (im InboxMessage) AsValue() value.Value {
 }
 
 func (im InboxMessage) CommitmentHash() common.Hash {
-	return hashing.SoliditySHA3(
-		hashing.Uint8(uint8(im.Kind)),
-		hashing.Address(im.Sender),
-		hashing.Uint256(im.ChainTime.BlockNum.AsInt()),
-		hashing.Uint256(im.ChainTime.Timestamp),
-		hashing.Uint256(im.InboxSeqNum),
-		hashing.Uint256(im.GasPrice),
-		hashing.Bytes32(hashing.SoliditySHA3(im.Data)),
-	)
+	dataHash := hashing.SoliditySHA3(im.Data)
+	data := make([]byte, 0, 1+20+5*32)
+	data = append(data, uint8(im.Kind))
+	data = append(data, im.Sender[:]...)
+	data = hashing.AppendUint256(data, im.ChainTime.BlockNum.AsInt())
+	data = hashing.AppendUint256(data, im.ChainTime.Timestamp)
+	data = hashing.AppendUint256(data, im.InboxSeqNum)
+	data = hashing.AppendUint256(data, im.GasPrice)
+	data = append(data, dataHash[:]...)
+	return hashing.SoliditySHA3(data)
 }
 
 func (im InboxMessage) Equals(o InboxMessage) bool {
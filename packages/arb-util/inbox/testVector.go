@@ -120,7 +120,7 @@ func jsonToValue(val JSONValue) (value.Value, error) {
 		}
 		return value.NewIntValue(intVal), nil
 	} else if val.Tuple != nil {
-		vals := make([]value.Value, 0)
+		vals := make([]value.Value, 0, len(*val.Tuple))
 		for _, jsonSubVal := range *val.Tuple {
 			subVal, err := jsonToValue(jsonSubVal)
 			if err != nil {
@@ -128,7 +128,7 @@ func jsonToValue(val JSONValue) (value.Value, error) {
 			}
 			vals = append(vals, subVal)
 		}
-		return value.NewTupleFromSlice(vals)
+		return value.NewTupleFromOwnedSlice(vals)
 	} else {
 		return nil, errors.New("unsupported json value")
 	}
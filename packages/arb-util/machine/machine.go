@@ -18,6 +18,7 @@ package machine
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
@@ -33,11 +34,26 @@ const (
 	Halt
 )
 
+func (s Status) String() string {
+	switch s {
+	case Extensive:
+		return "Extensive"
+	case ErrorStop:
+		return "ErrorStop"
+	case Halt:
+		return "Halt"
+	default:
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+}
+
 type Machine interface {
 	String() string
 	Hash() common.Hash
 	CodePointHash() common.Hash
 
+	// Clone returns a copy of the machine which can be executed, for example
+	// to run calls speculatively, without affecting the original
 	Clone() Machine
 
 	CurrentStatus() Status
@@ -49,4 +65,15 @@ type Machine interface {
 	MarshalForProof() ([]byte, []byte, error)
 
 	MarshalState() ([]byte, error)
+
+	// SetProfiling turns the opcode profiler on or off. Turning it on starts
+	// a new profile, which later assertions executed by the machine add to.
+	SetProfiling(enabled bool)
+	// Profile returns a copy of the profile gathered since profiling was last
+	// turned on, or nil if it never was
+	Profile() *Profile
+	// SetStepTracer sets a tracer which is given every instruction executed
+	// by later assertions, or removes it if tracer is nil. Tracing slows
+	// execution down a lot, so it's meant for debugging.
+	SetStepTracer(tracer StepTracer)
 }
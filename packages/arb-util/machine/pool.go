@@ -0,0 +1,88 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package machine
+
+import "sync"
+
+// Pool keeps clones of a machine ready in the background, so that callers
+// which each need their own copy of the same state, like RPC calls against
+// a snapshot, don't wait for the machine to be copied
+type Pool struct {
+	mach      Machine
+	machines  chan Machine
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPool starts keeping size clones of mach ready. mach must not be modified
+// while the pool is open, so callers which may go on to modify it should
+// pass in a clone.
+func NewPool(mach Machine, size int) *Pool {
+	p := &Pool{
+		mach:     mach,
+		machines: make(chan Machine, size),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go p.fill()
+	return p
+}
+
+func (p *Pool) fill() {
+	defer close(p.stopped)
+	for {
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+		mach := p.mach.Clone()
+		select {
+		case p.machines <- mach:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Get returns a clone of the pool's machine which the caller owns, cloning
+// it directly if none is ready
+func (p *Pool) Get() Machine {
+	select {
+	case mach := <-p.machines:
+		return mach
+	default:
+		return p.mach.Clone()
+	}
+}
+
+// Close stops refilling the pool and releases the clones it holds. Get can
+// still be called afterwards, but clones on every call.
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.done)
+	})
+	<-p.stopped
+	for {
+		select {
+		case <-p.machines:
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package machine
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// cloneCountingMachine only supports Clone, counting how often it's called
+type cloneCountingMachine struct {
+	Machine
+	clones *int64
+}
+
+func (m cloneCountingMachine) Clone() Machine {
+	atomic.AddInt64(m.clones, 1)
+	return cloneCountingMachine{clones: m.clones}
+}
+
+func waitForClones(t *testing.T, clones *int64, expected int64) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if atomic.LoadInt64(clones) >= expected {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected %v clones, got %v", expected, atomic.LoadInt64(clones))
+}
+
+func TestPool(t *testing.T) {
+	var clones int64
+	pool := NewPool(cloneCountingMachine{clones: &clones}, 2)
+
+	// The pool holds two clones with a third waiting to be added
+	waitForClones(t, &clones, 3)
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt64(&clones); n != 3 {
+		t.Fatalf("pool should have stopped after 3 clones, made %v", n)
+	}
+
+	for i := 0; i < 2; i++ {
+		if pool.Get() == nil {
+			t.Fatal("pool returned nil machine")
+		}
+	}
+	waitForClones(t, &clones, 5)
+
+	pool.Close()
+	time.Sleep(50 * time.Millisecond)
+	closedCount := atomic.LoadInt64(&clones)
+	if pool.Get() == nil {
+		t.Fatal("closed pool returned nil machine")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt64(&clones); n != closedCount+1 {
+		t.Errorf("closed pool should clone once per get, made %v clones", n-closedCount)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package machine
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+type ExecutionStats struct {
+	Count uint64
+	Gas   uint64
+}
+
+func (s *ExecutionStats) add(other ExecutionStats) {
+	s.Count += other.Count
+	s.Gas += other.Gas
+}
+
+// CodePointRef locates a codepoint by its code segment and its index in it
+type CodePointRef struct {
+	Segment uint64
+	PC      uint64
+}
+
+type OpcodeProfile struct {
+	Opcode value.Opcode
+	ExecutionStats
+}
+
+type CodePointProfile struct {
+	CodePointRef
+	ExecutionStats
+}
+
+// Profile records how many times each opcode and codepoint was executed and
+// how much ArbGas they used
+type Profile struct {
+	Opcodes    map[value.Opcode]ExecutionStats
+	CodePoints map[CodePointRef]ExecutionStats
+}
+
+func NewProfile() *Profile {
+	return &Profile{
+		Opcodes:    make(map[value.Opcode]ExecutionStats),
+		CodePoints: make(map[CodePointRef]ExecutionStats),
+	}
+}
+
+// NewProfileFromReader reads a profile in the format the AVM marshals it in
+func NewProfileFromReader(rd io.Reader) (*Profile, error) {
+	p := NewProfile()
+	var opcodeCount uint64
+	if err := binary.Read(rd, binary.BigEndian, &opcodeCount); err != nil {
+		return nil, errors.Wrap(err, "error reading profile opcode count")
+	}
+	for i := uint64(0); i < opcodeCount; i++ {
+		var entry struct {
+			Opcode value.Opcode
+			Count  uint64
+			Gas    uint64
+		}
+		if err := binary.Read(rd, binary.BigEndian, &entry); err != nil {
+			return nil, errors.Wrap(err, "error reading profile opcode")
+		}
+		p.Opcodes[entry.Opcode] = ExecutionStats{Count: entry.Count, Gas: entry.Gas}
+	}
+
+	var codePointCount uint64
+	if err := binary.Read(rd, binary.BigEndian, &codePointCount); err != nil {
+		return nil, errors.Wrap(err, "error reading profile codepoint count")
+	}
+	for i := uint64(0); i < codePointCount; i++ {
+		var entry struct {
+			Segment uint64
+			PC      uint64
+			Count   uint64
+			Gas     uint64
+		}
+		if err := binary.Read(rd, binary.BigEndian, &entry); err != nil {
+			return nil, errors.Wrap(err, "error reading profile codepoint")
+		}
+		ref := CodePointRef{Segment: entry.Segment, PC: entry.PC}
+		p.CodePoints[ref] = ExecutionStats{Count: entry.Count, Gas: entry.Gas}
+	}
+	return p, nil
+}
+
+// Merge adds the counts and gas of other into p
+func (p *Profile) Merge(other *Profile) {
+	for op, stats := range other.Opcodes {
+		total := p.Opcodes[op]
+		total.add(stats)
+		p.Opcodes[op] = total
+	}
+	for ref, stats := range other.CodePoints {
+		total := p.CodePoints[ref]
+		total.add(stats)
+		p.CodePoints[ref] = total
+	}
+}
+
+func (p *Profile) TotalGas() uint64 {
+	var total uint64
+	for _, stats := range p.Opcodes {
+		total += stats.Gas
+	}
+	return total
+}
+
+// OpcodesByGas returns every executed opcode, those which used the most gas
+// first
+func (p *Profile) OpcodesByGas() []OpcodeProfile {
+	ret := make([]OpcodeProfile, 0, len(p.Opcodes))
+	for op, stats := range p.Opcodes {
+		ret = append(ret, OpcodeProfile{Opcode: op, ExecutionStats: stats})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Gas != ret[j].Gas {
+			return ret[i].Gas > ret[j].Gas
+		}
+		return ret[i].Opcode < ret[j].Opcode
+	})
+	return ret
+}
+
+// HotCodePoints returns the n codepoints which used the most gas, or all of
+// them if n isn't positive
+func (p *Profile) HotCodePoints(n int) []CodePointProfile {
+	ret := make([]CodePointProfile, 0, len(p.CodePoints))
+	for ref, stats := range p.CodePoints {
+		ret = append(ret, CodePointProfile{CodePointRef: ref, ExecutionStats: stats})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Gas != ret[j].Gas {
+			return ret[i].Gas > ret[j].Gas
+		}
+		if ret[i].Segment != ret[j].Segment {
+			return ret[i].Segment < ret[j].Segment
+		}
+		return ret[i].PC < ret[j].PC
+	})
+	if n > 0 && len(ret) > n {
+		ret = ret[:n]
+	}
+	return ret
+}
+
+// Summary describes the opcodes and the hottest codepoints, up to
+// maxCodePoints of them, with the share of the total gas each one used
+func (p *Profile) Summary(maxCodePoints int) string {
+	total := p.TotalGas()
+	share := func(gas uint64) float64 {
+		if total == 0 {
+			return 0
+		}
+		return float64(gas) * 100 / float64(total)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "total gas %v\n", total)
+	for _, op := range p.OpcodesByGas() {
+		fmt.Fprintf(&sb, "opcode 0x%02x: count %v, gas %v (%.2f%%)\n", uint8(op.Opcode), op.Count, op.Gas, share(op.Gas))
+	}
+	for _, cp := range p.HotCodePoints(maxCodePoints) {
+		fmt.Fprintf(&sb, "codepoint %v:%v: count %v, gas %v (%.2f%%)\n", cp.Segment, cp.PC, cp.Count, cp.Gas, share(cp.Gas))
+	}
+	return sb.String()
+}
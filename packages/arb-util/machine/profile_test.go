@@ -0,0 +1,83 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package machine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+func marshalProfile(p *Profile) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, uint64(len(p.Opcodes)))
+	for op, stats := range p.Opcodes {
+		_ = binary.Write(&buf, binary.BigEndian, op)
+		_ = binary.Write(&buf, binary.BigEndian, stats)
+	}
+	_ = binary.Write(&buf, binary.BigEndian, uint64(len(p.CodePoints)))
+	for ref, stats := range p.CodePoints {
+		_ = binary.Write(&buf, binary.BigEndian, ref)
+		_ = binary.Write(&buf, binary.BigEndian, stats)
+	}
+	return buf.Bytes()
+}
+
+func TestProfile(t *testing.T) {
+	first := NewProfile()
+	first.Opcodes[value.Opcode(0x01)] = ExecutionStats{Count: 3, Gas: 9}
+	first.Opcodes[value.Opcode(0x34)] = ExecutionStats{Count: 1, Gas: 10}
+	first.CodePoints[CodePointRef{Segment: 0, PC: 5}] = ExecutionStats{Count: 3, Gas: 9}
+	first.CodePoints[CodePointRef{Segment: 0, PC: 7}] = ExecutionStats{Count: 1, Gas: 10}
+
+	parsed, err := NewProfileFromReader(bytes.NewReader(marshalProfile(first)))
+	test.FailIfError(t, err)
+	if parsed.TotalGas() != 19 {
+		t.Fatalf("expected total gas 19, got %v", parsed.TotalGas())
+	}
+
+	second := NewProfile()
+	second.Opcodes[value.Opcode(0x01)] = ExecutionStats{Count: 2, Gas: 6}
+	second.CodePoints[CodePointRef{Segment: 1, PC: 5}] = ExecutionStats{Count: 2, Gas: 6}
+	parsed.Merge(second)
+
+	opcodes := parsed.OpcodesByGas()
+	if len(opcodes) != 2 || opcodes[0].Opcode != 0x01 || opcodes[0].Count != 5 || opcodes[0].Gas != 15 {
+		t.Errorf("unexpected opcodes %v", opcodes)
+	}
+
+	hot := parsed.HotCodePoints(2)
+	expected := []CodePointProfile{
+		{CodePointRef{Segment: 0, PC: 7}, ExecutionStats{Count: 1, Gas: 10}},
+		{CodePointRef{Segment: 0, PC: 5}, ExecutionStats{Count: 3, Gas: 9}},
+	}
+	if len(hot) != len(expected) || hot[0] != expected[0] || hot[1] != expected[1] {
+		t.Errorf("expected hot codepoints %v, got %v", expected, hot)
+	}
+}
+
+func TestProfileTruncated(t *testing.T) {
+	profile := NewProfile()
+	profile.Opcodes[value.Opcode(0x01)] = ExecutionStats{Count: 1, Gas: 3}
+	data := marshalProfile(profile)
+	if _, err := NewProfileFromReader(bytes.NewReader(data[:len(data)-1])); err == nil {
+		t.Error("expected error reading truncated profile")
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package machine
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// SideloadResult is the output of executing sideloaded messages
+type SideloadResult struct {
+	Logs        []value.Value
+	DebugPrints []value.Value
+	GasUsed     uint64
+	Steps       uint64
+}
+
+// ExecuteSideloaded runs messages on mach without adding them to its inbox,
+// which is how calls and gas estimates see what a message would do against
+// the current state. The machine reads the messages, first to last, through
+// its sideload instruction, and execution stops once it asks for another
+// after reading them all or maxGas runs out. mach is modified, so callers
+// should pass in a clone.
+func ExecuteSideloaded(
+	ctx context.Context,
+	mach Machine,
+	messages []inbox.InboxMessage,
+	maxGas uint64,
+	trace bool,
+) (*SideloadResult, error) {
+	// The machine takes sideloads from the end of the list
+	sideloads := make([]inbox.InboxMessage, 0, len(messages))
+	for i := len(messages) - 1; i >= 0; i-- {
+		sideloads = append(sideloads, messages[i])
+	}
+	assertion, debugPrints, steps, err := mach.ExecuteAssertionAdvanced(ctx, maxGas, false, nil, sideloads, true, false, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	// If the machine wasn't able to run and it reports that it is currently
+	// blocked, return the block reason to give the client more information
+	// as opposed to just returning a general "call produced no output"
+	if br := mach.IsBlocked(true); steps == 0 && br != nil {
+		return nil, errors.Errorf("can't produce solution since machine is blocked %v", br)
+	}
+	return &SideloadResult{
+		Logs:        assertion.Logs,
+		DebugPrints: debugPrints,
+		GasUsed:     assertion.NumGas,
+		Steps:       steps,
+	}, nil
+}
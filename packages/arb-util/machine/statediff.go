@@ -0,0 +1,203 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package machine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// StateValue is the register or static value of a serialized machine state.
+// Only ints are serialized in full, so other values are known by their hash.
+type StateValue struct {
+	TypeCode uint8
+	Hash     common.Hash
+	Int      *big.Int
+}
+
+func (v StateValue) String() string {
+	if v.Int != nil {
+		return v.Int.String()
+	}
+	return fmt.Sprintf("value of type %v with hash %v", v.TypeCode, v.Hash)
+}
+
+// State is the state of a machine as MarshalState serializes it, which is
+// the same form the one step proof contracts read it in
+type State struct {
+	CodePointHash    common.Hash
+	Stack            value.HashPreImage
+	AuxStack         value.HashPreImage
+	Register         StateValue
+	Static           StateValue
+	ArbGasRemaining  *big.Int
+	ErrCodePointHash common.Hash
+}
+
+// GetState returns the current state of mach
+func GetState(mach Machine) (*State, error) {
+	data, err := mach.MarshalState()
+	if err != nil {
+		return nil, err
+	}
+	return NewStateFromReader(bytes.NewReader(data))
+}
+
+func NewStateFromReader(rd io.Reader) (*State, error) {
+	s := &State{}
+	var err error
+	if _, err := io.ReadFull(rd, s.CodePointHash[:]); err != nil {
+		return nil, errors.Wrap(err, "error reading codepoint hash")
+	}
+	if s.Stack, err = value.NewHashPreImageFromReader(rd); err != nil {
+		return nil, errors.Wrap(err, "error reading stack")
+	}
+	if s.AuxStack, err = value.NewHashPreImageFromReader(rd); err != nil {
+		return nil, errors.Wrap(err, "error reading aux stack")
+	}
+	if s.Register, err = newStateValueFromReader(rd); err != nil {
+		return nil, errors.Wrap(err, "error reading register")
+	}
+	if s.Static, err = newStateValueFromReader(rd); err != nil {
+		return nil, errors.Wrap(err, "error reading static")
+	}
+	gas, err := value.NewIntValueFromReader(rd)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading gas remaining")
+	}
+	s.ArbGasRemaining = gas.BigInt()
+	if _, err := io.ReadFull(rd, s.ErrCodePointHash[:]); err != nil {
+		return nil, errors.Wrap(err, "error reading error codepoint hash")
+	}
+	return s, nil
+}
+
+// newStateValueFromReader reads a value serialized the way the one step proof
+// serializes the top level of a value
+func newStateValueFromReader(rd io.Reader) (StateValue, error) {
+	var typeCode [1]byte
+	if _, err := io.ReadFull(rd, typeCode[:]); err != nil {
+		return StateValue{}, err
+	}
+	v := StateValue{TypeCode: typeCode[0]}
+	switch v.TypeCode {
+	case value.TypeCodeInt:
+		i, err := value.NewIntValueFromReader(rd)
+		if err != nil {
+			return StateValue{}, err
+		}
+		v.Int = i.BigInt()
+		v.Hash = i.Hash()
+	case value.TypeCodeHashPreImage:
+		preImage, err := value.NewHashPreImageFromReader(rd)
+		if err != nil {
+			return StateValue{}, err
+		}
+		v.TypeCode = value.TypeCodeTuple
+		v.Hash = preImage.Hash()
+	case value.TypeCodeBuffer:
+		if _, err := io.ReadFull(rd, v.Hash[:]); err != nil {
+			return StateValue{}, err
+		}
+	case value.TypeCodeCodePoint:
+		var op [2]byte
+		if _, err := io.ReadFull(rd, op[:]); err != nil {
+			return StateValue{}, err
+		}
+		data := [][]byte{hashing.Uint8(value.TypeCodeCodePoint), hashing.Uint8(op[1])}
+		if op[0] == 1 {
+			imm, err := newStateValueFromReader(rd)
+			if err != nil {
+				return StateValue{}, err
+			}
+			data = append(data, hashing.Bytes32(imm.Hash))
+		}
+		var nextHash common.Hash
+		if _, err := io.ReadFull(rd, nextHash[:]); err != nil {
+			return StateValue{}, err
+		}
+		data = append(data, hashing.Bytes32(nextHash))
+		v.Hash = hashing.SoliditySHA3(data...)
+	default:
+		return StateValue{}, errors.Errorf("unexpected value type %v in machine state", v.TypeCode)
+	}
+	return v, nil
+}
+
+// Diff describes each part of the machine state which differs between s and
+// other. The stacks and the contents of tuples aren't serialized, so a
+// difference in them can only be narrowed down by comparing the
+// machines' execution, for example with a StepTracer.
+func (s *State) Diff(other *State) []string {
+	var diffs []string
+	add := func(format string, args ...interface{}) {
+		diffs = append(diffs, fmt.Sprintf(format, args...))
+	}
+	if s.CodePointHash != other.CodePointHash {
+		add("codepoint: %v != %v", s.CodePointHash, other.CodePointHash)
+	}
+	if !s.Stack.Equal(other.Stack) {
+		add("stack: %v (size %v) != %v (size %v)", s.Stack.Hash(), s.Stack.Size(), other.Stack.Hash(), other.Stack.Size())
+	}
+	if !s.AuxStack.Equal(other.AuxStack) {
+		add("aux stack: %v (size %v) != %v (size %v)", s.AuxStack.Hash(), s.AuxStack.Size(), other.AuxStack.Hash(), other.AuxStack.Size())
+	}
+	if s.Register.Hash != other.Register.Hash {
+		add("register: %v != %v", s.Register, other.Register)
+	}
+	if s.Static.Hash != other.Static.Hash {
+		add("static: %v != %v", s.Static, other.Static)
+	}
+	if s.ArbGasRemaining.Cmp(other.ArbGasRemaining) != 0 {
+		add("gas remaining: %v != %v", s.ArbGasRemaining, other.ArbGasRemaining)
+	}
+	if s.ErrCodePointHash != other.ErrCodePointHash {
+		add("error codepoint: %v != %v", s.ErrCodePointHash, other.ErrCodePointHash)
+	}
+	return diffs
+}
+
+// DiffStates describes each part of the state which differs between two
+// machines, such as the machines two validators have at the start of a
+// disputed segment, or a machine loaded from a checkpoint and one reached by
+// executing from an earlier checkpoint. Machines which have halted or hit an
+// error don't have a state to compare beyond their status.
+func DiffStates(expected Machine, actual Machine) ([]string, error) {
+	if expected.CurrentStatus() != actual.CurrentStatus() {
+		return []string{fmt.Sprintf("status: %v != %v", expected.CurrentStatus(), actual.CurrentStatus())}, nil
+	}
+	if expected.CurrentStatus() != Extensive {
+		return nil, nil
+	}
+	expectedState, err := GetState(expected)
+	if err != nil {
+		return nil, err
+	}
+	actualState, err := GetState(actual)
+	if err != nil {
+		return nil, err
+	}
+	return expectedState.Diff(actualState), nil
+}
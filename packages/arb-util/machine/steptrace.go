@@ -0,0 +1,97 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package machine
+
+import (
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+const (
+	stackTopEmpty = 0
+	stackTopInt   = 1
+	stackTopHash  = 2
+)
+
+// ExecutionStep describes a single instruction executed by a machine
+type ExecutionStep struct {
+	CodePointRef
+	Opcode value.Opcode
+	// Gas is the ArbGas the instruction used
+	Gas uint64
+	// StackTop is the top of the data stack when the instruction started,
+	// after its immediate was pushed, or nil if the stack was empty or the
+	// top wasn't an int
+	StackTop *big.Int
+	// StackTopHash is the hash of the top of the data stack if it wasn't an
+	// int, since other values can be arbitrarily large
+	StackTopHash *common.Hash
+}
+
+// StepTracer is called with every instruction a machine executes, in order,
+// as the machine executes them, so it must not use the machine itself
+type StepTracer interface {
+	TraceStep(step ExecutionStep)
+}
+
+// StepTracerFunc lets an ordinary function be used as a StepTracer
+type StepTracerFunc func(step ExecutionStep)
+
+func (f StepTracerFunc) TraceStep(step ExecutionStep) {
+	f(step)
+}
+
+// NewExecutionStepFromReader reads a step in the format the AVM marshals it in
+func NewExecutionStepFromReader(rd io.Reader) (ExecutionStep, error) {
+	var header struct {
+		Segment uint64
+		PC      uint64
+		Opcode  value.Opcode
+		Gas     uint64
+		TopType uint8
+	}
+	if err := binary.Read(rd, binary.BigEndian, &header); err != nil {
+		return ExecutionStep{}, errors.Wrap(err, "error reading execution step")
+	}
+	step := ExecutionStep{
+		CodePointRef: CodePointRef{Segment: header.Segment, PC: header.PC},
+		Opcode:       header.Opcode,
+		Gas:          header.Gas,
+	}
+	if header.TopType == stackTopEmpty {
+		return step, nil
+	}
+	var top common.Hash
+	if _, err := io.ReadFull(rd, top[:]); err != nil {
+		return ExecutionStep{}, errors.Wrap(err, "error reading execution step stack top")
+	}
+	switch header.TopType {
+	case stackTopInt:
+		step.StackTop = new(big.Int).SetBytes(top[:])
+	case stackTopHash:
+		step.StackTopHash = &top
+	default:
+		return ExecutionStep{}, errors.Errorf("unknown stack top type %v", header.TopType)
+	}
+	return step, nil
+}
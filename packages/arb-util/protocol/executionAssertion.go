@@ -75,8 +75,9 @@ func parseSends(sendData []byte, sendCount uint64) ([][]byte, error) {
 func BytesArrayToVals(data []byte, valCount uint64) ([]value.Value, error) {
 	rd := bytes.NewReader(data)
 	vals := make([]value.Value, 0, valCount)
+	arena := value.NewTupleArena(0)
 	for i := uint64(0); i < valCount; i++ {
-		val, err := value.UnmarshalValue(rd)
+		val, err := arena.UnmarshalValue(rd)
 		if err != nil {
 			return nil, err
 		}
@@ -31,20 +31,19 @@ func NewMerkleTree(elements [][32]byte) *MerkleTree {
 	layers = append(layers, elements)
 	for len(layers[len(layers)-1]) > 1 {
 		elements := layers[len(layers)-1]
-		var nextLayer [][32]byte
-		for i := 0; i < len(elements); i++ {
-			if i%2 == 1 {
-				continue
-			}
-			if i+1 >= len(elements) {
-				nextLayer = append(nextLayer, elements[i])
-			} else {
-				data := hashing.SoliditySHA3(
-					hashing.Bytes32(elements[i]),
-					hashing.Bytes32(elements[i+1]),
-				)
-				nextLayer = append(nextLayer, data)
-			}
+		pairs := make([][]byte, len(elements)/2)
+		pairData := make([]byte, 0, len(pairs)*64)
+		for i := range pairs {
+			pairData = append(pairData, elements[2*i][:]...)
+			pairData = append(pairData, elements[2*i+1][:]...)
+			pairs[i] = pairData[i*64 : (i+1)*64]
+		}
+		nextLayer := make([][32]byte, 0, len(pairs)+1)
+		for _, h := range hashing.Sum256Batch(pairs) {
+			nextLayer = append(nextLayer, h)
+		}
+		if len(elements)%2 == 1 {
+			nextLayer = append(nextLayer, elements[len(elements)-1])
 		}
 		layers = append(layers, nextLayer)
 	}
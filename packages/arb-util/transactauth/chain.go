@@ -19,6 +19,7 @@ package transactauth
 import (
 	"context"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -26,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
@@ -149,7 +151,7 @@ func WaitForReceiptWithResultsAndReplaceByFee(
 				return arbTx, nil
 			}
 			var rawTx *types.Transaction
-			tipCap, tipCapErr := client.SuggestGasTipCap(ctx)
+			tipCap, maxFeeCap, tipCapErr := suggestReplacementFees(ctx, client, transactAuth)
 			if arbTx.Type() == types.DynamicFeeTxType && tipCapErr == nil {
 				block, err := client.HeaderByNumber(ctx, nil)
 				if err != nil {
@@ -168,6 +170,14 @@ func WaitForReceiptWithResultsAndReplaceByFee(
 				if feeCap.Cmp(minFeeCap) < 0 {
 					feeCap = minFeeCap
 				}
+				if maxFeeCap != nil && feeCap.Cmp(maxFeeCap) > 0 {
+					feeCap = maxFeeCap
+				}
+				if feeCap.Cmp(minFeeCap) < 0 {
+					// The oracle's maximum fee doesn't leave room for a
+					// replacement the L1 node would accept
+					return arbTx, nil
+				}
 				baseTx := &types.DynamicFeeTx{
 					ChainID:    arbTx.ChainId(),
 					Nonce:      arbTx.Nonce(),
@@ -185,6 +195,9 @@ func WaitForReceiptWithResultsAndReplaceByFee(
 				if err != nil {
 					return nil, err
 				}
+				if tipCapErr == nil && maxFeeCap != nil && gasPrice.Cmp(maxFeeCap) > 0 {
+					gasPrice = maxFeeCap
+				}
 				if gasPrice.Cmp(increaseByPercent(arbTx.GasPrice(), 10)) < 0 {
 					// We only replace by fee when we'd increase the fee by at least 10%
 					return arbTx, nil
@@ -204,7 +217,9 @@ func WaitForReceiptWithResultsAndReplaceByFee(
 				return nil, err
 			}
 
-			newTx, err := transactAuth.SendTransaction(ctx, signedTx, arbTx.Hash().String())
+			newTx, err := getSubmissionPolicy(transactAuth).Send(ctx, getTransactionFinder(transactAuth), signedTx, func() (*arbtransaction.ArbTransaction, error) {
+				return transactAuth.SendTransaction(ctx, signedTx, arbTx.Hash().String())
+			})
 			if err != nil {
 				return nil, err
 			}
@@ -219,31 +234,90 @@ func WaitForReceiptWithResultsAndReplaceByFee(
 			nonce:   arbTx.Nonce(),
 		}
 	}
+	tracker := getTransactionTracker(transactAuth)
+	tracker.setAction(arbTx.Nonce(), methodName)
 	receipt, err := waitForReceiptWithResultsSimpleInternal(ctx, receiptFetcher, arbTx, rbfInfo)
 	if err != nil {
 		logger.Warn().Err(err).Hex("tx", arbTx.Hash().Bytes()).Msg("error while waiting for transaction receipt")
 		return nil, errors.WithStack(err)
 	}
+	tracker.resolved(arbTx.Nonce())
+	if receipt != nil {
+		recordGasUsed(getMetricsRegistry(transactAuth), methodName, receipt)
+	}
 	if receipt != nil && receipt.Status != 1 {
 		logger.Warn().Hex("tx", arbTx.Hash().Bytes()).Msg("failed transaction")
-		callMsg := ethereum.CallMsg{
-			From:      from,
-			To:        arbTx.To(),
-			Gas:       arbTx.Gas(),
-			GasTipCap: arbTx.GasTipCap(),
-			GasFeeCap: arbTx.GasFeeCap(),
-			Value:     arbTx.Value(),
-			Data:      arbTx.Data(),
-		}
-		data, err := client.CallContract(ctx, callMsg, receipt.BlockNumber)
-		if err != nil {
-			return nil, errors.Wrapf(err, "transaction %v failed", methodName)
-		}
-		return nil, errors.Errorf("transaction %v failed with tx %v", methodName, string(data))
+		return nil, failedTransactionError(ctx, client, from, arbTx, receipt, methodName)
 	}
 	return receipt, nil
 }
 
+// suggestReplacementFees returns the tip to use for a replacement transaction
+// along with the highest fee cap the gas price oracle allows, which is nil if
+// it doesn't limit the fee cap
+func suggestReplacementFees(ctx context.Context, client ethutils.EthClient, transactAuth TransactAuth) (*big.Int, *big.Int, error) {
+	oracle := getGasPriceOracle(transactAuth)
+	if oracle == nil {
+		tipCap, err := client.SuggestGasTipCap(ctx)
+		return tipCap, nil, err
+	}
+	return oracle.SuggestGasTipCap(ctx, UrgencyFromContext(ctx))
+}
+
+func recordGasUsed(registry metrics.Registry, methodName string, receipt *types.Receipt) {
+	name := strings.ReplaceAll(strings.TrimSpace(methodName), " ", "_")
+	if name == "" {
+		name = "unknown"
+	}
+	metrics.GetOrRegisterCounter("arbitrum/ethbridge/tx/"+name+"/gas_used", registry).Inc(int64(receipt.GasUsed))
+	metrics.GetOrRegisterCounter("arbitrum/ethbridge/tx/"+name+"/count", registry).Inc(1)
+	if receipt.Status != 1 {
+		metrics.GetOrRegisterCounter("arbitrum/ethbridge/tx/"+name+"/failed", registry).Inc(1)
+	}
+}
+
+// failedTransactionError re-executes a reverted transaction at the block it
+// was included in so that the revert reason can be reported to the caller.
+// If the call no longer reverts, debug_traceTransaction is used as a fallback
+// when the client supports it.
+func failedTransactionError(
+	ctx context.Context,
+	client ethutils.EthClient,
+	from ethcommon.Address,
+	arbTx *arbtransaction.ArbTransaction,
+	receipt *types.Receipt,
+	methodName string,
+) error {
+	callMsg := ethereum.CallMsg{
+		From:      from,
+		To:        arbTx.To(),
+		Gas:       arbTx.Gas(),
+		GasTipCap: arbTx.GasTipCap(),
+		GasFeeCap: arbTx.GasFeeCap(),
+		Value:     arbTx.Value(),
+		Data:      arbTx.Data(),
+	}
+	data, callErr := client.CallContract(ctx, callMsg, receipt.BlockNumber)
+	reason, found := ethutils.RevertReason(data, callErr)
+	if !found {
+		if tracer, ok := client.(ethutils.TransactionTracer); ok {
+			output, err := tracer.TraceTransactionOutput(ctx, arbTx.Hash())
+			if err != nil {
+				logger.Debug().Err(err).Hex("tx", arbTx.Hash().Bytes()).Msg("unable to trace failed transaction")
+			} else {
+				reason, found = ethutils.RevertReason(output, nil)
+			}
+		}
+	}
+	if found {
+		return errors.Errorf("transaction %v failed with revert reason: %v", methodName, reason)
+	}
+	if callErr != nil {
+		return errors.Wrapf(callErr, "transaction %v failed", methodName)
+	}
+	return errors.Errorf("transaction %v failed with tx %v", methodName, string(data))
+}
+
 func WaitForReceiptWithResults(ctx context.Context, client ethutils.EthClient, from ethcommon.Address, tx *arbtransaction.ArbTransaction, methodName string, receiptFetcher ArbReceiptFetcher) (*types.Receipt, error) {
 	return WaitForReceiptWithResultsAndReplaceByFee(ctx, client, from, tx, methodName, nil, receiptFetcher)
 }
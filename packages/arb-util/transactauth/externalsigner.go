@@ -0,0 +1,65 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactauth
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// NewExternalSignerTransactor creates transact opts which forward all signing
+// requests to an external signer such as clef, so that the private key never
+// needs to be present on this host. If address is empty, the signer must
+// expose exactly one account.
+func NewExternalSignerTransactor(endpoint string, address string, chainId *big.Int) (*bind.TransactOpts, error) {
+	signer, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error connecting to external signer %v", endpoint)
+	}
+	var account accounts.Account
+	if len(address) != 0 {
+		if !ethcommon.IsHexAddress(address) {
+			return nil, errors.Errorf("invalid external signer address %v", address)
+		}
+		account = accounts.Account{Address: ethcommon.HexToAddress(address)}
+		if !signer.Contains(account) {
+			return nil, errors.Errorf("external signer does not manage account %v", address)
+		}
+	} else {
+		available := signer.Accounts()
+		if len(available) != 1 {
+			return nil, errors.Errorf("external signer has %v accounts, address must be specified", len(available))
+		}
+		account = available[0]
+	}
+	logger.Info().Str("address", account.Address.Hex()).Str("endpoint", endpoint).Msg("using external signer")
+	return &bind.TransactOpts{
+		From: account.Address,
+		Signer: func(from ethcommon.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if from != account.Address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return signer.SignTx(account, tx, chainId)
+		},
+	}, nil
+}
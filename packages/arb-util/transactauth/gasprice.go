@@ -0,0 +1,232 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactauth
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+type Urgency int
+
+const (
+	// UrgencyNormal is used for routine transactions such as confirmations
+	UrgencyNormal Urgency = iota
+	// UrgencyHigh is used for transactions which must be included before a
+	// deadline, such as challenge moves
+	UrgencyHigh
+)
+
+type urgencyKey struct{}
+
+// WithUrgency marks transactions sent with the returned context as having the
+// given urgency, which gas price oracles may use to bid higher
+func WithUrgency(ctx context.Context, urgency Urgency) context.Context {
+	return context.WithValue(ctx, urgencyKey{}, urgency)
+}
+
+func UrgencyFromContext(ctx context.Context) Urgency {
+	urgency, ok := ctx.Value(urgencyKey{}).(Urgency)
+	if !ok {
+		return UrgencyNormal
+	}
+	return urgency
+}
+
+// GasPriceOracle picks the priority fee for new transactions. A nil fee cap
+// leaves it to be derived from the current base fee.
+type GasPriceOracle interface {
+	SuggestGasTipCap(ctx context.Context, urgency Urgency) (tipCap *big.Int, feeCap *big.Int, err error)
+}
+
+func NewGasPriceOracle(client ethutils.EthClient, config configuration.GasPriceOracle) (GasPriceOracle, error) {
+	var oracle GasPriceOracle
+	switch config.Strategy {
+	case "node":
+		oracle = &NodeGasPriceOracle{client: client}
+	case "percentile":
+		if config.Percentile < 0 || config.Percentile > 100 {
+			return nil, errors.Errorf("invalid gas price percentile %v", config.Percentile)
+		}
+		if config.PercentileBlocks <= 0 {
+			return nil, errors.New("gas price percentile blocks must be positive")
+		}
+		oracle = NewPercentileGasPriceOracle(client, config.PercentileBlocks, config.Percentile)
+	default:
+		return nil, errors.Errorf("unknown gas price oracle strategy %v", config.Strategy)
+	}
+	if config.UrgentTipPercent > 0 && config.UrgentTipPercent != 100 {
+		oracle = &UrgencyGasPriceOracle{inner: oracle, urgentTipPercent: config.UrgentTipPercent}
+	}
+	if config.MaxFeeGwei > 0 {
+		maxFee, _ := new(big.Float).Mul(big.NewFloat(config.MaxFeeGwei), big.NewFloat(params.GWei)).Int(nil)
+		oracle = &CeilingGasPriceOracle{inner: oracle, maxFee: maxFee}
+	}
+	return oracle, nil
+}
+
+// NodeGasPriceOracle uses the priority fee suggested by the L1 node
+type NodeGasPriceOracle struct {
+	client ethutils.EthClient
+}
+
+func (o *NodeGasPriceOracle) SuggestGasTipCap(ctx context.Context, _ Urgency) (*big.Int, *big.Int, error) {
+	tip, err := o.client.SuggestGasTipCap(ctx)
+	return tip, nil, errors.WithStack(err)
+}
+
+// PercentileGasPriceOracle uses the given percentile of the priority fees paid
+// by transactions in recent blocks
+type PercentileGasPriceOracle struct {
+	client     ethutils.EthClient
+	blocks     int
+	percentile int
+
+	mutex      sync.Mutex
+	cacheBlock ethcommon.Hash
+	cacheTip   *big.Int
+}
+
+func NewPercentileGasPriceOracle(client ethutils.EthClient, blocks int, percentile int) *PercentileGasPriceOracle {
+	return &PercentileGasPriceOracle{
+		client:     client,
+		blocks:     blocks,
+		percentile: percentile,
+	}
+}
+
+func (o *PercentileGasPriceOracle) SuggestGasTipCap(ctx context.Context, _ Urgency) (*big.Int, *big.Int, error) {
+	latest, err := o.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.cacheTip != nil && o.cacheBlock == latest.Hash() {
+		return new(big.Int).Set(o.cacheTip), nil, nil
+	}
+
+	var tips []*big.Int
+	header := latest
+	for i := 0; i < o.blocks && header != nil; i++ {
+		block, err := o.client.BlockByHash(ctx, header.Hash())
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		for _, tx := range block.Transactions() {
+			tip, err := tx.EffectiveGasTip(block.BaseFee())
+			if err == nil {
+				tips = append(tips, tip)
+			}
+		}
+		if header.Number.Sign() == 0 {
+			break
+		}
+		header, err = o.client.HeaderByHash(ctx, header.ParentHash)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+	}
+	if len(tips) == 0 {
+		tip, err := o.client.SuggestGasTipCap(ctx)
+		return tip, nil, errors.WithStack(err)
+	}
+	sort.Slice(tips, func(i, j int) bool {
+		return tips[i].Cmp(tips[j]) < 0
+	})
+	tip := tips[(len(tips)-1)*o.percentile/100]
+	o.cacheBlock = latest.Hash()
+	o.cacheTip = tip
+	return new(big.Int).Set(tip), nil, nil
+}
+
+// UrgencyGasPriceOracle scales up the tip of urgent transactions
+type UrgencyGasPriceOracle struct {
+	inner            GasPriceOracle
+	urgentTipPercent int
+}
+
+func (o *UrgencyGasPriceOracle) SuggestGasTipCap(ctx context.Context, urgency Urgency) (*big.Int, *big.Int, error) {
+	tip, feeCap, err := o.inner.SuggestGasTipCap(ctx, urgency)
+	if err != nil || urgency != UrgencyHigh {
+		return tip, feeCap, err
+	}
+	tip = new(big.Int).Mul(tip, big.NewInt(int64(o.urgentTipPercent)))
+	tip.Div(tip, big.NewInt(100))
+	return tip, feeCap, nil
+}
+
+// CeilingGasPriceOracle never pays more than a fixed maximum fee per gas
+type CeilingGasPriceOracle struct {
+	inner  GasPriceOracle
+	maxFee *big.Int
+}
+
+func (o *CeilingGasPriceOracle) SuggestGasTipCap(ctx context.Context, urgency Urgency) (*big.Int, *big.Int, error) {
+	tip, feeCap, err := o.inner.SuggestGasTipCap(ctx, urgency)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tip.Cmp(o.maxFee) > 0 {
+		tip = new(big.Int).Set(o.maxFee)
+	}
+	if feeCap == nil || feeCap.Cmp(o.maxFee) > 0 {
+		feeCap = new(big.Int).Set(o.maxFee)
+	}
+	return tip, feeCap, nil
+}
+
+// SetGasPriceOracle configures auth to price new transactions with oracle.
+// Auths which manage their own fees, like fireblocks, are left unchanged.
+func SetGasPriceOracle(auth TransactAuth, oracle GasPriceOracle) {
+	if local, ok := auth.(*LocalTransactAuth); ok {
+		local.gasPriceOracle = oracle
+	}
+}
+
+func getGasPriceOracle(t TransactAuth) GasPriceOracle {
+	if local, ok := t.(*LocalTransactAuth); ok {
+		return local.gasPriceOracle
+	}
+	return nil
+}
+
+// applyGasPriceOracle fills in the fees of auth unless a fixed gas price was
+// configured
+func applyGasPriceOracle(ctx context.Context, t TransactAuth, auth *bind.TransactOpts) error {
+	oracle := getGasPriceOracle(t)
+	if oracle == nil || auth.GasPrice != nil {
+		return nil
+	}
+	tip, feeCap, err := oracle.SuggestGasTipCap(ctx, UrgencyFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	auth.GasTipCap = tip
+	auth.GasFeeCap = feeCap
+	return nil
+}
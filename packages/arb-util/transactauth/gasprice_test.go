@@ -0,0 +1,59 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactauth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+type fixedGasPriceOracle struct {
+	tip *big.Int
+}
+
+func (o fixedGasPriceOracle) SuggestGasTipCap(_ context.Context, _ Urgency) (*big.Int, *big.Int, error) {
+	return new(big.Int).Set(o.tip), nil, nil
+}
+
+func TestGasPriceOracleTiers(t *testing.T) {
+	oracle := &CeilingGasPriceOracle{
+		inner: &UrgencyGasPriceOracle{
+			inner:            fixedGasPriceOracle{tip: big.NewInt(100)},
+			urgentTipPercent: 300,
+		},
+		maxFee: big.NewInt(250),
+	}
+
+	ctx := context.Background()
+	tip, feeCap, err := oracle.SuggestGasTipCap(ctx, UrgencyFromContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tip.Cmp(big.NewInt(100)) != 0 || feeCap.Cmp(big.NewInt(250)) != 0 {
+		t.Errorf("unexpected normal fees tip %v cap %v", tip, feeCap)
+	}
+
+	urgentCtx := WithUrgency(ctx, UrgencyHigh)
+	tip, _, err = oracle.SuggestGasTipCap(urgentCtx, UrgencyFromContext(urgentCtx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tip.Cmp(big.NewInt(250)) != 0 {
+		t.Errorf("urgent tip should be capped at 250, got %v", tip)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
 type LocalTransactAuth struct {
@@ -34,6 +35,40 @@ type LocalTransactAuth struct {
 	auth   *bind.TransactOpts
 	signer bind.SignerFn
 	client ethutils.EthClient
+
+	gasPriceOracle GasPriceOracle
+	sender         TransactionSender
+	tracker        *TransactionTracker
+
+	submissionPolicy *SubmissionPolicy
+	metricsRegistry  metrics.Registry
+}
+
+// TransactionSender broadcasts signed transactions, allowing them to be routed
+// somewhere other than the L1 node's public mempool
+type TransactionSender interface {
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+func SetTransactionSender(auth TransactAuth, sender TransactionSender) {
+	if local, ok := auth.(*LocalTransactAuth); ok {
+		local.sender = sender
+	}
+}
+
+// SetMetricsRegistry records the gas used by auth's transactions in registry
+// instead of the default registry
+func SetMetricsRegistry(auth TransactAuth, registry metrics.Registry) {
+	if local, ok := auth.(*LocalTransactAuth); ok {
+		local.metricsRegistry = registry
+	}
+}
+
+func getMetricsRegistry(t TransactAuth) metrics.Registry {
+	if local, ok := t.(*LocalTransactAuth); ok && local.metricsRegistry != nil {
+		return local.metricsRegistry
+	}
+	return metrics.DefaultRegistry
 }
 
 func NewTransactAuthAdvanced(
@@ -62,7 +97,12 @@ func NewTransactAuth(
 	return NewTransactAuthAdvanced(ctx, client, auth, true)
 }
 func (ta *LocalTransactAuth) TransactionReceipt(ctx context.Context, tx *arbtransaction.ArbTransaction) (*types.Receipt, error) {
-	return ta.client.TransactionReceipt(ctx, tx.Hash())
+	receipt, err := ta.client.TransactionReceipt(ctx, tx.Hash())
+	if err == nil && receipt != nil {
+		// The transaction is no longer in flight, whoever is waiting for it
+		ta.tracker.resolved(tx.Nonce())
+	}
+	return receipt, err
 }
 
 func (ta *LocalTransactAuth) NonceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (uint64, error) {
@@ -70,7 +110,11 @@ func (ta *LocalTransactAuth) NonceAt(ctx context.Context, account ethcommon.Addr
 }
 
 func (ta *LocalTransactAuth) SendTransaction(ctx context.Context, tx *types.Transaction, replaceTxByHash string) (*arbtransaction.ArbTransaction, error) {
-	err := ta.client.SendTransaction(ctx, tx)
+	var sender TransactionSender = ta.client
+	if ta.sender != nil {
+		sender = ta.sender
+	}
+	err := sender.SendTransaction(ctx, tx)
 	if err != nil {
 		logger.Error().Err(err).Hex("data", tx.Data()).Msg("error sending transaction")
 		return nil, err
@@ -78,6 +122,7 @@ func (ta *LocalTransactAuth) SendTransaction(ctx context.Context, tx *types.Tran
 
 	logger.Debug().Hex("data", tx.Data()).Msg("sent transaction")
 	arbTx := arbtransaction.NewArbTransaction(tx)
+	ta.tracker.sent(tx, arbTx.Hash())
 	return arbTx, nil
 }
 
@@ -0,0 +1,310 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactauth
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
+)
+
+// Resolved entries older than this are dropped the next time the cache is saved
+const receiptCacheRetention = 24 * time.Hour
+
+// defaultResolveTimeout is how long Resolve waits for a pending transaction
+// to be mined or replaced before rebroadcasting it, and then before
+// abandoning it
+const defaultResolveTimeout = 10 * time.Minute
+
+type CachedTransaction struct {
+	Hash    ethcommon.Hash    `json:"hash"`
+	From    ethcommon.Address `json:"from"`
+	Nonce   uint64            `json:"nonce"`
+	Method  string            `json:"method"`
+	SentAt  time.Time         `json:"sentAt"`
+	Receipt *types.Receipt    `json:"receipt,omitempty"`
+	// RawTx is the signed transaction, kept so it can be rebroadcast if it's
+	// dropped from the mempool
+	RawTx hexutil.Bytes `json:"rawTx,omitempty"`
+	// Replaced is set when a transaction with the same nonce was mined instead
+	Replaced bool `json:"replaced,omitempty"`
+	// Abandoned is set when the transaction was neither mined nor replaced
+	// in time after a restart, even after being rebroadcast
+	Abandoned bool `json:"abandoned,omitempty"`
+}
+
+func (c *CachedTransaction) Resolved() bool {
+	return c.Receipt != nil || c.Replaced || c.Abandoned
+}
+
+// ReceiptCache persists the transactions this node has sent along with their
+// receipts once known, so that after a restart the node can tell whether a
+// transaction it sent before going down was already mined.
+type ReceiptCache struct {
+	mutex          sync.Mutex
+	filename       string
+	txs            map[ethcommon.Hash]*CachedTransaction
+	resolveTimeout time.Duration
+}
+
+func NewReceiptCache(filename string) (*ReceiptCache, error) {
+	cache := &ReceiptCache{
+		filename:       filename,
+		txs:            make(map[ethcommon.Hash]*CachedTransaction),
+		resolveTimeout: defaultResolveTimeout,
+	}
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read receipt cache")
+	}
+	var txs []*CachedTransaction
+	if err := json.Unmarshal(data, &txs); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal receipt cache")
+	}
+	for _, tx := range txs {
+		cache.txs[tx.Hash] = tx
+	}
+	return cache, nil
+}
+
+// SetResolveTimeout sets how long Resolve waits for each pending transaction
+// before rebroadcasting it, and again before abandoning it
+func (c *ReceiptCache) SetResolveTimeout(timeout time.Duration) {
+	c.resolveTimeout = timeout
+}
+
+// RecordSent must be called after a transaction is sent and before waiting
+// for its receipt
+func (c *ReceiptCache) RecordSent(from ethcommon.Address, tx *arbtransaction.ArbTransaction, methodName string) error {
+	var rawTx []byte
+	if signed := tx.Transaction(); signed != nil {
+		var err error
+		rawTx, err = signed.MarshalBinary()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.txs[tx.Hash()] = &CachedTransaction{
+		Hash:   tx.Hash(),
+		From:   from,
+		Nonce:  tx.Nonce(),
+		Method: methodName,
+		SentAt: time.Now(),
+		RawTx:  rawTx,
+	}
+	return c.save()
+}
+
+// RecordReceipt stores the receipt for a transaction. Any other pending
+// transactions from the same account with the same nonce, such as the
+// originals of a replace by fee, are marked as replaced.
+func (c *ReceiptCache) RecordReceipt(from ethcommon.Address, nonce uint64, receipt *types.Receipt) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for hash, tx := range c.txs {
+		if hash != receipt.TxHash && tx.From == from && tx.Nonce == nonce && !tx.Resolved() {
+			tx.Replaced = true
+		}
+	}
+	tx, ok := c.txs[receipt.TxHash]
+	if !ok {
+		tx = &CachedTransaction{
+			Hash:   receipt.TxHash,
+			From:   from,
+			Nonce:  nonce,
+			SentAt: time.Now(),
+		}
+		c.txs[receipt.TxHash] = tx
+	}
+	tx.Receipt = receipt
+	return c.save()
+}
+
+func (c *ReceiptCache) Get(hash ethcommon.Hash) *CachedTransaction {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	tx, ok := c.txs[hash]
+	if !ok {
+		return nil
+	}
+	txCopy := *tx
+	return &txCopy
+}
+
+// Pending returns the transactions which were sent but whose outcome isn't known
+func (c *ReceiptCache) Pending() []*CachedTransaction {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var pending []*CachedTransaction
+	for _, tx := range c.txs {
+		if !tx.Resolved() {
+			txCopy := *tx
+			pending = append(pending, &txCopy)
+		}
+	}
+	return pending
+}
+
+// Resolve waits for the outcome of every pending transaction in the cache,
+// returning the receipts of those which were mined. Pending transactions whose
+// nonce was consumed by another transaction are marked as replaced. A
+// transaction which is neither mined nor replaced within the resolve timeout
+// may have been dropped from the mempool, so it's rebroadcast if receiptFetcher
+// can send transactions, and abandoned if that doesn't resolve it either.
+func (c *ReceiptCache) Resolve(ctx context.Context, receiptFetcher ArbReceiptFetcher) ([]*types.Receipt, error) {
+	var receipts []*types.Receipt
+	for _, tx := range c.Pending() {
+		logger.Info().Hex("tx", tx.Hash.Bytes()).Str("method", tx.Method).Msg("checking transaction sent before restart")
+		receipt, err := c.resolveTransaction(ctx, receiptFetcher, tx)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			receipts = append(receipts, receipt)
+		}
+	}
+	return receipts, nil
+}
+
+func (c *ReceiptCache) resolveTransaction(ctx context.Context, receiptFetcher ArbReceiptFetcher, tx *CachedTransaction) (*types.Receipt, error) {
+	arbTx := arbtransaction.NewMockArbTx(tx.Hash)
+	deadline := time.Now().Add(c.resolveTimeout)
+	rebroadcast := false
+	for {
+		receipt, err := receiptFetcher.TransactionReceipt(ctx, arbTx)
+		if err != nil && err.Error() != ethereum.NotFound.Error() {
+			return nil, errors.WithStack(err)
+		}
+		if receipt != nil {
+			return receipt, c.RecordReceipt(tx.From, tx.Nonce, receipt)
+		}
+		nonce, err := receiptFetcher.NonceAt(ctx, tx.From, nil)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if nonce > tx.Nonce {
+			// Check for the receipt once more in case it was mined since the first check
+			receipt, err := receiptFetcher.TransactionReceipt(ctx, arbTx)
+			if err == nil && receipt != nil {
+				return receipt, c.RecordReceipt(tx.From, tx.Nonce, receipt)
+			}
+			logger.Info().Hex("tx", tx.Hash.Bytes()).Msg("transaction sent before restart was replaced")
+			return nil, c.markResolved(tx.Hash, func(cached *CachedTransaction) { cached.Replaced = true })
+		}
+		if time.Now().After(deadline) {
+			if rebroadcast || !c.rebroadcast(ctx, receiptFetcher, tx) {
+				logger.
+					Warn().
+					Hex("tx", tx.Hash.Bytes()).
+					Str("method", tx.Method).
+					Uint64("nonce", tx.Nonce).
+					Msg("abandoning transaction sent before restart which was neither mined nor replaced")
+				return nil, c.markResolved(tx.Hash, func(cached *CachedTransaction) { cached.Abandoned = true })
+			}
+			rebroadcast = true
+			deadline = time.Now().Add(c.resolveTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// rebroadcast sends tx again, in case it was dropped from the mempool,
+// returning whether it's worth waiting for it again
+func (c *ReceiptCache) rebroadcast(ctx context.Context, receiptFetcher ArbReceiptFetcher, tx *CachedTransaction) bool {
+	sender, ok := receiptFetcher.(TransactAuth)
+	if !ok || len(tx.RawTx) == 0 {
+		return false
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(tx.RawTx); err != nil {
+		logger.Warn().Err(err).Hex("tx", tx.Hash.Bytes()).Msg("failed to decode transaction sent before restart")
+		return false
+	}
+	if signed.Hash() != tx.Hash {
+		// Signed elsewhere, such as by fireblocks, so sending it again would
+		// create a different transaction
+		return false
+	}
+	logger.Info().Hex("tx", tx.Hash.Bytes()).Str("method", tx.Method).Msg("rebroadcasting transaction sent before restart")
+	if _, err := sender.SendTransaction(ctx, signed, ""); err != nil && !isAlreadySubmittedError(err) {
+		logger.Warn().Err(err).Hex("tx", tx.Hash.Bytes()).Msg("failed to rebroadcast transaction sent before restart")
+		return false
+	}
+	return true
+}
+
+func (c *ReceiptCache) markResolved(hash ethcommon.Hash, update func(*CachedTransaction)) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	update(c.txs[hash])
+	return c.save()
+}
+
+// save must be called with the mutex held
+func (c *ReceiptCache) save() error {
+	txs := make([]*CachedTransaction, 0, len(c.txs))
+	for hash, tx := range c.txs {
+		if tx.Resolved() && time.Since(tx.SentAt) > receiptCacheRetention {
+			delete(c.txs, hash)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	data, err := json.Marshal(txs)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// Write to a temporary file first so a crash never leaves a partial cache
+	tmpFile, err := ioutil.TempFile(filepath.Dir(c.filename), filepath.Base(c.filename)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create receipt cache file")
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return errors.Wrap(err, "failed to write receipt cache")
+	}
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return errors.Wrap(err, "failed to sync receipt cache")
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmpFile.Name(), c.filename))
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactauth
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
+)
+
+func TestReceiptCachePersistence(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "receipts.json")
+	cache, err := NewReceiptCache(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := ethcommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := ethcommon.HexToAddress("0x2222222222222222222222222222222222222222")
+	original := arbtransaction.NewArbTransaction(types.NewTransaction(5, to, nil, 21000, nil, nil))
+	replacement := arbtransaction.NewArbTransaction(types.NewTransaction(5, to, nil, 22000, nil, nil))
+	other := arbtransaction.NewArbTransaction(types.NewTransaction(6, to, nil, 21000, nil, nil))
+	for _, tx := range []*arbtransaction.ArbTransaction{original, replacement, other} {
+		if err := cache.RecordSent(from, tx, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	receipt := &types.Receipt{TxHash: replacement.Hash(), Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{}}
+	if err := cache.RecordReceipt(from, 5, receipt); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewReceiptCache(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx := reloaded.Get(original.Hash()); tx == nil || !tx.Replaced {
+		t.Error("original transaction should be marked as replaced")
+	}
+	if tx := reloaded.Get(replacement.Hash()); tx == nil || tx.Receipt == nil || tx.Receipt.Status != types.ReceiptStatusSuccessful {
+		t.Error("replacement transaction should have its receipt")
+	}
+	pending := reloaded.Pending()
+	if len(pending) != 1 || pending[0].Hash != other.Hash() {
+		t.Errorf("expected only the unrelated transaction to be pending, got %v", pending)
+	}
+}
+
+// droppedTxFetcher behaves as if every transaction was dropped from the mempool
+type droppedTxFetcher struct {
+	nonce uint64
+}
+
+func (f droppedTxFetcher) TransactionReceipt(context.Context, *arbtransaction.ArbTransaction) (*types.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+func (f droppedTxFetcher) NonceAt(context.Context, ethcommon.Address, *big.Int) (uint64, error) {
+	return f.nonce, nil
+}
+
+func TestReceiptCacheAbandonsDroppedTransaction(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "receipts.json")
+	cache, err := NewReceiptCache(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.SetResolveTimeout(0)
+	from := ethcommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := ethcommon.HexToAddress("0x2222222222222222222222222222222222222222")
+	dropped := arbtransaction.NewArbTransaction(types.NewTransaction(5, to, nil, 21000, nil, nil))
+	if err := cache.RecordSent(from, dropped, "test"); err != nil {
+		t.Fatal(err)
+	}
+	receipts, err := cache.Resolve(context.Background(), droppedTxFetcher{nonce: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(receipts) != 0 {
+		t.Error("dropped transaction shouldn't have a receipt")
+	}
+	reloaded, err := NewReceiptCache(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx := reloaded.Get(dropped.Hash()); tx == nil || !tx.Abandoned || tx.Method != "test" || len(tx.RawTx) == 0 {
+		t.Errorf("dropped transaction should have been abandoned, got %+v", tx)
+	}
+	if len(reloaded.Pending()) != 0 {
+		t.Error("abandoned transaction shouldn't be pending")
+	}
+}
@@ -0,0 +1,265 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactauth
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+)
+
+var ErrCircuitOpen = errors.New("on-chain actions paused after repeated transaction failures")
+
+// Errors which won't go away by sending the same transaction again
+var permanentSendErrors = []string{
+	"nonce too low",
+	"already known",
+	"insufficient funds",
+	"execution reverted",
+	"underpriced",
+	"exceeds block gas limit",
+	"intrinsic gas too low",
+	"fee cap less than block base fee",
+}
+
+// Errors which, when retrying, mean an earlier attempt reached the node even
+// though sending it appeared to fail
+var alreadySubmittedErrors = []string{
+	"already known",
+}
+
+func isAlreadySubmittedError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, substr := range alreadySubmittedErrors {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionFinder looks up transactions known to the L1 node
+type TransactionFinder interface {
+	TransactionByHash(ctx context.Context, hash ethcommon.Hash) (tx *types.Transaction, isPending bool, err error)
+}
+
+func getTransactionFinder(t TransactAuth) TransactionFinder {
+	if local, ok := t.(*LocalTransactAuth); ok {
+		return local.client
+	}
+	return nil
+}
+
+// wasSubmitted returns whether the node knows about tx after a retry failed
+// with a low nonce, which may mean an earlier attempt was accepted but could
+// equally mean a different transaction used the nonce
+func wasSubmitted(ctx context.Context, finder TransactionFinder, tx *types.Transaction, err error) bool {
+	if finder == nil || !strings.Contains(strings.ToLower(err.Error()), "nonce too low") {
+		return false
+	}
+	found, _, lookupErr := finder.TransactionByHash(ctx, tx.Hash())
+	if lookupErr != nil && !errors.Is(lookupErr, ethereum.NotFound) {
+		logger.Warn().Err(lookupErr).Hex("tx", tx.Hash().Bytes()).Msg("failed to look up transaction after nonce too low")
+	}
+	return lookupErr == nil && found != nil
+}
+
+func isRetryableSendError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, substr := range permanentSendErrors {
+		if strings.Contains(msg, substr) {
+			return false
+		}
+	}
+	return true
+}
+
+// SubmissionPolicy retries transaction submissions which fail with transient
+// errors and pauses all submissions after too many consecutive failures, or
+// when a transaction would cost far more than it should. Pausing surfaces the
+// problem to operators rather than draining the wallet.
+type SubmissionPolicy struct {
+	mutex               sync.Mutex
+	maxAttempts         int
+	retryDelay          time.Duration
+	breakerFailures     int
+	breakerCooldown     time.Duration
+	maxTxFee            *big.Int
+	consecutiveFailures int
+	openUntil           time.Time
+	trippedCounter      metrics.Counter
+}
+
+// NewSubmissionPolicy creates a policy which counts the times its breaker
+// trips in registry. It returns nil, which just sends each transaction once,
+// if config disables retries, the breaker and the fee limit.
+func NewSubmissionPolicy(config configuration.TxSubmission, registry metrics.Registry) *SubmissionPolicy {
+	var maxTxFee *big.Int
+	if config.MaxTxFeeEth > 0 {
+		maxTxFee, _ = new(big.Float).Mul(big.NewFloat(config.MaxTxFeeEth), big.NewFloat(1e18)).Int(nil)
+	}
+	maxAttempts := config.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	if maxAttempts == 1 && config.BreakerFailures <= 0 && maxTxFee == nil {
+		return nil
+	}
+	return &SubmissionPolicy{
+		maxAttempts:     maxAttempts,
+		retryDelay:      config.RetryDelay,
+		breakerFailures: config.BreakerFailures,
+		breakerCooldown: config.BreakerCooldown,
+		maxTxFee:        maxTxFee,
+		trippedCounter:  metrics.GetOrRegisterCounter("arbitrum/transactauth/breaker/tripped", registry),
+	}
+}
+
+// SetSubmissionPolicy makes auth send its transactions through policy. Only
+// locally signed transactions can be retried safely, as other signers such as
+// Fireblocks pick the nonce themselves, so it fails for any other auth unless
+// policy is nil.
+func SetSubmissionPolicy(auth TransactAuth, policy *SubmissionPolicy) error {
+	if local, ok := auth.(*LocalTransactAuth); ok {
+		local.submissionPolicy = policy
+		return nil
+	}
+	if policy != nil {
+		return errors.New("transaction submission policy requires a local wallet, set tx-submission.max-attempts to 1, tx-submission.breaker-failures to 0 and tx-submission.max-tx-fee-eth to 0 to disable it")
+	}
+	return nil
+}
+
+func getSubmissionPolicy(t TransactAuth) *SubmissionPolicy {
+	if local, ok := t.(*LocalTransactAuth); ok {
+		return local.submissionPolicy
+	}
+	return nil
+}
+
+// Send sends tx through send, retrying transient failures. If a retry finds
+// that tx was already submitted, it is returned so that the caller waits for
+// its receipt. A retry failing with a low nonce only counts as submitted if
+// finder, which may be nil, knows the transaction. A nil policy just sends
+// the transaction once.
+func (p *SubmissionPolicy) Send(
+	ctx context.Context,
+	finder TransactionFinder,
+	tx *types.Transaction,
+	send func() (*arbtransaction.ArbTransaction, error),
+) (*arbtransaction.ArbTransaction, error) {
+	if p == nil {
+		return send()
+	}
+	if err := p.allow(); err != nil {
+		return nil, err
+	}
+	// Refusing one transaction's fee doesn't mean L1 is failing, so it
+	// doesn't pause other submissions
+	if err := p.checkFee(tx); err != nil {
+		return nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		arbTx, err := send()
+		if err == nil {
+			p.recordSuccess()
+			return arbTx, nil
+		}
+		if attempt > 1 && (isAlreadySubmittedError(err) || wasSubmitted(ctx, finder, tx, err)) {
+			logger.Info().Err(err).Hex("tx", tx.Hash().Bytes()).Msg("transaction already submitted by an earlier attempt")
+			p.recordSuccess()
+			return arbtransaction.NewArbTransaction(tx), nil
+		}
+		if !isRetryableSendError(err) {
+			return nil, err
+		}
+		if attempt >= p.maxAttempts {
+			p.recordFailure(err)
+			return nil, err
+		}
+		logger.Warn().Err(err).Int("attempt", attempt).Msg("retrying transaction submission")
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(p.retryDelay):
+		}
+	}
+}
+
+func (p *SubmissionPolicy) allow() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if time.Now().Before(p.openUntil) {
+		return errors.Wrapf(ErrCircuitOpen, "resuming at %v", p.openUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (p *SubmissionPolicy) checkFee(tx *types.Transaction) error {
+	if p.maxTxFee == nil {
+		return nil
+	}
+	// Cost is the gas limit at the maximum fee per gas plus the value sent
+	maxFee := new(big.Int).Sub(tx.Cost(), tx.Value())
+	if maxFee.Cmp(p.maxTxFee) > 0 {
+		return errors.Errorf("transaction maximum fee %v wei exceeds limit %v wei, fee estimate is likely wrong", maxFee, p.maxTxFee)
+	}
+	return nil
+}
+
+func (p *SubmissionPolicy) recordSuccess() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.consecutiveFailures = 0
+}
+
+func (p *SubmissionPolicy) recordFailure(err error) {
+	p.mutex.Lock()
+	p.consecutiveFailures++
+	failures := p.consecutiveFailures
+	p.mutex.Unlock()
+	if p.breakerFailures > 0 && failures >= p.breakerFailures {
+		p.trip(errors.Wrapf(err, "%v consecutive transaction submission failures", failures))
+	}
+}
+
+func (p *SubmissionPolicy) trip(err error) {
+	p.mutex.Lock()
+	p.openUntil = time.Now().Add(p.breakerCooldown)
+	p.consecutiveFailures = 0
+	p.mutex.Unlock()
+	p.trippedCounter.Inc(1)
+	logger.
+		Error().
+		Err(err).
+		Dur("cooldown", p.breakerCooldown).
+		Msg("pausing on-chain actions, check the L1 node and wallet")
+}
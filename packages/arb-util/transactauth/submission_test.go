@@ -0,0 +1,195 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactauth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestSubmissionPolicyBreaker(t *testing.T) {
+	ctx := context.Background()
+	registry := metrics.NewRegistry()
+	policy := NewSubmissionPolicy(configuration.TxSubmission{
+		MaxAttempts:     2,
+		BreakerFailures: 2,
+		BreakerCooldown: time.Hour,
+		MaxTxFeeEth:     1,
+	}, registry)
+	tx := types.NewTransaction(0, ethcommon.Address{}, nil, 21000, big.NewInt(1e9), nil)
+
+	attempts := 0
+	failingSend := func() (*arbtransaction.ArbTransaction, error) {
+		attempts++
+		return nil, errors.New("connection refused")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := policy.Send(ctx, nil, tx, failingSend); err == nil {
+			t.Fatal("expected send to fail")
+		}
+	}
+	if attempts != 4 {
+		t.Errorf("expected 4 attempts, got %v", attempts)
+	}
+	_, err := policy.Send(ctx, nil, tx, func() (*arbtransaction.ArbTransaction, error) {
+		t.Fatal("transaction sent while breaker open")
+		return nil, nil
+	})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected breaker to be open, got %v", err)
+	}
+	if tripped := metrics.GetOrRegisterCounter("arbitrum/transactauth/breaker/tripped", registry).Count(); tripped != 1 {
+		t.Errorf("expected breaker to have tripped once in the given registry, got %v", tripped)
+	}
+}
+
+func TestSubmissionPolicyMaxFee(t *testing.T) {
+	policy := NewSubmissionPolicy(configuration.TxSubmission{MaxAttempts: 1, BreakerCooldown: time.Hour, MaxTxFeeEth: 0.01}, metrics.NewRegistry())
+	// 1,000,000 gas at 100 gwei costs 0.1 ETH
+	tx := types.NewTransaction(0, ethcommon.Address{}, big.NewInt(5e18), 1_000_000, big.NewInt(100e9), nil)
+	_, err := policy.Send(context.Background(), nil, tx, func() (*arbtransaction.ArbTransaction, error) {
+		t.Fatal("transaction with excessive fee sent")
+		return nil, nil
+	})
+	if err == nil {
+		t.Error("expected excessive fee to be rejected")
+	}
+
+	// Other transactions are still sent
+	cheapTx := types.NewTransaction(1, ethcommon.Address{}, nil, 21000, big.NewInt(1e9), nil)
+	sent := false
+	_, err = policy.Send(context.Background(), nil, cheapTx, func() (*arbtransaction.ArbTransaction, error) {
+		sent = true
+		return arbtransaction.NewArbTransaction(cheapTx), nil
+	})
+	test.FailIfError(t, err)
+	if !sent {
+		t.Error("transaction not sent after another's fee was refused")
+	}
+}
+
+func TestSubmissionPolicyAlreadySubmitted(t *testing.T) {
+	ctx := context.Background()
+	policy := NewSubmissionPolicy(configuration.TxSubmission{MaxAttempts: 3}, metrics.NewRegistry())
+	tx := types.NewTransaction(0, ethcommon.Address{}, nil, 21000, big.NewInt(1e9), nil)
+
+	// The first attempt reached the node but the response was lost
+	attempts := 0
+	arbTx, err := policy.Send(ctx, nil, tx, func() (*arbtransaction.ArbTransaction, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("connection reset by peer")
+		}
+		return nil, errors.New("already known")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arbTx.Hash() != tx.Hash() {
+		t.Errorf("expected hash of submitted transaction, got %v", arbTx.Hash())
+	}
+
+	// Without an earlier attempt, a low nonce is a real failure
+	_, err = policy.Send(ctx, nil, tx, func() (*arbtransaction.ArbTransaction, error) {
+		return nil, errors.New("nonce too low")
+	})
+	if err == nil {
+		t.Error("expected nonce too low to fail the first attempt")
+	}
+}
+
+// knownTransactions is a TransactionFinder which knows the given transactions
+type knownTransactions map[ethcommon.Hash]*types.Transaction
+
+func (k knownTransactions) TransactionByHash(_ context.Context, hash ethcommon.Hash) (*types.Transaction, bool, error) {
+	tx, ok := k[hash]
+	if !ok {
+		return nil, false, ethereum.NotFound
+	}
+	return tx, true, nil
+}
+
+func TestSubmissionPolicyNonceTooLow(t *testing.T) {
+	ctx := context.Background()
+	policy := NewSubmissionPolicy(configuration.TxSubmission{MaxAttempts: 3}, metrics.NewRegistry())
+	tx := types.NewTransaction(0, ethcommon.Address{}, nil, 21000, big.NewInt(1e9), nil)
+	other := types.NewTransaction(0, ethcommon.Address{}, nil, 21000, big.NewInt(2e9), nil)
+
+	lostResponse := func() func() (*arbtransaction.ArbTransaction, error) {
+		attempts := 0
+		return func() (*arbtransaction.ArbTransaction, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, errors.New("connection reset by peer")
+			}
+			return nil, errors.New("nonce too low")
+		}
+	}
+
+	// A different transaction used the nonce
+	if _, err := policy.Send(ctx, knownTransactions{other.Hash(): other}, tx, lostResponse()); err == nil {
+		t.Error("expected nonce too low to fail when the transaction isn't known")
+	}
+	if _, err := policy.Send(ctx, nil, tx, lostResponse()); err == nil {
+		t.Error("expected nonce too low to fail when the transaction can't be looked up")
+	}
+
+	// The first attempt reached the node, which has since mined it
+	arbTx, err := policy.Send(ctx, knownTransactions{tx.Hash(): tx}, tx, lostResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arbTx.Hash() != tx.Hash() {
+		t.Errorf("expected hash of submitted transaction, got %v", arbTx.Hash())
+	}
+}
+
+// remoteAuth stands in for an auth which signs transactions elsewhere
+type remoteAuth struct {
+	TransactAuth
+}
+
+func TestSetSubmissionPolicyRequiresLocalAuth(t *testing.T) {
+	policy := NewSubmissionPolicy(configuration.TxSubmission{MaxAttempts: 3}, metrics.NewRegistry())
+	if err := SetSubmissionPolicy(remoteAuth{}, policy); err == nil {
+		t.Error("policy attached to an auth which can't apply it")
+	}
+
+	disabled := NewSubmissionPolicy(configuration.TxSubmission{MaxAttempts: 1}, metrics.NewRegistry())
+	if disabled != nil {
+		t.Fatal("expected disabled policy to be nil")
+	}
+	test.FailIfError(t, SetSubmissionPolicy(remoteAuth{}, disabled))
+
+	local := &LocalTransactAuth{}
+	test.FailIfError(t, SetSubmissionPolicy(local, policy))
+	if getSubmissionPolicy(local) != policy {
+		t.Error("policy not attached to local auth")
+	}
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactauth
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type OutstandingTransaction struct {
+	Action       string         `json:"action"`
+	Hash         ethcommon.Hash `json:"hash"`
+	Nonce        uint64         `json:"nonce"`
+	GasPrice     *hexutil.Big   `json:"gasPrice,omitempty"`
+	GasTipCap    *hexutil.Big   `json:"gasTipCap,omitempty"`
+	GasFeeCap    *hexutil.Big   `json:"gasFeeCap,omitempty"`
+	FirstSent    time.Time      `json:"firstSent"`
+	LastSent     time.Time      `json:"lastSent"`
+	Replacements int            `json:"replacements"`
+	// AgeSeconds is the time since the first transaction with this nonce was sent
+	AgeSeconds uint64 `json:"ageSeconds"`
+}
+
+// TransactionTracker keeps track of the transactions sent by a TransactAuth
+// which haven't been mined yet. Replacements are tracked under the nonce of
+// the transaction they replace.
+type TransactionTracker struct {
+	mutex sync.Mutex
+	txs   map[uint64]*OutstandingTransaction
+}
+
+func NewTransactionTracker() *TransactionTracker {
+	return &TransactionTracker{
+		txs: make(map[uint64]*OutstandingTransaction),
+	}
+}
+
+func SetTransactionTracker(auth TransactAuth, tracker *TransactionTracker) {
+	if local, ok := auth.(*LocalTransactAuth); ok {
+		local.tracker = tracker
+	}
+}
+
+func getTransactionTracker(t TransactAuth) *TransactionTracker {
+	if local, ok := t.(*LocalTransactAuth); ok {
+		return local.tracker
+	}
+	return nil
+}
+
+// Outstanding returns the transactions in flight ordered by nonce
+func (t *TransactionTracker) Outstanding() []OutstandingTransaction {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	txs := make([]OutstandingTransaction, 0, len(t.txs))
+	for _, tx := range t.txs {
+		entry := *tx
+		entry.AgeSeconds = uint64(time.Since(tx.FirstSent) / time.Second)
+		txs = append(txs, entry)
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].Nonce < txs[j].Nonce
+	})
+	return txs
+}
+
+func (t *TransactionTracker) sent(tx *types.Transaction, hash ethcommon.Hash) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	now := time.Now()
+	entry, ok := t.txs[tx.Nonce()]
+	if ok {
+		entry.Replacements++
+	} else {
+		entry = &OutstandingTransaction{Nonce: tx.Nonce(), FirstSent: now}
+		t.txs[tx.Nonce()] = entry
+	}
+	entry.Hash = hash
+	entry.LastSent = now
+	entry.GasPrice = nil
+	entry.GasTipCap = nil
+	entry.GasFeeCap = nil
+	if tx.Type() == types.DynamicFeeTxType {
+		entry.GasTipCap = (*hexutil.Big)(new(big.Int).Set(tx.GasTipCap()))
+		entry.GasFeeCap = (*hexutil.Big)(new(big.Int).Set(tx.GasFeeCap()))
+	} else if tx.GasPrice() != nil {
+		entry.GasPrice = (*hexutil.Big)(new(big.Int).Set(tx.GasPrice()))
+	}
+}
+
+func (t *TransactionTracker) setAction(nonce uint64, action string) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if entry, ok := t.txs[nonce]; ok {
+		entry.Action = action
+	}
+}
+
+// resolved removes every transaction with a nonce up to and including nonce
+func (t *TransactionTracker) resolved(nonce uint64) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for txNonce := range t.txs {
+		if txNonce <= nonce {
+			delete(t.txs, txNonce)
+		}
+	}
+}
+
+// OutstandingTransactions lists the in flight transactions of auth if it has
+// a tracker set
+func OutstandingTransactions(auth TransactAuth) []OutstandingTransaction {
+	tracker := getTransactionTracker(auth)
+	if tracker == nil {
+		return nil
+	}
+	return tracker.Outstanding()
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactauth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/arbtransaction"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/ethutils"
+)
+
+// receiptClient only knows the receipts of mined transactions
+type receiptClient struct {
+	ethutils.EthClient
+	mined map[ethcommon.Hash]bool
+}
+
+func (c *receiptClient) TransactionReceipt(_ context.Context, txHash ethcommon.Hash) (*types.Receipt, error) {
+	if !c.mined[txHash] {
+		return nil, ethereum.NotFound
+	}
+	return &types.Receipt{TxHash: txHash, Status: 1}, nil
+}
+
+func TestTransactionTrackerEviction(t *testing.T) {
+	ctx := context.Background()
+	client := &receiptClient{mined: make(map[ethcommon.Hash]bool)}
+	tracker := NewTransactionTracker()
+	auth := &LocalTransactAuth{client: client, tracker: tracker}
+
+	var txs []*arbtransaction.ArbTransaction
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		tx := types.NewTransaction(nonce, ethcommon.Address{}, nil, 21000, big.NewInt(1e9), nil)
+		tracker.sent(tx, tx.Hash())
+		txs = append(txs, arbtransaction.NewArbTransaction(tx))
+	}
+	replacement := types.NewTransaction(2, ethcommon.Address{}, nil, 21000, big.NewInt(2e9), nil)
+	tracker.sent(replacement, replacement.Hash())
+
+	outstanding := tracker.Outstanding()
+	if len(outstanding) != 3 {
+		t.Fatalf("expected 3 outstanding transactions, got %v", len(outstanding))
+	}
+	if outstanding[2].Replacements != 1 || outstanding[2].Hash != replacement.Hash() {
+		t.Errorf("replacement not tracked under its nonce: %+v", outstanding[2])
+	}
+
+	// Checking a transaction which hasn't been mined leaves it in flight
+	if _, err := auth.TransactionReceipt(ctx, txs[1]); err == nil {
+		t.Fatal("expected missing receipt")
+	}
+	if len(tracker.Outstanding()) != 3 {
+		t.Fatal("transaction evicted without a receipt")
+	}
+
+	// Seeing a receipt evicts it along with every earlier nonce
+	client.mined[txs[1].Hash()] = true
+	if _, err := auth.TransactionReceipt(ctx, txs[1]); err != nil {
+		t.Fatal(err)
+	}
+	outstanding = tracker.Outstanding()
+	if len(outstanding) != 1 || outstanding[0].Nonce != 2 {
+		t.Errorf("expected only nonce 2 outstanding, got %+v", outstanding)
+	}
+}
@@ -61,6 +61,28 @@ func getNonce(ctx context.Context, client ethutils.EthClient, auth *bind.Transac
 	return nil
 }
 
+// ResyncNonce refetches the pending nonce of t's account from client, for
+// when another process may have sent transactions from the same account since
+// t last did
+func ResyncNonce(ctx context.Context, client ethutils.EthClient, t TransactAuth) error {
+	var auth *bind.TransactOpts
+	switch ta := t.(type) {
+	case *LocalTransactAuth:
+		auth = ta.auth
+	case *FireblocksTransactAuth:
+		auth = ta.auth
+	default:
+		return errors.Errorf("can't resync nonce of %T", t)
+	}
+	nonce, err := client.PendingNonceAt(ctx, auth.From)
+	if err != nil {
+		return errors.Wrap(err, "failed to get nonce")
+	}
+	// Every copy made by GetAuth shares the nonce, so it's updated in place
+	auth.Nonce.SetUint64(nonce)
+	return nil
+}
+
 func makeContract(
 	ctx context.Context,
 	t TransactAuth,
@@ -106,6 +128,11 @@ func makeContractImpl(
 	auth *bind.TransactOpts,
 	contractFunc func(auth *bind.TransactOpts) (ethcommon.Address, *types.Transaction, interface{}, error),
 ) (ethcommon.Address, *arbtransaction.ArbTransaction, error) {
+	if err := applyGasPriceOracle(ctx, t, auth); err != nil {
+		logger.Error().Err(err).Msg("error getting gas price")
+		return ethcommon.Address{}, nil, err
+	}
+
 	// Form transaction without sending it
 	auth.NoSend = true
 	addr, tx, _, err := contractFunc(auth)
@@ -117,13 +144,15 @@ func makeContractImpl(
 	}
 
 	// Actually send transaction
-	arbTx, err := t.SendTransaction(ctx, tx, "")
+	arbTx, err := getSubmissionPolicy(t).Send(ctx, getTransactionFinder(t), tx, func() (*arbtransaction.ArbTransaction, error) {
+		return t.SendTransaction(ctx, tx, "")
+	})
 	if err != nil {
 		ev := logger.
 			Error().
 			Err(err).
 			Str("nonce", auth.Nonce.String()).
-			Hex("sender", auth.From.Bytes()).
+			Str("sender", auth.From.Hex()).
 			Hex("to", tx.To().Bytes()).
 			Hex("data", tx.Data()).
 			Str("nonce", auth.Nonce.String())
@@ -146,7 +175,7 @@ func makeContractImpl(
 		return addr, nil, err
 	}
 
-	logger.Info().Str("nonce", auth.Nonce.String()).Hex("sender", auth.From.Bytes()).Msg("transaction sent")
+	logger.Info().Str("nonce", auth.Nonce.String()).Str("sender", auth.From.Hex()).Msg("transaction sent")
 
 	return addr, arbTx, nil
 }
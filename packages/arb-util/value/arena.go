@@ -0,0 +1,103 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// How many tuples an arena allocates at once if not configured
+const defaultArenaSlabSize = 1024
+
+// TupleArena allocates tuples, and the slices holding their contents, from
+// slabs rather than one at a time. This cuts down on allocations and GC work
+// when many tuples are built at once, such as when unmarshalling a batch of
+// logs during catch up. A slab is only freed once none of the tuples
+// allocated from it are reachable, so an arena should only be used for
+// values which are discarded together. A nil arena allocates each tuple
+// separately. A TupleArena isn't safe for concurrent use.
+type TupleArena struct {
+	slabSize int
+	tuples   []TupleValue
+	contents []Value
+}
+
+// NewTupleArena creates an arena which allocates slabSize tuples at a time,
+// or a default number if slabSize isn't positive
+func NewTupleArena(slabSize int) *TupleArena {
+	if slabSize <= 0 {
+		slabSize = defaultArenaSlabSize
+	}
+	return &TupleArena{slabSize: slabSize}
+}
+
+// allocContents returns a slice for the contents of a tuple of the given
+// size. Its capacity is limited to its length so that appending to it can't
+// overwrite the contents of another tuple.
+func (a *TupleArena) allocContents(size int) []Value {
+	if a == nil || size == 0 {
+		return make([]Value, size)
+	}
+	if size > len(a.contents) {
+		// The rest of the old slab is dropped, which wastes less than a
+		// tuple's worth of space
+		a.contents = make([]Value, a.slabSize*2)
+	}
+	contents := a.contents[:size:size]
+	a.contents = a.contents[size:]
+	return contents
+}
+
+// newTuple creates a tuple which owns contents, which must be a valid size
+func (a *TupleArena) newTuple(contents []Value) *TupleValue {
+	if a == nil {
+		return newTuple(contents)
+	}
+	if len(a.tuples) == 0 {
+		a.tuples = make([]TupleValue, a.slabSize)
+	}
+	tup := &a.tuples[0]
+	a.tuples = a.tuples[1:]
+	tup.contents = contents
+	tup.size = tup.internalSize()
+	return tup
+}
+
+func (a *TupleArena) NewTuple2(value1 Value, value2 Value) *TupleValue {
+	contents := a.allocContents(2)
+	contents[0] = value1
+	contents[1] = value2
+	return a.newTuple(contents)
+}
+
+// NewTupleFromSlice creates a tuple in the arena with a copy of slice
+func (a *TupleArena) NewTupleFromSlice(slice []Value) (*TupleValue, error) {
+	if !IsValidTupleSizeI64(int64(len(slice))) {
+		return nil, errors.New("requested tuple size is too big")
+	}
+	contents := a.allocContents(len(slice))
+	copy(contents, slice)
+	return a.newTuple(contents), nil
+}
+
+// UnmarshalValue reads a value like UnmarshalValue, allocating its tuples in
+// the arena
+func (a *TupleArena) UnmarshalValue(r io.Reader) (Value, error) {
+	return unmarshalValue(r, a)
+}
@@ -5,10 +5,41 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math/big"
+	"sync"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
 )
 
+// bufferLeafSize is the number of bytes in each leaf of a buffer's merkle tree
+const bufferLeafSize = 32
+
+// bufferHashTag is hashed with the merkle root of a buffer to give its hash
+var bufferHashTag = big.NewInt(123)
+
+// zeroBufferHashes holds the merkle root of an all zero buffer tree of each
+// depth, where a single leaf has depth 0
+var zeroBufferHashes = func() []common.Hash {
+	hashes := make([]common.Hash, 64)
+	hashes[0] = hashing.SoliditySHA3(make([]byte, bufferLeafSize))
+	for i := 1; i < len(hashes); i++ {
+		hashes[i] = hashing.SoliditySHA3(hashes[i-1][:], hashes[i-1][:])
+	}
+	return hashes
+}()
+
+// Buffer is a byte array value. It's stored contiguously, while the AVM
+// stores it as a merkle tree of 32 byte leaves, which is only built to
+// compute its hash.
 type Buffer struct {
 	data []byte
+	hash bufferHash
+}
+
+type bufferHash struct {
+	once sync.Once
+	hash common.Hash
 }
 
 func NewBufferFromReader(rd io.Reader) (*Buffer, error) {
@@ -48,6 +79,68 @@ func (b *Buffer) String() string {
 	return fmt.Sprintf("Buffer(0x%x)", b.data)
 }
 
+func (b *Buffer) Marshal(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, uint64(len(b.data))); err != nil {
+		return err
+	}
+	_, err := w.Write(b.data)
+	return err
+}
+
+// Hash returns the hash the AVM gives the buffer, which is memoized after the
+// first call
+func (b *Buffer) Hash() common.Hash {
+	b.hash.once.Do(func() {
+		b.hash.hash = hashing.SoliditySHA3(
+			hashing.Uint256(bufferHashTag),
+			hashing.Bytes32(bufferMerkleRoot(b.data)),
+		)
+	})
+	return b.hash.hash
+}
+
+// bufferMerkleRoot returns the root of the merkle tree the AVM stores data
+// in. Trailing zeroes are dropped, and the remaining data is split into
+// leaves which are padded with zero leaves up to a power of two. The tree is
+// built a leaf at a time, only keeping the roots of the complete subtrees
+// which haven't been joined yet, so hashing a large buffer doesn't need a
+// copy of it.
+func bufferMerkleRoot(data []byte) common.Hash {
+	data = bytes.TrimRight(data, "\x00")
+	type subtree struct {
+		hash  common.Hash
+		depth int
+	}
+	var subtrees []subtree
+	for i := 0; i < len(data); i += bufferLeafSize {
+		var leaf [bufferLeafSize]byte
+		copy(leaf[:], data[i:])
+		node := subtree{hash: hashing.SoliditySHA3(leaf[:])}
+		for len(subtrees) > 0 && subtrees[len(subtrees)-1].depth == node.depth {
+			left := subtrees[len(subtrees)-1]
+			subtrees = subtrees[:len(subtrees)-1]
+			node = subtree{hash: hashing.SoliditySHA3(left.hash[:], node.hash[:]), depth: node.depth + 1}
+		}
+		subtrees = append(subtrees, node)
+	}
+	if len(subtrees) == 0 {
+		return zeroBufferHashes[0]
+	}
+	// Join the remaining subtrees from the right, padding each with zeroes up
+	// to the depth of the subtree to its left
+	for len(subtrees) > 1 {
+		right := subtrees[len(subtrees)-1]
+		subtrees = subtrees[:len(subtrees)-1]
+		left := &subtrees[len(subtrees)-1]
+		for right.depth < left.depth {
+			right.hash = hashing.SoliditySHA3(right.hash[:], zeroBufferHashes[right.depth][:])
+			right.depth++
+		}
+		*left = subtree{hash: hashing.SoliditySHA3(left.hash[:], right.hash[:]), depth: left.depth + 1}
+	}
+	return subtrees[0].hash
+}
+
 func (b *Buffer) Data() []byte {
 	return b.data
 }
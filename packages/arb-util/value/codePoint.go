@@ -43,6 +43,7 @@ type Operation interface {
 	GetOp() Opcode
 	TypeCode() uint8
 	Equals(other Operation) bool
+	Marshal(wr io.Writer) error
 }
 
 type BasicOperation struct {
@@ -70,6 +71,13 @@ func (op BasicOperation) Equals(other Operation) bool {
 	return op.Op == o.Op
 }
 
+func (op BasicOperation) Marshal(wr io.Writer) error {
+	if _, err := wr.Write([]byte{op.TypeCode()}); err != nil {
+		return err
+	}
+	return op.Op.Marshal(wr)
+}
+
 func (op BasicOperation) String() string {
 	return fmt.Sprintf("0x%x", op.GetOp())
 }
@@ -92,6 +100,16 @@ func (op ImmediateOperation) TypeCode() uint8 {
 	return 1
 }
 
+func (op ImmediateOperation) Marshal(wr io.Writer) error {
+	if _, err := wr.Write([]byte{op.TypeCode()}); err != nil {
+		return err
+	}
+	if err := op.Op.Marshal(wr); err != nil {
+		return err
+	}
+	return MarshalValue(op.Val, wr)
+}
+
 func (op ImmediateOperation) String() string {
 	return fmt.Sprintf("0x%x Imd(%v)", op.GetOp(), op.Val)
 }
@@ -151,6 +169,14 @@ func (cv CodePointValue) Equal(val Value) bool {
 	return cv.NextHash == o.NextHash && cv.Op.Equals(o.Op)
 }
 
+func (cv CodePointValue) Marshal(wr io.Writer) error {
+	if err := cv.Op.Marshal(wr); err != nil {
+		return err
+	}
+	_, err := wr.Write(cv.NextHash[:])
+	return err
+}
+
 func (cv CodePointValue) Size() int64 {
 	return 1
 }
@@ -24,8 +24,15 @@ import (
 )
 
 type CodePointStub struct {
-	PC   uint64
-	hash common.Hash
+	// Segment is the code segment the codepoint is in. It's only known for
+	// stubs created by a machine, since it isn't marshaled.
+	Segment uint64
+	PC      uint64
+	hash    common.Hash
+}
+
+func NewCodePointStub(segment uint64, pc uint64, hash common.Hash) CodePointStub {
+	return CodePointStub{Segment: segment, PC: pc, hash: hash}
 }
 
 func NewCodePointStubFromReader(rd io.Reader) (CodePointStub, error) {
@@ -34,7 +41,7 @@ func NewCodePointStubFromReader(rd io.Reader) (CodePointStub, error) {
 		return CodePointStub{}, err
 	}
 	var hash common.Hash
-	if _, err := rd.Read(hash[:]); err != nil {
+	if _, err := io.ReadFull(rd, hash[:]); err != nil {
 		return CodePointStub{}, err
 	}
 	return CodePointStub{
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Differences only need to show enough of each value to tell them apart, as
+// their paths locate them
+var diffFormatOptions = FormatOptions{MaxDepth: 2, MaxBytes: 32}
+
+// Diff walks expected and actual together and describes the places where
+// they differ, stopping after maxDifferences of them if it's positive. Each
+// difference starts with the path of tuple indices leading to it, so that a
+// divergence deep in a machine state or inbox can be found without printing
+// either value in full. Tuples which hash the same aren't walked.
+func Diff(expected Value, actual Value, maxDifferences int) []string {
+	d := &differ{maxDifferences: maxDifferences}
+	d.diff(expected, actual, nil)
+	return d.differences
+}
+
+type differ struct {
+	maxDifferences int
+	differences    []string
+}
+
+func (d *differ) done() bool {
+	return d.maxDifferences > 0 && len(d.differences) >= d.maxDifferences
+}
+
+func (d *differ) add(path []int, format string, args ...interface{}) {
+	var sb strings.Builder
+	sb.WriteString("value")
+	for _, index := range path {
+		fmt.Fprintf(&sb, "[%v]", index)
+	}
+	sb.WriteString(": ")
+	fmt.Fprintf(&sb, format, args...)
+	d.differences = append(d.differences, sb.String())
+}
+
+func (d *differ) diff(expected Value, actual Value, path []int) {
+	if d.done() {
+		return
+	}
+	if expected == nil || actual == nil {
+		if expected != actual {
+			d.addMismatch(path, expected, actual)
+		}
+		return
+	}
+	expectedTup, ok := expected.(*TupleValue)
+	if !ok {
+		if !expected.Equal(actual) {
+			d.addMismatch(path, expected, actual)
+		}
+		return
+	}
+	actualTup, ok := actual.(*TupleValue)
+	if !ok || expectedTup.Len() != actualTup.Len() {
+		d.addMismatch(path, expected, actual)
+		return
+	}
+	expectedHash, err1 := expectedTup.Hash()
+	actualHash, err2 := actualTup.Hash()
+	if err1 == nil && err2 == nil && expectedHash == actualHash {
+		return
+	}
+	for i := range expectedTup.contents {
+		d.diff(expectedTup.contents[i], actualTup.contents[i], append(path, i))
+	}
+}
+
+func (d *differ) addMismatch(path []int, expected Value, actual Value) {
+	d.add(path, "expected %v, got %v", Format(expected, diffFormatOptions), Format(actual, diffFormatOptions))
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	if differences := Diff(tupleList(10), tupleList(10), 0); len(differences) != 0 {
+		t.Error("equal values differ:", differences)
+	}
+
+	expected := NewTuple2(NewInt64Value(1), NewTuple2(NewInt64Value(2), NewBuffer([]byte{3})))
+	actual := NewTuple2(NewInt64Value(4), NewTuple2(NewInt64Value(2), NewEmptyTuple()))
+	differences := Diff(expected, actual, 0)
+	expectedDifferences := []string{
+		"value[0]: expected 1, got 4",
+		"value[1][1]: expected Buffer(0x03), got ()",
+	}
+	if !reflect.DeepEqual(differences, expectedDifferences) {
+		t.Errorf("wrong differences %q", differences)
+	}
+	if differences := Diff(expected, actual, 1); len(differences) != 1 {
+		t.Errorf("expected differences to be limited to 1, got %v", len(differences))
+	}
+
+	differences = Diff(tupleList(2), tupleList(3), 0)
+	expectedDifferences = []string{
+		"value[0]: expected 1, got 2",
+		"value[1][0]: expected 0, got 1",
+		"value[1][1]: expected (), got (0, ())",
+	}
+	if !reflect.DeepEqual(differences, expectedDifferences) {
+		t.Errorf("wrong differences for lists of different lengths %q", differences)
+	}
+}
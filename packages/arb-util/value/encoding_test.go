@@ -0,0 +1,60 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+// TestTypeCodesMatchSchema catches encoding.json being edited without
+// typecodes.go being regenerated
+func TestTypeCodesMatchSchema(t *testing.T) {
+	data, err := ioutil.ReadFile("encoding.json")
+	test.FailIfError(t, err)
+	var schema struct {
+		MaxTupleSize int `json:"maxTupleSize"`
+		Types        []struct {
+			Name string `json:"name"`
+			Code uint8  `json:"code"`
+		} `json:"types"`
+	}
+	test.FailIfError(t, json.Unmarshal(data, &schema))
+
+	typeCodes := map[string]uint8{
+		"Int":           TypeCodeInt,
+		"CodePoint":     TypeCodeCodePoint,
+		"HashPreImage":  TypeCodeHashPreImage,
+		"Tuple":         TypeCodeTuple,
+		"Buffer":        TypeCodeBuffer,
+		"CodePointStub": TypeCodeCodePointStub,
+	}
+	if len(schema.Types) != len(typeCodes) {
+		t.Errorf("schema has %v types but %v are generated", len(schema.Types), len(typeCodes))
+	}
+	for _, typ := range schema.Types {
+		if code, ok := typeCodes[typ.Name]; !ok || code != typ.Code {
+			t.Errorf("type code of %v doesn't match schema, run go generate", typ.Name)
+		}
+	}
+	if schema.MaxTupleSize != MaxTupleSize {
+		t.Error("max tuple size doesn't match schema, run go generate")
+	}
+}
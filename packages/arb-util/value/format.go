@@ -0,0 +1,132 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+)
+
+type FormatOptions struct {
+	// MaxDepth is how many levels of nested tuples are printed before the
+	// rest are elided, or 0 to print them all
+	MaxDepth int
+	// MaxBytes is how many bytes of a buffer are printed before the rest are
+	// folded into a count, or 0 to print them all
+	MaxBytes int
+	// Indent, if set, puts each tuple element on its own line indented by
+	// this much per level of nesting
+	Indent string
+}
+
+// DefaultFormatOptions keeps values in error messages and logs short
+var DefaultFormatOptions = FormatOptions{MaxDepth: 6, MaxBytes: 64}
+
+// Format prints val for humans to read. The output only depends on val and
+// opts, so the formatted values of two machine states can be diffed.
+func Format(val Value, opts FormatOptions) string {
+	var sb strings.Builder
+	formatValue(&sb, val, opts, 0)
+	return sb.String()
+}
+
+func formatValue(sb *strings.Builder, val Value, opts FormatOptions, depth int) {
+	switch v := val.(type) {
+	case nil:
+		sb.WriteString("nil")
+	case IntValue:
+		if v.val.IsUint64() && v.val.Uint64() <= math.MaxUint32 {
+			sb.WriteString(v.val.String())
+		} else {
+			// Large ints are usually hashes or addresses, which are easier to
+			// recognize in hex
+			sb.WriteString("0x")
+			sb.WriteString(v.val.Text(16))
+		}
+	case *TupleValue:
+		formatTuple(sb, v, opts, depth)
+	case *Buffer:
+		sb.WriteString("Buffer(")
+		formatBytes(sb, v.data, opts.MaxBytes)
+		sb.WriteString(")")
+	case HashPreImage:
+		fmt.Fprintf(sb, "HashPreImage(%v, %v)", v.hashImage, v.size)
+	case CodePointValue:
+		fmt.Fprintf(sb, "CodePoint(0x%02x", uint8(v.Op.GetOp()))
+		if imm, ok := v.Op.(ImmediateOperation); ok {
+			sb.WriteString(", ")
+			formatValue(sb, imm.Val, opts, depth+1)
+		}
+		fmt.Fprintf(sb, ", %v)", v.NextHash)
+	case CodePointStub:
+		fmt.Fprintf(sb, "CodePointStub(%v, %v)", v.PC, v.hash)
+	default:
+		sb.WriteString(val.String())
+	}
+}
+
+func formatTuple(sb *strings.Builder, tup *TupleValue, opts FormatOptions, depth int) {
+	if len(tup.contents) == 0 {
+		sb.WriteString("()")
+		return
+	}
+	if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+		fmt.Fprintf(sb, "(...%v values)", tup.Size()-1)
+		return
+	}
+	sb.WriteString("(")
+	for i, val := range tup.contents {
+		if opts.Indent != "" {
+			sb.WriteString("\n")
+			sb.WriteString(strings.Repeat(opts.Indent, depth+1))
+		} else if i > 0 {
+			sb.WriteString(" ")
+		}
+		formatValue(sb, val, opts, depth+1)
+		if i < len(tup.contents)-1 || opts.Indent != "" {
+			sb.WriteString(",")
+		}
+	}
+	if opts.Indent != "" {
+		sb.WriteString("\n")
+		sb.WriteString(strings.Repeat(opts.Indent, depth))
+	}
+	sb.WriteString(")")
+}
+
+// formatBytes prints data in hex, folding anything past maxBytes and any
+// trailing zeroes, which buffers are usually padded with, into a count
+func formatBytes(sb *strings.Builder, data []byte, maxBytes int) {
+	trimmed := strings.TrimRight(string(data), "\x00")
+	zeroes := len(data) - len(trimmed)
+	shown := []byte(trimmed)
+	folded := 0
+	if maxBytes > 0 && len(shown) > maxBytes {
+		folded = len(shown) - maxBytes
+		shown = shown[:maxBytes]
+	}
+	sb.WriteString("0x")
+	sb.WriteString(hex.EncodeToString(shown))
+	if folded > 0 {
+		fmt.Fprintf(sb, "...+%v bytes", folded)
+	}
+	if zeroes > 0 {
+		fmt.Fprintf(sb, "+%v zero bytes", zeroes)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	large := new(big.Int).Lsh(big.NewInt(1), 160)
+	buf := append([]byte{0xab, 0xcd, 0xef}, make([]byte, 29)...)
+	cases := []struct {
+		name     string
+		val      Value
+		opts     FormatOptions
+		expected string
+	}{
+		{"small int", NewInt64Value(42), FormatOptions{}, "42"},
+		{"large int", NewIntValue(large), FormatOptions{}, "0x10000000000000000000000000000000000000000"},
+		{"empty tuple", NewEmptyTuple(), FormatOptions{}, "()"},
+		{"list", tupleList(3), FormatOptions{}, "(2, (1, (0, ())))"},
+		{"depth limited", tupleList(3), FormatOptions{MaxDepth: 2}, "(2, (1, (...2 values)))"},
+		{"indented", NewTuple2(NewInt64Value(1), NewEmptyTuple()), FormatOptions{Indent: "  "}, "(\n  1,\n  (),\n)"},
+		{"buffer", NewBuffer(buf), FormatOptions{}, "Buffer(0xabcdef+29 zero bytes)"},
+		{"folded buffer", NewBuffer(buf), FormatOptions{MaxBytes: 2}, "Buffer(0xabcd...+1 bytes+29 zero bytes)"},
+		{"nil", nil, FormatOptions{}, "nil"},
+	}
+	for _, c := range cases {
+		if formatted := Format(c.val, c.opts); formatted != c.expected {
+			t.Errorf("wrong format for %v: got %q, expected %q", c.name, formatted, c.expected)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// +build ignore
+
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// gentypecodes generates the value type codes of the Go validator and the
+// C++ AVM from encoding.json, and checks that the ones the Solidity bridge
+// declares match it
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	schemaFile   = "encoding.json"
+	goFile       = "typecodes.go"
+	cppFile      = "../../arb-avm-cpp/avm_values/include/avm_values/valuetype.hpp"
+	solidityFile = "../../arb-bridge-eth/contracts/arch/Value.sol"
+)
+
+type valueType struct {
+	Name     string `json:"name"`
+	Cpp      string `json:"cpp"`
+	Solidity string `json:"solidity"`
+	Code     uint8  `json:"code"`
+	Encoding string `json:"encoding"`
+}
+
+type schema struct {
+	MaxTupleSize uint8       `json:"maxTupleSize"`
+	Types        []valueType `json:"types"`
+}
+
+const headerFormat = `/*
+ * Copyright %v, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+`
+
+// licenseHeader returns the license comment for a generated file. Files
+// which existed before they were generated keep their original copyright
+// years.
+func licenseHeader(years string) string {
+	return fmt.Sprintf(headerFormat, years)
+}
+
+var goTemplate = template.Must(template.New("go").Parse(licenseHeader("2021") + `
+// Code generated by gentypecodes.go from encoding.json. DO NOT EDIT.
+
+package value
+
+const (
+{{- range .Types}}
+	// {{.Encoding}}
+	TypeCode{{.Name}} uint8 = {{.Code}}
+{{- end}}
+)
+
+const MaxTupleSize = {{.MaxTupleSize}}
+`))
+
+var cppTemplate = template.Must(template.New("cpp").Parse(licenseHeader("2019-2020") + `
+// Code generated by packages/arb-util/value/gentypecodes.go from
+// packages/arb-util/value/encoding.json. DO NOT EDIT.
+
+#ifndef valuetype_h
+#define valuetype_h
+
+// Proof values will only include values types up to TUPLE + 8 (11)
+// All types declared with types creater than 11 are used for the internal
+// marshalling format Used to pass values between the AVM and the validator
+enum ValueTypes {
+{{- range $i, $t := .Types}}{{if $i}},{{end}}
+    {{$t.Cpp}} = {{$t.Code}}
+{{- end}}
+};
+
+#endif /* valuetype_h */
+`))
+
+var solidityConstant = regexp.MustCompile(`uint8 internal constant (\w+) = ([\w +]+);`)
+
+// solidityConstants parses the uint8 constants declared in a Solidity
+// source, which are either numbers or sums of numbers and earlier constants
+func solidityConstants(source string) (map[string]uint8, error) {
+	constants := make(map[string]uint8)
+	for _, match := range solidityConstant.FindAllStringSubmatch(source, -1) {
+		var sum uint8
+		for _, term := range strings.Split(match[2], "+") {
+			term = strings.TrimSpace(term)
+			if val, ok := constants[term]; ok {
+				sum += val
+				continue
+			}
+			val, err := strconv.ParseUint(term, 10, 8)
+			if err != nil {
+				return nil, errors.Errorf("can't evaluate constant %v = %v", match[1], match[2])
+			}
+			sum += uint8(val)
+		}
+		constants[match[1]] = sum
+	}
+	return constants, nil
+}
+
+func checkSolidity(s *schema) error {
+	source, err := ioutil.ReadFile(solidityFile)
+	if err != nil {
+		return err
+	}
+	constants, err := solidityConstants(string(source))
+	if err != nil {
+		return err
+	}
+	for _, t := range s.Types {
+		if t.Solidity == "" {
+			continue
+		}
+		code, ok := constants[t.Solidity]
+		if !ok {
+			return errors.Errorf("%v doesn't declare %v", solidityFile, t.Solidity)
+		}
+		if code != t.Code {
+			return errors.Errorf("%v declares %v as %v but the schema has %v", solidityFile, t.Solidity, code, t.Code)
+		}
+	}
+	return nil
+}
+
+func generate(tmpl *template.Template, s *schema, path string, isGo bool) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	if isGo {
+		var err error
+		data, err = format.Source(data)
+		if err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func run() error {
+	data, err := ioutil.ReadFile(schemaFile)
+	if err != nil {
+		return err
+	}
+	s := new(schema)
+	if err := json.Unmarshal(data, s); err != nil {
+		return errors.Wrap(err, "invalid schema")
+	}
+	if err := checkSolidity(s); err != nil {
+		return err
+	}
+	if err := generate(goTemplate, s, goFile, true); err != nil {
+		return err
+	}
+	return generate(cppTemplate, s, cppFile, false)
+}
+
+func main() {
+	if err := run(); err != nil {
+		log.Error().Err(err).Msg("error generating value type codes")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
+)
+
+// ParallelHashThreshold is the size, in values, from which a tuple's
+// children are hashed on separate goroutines when more than one of them is
+// at least that large. The last of them is hashed by the goroutine hashing
+// the tuple, so long lists, which only have one large child per tuple, are
+// still hashed serially. Smaller tuples are hashed serially, as the hashing
+// would take less time than handing it off. Setting it to 0 disables
+// parallel hashing. It should only be changed before any hashing starts.
+var ParallelHashThreshold int64 = 1 << 10
+
+// hashWorkers limits how many goroutines hash subtrees at once. When they're
+// all busy, subtrees are hashed by the goroutine which needs them, so nested
+// tuples can't deadlock waiting for a worker.
+var hashWorkers = make(chan struct{}, runtime.NumCPU())
+
+// HashValue returns the hash the AVM gives val
+func HashValue(val Value) (common.Hash, error) {
+	switch v := val.(type) {
+	case IntValue:
+		return v.Hash(), nil
+	case HashPreImage:
+		return v.Hash(), nil
+	case CodePointStub:
+		return v.Hash(), nil
+	case CodePointValue:
+		return v.Hash()
+	case *TupleValue:
+		return v.Hash()
+	case *Buffer:
+		return v.Hash(), nil
+	default:
+		return common.Hash{}, errors.Errorf("can't hash value of type %v", val.TypeCode())
+	}
+}
+
+func (hp HashPreImage) Hash() common.Hash {
+	return hashing.SoliditySHA3(
+		hashing.Uint8(TypeCodeTuple),
+		hashing.Bytes32(hp.hashImage),
+		hashing.Uint256(big.NewInt(hp.size)),
+	)
+}
+
+func (cv CodePointValue) Hash() (common.Hash, error) {
+	data := [][]byte{
+		hashing.Uint8(TypeCodeCodePoint),
+		hashing.Uint8(uint8(cv.Op.GetOp())),
+	}
+	if imm, ok := cv.Op.(ImmediateOperation); ok {
+		immHash, err := HashValue(imm.Val)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		data = append(data, hashing.Bytes32(immHash))
+	}
+	data = append(data, hashing.Bytes32(cv.NextHash))
+	return hashing.SoliditySHA3(data...), nil
+}
+
+// tupleHash memoizes the hash preimage of a tuple. Tuples are immutable, so
+// it's computed at most once, the first time it's needed.
+type tupleHash struct {
+	once     sync.Once
+	preImage HashPreImage
+	hash     common.Hash
+	err      error
+}
+
+// HashPreImage returns the preimage of the tuple's hash, computing it only
+// the first time it's called. Child tuples memoize their own hashes, so
+// hashing a tuple that shares structure with one hashed before only hashes
+// the parts that are new.
+func (tv *TupleValue) HashPreImage() (HashPreImage, error) {
+	tv.calculateHash()
+	return tv.hash.preImage, tv.hash.err
+}
+
+func (tv *TupleValue) calculateHash() {
+	tv.hash.once.Do(func() {
+		tv.hash.preImage, tv.hash.err = tv.calculateHashPreImage()
+		if tv.hash.err == nil {
+			tv.hash.hash = tv.hash.preImage.Hash()
+		}
+	})
+}
+
+func (tv *TupleValue) calculateHashPreImage() (HashPreImage, error) {
+	data := make([][]byte, 0, 1+len(tv.contents))
+	data = append(data, hashing.Uint8(uint8(len(tv.contents))))
+	large := tv.largeChildren()
+	if len(large) < 2 {
+		for _, val := range tv.contents {
+			h, err := HashValue(val)
+			if err != nil {
+				return HashPreImage{}, err
+			}
+			data = append(data, hashing.Bytes32(h))
+		}
+	} else {
+		hashes, err := tv.hashChildrenInParallel(large)
+		if err != nil {
+			return HashPreImage{}, err
+		}
+		for _, h := range hashes {
+			data = append(data, hashing.Bytes32(h))
+		}
+	}
+	return NewPreImage(hashing.SoliditySHA3(data...), tv.size), nil
+}
+
+// hashChildrenInParallel hashes the tuple's children, handing all of the
+// large ones but the last off to other goroutines while workers are free
+func (tv *TupleValue) hashChildrenInParallel(large []int) ([]common.Hash, error) {
+	hashes := make([]common.Hash, len(tv.contents))
+	errs := make([]error, len(tv.contents))
+	handedOff := make([]bool, len(tv.contents))
+	var wg sync.WaitGroup
+	for _, i := range large[:len(large)-1] {
+		if !acquireHashWorker() {
+			// Every worker is busy, so the rest are hashed on this goroutine
+			break
+		}
+		i := i
+		handedOff[i] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-hashWorkers }()
+			hashes[i], errs[i] = HashValue(tv.contents[i])
+		}()
+	}
+	for i, val := range tv.contents {
+		if !handedOff[i] {
+			hashes[i], errs[i] = HashValue(val)
+		}
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+func acquireHashWorker() bool {
+	select {
+	case hashWorkers <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// largeChildren returns the indexes of the tuple's children which are tuples
+// large enough to be worth hashing in parallel
+func (tv *TupleValue) largeChildren() []int {
+	if ParallelHashThreshold <= 0 {
+		return nil
+	}
+	var large []int
+	for i, val := range tv.contents {
+		if tup, ok := val.(*TupleValue); ok && tup.size >= ParallelHashThreshold {
+			large = append(large, i)
+		}
+	}
+	return large
+}
+
+// Hash returns the hash the AVM gives the tuple, which is memoized after the
+// first call
+func (tv *TupleValue) Hash() (common.Hash, error) {
+	tv.calculateHash()
+	return tv.hash.hash, tv.hash.err
+}
@@ -55,6 +55,13 @@ func (hp HashPreImage) GetInnerHash() common.Hash {
 	return hp.hashImage
 }
 
+func (hp HashPreImage) Marshal(w io.Writer) error {
+	if _, err := w.Write(hp.hashImage[:]); err != nil {
+		return err
+	}
+	return NewInt64Value(hp.size).Marshal(w)
+}
+
 func (hp HashPreImage) TypeCode() uint8 {
 	return TypeCodeHashPreImage
 }
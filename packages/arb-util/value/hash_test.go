@@ -0,0 +1,203 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+// tupleList builds a linked list of tuples, the shape the inbox and stacks
+// take in the AVM
+func tupleList(length int) *TupleValue {
+	list := NewEmptyTuple()
+	for i := 0; i < length; i++ {
+		list = NewTuple2(NewInt64Value(int64(i)), list)
+	}
+	return list
+}
+
+// wideTree builds a tree of tuples with width children each, the shape of
+// large data values
+func wideTree(depth int, width int) *TupleValue {
+	contents := make([]Value, width)
+	for i := range contents {
+		if depth == 0 {
+			contents[i] = NewInt64Value(int64(i))
+		} else {
+			contents[i] = wideTree(depth-1, width)
+		}
+	}
+	tup, _ := NewTupleFromOwnedSlice(contents)
+	return tup
+}
+
+func TestTupleHash(t *testing.T) {
+	emptyHash, err := NewEmptyTuple().Hash()
+	test.FailIfError(t, err)
+	expectedEmpty := hashing.SoliditySHA3(
+		hashing.Uint8(TypeCodeTuple),
+		hashing.Bytes32(hashing.SoliditySHA3(hashing.Uint8(0))),
+		hashing.Uint256(big.NewInt(1)),
+	)
+	if emptyHash != expectedEmpty {
+		t.Error("wrong empty tuple hash")
+	}
+
+	tup := NewTuple2(NewInt64Value(5), NewEmptyTuple())
+	h, err := tup.Hash()
+	test.FailIfError(t, err)
+	expected := hashing.SoliditySHA3(
+		hashing.Uint8(TypeCodeTuple),
+		hashing.Bytes32(hashing.SoliditySHA3(
+			hashing.Uint8(2),
+			hashing.Bytes32(NewInt64Value(5).Hash()),
+			hashing.Bytes32(emptyHash),
+		)),
+		hashing.Uint256(big.NewInt(3)),
+	)
+	if h != expected {
+		t.Error("wrong tuple hash")
+	}
+
+	// A preimage hashes the same as the tuple it stands in for
+	preImage, err := tup.HashPreImage()
+	test.FailIfError(t, err)
+	if preImage.Hash() != h {
+		t.Error("preimage hash doesn't match tuple hash")
+	}
+	h2, err := tup.Hash()
+	test.FailIfError(t, err)
+	if h2 != h {
+		t.Error("memoized hash differs")
+	}
+
+	withStub := NewTuple2(CodePointStub{hash: common.RandHash()}, NewEmptyTuple())
+	_, err = withStub.Hash()
+	test.FailIfError(t, err)
+}
+
+func TestBufferHash(t *testing.T) {
+	hundred := make([]byte, 100)
+	for i := range hundred {
+		hundred[i] = byte(i + 1)
+	}
+	large := make([]byte, 5000)
+	for i := range large {
+		large[i] = byte(i*7 + 1)
+	}
+	// Hashes computed with the contracts' Hashing.bytesToBufferHash
+	cases := []struct {
+		name string
+		data []byte
+		hash common.Hash
+	}{
+		{"empty", nil, common.HexToHash("0xc7475bc300e0a371cfa639ceb1e1d08802941c2df94c0ffe34929c5fed808638")},
+		{"single leaf", []byte{1, 2, 3}, common.HexToHash("0x1b1cca1677ea06f92657a0091efdd2b693bc70c7743c7bf7866c084ec92cc017")},
+		{"partial tree", hundred, common.HexToHash("0xb0851a6185b24977934814b7ce5e0199b32c46b8e40395cc89204914763f2746")},
+		{"trailing zeroes", append(hundred, make([]byte, 200)...), common.HexToHash("0xb0851a6185b24977934814b7ce5e0199b32c46b8e40395cc89204914763f2746")},
+		{"large", large, common.HexToHash("0x2c2bd83cf3776abc192cc98f53289c2c7c80e6032e2ea9371dab99e162787303")},
+	}
+	for _, c := range cases {
+		if h := NewBuffer(c.data).Hash(); h != c.hash {
+			t.Errorf("wrong hash for %v buffer: %v", c.name, h)
+		}
+	}
+
+	tup := NewTuple2(NewBuffer([]byte{1}), NewEmptyTuple())
+	_, err := tup.Hash()
+	test.FailIfError(t, err)
+}
+
+func TestParallelTupleHash(t *testing.T) {
+	defer func(threshold int64) {
+		ParallelHashThreshold = threshold
+	}(ParallelHashThreshold)
+
+	ParallelHashThreshold = 0
+	serial, err := wideTree(5, 4).Hash()
+	test.FailIfError(t, err)
+	ParallelHashThreshold = 10
+	parallel, err := wideTree(5, 4).Hash()
+	test.FailIfError(t, err)
+	if serial != parallel {
+		t.Error("parallel hash differs from serial hash")
+	}
+}
+
+func BenchmarkTupleHash(b *testing.B) {
+	b.Run("Unmemoized", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			list := tupleList(1000)
+			b.StartTimer()
+			if _, err := list.Hash(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Memoized", func(b *testing.B) {
+		list := tupleList(1000)
+		if _, err := list.Hash(); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := list.Hash(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("WideSerial", func(b *testing.B) {
+		benchmarkWideTreeHash(b, 0)
+	})
+	b.Run("WideParallel", func(b *testing.B) {
+		benchmarkWideTreeHash(b, ParallelHashThreshold)
+	})
+	b.Run("Extended", func(b *testing.B) {
+		// Pushing onto a hashed list only hashes the new tuple
+		list := tupleList(1000)
+		if _, err := list.Hash(); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := NewTuple2(NewInt64Value(int64(i)), list).Hash(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func benchmarkWideTreeHash(b *testing.B, threshold int64) {
+	defer func(threshold int64) {
+		ParallelHashThreshold = threshold
+	}(ParallelHashThreshold)
+	ParallelHashThreshold = threshold
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tree := wideTree(6, 4)
+		b.StartTimer()
+		if _, err := tree.Hash(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -55,7 +55,7 @@ func NewValueFromAddress(addr common.Address) IntValue {
 
 func NewIntValueFromReader(rd io.Reader) (IntValue, error) {
 	var data common.Hash
-	_, err := rd.Read(data[:])
+	_, err := io.ReadFull(rd, data[:])
 	if err != nil {
 		return IntValue{}, err
 	}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"bytes"
+	"encoding/json"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+// Values have a canonical JSON representation for debugging tools, RPC
+// endpoints and test fixtures. Ints are hex strings and tuples are arrays of
+// their contents. Every other type is an object with a single key naming the
+// type:
+//
+//   {"buffer": "0x0102"}
+//   {"hashPreImage": {"hash": "0x..", "size": "0x3"}}
+//   {"codePoint": {"opcode": "0x3c", "immediate": "0x1", "nextHash": "0x.."}}
+//   {"codePointStub": {"pc": "0x5", "hash": "0x.."}}
+
+type jsonHashPreImage struct {
+	Hash ethcommon.Hash `json:"hash"`
+	Size hexutil.Uint64 `json:"size"`
+}
+
+type jsonCodePoint struct {
+	Opcode    hexutil.Uint64 `json:"opcode"`
+	Immediate *JSONValue     `json:"immediate,omitempty"`
+	NextHash  ethcommon.Hash `json:"nextHash"`
+}
+
+type jsonCodePointStub struct {
+	PC   hexutil.Uint64 `json:"pc"`
+	Hash ethcommon.Hash `json:"hash"`
+}
+
+type jsonTaggedValue struct {
+	Buffer        *hexutil.Bytes     `json:"buffer,omitempty"`
+	HashPreImage  *jsonHashPreImage  `json:"hashPreImage,omitempty"`
+	CodePoint     *jsonCodePoint     `json:"codePoint,omitempty"`
+	CodePointStub *jsonCodePointStub `json:"codePointStub,omitempty"`
+}
+
+// JSONValue wraps a value so that it can be unmarshalled from JSON, which
+// requires knowing its type in advance
+type JSONValue struct {
+	Value
+}
+
+func (v JSONValue) MarshalJSON() ([]byte, error) {
+	if v.Value == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(v.Value)
+}
+
+func (v *JSONValue) UnmarshalJSON(data []byte) error {
+	val, err := UnmarshalValueJSON(data)
+	if err != nil {
+		return err
+	}
+	v.Value = val
+	return nil
+}
+
+// UnmarshalValueJSON parses a value from its canonical JSON representation
+func UnmarshalValueJSON(data []byte) (Value, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("empty value JSON")
+	}
+	switch data[0] {
+	case '"':
+		var val hexutil.Big
+		if err := json.Unmarshal(data, &val); err != nil {
+			return nil, errors.Wrap(err, "invalid int value")
+		}
+		if val.ToInt().Sign() < 0 || val.ToInt().BitLen() > 256 {
+			return nil, errors.New("int value out of range")
+		}
+		return NewIntValue(val.ToInt()), nil
+	case '[':
+		var contents []JSONValue
+		if err := json.Unmarshal(data, &contents); err != nil {
+			return nil, err
+		}
+		vals := make([]Value, 0, len(contents))
+		for _, val := range contents {
+			vals = append(vals, val.Value)
+		}
+		return NewTupleFromOwnedSlice(vals)
+	case '{':
+		var tagged jsonTaggedValue
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&tagged); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return tagged.value()
+	default:
+		return nil, errors.New("invalid value JSON")
+	}
+}
+
+func (t jsonTaggedValue) value() (Value, error) {
+	var vals []Value
+	if t.Buffer != nil {
+		vals = append(vals, NewBuffer(*t.Buffer))
+	}
+	if t.HashPreImage != nil {
+		vals = append(vals, NewPreImage(common.NewHashFromEth(t.HashPreImage.Hash), int64(t.HashPreImage.Size)))
+	}
+	if t.CodePoint != nil {
+		if t.CodePoint.Opcode > 0xff {
+			return nil, errors.New("invalid opcode")
+		}
+		opcode := Opcode(t.CodePoint.Opcode)
+		var op Operation = BasicOperation{Op: opcode}
+		if t.CodePoint.Immediate != nil {
+			op = ImmediateOperation{Op: opcode, Val: t.CodePoint.Immediate.Value}
+		}
+		vals = append(vals, CodePointValue{Op: op, NextHash: common.NewHashFromEth(t.CodePoint.NextHash)})
+	}
+	if t.CodePointStub != nil {
+		vals = append(vals, CodePointStub{PC: uint64(t.CodePointStub.PC), hash: common.NewHashFromEth(t.CodePointStub.Hash)})
+	}
+	if len(vals) != 1 {
+		return nil, errors.New("value JSON object must have exactly one type key")
+	}
+	return vals[0], nil
+}
+
+func (iv IntValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*hexutil.Big)(iv.val))
+}
+
+func (tv *TupleValue) MarshalJSON() ([]byte, error) {
+	contents := make([]JSONValue, 0, len(tv.contents))
+	for _, val := range tv.contents {
+		contents = append(contents, JSONValue{val})
+	}
+	return json.Marshal(contents)
+}
+
+func (b *Buffer) MarshalJSON() ([]byte, error) {
+	data := hexutil.Bytes(b.data)
+	return json.Marshal(jsonTaggedValue{Buffer: &data})
+}
+
+func (hp HashPreImage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTaggedValue{HashPreImage: &jsonHashPreImage{
+		Hash: hp.hashImage.ToEthHash(),
+		Size: hexutil.Uint64(hp.size),
+	}})
+}
+
+func (cv CodePointValue) MarshalJSON() ([]byte, error) {
+	cp := &jsonCodePoint{
+		Opcode:   hexutil.Uint64(cv.Op.GetOp()),
+		NextHash: cv.NextHash.ToEthHash(),
+	}
+	if imm, ok := cv.Op.(ImmediateOperation); ok {
+		cp.Immediate = &JSONValue{imm.Val}
+	}
+	return json.Marshal(jsonTaggedValue{CodePoint: cp})
+}
+
+func (cp CodePointStub) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTaggedValue{CodePointStub: &jsonCodePointStub{
+		PC:   hexutil.Uint64(cp.PC),
+		Hash: cp.hash.ToEthHash(),
+	}})
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestValueJSON(t *testing.T) {
+	val, err := NewTupleFromSlice([]Value{
+		NewInt64Value(255),
+		NewEmptyTuple(),
+		NewBuffer([]byte{1, 2}),
+		NewPreImage(common.RandHash(), 3),
+		CodePointValue{Op: ImmediateOperation{Op: 0x3c, Val: NewTuple2(NewInt64Value(1), NewEmptyTuple())}, NextHash: common.RandHash()},
+		CodePointValue{Op: BasicOperation{Op: 0x01}, NextHash: common.RandHash()},
+		CodePointStub{PC: 5, hash: common.RandHash()},
+	})
+	test.FailIfError(t, err)
+	data, err := json.Marshal(JSONValue{val})
+	test.FailIfError(t, err)
+	var decoded JSONValue
+	test.FailIfError(t, json.Unmarshal(data, &decoded))
+	if !val.Equal(decoded.Value) {
+		t.Errorf("value didn't round trip through %s", data)
+	}
+
+	data, err = json.Marshal(NewTuple2(NewInt64Value(16), NewEmptyTuple()))
+	test.FailIfError(t, err)
+	if string(data) != `["0x10",[]]` {
+		t.Errorf("unexpected tuple JSON %s", data)
+	}
+
+	for _, invalid := range []string{
+		`null`,
+		`"0x"`,
+		`"-0x1"`,
+		`[1]`,
+		`{}`,
+		`{"buffer": "0x", "codePointStub": {"pc": "0x0", "hash": "0x0000000000000000000000000000000000000000000000000000000000000000"}}`,
+		`{"unknown": "0x1"}`,
+		`["0x1", "0x1", "0x1", "0x1", "0x1", "0x1", "0x1", "0x1", "0x1"]`,
+	} {
+		if _, err := UnmarshalValueJSON([]byte(invalid)); err == nil {
+			t.Errorf("parsed invalid value JSON %v", invalid)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Marshaler is implemented by values which can write their serialization,
+// without the leading type code, to a stream
+type Marshaler interface {
+	Marshal(w io.Writer) error
+}
+
+// MarshalValue writes the serialization of val, as read by UnmarshalValue, to
+// w. Tuples and buffers are written directly from the value, so that large
+// values such as machine state can be checkpointed without building the
+// whole serialization in memory. Values are written in many small pieces, so
+// w should be buffered if writes to it are expensive.
+func MarshalValue(val Value, w io.Writer) error {
+	if _, err := w.Write([]byte{val.TypeCode()}); err != nil {
+		return err
+	}
+	m, ok := val.(Marshaler)
+	if !ok {
+		return errors.Errorf("can't marshal value of type %v", val.TypeCode())
+	}
+	return m.Marshal(w)
+}
+
+// MarshalValueToBytes returns the serialization of val
+func MarshalValueToBytes(val Value) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := MarshalValue(val, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteValue writes the serialization of val to w through a buffer, flushing
+// it before returning
+func WriteValue(val Value, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := MarshalValue(val, bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ReadValue reads a value written by WriteValue from r through a buffer.
+// Reading may consume data from r past the end of the value.
+func ReadValue(r io.Reader) (Value, error) {
+	return UnmarshalValue(bufio.NewReader(r))
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"bytes"
+	"testing"
+	"testing/iotest"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	largeBuffer := make([]byte, 1<<20)
+	for i := range largeBuffer {
+		largeBuffer[i] = byte(i)
+	}
+	nested, err := NewTupleFromSlice([]Value{
+		NewInt64Value(0),
+		NewBuffer(largeBuffer),
+		NewPreImage(common.RandHash(), 12),
+		NewTuple2(NewEmptyTuple(), NewBuffer(nil)),
+	})
+	test.FailIfError(t, err)
+	values := []Value{
+		NewInt64Value(42),
+		NewEmptyTuple(),
+		NewBuffer([]byte{1, 2, 3}),
+		NewPreImage(common.RandHash(), 5),
+		CodePointStub{PC: 7, hash: common.RandHash()},
+		CodePointValue{Op: BasicOperation{Op: 3}, NextHash: common.RandHash()},
+		CodePointValue{Op: ImmediateOperation{Op: 4, Val: NewInt64Value(9)}, NextHash: common.RandHash()},
+		nested,
+	}
+	for _, val := range values {
+		var buf bytes.Buffer
+		test.FailIfError(t, WriteValue(val, &buf))
+		// Read a byte at a time to make sure short reads from a stream are
+		// handled
+		decoded, err := UnmarshalValue(iotest.OneByteReader(bytes.NewReader(buf.Bytes())))
+		test.FailIfError(t, err)
+		if !val.Equal(decoded) {
+			t.Errorf("value %v didn't round trip", val.TypeCode())
+		}
+		data, err := MarshalValueToBytes(val)
+		test.FailIfError(t, err)
+		if !bytes.Equal(data, buf.Bytes()) {
+			t.Errorf("buffered serialization of value %v differs", val.TypeCode())
+		}
+		size, err := MarshaledSize(val)
+		test.FailIfError(t, err)
+		if size != int64(len(data)) {
+			t.Errorf("marshaled size of value %v is %v, not %v", val.TypeCode(), size, len(data))
+		}
+	}
+}
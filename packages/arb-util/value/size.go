@@ -0,0 +1,80 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	// HashGas is the AVM gas cost of the hash instruction, which hashes any
+	// value in a single step as the hashes of its parts are cached
+	HashGas = 7
+	// KeccakPermutationGas is the AVM gas cost of the keccakf instruction,
+	// which ArbOS runs once per block of data it hashes with keccak256
+	KeccakPermutationGas = 600
+
+	keccakRate = 136
+)
+
+// MarshaledSize returns the number of bytes MarshalValue writes for val,
+// without marshalling it. Unlike Size, which counts the values val is made
+// of, this accounts for the length of buffers, so it can be used to bound the
+// size of a message before it's built.
+func MarshaledSize(val Value) (int64, error) {
+	// Every value starts with its type code
+	size := int64(1)
+	switch v := val.(type) {
+	case IntValue:
+		size += 32
+	case *TupleValue:
+		for _, child := range v.Contents() {
+			childSize, err := MarshaledSize(child)
+			if err != nil {
+				return 0, err
+			}
+			size += childSize
+		}
+	case *Buffer:
+		size += 8 + int64(len(v.data))
+	case HashPreImage:
+		size += 32 + 32
+	case CodePointValue:
+		// Immediate count and opcode
+		size += 2
+		if imm, ok := v.Op.(ImmediateOperation); ok {
+			immSize, err := MarshaledSize(imm.Val)
+			if err != nil {
+				return 0, err
+			}
+			size += immSize
+		}
+		size += 32
+	case CodePointStub:
+		size += 8 + 32
+	default:
+		return 0, errors.Errorf("can't size value of type %v", val.TypeCode())
+	}
+	return size, nil
+}
+
+// EstimateKeccakGas estimates the AVM gas ArbOS uses to keccak256 hash length
+// bytes of data, which is dominated by a keccakf permutation for each block
+// of the padded input
+func EstimateKeccakGas(length uint64) uint64 {
+	return (length/keccakRate + 1) * KeccakPermutationGas
+}
@@ -23,55 +23,74 @@ import (
 	"io"
 )
 
-const MaxTupleSize = 8
-
 type TupleValue struct {
-	contentsArr [MaxTupleSize]Value
-	itemCount   int8
-	size        int64
+	contents []Value
+	size     int64
+	hash     tupleHash
 }
 
 func NewEmptyTuple() *TupleValue {
-	return &TupleValue{[MaxTupleSize]Value{}, 0, 1}
+	return &TupleValue{size: 1}
 }
 
 func NewTupleOfSizeWithContents(contents [MaxTupleSize]Value, size int8) (*TupleValue, error) {
 	if !IsValidTupleSizeI64(int64(size)) {
 		return nil, errors.New("requested empty tuple size is too big")
 	}
-	ret := &TupleValue{contents, size, 0}
-	ret.size = ret.internalSize()
-	return ret, nil
+	return newTuple(contents[:size]), nil
 }
 
+// NewTupleFromSlice creates a tuple with a copy of slice, so slice can be
+// reused afterwards
 func NewTupleFromSlice(slice []Value) (*TupleValue, error) {
 	if !IsValidTupleSizeI64(int64(len(slice))) {
 		return nil, errors.New("requested tuple size is too big")
 	}
-	var contents [MaxTupleSize]Value
-	for i, v := range slice {
-		contents[i] = v
+	contents := make([]Value, len(slice))
+	copy(contents, slice)
+	return newTuple(contents), nil
+}
+
+// NewTupleFromOwnedSlice creates a tuple which takes ownership of slice
+// instead of copying it. slice must not be modified afterwards.
+func NewTupleFromOwnedSlice(slice []Value) (*TupleValue, error) {
+	if !IsValidTupleSizeI64(int64(len(slice))) {
+		return nil, errors.New("requested tuple size is too big")
 	}
-	return NewTupleOfSizeWithContents(contents, int8(len(slice)))
+	return newTuple(slice), nil
 }
 
 func NewTuple2(value1 Value, value2 Value) *TupleValue {
-	ret := &TupleValue{[MaxTupleSize]Value{value1, value2}, 2, 0}
+	return newTuple([]Value{value1, value2})
+}
+
+// newTuple creates a tuple which owns contents, which must be a valid size
+func newTuple(contents []Value) *TupleValue {
+	if len(contents) == 0 {
+		return NewEmptyTuple()
+	}
+	ret := &TupleValue{contents: contents}
 	ret.size = ret.internalSize()
 	return ret
 }
 
 func NewSizedTupleFromReader(rd io.Reader, size byte) (*TupleValue, error) {
-	var contentsArr [MaxTupleSize]Value
-	sz := int8(size)
-	for i := 0; i < int(sz); i++ {
-		boxedVal, err := UnmarshalValue(rd)
+	return newSizedTupleFromReader(rd, size, nil)
+}
+
+func newSizedTupleFromReader(rd io.Reader, size byte, arena *TupleArena) (*TupleValue, error) {
+	if !IsValidTupleSizeI64(int64(size)) {
+		return nil, errors.New("requested tuple size is too big")
+	}
+	contents := arena.allocContents(int(size))
+	for i := range contents {
+		boxedVal, err := unmarshalValue(rd, arena)
 		if err != nil {
 			return nil, err
 		}
-		contentsArr[i] = boxedVal
+		contents[i] = boxedVal
 	}
-	return NewTupleOfSizeWithContents(contentsArr, sz)
+	return arena.newTuple(contents), nil
 }
 
 func IsValidTupleSizeI64(size int64) bool {
@@ -79,22 +98,22 @@ func IsValidTupleSizeI64(size int64) bool {
 }
 
 func (tv *TupleValue) Contents() []Value {
-	return tv.contentsArr[:tv.itemCount]
+	return tv.contents
 }
 
 func (tv *TupleValue) Len() int64 {
-	return int64(tv.itemCount)
+	return int64(len(tv.contents))
 }
 
 func (tv *TupleValue) GetByInt64(idx int64) (Value, error) {
 	if idx < 0 || idx >= tv.Len() {
 		return nil, errors.New("tuple index out of bounds")
 	}
-	return tv.contentsArr[idx], nil
+	return tv.contents[idx], nil
 }
 
 func (tv *TupleValue) TypeCode() uint8 {
-	return TypeCodeTuple + byte(tv.itemCount)
+	return TypeCodeTuple + byte(len(tv.contents))
 }
 
 func (tv *TupleValue) Equal(val Value) bool {
@@ -106,7 +125,7 @@ func (tv *TupleValue) Equal(val Value) bool {
 		return false
 	}
 	for i, val := range tv.Contents() {
-		if !Eq(val, tup.contentsArr[i]) {
+		if !Eq(val, tup.contents[i]) {
 			return false
 		}
 	}
@@ -125,6 +144,15 @@ func (tv *TupleValue) Size() int64 {
 	return tv.size
 }
 
+func (tv *TupleValue) Marshal(w io.Writer) error {
+	for _, val := range tv.Contents() {
+		if err := MarshalValue(val, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (tv *TupleValue) String() string {
 	var buf bytes.Buffer
 	buf.WriteString("Tuple(")
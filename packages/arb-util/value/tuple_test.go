@@ -0,0 +1,130 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package value
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestTupleFromSlice(t *testing.T) {
+	slice := []Value{NewInt64Value(1), NewInt64Value(2)}
+	copied, err := NewTupleFromSlice(slice)
+	test.FailIfError(t, err)
+	owned, err := NewTupleFromOwnedSlice(slice)
+	test.FailIfError(t, err)
+	if !copied.Equal(owned) || owned.Size() != 3 {
+		t.Fatal("tuples built from the same slice differ")
+	}
+	slice[0] = NewInt64Value(3)
+	if !copied.contents[0].Equal(NewInt64Value(1)) {
+		t.Error("copied tuple shares its slice")
+	}
+	if !owned.contents[0].Equal(NewInt64Value(3)) {
+		t.Error("owned tuple copied its slice")
+	}
+
+	_, err = NewTupleFromOwnedSlice(make([]Value, MaxTupleSize+1))
+	if err == nil {
+		t.Error("created oversized tuple")
+	}
+	empty, err := NewTupleFromOwnedSlice(nil)
+	test.FailIfError(t, err)
+	if !empty.Equal(NewEmptyTuple()) || empty.Size() != 1 {
+		t.Error("wrong empty tuple")
+	}
+}
+
+func BenchmarkTupleConstruction(b *testing.B) {
+	b.Run("Copied", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			contents := make([]Value, MaxTupleSize)
+			for j := range contents {
+				contents[j] = NewInt64Value(int64(j))
+			}
+			if _, err := NewTupleFromSlice(contents); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Owned", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			contents := make([]Value, MaxTupleSize)
+			for j := range contents {
+				contents[j] = NewInt64Value(int64(j))
+			}
+			if _, err := NewTupleFromOwnedSlice(contents); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestTupleArena(t *testing.T) {
+	// A small slab size makes the list span several slabs
+	arena := NewTupleArena(4)
+	list := tupleList(20)
+	arenaList := NewEmptyTuple()
+	for i := 0; i < 20; i++ {
+		arenaList = arena.NewTuple2(NewInt64Value(int64(i)), arenaList)
+	}
+	if !list.Equal(arenaList) || list.Size() != arenaList.Size() {
+		t.Fatal("tuples built in arena differ")
+	}
+
+	data, err := MarshalValueToBytes(list)
+	test.FailIfError(t, err)
+	decoded, err := arena.UnmarshalValue(bytes.NewReader(data))
+	test.FailIfError(t, err)
+	if !decoded.Equal(list) {
+		t.Error("value unmarshalled in arena differs")
+	}
+	h1, err := list.Hash()
+	test.FailIfError(t, err)
+	h2, err := decoded.(*TupleValue).Hash()
+	test.FailIfError(t, err)
+	if h1 != h2 {
+		t.Error("value unmarshalled in arena hashes differently")
+	}
+}
+
+func BenchmarkUnmarshalTupleList(b *testing.B) {
+	data, err := MarshalValueToBytes(tupleList(1000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("Heap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := UnmarshalValue(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Arena", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := NewTupleArena(0).UnmarshalValue(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
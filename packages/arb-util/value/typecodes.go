@@ -0,0 +1,36 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Code generated by gentypecodes.go from encoding.json. DO NOT EDIT.
+
+package value
+
+const (
+	// 32 byte big endian unsigned integer
+	TypeCodeInt uint8 = 0
+	// 1 byte immediate flag, 1 byte opcode, the immediate value if the flag is 1, 32 byte next hash
+	TypeCodeCodePoint uint8 = 1
+	// 32 byte preimage hash, 32 byte big endian size
+	TypeCodeHashPreImage uint8 = 2
+	// type code is 3 plus the number of values, followed by each value
+	TypeCodeTuple uint8 = 3
+	// 8 byte big endian length, followed by that many bytes
+	TypeCodeBuffer uint8 = 12
+	// 8 byte big endian pc, 32 byte hash; only passed between the AVM and the validator
+	TypeCodeCodePointStub uint8 = 13
+)
+
+const MaxTupleSize = 8
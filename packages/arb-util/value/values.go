@@ -20,18 +20,15 @@ import (
 	"io"
 )
 
-const (
-	TypeCodeInt           uint8 = 0
-	TypeCodeCodePoint     uint8 = 1
-	TypeCodeHashPreImage  uint8 = 2
-	TypeCodeTuple         uint8 = 3
-	TypeCodeBuffer        uint8 = 12
-	TypeCodeCodePointStub uint8 = 13
-)
+// The type codes are shared with the AVM and the bridge contracts, and are
+// generated from encoding.json
+//go:generate go run gentypecodes.go
 
 type Value interface {
 	TypeCode() uint8
 	Equal(Value) bool
+	// Size is the number of values this value is made of, as the AVM counts
+	// them. See MarshaledSize for the size of its serialization.
 	Size() int64
 	String() string
 }
@@ -49,6 +46,10 @@ func (e UnmarshalError) Error() string {
 }
 
 func UnmarshalValueWithType(tipe byte, r io.Reader) (Value, error) {
+	return unmarshalValueWithType(tipe, r, nil)
+}
+
+func unmarshalValueWithType(tipe byte, r io.Reader, arena *TupleArena) (Value, error) {
 	switch {
 	case tipe == TypeCodeInt:
 		return NewIntValueFromReader(r)
@@ -57,7 +58,7 @@ func UnmarshalValueWithType(tipe byte, r io.Reader) (Value, error) {
 	case tipe == TypeCodeHashPreImage:
 		return NewHashPreImageFromReader(r)
 	case tipe <= TypeCodeTuple+MaxTupleSize:
-		return NewSizedTupleFromReader(r, tipe-TypeCodeTuple)
+		return newSizedTupleFromReader(r, tipe-TypeCodeTuple, arena)
 	case tipe == TypeCodeBuffer:
 		return NewBufferFromReader(r)
 	case tipe == TypeCodeCodePointStub:
@@ -68,10 +69,14 @@ func UnmarshalValueWithType(tipe byte, r io.Reader) (Value, error) {
 }
 
 func UnmarshalValue(r io.Reader) (Value, error) {
+	return unmarshalValue(r, nil)
+}
+
+func unmarshalValue(r io.Reader, arena *TupleArena) (Value, error) {
 	tipe := make([]byte, 1)
 	_, err := io.ReadFull(r, tipe)
 	if err != nil {
 		return nil, err
 	}
-	return UnmarshalValueWithType(tipe[0], r)
+	return unmarshalValueWithType(tipe[0], r, arena)
 }